@@ -0,0 +1,264 @@
+package adjustments
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"agromart2/internal/auth"
+	"agromart2/internal/database"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	service *AdjustmentService
+}
+
+func NewHandler(service *AdjustmentService) *Handler {
+	return &Handler{service: service}
+}
+
+// CreateAdjustment creates a draft stock adjustment with line items
+func (h *Handler) CreateAdjustment(c echo.Context) error {
+	var req AdjustmentRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if req.LocationID == uuid.Nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "location_id is required")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	userID, err := uuid.Parse(c.Get("user_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid user ID")
+	}
+
+	items := make([]LineItemParams, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, LineItemParams{
+			ProductID:      item.ProductID,
+			BatchID:        item.BatchID,
+			QuantityChange: item.QuantityChange,
+			ReasonCode:     strings.ToUpper(item.ReasonCode),
+			Notes:          item.Notes,
+		})
+	}
+
+	adjustment, err := h.service.CreateAdjustment(c.Request().Context(), CreateAdjustmentParams{
+		TenantID:         tenantID,
+		AdjustmentNumber: req.AdjustmentNumber,
+		LocationID:       req.LocationID,
+		Notes:            req.Notes,
+		CreatedBy:        userID,
+		Items:            items,
+	})
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    adjustment,
+		"message": "Stock adjustment created successfully",
+	})
+}
+
+// GetAdjustment retrieves a stock adjustment with its line items
+func (h *Handler) GetAdjustment(c echo.Context) error {
+	adjustmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid stock adjustment ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	adjustment, err := h.service.GetAdjustment(c.Request().Context(), adjustmentID, tenantID)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    adjustment,
+	})
+}
+
+// ListAdjustments lists stock adjustments with optional status/location filters
+func (h *Handler) ListAdjustments(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	var locationID *uuid.UUID
+	if locationIDStr := c.QueryParam("location_id"); locationIDStr != "" {
+		id, err := uuid.Parse(locationIDStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid location ID")
+		}
+		locationID = &id
+	}
+
+	status := strings.ToUpper(c.QueryParam("status"))
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offset := int32((page - 1) * limit)
+
+	adjustments, err := h.service.ListAdjustments(c.Request().Context(), tenantID, status, locationID, int32(limit), offset)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    adjustments,
+		"pagination": map[string]interface{}{
+			"page":  page,
+			"limit": limit,
+		},
+	})
+}
+
+// ApproveAdjustment approves a draft stock adjustment
+func (h *Handler) ApproveAdjustment(c echo.Context) error {
+	adjustmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid stock adjustment ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	userID, err := uuid.Parse(c.Get("user_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid user ID")
+	}
+
+	adjustment, err := h.service.ApproveAdjustment(c.Request().Context(), adjustmentID, tenantID, userID,
+		auth.Granted(c, auth.PermInventoryApprove))
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    adjustment,
+		"message": "Stock adjustment approved successfully",
+	})
+}
+
+// PostAdjustment applies an approved stock adjustment to inventory
+func (h *Handler) PostAdjustment(c echo.Context) error {
+	adjustmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid stock adjustment ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	adjustment, err := h.service.PostAdjustment(c.Request().Context(), adjustmentID, tenantID)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    adjustment,
+		"message": "Stock adjustment posted successfully",
+	})
+}
+
+// CancelAdjustment cancels a stock adjustment that has not been posted
+func (h *Handler) CancelAdjustment(c echo.Context) error {
+	adjustmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid stock adjustment ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	adjustment, err := h.service.CancelAdjustment(c.Request().Context(), adjustmentID, tenantID)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    adjustment,
+		"message": "Stock adjustment cancelled successfully",
+	})
+}
+
+// RegisterRoutes registers all stock adjustment routes
+func (h *Handler) RegisterRoutes(g *echo.Group) {
+	g.POST("/adjustments", h.CreateAdjustment, auth.RequirePermission(auth.PermInventoryAdjust))
+	g.GET("/adjustments", h.ListAdjustments, auth.RequirePermission(auth.PermInventoryView))
+	g.GET("/adjustments/:id", h.GetAdjustment, auth.RequirePermission(auth.PermInventoryView))
+	g.POST("/adjustments/:id/approve", h.ApproveAdjustment, auth.RequirePermission(auth.PermInventoryAdjust))
+	g.POST("/adjustments/:id/post", h.PostAdjustment, auth.RequirePermission(auth.PermInventoryAdjust))
+	g.POST("/adjustments/:id/cancel", h.CancelAdjustment, auth.RequirePermission(auth.PermInventoryAdjust))
+}
+
+// toHTTPError maps service errors to HTTP errors
+func toHTTPError(err error) error {
+	switch {
+	case database.IsNotFound(err):
+		return echo.NewHTTPError(http.StatusNotFound, "stock adjustment not found")
+	case errors.Is(err, ErrApprovalRequired):
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	case errors.Is(err, ErrInvalidStatusTransition), errors.Is(err, ErrInsufficientStock):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, ErrNoLineItems), errors.Is(err, ErrInvalidLineItem), errors.Is(err, ErrBatchMismatch):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case database.IsDuplicateKey(err):
+		return echo.NewHTTPError(http.StatusConflict, "adjustment number already exists")
+	case database.IsForeignKeyViolation(err):
+		return echo.NewHTTPError(http.StatusBadRequest, "referenced location, product or batch does not exist")
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// Request types
+type AdjustmentRequest struct {
+	AdjustmentNumber string            `json:"adjustment_number"`
+	LocationID       uuid.UUID         `json:"location_id" validate:"required"`
+	Notes            string            `json:"notes"`
+	Items            []LineItemRequest `json:"items" validate:"required,min=1"`
+}
+
+type LineItemRequest struct {
+	ProductID      uuid.UUID `json:"product_id" validate:"required"`
+	BatchID        uuid.UUID `json:"batch_id" validate:"required"`
+	QuantityChange int       `json:"quantity_change" validate:"required"`
+	ReasonCode     string    `json:"reason_code" validate:"required"`
+	Notes          string    `json:"notes"`
+}
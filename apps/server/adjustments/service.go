@@ -0,0 +1,383 @@
+package adjustments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"agromart2/apps/server/inventory"
+	"agromart2/db"
+	"agromart2/internal/database"
+	"agromart2/internal/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrInvalidStatusTransition = errors.New("invalid stock adjustment status transition")
+	ErrNoLineItems             = errors.New("stock adjustment must have at least one line item")
+	ErrInvalidLineItem         = errors.New("line item requires a product, batch, non-zero quantity change and reason code")
+	ErrBatchMismatch           = errors.New("batch does not exist or belongs to another product")
+	ErrApprovalRequired        = errors.New("stock adjustment value exceeds the approval threshold and needs a manager to approve")
+	ErrInsufficientStock       = inventory.ErrInsufficientStock
+)
+
+type AdjustmentService struct {
+	db *pgxpool.Pool
+	q  *db.Queries
+	// approvalThreshold is the value above which approving needs inventory.approve
+	approvalThreshold float64
+}
+
+func NewAdjustmentService(db *pgxpool.Pool, queries *db.Queries, approvalThreshold float64) *AdjustmentService {
+	return &AdjustmentService{
+		db:                db,
+		q:                 queries,
+		approvalThreshold: approvalThreshold,
+	}
+}
+
+type LineItemParams struct {
+	ProductID uuid.UUID
+	BatchID   uuid.UUID
+	// QuantityChange is added to stock: negative writes stock off, positive finds it
+	QuantityChange int
+	ReasonCode     string
+	Notes          string
+}
+
+type CreateAdjustmentParams struct {
+	TenantID         uuid.UUID
+	AdjustmentNumber string
+	LocationID       uuid.UUID
+	Notes            string
+	CreatedBy        uuid.UUID
+	Items            []LineItemParams
+}
+
+// AdjustmentWithItems is a stock adjustment together with its line items
+type AdjustmentWithItems struct {
+	db.StockAdjustment
+	Items []db.StockAdjustmentItem `json:"items"`
+}
+
+// CreateAdjustment creates a draft stock adjustment with its line items
+func (s *AdjustmentService) CreateAdjustment(ctx context.Context, params CreateAdjustmentParams) (*AdjustmentWithItems, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	created, err := s.create(ctx, s.q.WithTx(tx), params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return created, nil
+}
+
+// CreatePosted creates, approves and posts an adjustment within the caller's transaction,
+// for documents such as stock counts whose review stands in for the adjustment's own
+func (s *AdjustmentService) CreatePosted(ctx context.Context, qtx *db.Queries, params CreateAdjustmentParams, approverID uuid.UUID, canApproveAboveThreshold bool) (*AdjustmentWithItems, error) {
+	created, err := s.create(ctx, qtx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	approved, err := s.approve(ctx, qtx, created.StockAdjustment, approverID, canApproveAboveThreshold)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.post(ctx, qtx, approved)
+}
+
+// create validates and inserts a draft adjustment and its lines
+func (s *AdjustmentService) create(ctx context.Context, qtx *db.Queries, params CreateAdjustmentParams) (*AdjustmentWithItems, error) {
+	if len(params.Items) == 0 {
+		return nil, ErrNoLineItems
+	}
+	for _, item := range params.Items {
+		if item.ProductID == uuid.Nil || item.BatchID == uuid.Nil || item.QuantityChange == 0 || !ValidReason(item.ReasonCode) {
+			return nil, ErrInvalidLineItem
+		}
+	}
+
+	adjustmentNumber := params.AdjustmentNumber
+	if adjustmentNumber == "" {
+		adjustmentNumber = generateAdjustmentNumber()
+	}
+
+	adjustment, err := qtx.CreateStockAdjustment(ctx, db.CreateStockAdjustmentParams{
+		TenantID:         params.TenantID,
+		AdjustmentNumber: adjustmentNumber,
+		LocationID:       params.LocationID,
+		Notes:            utils.P.Text(params.Notes),
+		CreatedBy:        utils.P.UUID(params.CreatedBy),
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("failed to create stock adjustment")
+		return nil, database.WrapError(err, "failed to create stock adjustment")
+	}
+
+	items := make([]db.StockAdjustmentItem, 0, len(params.Items))
+	for _, item := range params.Items {
+		batch, err := qtx.GetBatchByID(ctx, db.GetBatchByIDParams{ID: item.BatchID, TenantID: params.TenantID})
+		if err != nil && !database.IsNotFound(err) {
+			return nil, database.WrapError(err, "failed to get batch")
+		}
+		if err != nil || batch.ProductID != item.ProductID {
+			return nil, fmt.Errorf("%w: batch %s", ErrBatchMismatch, item.BatchID)
+		}
+
+		created, err := qtx.CreateStockAdjustmentItem(ctx, db.CreateStockAdjustmentItemParams{
+			TenantID:          params.TenantID,
+			StockAdjustmentID: adjustment.ID,
+			ProductID:         item.ProductID,
+			BatchID:           item.BatchID,
+			QuantityChange:    utils.P.Numeric(item.QuantityChange),
+			ReasonCode:        item.ReasonCode,
+			Notes:             utils.P.Text(item.Notes),
+		})
+		if err != nil {
+			return nil, database.WrapError(err, "failed to create stock adjustment item")
+		}
+		items = append(items, created)
+	}
+
+	return &AdjustmentWithItems{StockAdjustment: adjustment, Items: items}, nil
+}
+
+// GetAdjustment retrieves a stock adjustment and its line items
+func (s *AdjustmentService) GetAdjustment(ctx context.Context, id, tenantID uuid.UUID) (*AdjustmentWithItems, error) {
+	adjustment, err := s.q.GetStockAdjustment(ctx, db.GetStockAdjustmentParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get stock adjustment")
+	}
+
+	items, err := s.q.GetStockAdjustmentItems(ctx, db.GetStockAdjustmentItemsParams{
+		StockAdjustmentID: id,
+		TenantID:          tenantID,
+	})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get stock adjustment items")
+	}
+
+	return &AdjustmentWithItems{StockAdjustment: adjustment, Items: items}, nil
+}
+
+// ListAdjustments lists stock adjustments, optionally filtered by status or location
+func (s *AdjustmentService) ListAdjustments(ctx context.Context, tenantID uuid.UUID, status string, locationID *uuid.UUID, limit, offset int32) ([]db.StockAdjustment, error) {
+	adjustments, err := s.q.ListStockAdjustments(ctx, db.ListStockAdjustmentsParams{
+		TenantID:   tenantID,
+		Status:     utils.P.Text(status),
+		LocationID: utils.P.UUIDPtr(locationID),
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("failed to list stock adjustments")
+		return []db.StockAdjustment{}, fmt.Errorf("failed to list stock adjustments: %w", err)
+	}
+
+	return adjustments, nil
+}
+
+// ApproveAdjustment approves a draft adjustment, recording its value at batch cost.
+// Adjustments worth more than the approval threshold can only be approved by a caller
+// holding inventory.approve, which canApproveAboveThreshold reports.
+func (s *AdjustmentService) ApproveAdjustment(ctx context.Context, id, tenantID, approverID uuid.UUID, canApproveAboveThreshold bool) (*AdjustmentWithItems, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	adjustment, err := qtx.GetStockAdjustmentForUpdate(ctx, db.GetStockAdjustmentForUpdateParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get stock adjustment")
+	}
+
+	if _, err := s.approve(ctx, qtx, adjustment, approverID, canApproveAboveThreshold); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return s.GetAdjustment(ctx, id, tenantID)
+}
+
+// approve values a draft adjustment and marks it approved, enforcing the approval threshold
+func (s *AdjustmentService) approve(ctx context.Context, qtx *db.Queries, adjustment db.StockAdjustment, approverID uuid.UUID, canApproveAboveThreshold bool) (db.StockAdjustment, error) {
+	if !CanTransition(adjustment.Status, StatusApproved) {
+		return db.StockAdjustment{}, fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, adjustment.Status, StatusApproved)
+	}
+
+	value, err := qtx.GetStockAdjustmentValue(ctx, db.GetStockAdjustmentValueParams{
+		StockAdjustmentID: adjustment.ID,
+		TenantID:          adjustment.TenantID,
+	})
+	if err != nil {
+		return db.StockAdjustment{}, database.WrapError(err, "failed to value stock adjustment")
+	}
+	if total := utils.PgNumericToFloat64(value); total > s.approvalThreshold && !canApproveAboveThreshold {
+		return db.StockAdjustment{}, fmt.Errorf("%w: value %.2f, threshold %.2f", ErrApprovalRequired, total, s.approvalThreshold)
+	}
+
+	approved, err := qtx.ApproveStockAdjustment(ctx, db.ApproveStockAdjustmentParams{
+		ID:         adjustment.ID,
+		TenantID:   adjustment.TenantID,
+		ApprovedBy: utils.P.UUID(approverID),
+		TotalValue: value,
+	})
+	if err != nil {
+		return db.StockAdjustment{}, database.WrapError(err, "failed to approve stock adjustment")
+	}
+
+	return approved, nil
+}
+
+// PostAdjustment applies an approved adjustment to inventory at its location
+func (s *AdjustmentService) PostAdjustment(ctx context.Context, id, tenantID uuid.UUID) (*AdjustmentWithItems, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	adjustment, err := qtx.GetStockAdjustmentForUpdate(ctx, db.GetStockAdjustmentForUpdateParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get stock adjustment")
+	}
+
+	posted, err := s.post(ctx, qtx, adjustment)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return posted, nil
+}
+
+// post changes inventory for each line and logs it as an ADJUSTMENT with its signed
+// quantity change. Write-offs are checked against the batch's stock under a row lock.
+func (s *AdjustmentService) post(ctx context.Context, qtx *db.Queries, adjustment db.StockAdjustment) (*AdjustmentWithItems, error) {
+	id, tenantID := adjustment.ID, adjustment.TenantID
+
+	if !CanTransition(adjustment.Status, StatusPosted) {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, adjustment.Status, StatusPosted)
+	}
+
+	items, err := qtx.GetStockAdjustmentItems(ctx, db.GetStockAdjustmentItemsParams{StockAdjustmentID: id, TenantID: tenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get stock adjustment items")
+	}
+
+	// Lock rows in a stable order so concurrent postings cannot deadlock
+	ordered := make([]db.StockAdjustmentItem, len(items))
+	copy(ordered, items)
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].BatchID.String() < ordered[j].BatchID.String() })
+
+	for _, item := range ordered {
+		change := utils.PgNumericToInt(item.QuantityChange)
+
+		if change < 0 {
+			if _, err := inventory.Decrement(ctx, qtx, tenantID, adjustment.LocationID, item.ProductID, item.BatchID, -change); err != nil {
+				return nil, err
+			}
+		} else {
+			if err := qtx.AddInventoryQuantity(ctx, db.AddInventoryQuantityParams{
+				TenantID:   tenantID,
+				ProductID:  item.ProductID,
+				BatchID:    item.BatchID,
+				Quantity:   item.QuantityChange,
+				LocationID: adjustment.LocationID,
+			}); err != nil {
+				return nil, database.WrapError(err, "failed to add inventory")
+			}
+		}
+
+		if err := qtx.CreateInventoryLog(ctx, db.CreateInventoryLogParams{
+			TenantID:        tenantID,
+			ProductID:       item.ProductID,
+			BatchID:         item.BatchID,
+			LocationID:      adjustment.LocationID,
+			TransactionType: inventory.TxAdjustment,
+			QuantityChange:  item.QuantityChange,
+			ReferenceID:     utils.P.UUID(adjustment.ID),
+			Notes:           utils.P.Text(fmt.Sprintf("%s on %s", item.ReasonCode, adjustment.AdjustmentNumber)),
+		}); err != nil {
+			return nil, database.WrapError(err, "failed to log adjustment")
+		}
+	}
+
+	if _, err := s.transition(ctx, qtx, adjustment, StatusPosted); err != nil {
+		return nil, err
+	}
+	if err := qtx.SetStockAdjustmentPosted(ctx, db.SetStockAdjustmentPostedParams{ID: id, TenantID: tenantID}); err != nil {
+		return nil, database.WrapError(err, "failed to set posting time")
+	}
+
+	adjustment, err = qtx.GetStockAdjustment(ctx, db.GetStockAdjustmentParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to reload stock adjustment")
+	}
+
+	return &AdjustmentWithItems{StockAdjustment: adjustment, Items: items}, nil
+}
+
+// CancelAdjustment cancels an adjustment that has not been posted
+func (s *AdjustmentService) CancelAdjustment(ctx context.Context, id, tenantID uuid.UUID) (db.StockAdjustment, error) {
+	adjustment, err := s.q.GetStockAdjustment(ctx, db.GetStockAdjustmentParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return db.StockAdjustment{}, database.WrapError(err, "failed to get stock adjustment")
+	}
+
+	return s.transition(ctx, s.q, adjustment, StatusCancelled)
+}
+
+// transition moves a stock adjustment to a new status if the transition table allows it.
+// The update is guarded on the current status so concurrent transitions cannot both win.
+func (s *AdjustmentService) transition(ctx context.Context, q *db.Queries, current db.StockAdjustment, to string) (db.StockAdjustment, error) {
+	if !CanTransition(current.Status, to) {
+		return db.StockAdjustment{}, fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, current.Status, to)
+	}
+
+	adjustment, err := q.TransitionStockAdjustmentStatus(ctx, db.TransitionStockAdjustmentStatusParams{
+		NewStatus:     to,
+		ID:            current.ID,
+		TenantID:      current.TenantID,
+		CurrentStatus: current.Status,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.StockAdjustment{}, fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, current.Status, to)
+		}
+		return db.StockAdjustment{}, database.WrapError(err, "failed to update stock adjustment status")
+	}
+
+	return adjustment, nil
+}
+
+func generateAdjustmentNumber() string {
+	return fmt.Sprintf("ADJ-%s-%s", time.Now().Format("20060102"), strings.ToUpper(uuid.NewString()[:8]))
+}
@@ -0,0 +1,48 @@
+package adjustments
+
+// Stock adjustment statuses as stored in stock_adjustments.status
+const (
+	StatusDraft     = "DRAFT"
+	StatusApproved  = "APPROVED"
+	StatusPosted    = "POSTED"
+	StatusCancelled = "CANCELLED"
+)
+
+// Reason codes a stock adjustment line may carry
+const (
+	ReasonDamage          = "DAMAGE"
+	ReasonTheft           = "THEFT"
+	ReasonExpired         = "EXPIRED"
+	ReasonCountCorrection = "COUNT_CORRECTION"
+	ReasonSample          = "SAMPLE"
+	ReasonOther           = "OTHER"
+)
+
+// ReasonCodes lists every valid reason code
+var ReasonCodes = []string{ReasonDamage, ReasonTheft, ReasonExpired, ReasonCountCorrection, ReasonSample, ReasonOther}
+
+// transitions lists the statuses each status may move to
+var transitions = map[string][]string{
+	StatusDraft:    {StatusApproved, StatusCancelled},
+	StatusApproved: {StatusPosted, StatusCancelled},
+}
+
+// CanTransition reports whether a stock adjustment may move from one status to another
+func CanTransition(from, to string) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidReason reports whether code is a known reason code
+func ValidReason(code string) bool {
+	for _, r := range ReasonCodes {
+		if r == code {
+			return true
+		}
+	}
+	return false
+}
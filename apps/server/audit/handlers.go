@@ -0,0 +1,118 @@
+package audit
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"agromart2/internal/auth"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	service *AuditService
+}
+
+func NewHandler(service *AuditService) *Handler {
+	return &Handler{service: service}
+}
+
+// ListEntries lists the tenant's audit trail with optional ?entity_type=, ?entity_id=,
+// ?action=, ?actor_id=, ?impersonator_id=, ?request_id=, ?since= and ?until= (RFC 3339)
+// filters
+func (h *Handler) ListEntries(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	filter := Filter{
+		EntityType: c.QueryParam("entity_type"),
+		Action:     c.QueryParam("action"),
+		RequestID:  c.QueryParam("request_id"),
+	}
+	if filter.EntityID, err = uuidParam(c, "entity_id"); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid entity ID")
+	}
+	if filter.ActorUserID, err = uuidParam(c, "actor_id"); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid actor ID")
+	}
+	if filter.ImpersonatorID, err = uuidParam(c, "impersonator_id"); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid impersonator ID")
+	}
+	if filter.Since, err = timeParam(c, "since"); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "since must be an RFC 3339 timestamp")
+	}
+	if filter.Until, err = timeParam(c, "until"); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "until must be an RFC 3339 timestamp")
+	}
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offset := int32((page - 1) * limit)
+
+	entries, err := h.service.ListEntries(c.Request().Context(), tenantID, filter, int32(limit), offset)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    entries,
+		"pagination": map[string]interface{}{
+			"page":  page,
+			"limit": limit,
+		},
+	})
+}
+
+// RegisterRoutes registers audit trail routes
+func (h *Handler) RegisterRoutes(g *echo.Group) {
+	g.GET("/audit", h.ListEntries, auth.RequirePermission(auth.PermAuditView))
+}
+
+// toHTTPError maps service errors to HTTP errors
+func toHTTPError(err error) error {
+	switch {
+	case errors.Is(err, ErrInvalidEntityType), errors.Is(err, ErrInvalidAction):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// uuidParam parses an optional UUID query parameter
+func uuidParam(c echo.Context, name string) (*uuid.UUID, error) {
+	s := c.QueryParam(name)
+	if s == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// timeParam parses an optional RFC 3339 query parameter
+func timeParam(c echo.Context, name string) (*time.Time, error) {
+	s := c.QueryParam(name)
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
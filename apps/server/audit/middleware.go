@@ -0,0 +1,21 @@
+package audit
+
+import (
+	"agromart2/internal/database"
+	"github.com/labstack/echo/v4"
+)
+
+// RequestContext ties the changes a request makes to its request ID and client IP in the
+// audit trail; RequireAuth adds the actor. It must run after echo's RequestID middleware.
+// The IP comes from the server's IPExtractor, so forwarding headers only count when a
+// trusted proxy set them.
+func RequestContext(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := database.WithRequestInfo(c.Request().Context(), database.RequestInfo{
+			RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
+			IPAddress: c.RealIP(),
+		})
+		c.SetRequest(c.Request().WithContext(ctx))
+		return next(c)
+	}
+}
@@ -0,0 +1,73 @@
+package audit_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"agromart2/apps/server/audit"
+	"agromart2/apps/server/config"
+	"agromart2/internal/database"
+	"github.com/labstack/echo/v4"
+)
+
+func TestRequestContextIgnoresSpoofedForwardedFor(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies string
+		remoteAddr     string
+		forwardedFor   string
+		want           string
+	}{
+		{
+			name:         "direct client cannot claim another address",
+			remoteAddr:   "203.0.113.7:51000",
+			forwardedFor: "198.51.100.1",
+			want:         "203.0.113.7",
+		},
+		{
+			name:           "untrusted peer cannot claim another address",
+			trustedProxies: "10.0.0.0/8",
+			remoteAddr:     "203.0.113.7:51000",
+			forwardedFor:   "198.51.100.1",
+			want:           "203.0.113.7",
+		},
+		{
+			name:           "trusted proxy reports the client it saw, not what the client sent",
+			trustedProxies: "10.0.0.0/8",
+			remoteAddr:     "10.0.0.2:51000",
+			forwardedFor:   "198.51.100.1, 203.0.113.7",
+			want:           "203.0.113.7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := &config.Config{TrustedProxies: tt.trustedProxies}
+			extractor, err := conf.IPExtractor()
+			if err != nil {
+				t.Fatalf("failed to build IP extractor: %v", err)
+			}
+
+			e := echo.New()
+			e.IPExtractor = extractor
+
+			var got string
+			e.Use(audit.RequestContext)
+			e.GET("/", func(c echo.Context) error {
+				got = database.RequestInfoFromContext(c.Request().Context()).IPAddress
+				return c.NoContent(http.StatusNoContent)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set(echo.HeaderXForwardedFor, tt.forwardedFor)
+			req.Header.Set(echo.HeaderXRealIP, "198.51.100.1")
+			e.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.want {
+				t.Fatalf("audit IP is %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
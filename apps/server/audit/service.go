@@ -0,0 +1,147 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"agromart2/db"
+	"agromart2/internal/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Actions as stored in audit_log.action
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// EntityTypes lists the audited entities, as passed to the audit_row_change triggers
+var EntityTypes = []string{
+	"product", "unit", "batch", "inventory", "supplier", "customer", "location",
+	"purchase_order", "purchase_order_item", "sales_order", "sales_order_item",
+	"transfer_order", "transfer_order_item", "stock_adjustment", "stock_adjustment_item",
+	"stock_count", "stock_count_entry", "inventory_reconciliation", "user", "membership",
+}
+
+var (
+	ErrInvalidEntityType = errors.New("invalid entity type")
+	ErrInvalidAction     = errors.New("action must be one of create, update, delete")
+)
+
+// AuditService reads the audit trail. Nothing in the application writes it: the
+// audit_row_change triggers record every change together with the actor, impersonator,
+// request ID and IP the connection was checked out with.
+type AuditService struct {
+	db *pgxpool.Pool
+	q  *db.Queries
+}
+
+func NewAuditService(db *pgxpool.Pool, queries *db.Queries) *AuditService {
+	return &AuditService{
+		db: db,
+		q:  queries,
+	}
+}
+
+// Entry is one recorded change. Before and After hold the whole row for creates and
+// deletes and only the changed columns for updates.
+type Entry struct {
+	ID          uuid.UUID  `json:"id"`
+	ActorUserID *uuid.UUID `json:"actor_user_id"`
+	APIKeyID    *uuid.UUID `json:"api_key_id"`
+	// ImpersonatorID is the platform super admin who made the change as ActorUserID
+	ImpersonatorID *uuid.UUID      `json:"impersonator_id"`
+	EntityType     string          `json:"entity_type"`
+	EntityID       uuid.UUID       `json:"entity_id"`
+	Action         string          `json:"action"`
+	Before         json.RawMessage `json:"before"`
+	After          json.RawMessage `json:"after"`
+	RequestID      string          `json:"request_id"`
+	IPAddress      string          `json:"ip_address"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+func newEntry(l db.AuditLog) Entry {
+	entry := Entry{
+		ID:             l.ID,
+		ActorUserID:    uuidPtr(l.ActorUserID),
+		APIKeyID:       uuidPtr(l.ApiKeyID),
+		ImpersonatorID: uuidPtr(l.ImpersonatorID),
+		EntityType:     l.EntityType,
+		EntityID:       l.EntityID,
+		Action:         l.Action,
+		RequestID:      l.RequestID.String,
+		IPAddress:      l.IpAddress.String,
+		CreatedAt:      l.CreatedAt,
+	}
+	if l.Before != nil {
+		entry.Before = json.RawMessage(l.Before)
+	}
+	if l.After != nil {
+		entry.After = json.RawMessage(l.After)
+	}
+	return entry
+}
+
+// Filter narrows ListEntries; zero values match everything
+type Filter struct {
+	EntityType     string
+	EntityID       *uuid.UUID
+	Action         string
+	ActorUserID    *uuid.UUID
+	ImpersonatorID *uuid.UUID
+	RequestID      string
+	Since          *time.Time
+	Until          *time.Time
+}
+
+// ListEntries lists the tenant's audit trail, newest first
+func (s *AuditService) ListEntries(ctx context.Context, tenantID uuid.UUID, filter Filter, limit, offset int32) ([]Entry, error) {
+	if filter.EntityType != "" && !slices.Contains(EntityTypes, filter.EntityType) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidEntityType, filter.EntityType)
+	}
+	switch filter.Action {
+	case "", ActionCreate, ActionUpdate, ActionDelete:
+	default:
+		return nil, ErrInvalidAction
+	}
+
+	logs, err := s.q.ListAuditLog(ctx, db.ListAuditLogParams{
+		TenantID:       tenantID,
+		EntityType:     utils.P.Text(filter.EntityType),
+		EntityID:       utils.P.UUIDPtr(filter.EntityID),
+		Action:         utils.P.Text(filter.Action),
+		ActorUserID:    utils.P.UUIDPtr(filter.ActorUserID),
+		ImpersonatorID: utils.P.UUIDPtr(filter.ImpersonatorID),
+		RequestID:      utils.P.Text(filter.RequestID),
+		Since:          utils.P.TimestamptzPtr(filter.Since),
+		Until:          utils.P.TimestamptzPtr(filter.Until),
+		Limit:          limit,
+		Offset:         offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit log: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(logs))
+	for _, l := range logs {
+		entries = append(entries, newEntry(l))
+	}
+	return entries, nil
+}
+
+// uuidPtr returns nil for a NULL UUID
+func uuidPtr(id pgtype.UUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	u := uuid.UUID(id.Bytes)
+	return &u
+}
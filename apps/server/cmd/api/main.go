@@ -8,15 +8,25 @@ import (
 	"syscall"
 	"time"
 
+	"agromart2/apps/server/adjustments"
+	"agromart2/apps/server/audit"
 	"agromart2/apps/server/config"
 	"agromart2/apps/server/customers"
 	"agromart2/apps/server/handler"
 	"agromart2/apps/server/inventory"
+	"agromart2/apps/server/locations"
 	"agromart2/apps/server/products"
+	"agromart2/apps/server/purchaseorders"
+	"agromart2/apps/server/reconciliation"
+	"agromart2/apps/server/reports"
+	"agromart2/apps/server/salesorders"
+	"agromart2/apps/server/stockcounts"
 	"agromart2/apps/server/suppliers"
+	"agromart2/apps/server/transfers"
 	"agromart2/db"
 	"agromart2/internal/auth"
 	"agromart2/internal/database"
+	"agromart2/internal/mailer"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/rs/zerolog/log"
@@ -31,6 +41,10 @@ func main() {
 		log.Fatal().Err(err).Msg("failed to load config")
 	}
 
+	if err := conf.Validate(); err != nil {
+		log.Fatal().Err(err).Msg("invalid configuration")
+	}
+
 	// Initialize database configuration
 	dbConfig := &database.Config{
 		Host:              conf.DB_Host,
@@ -44,6 +58,7 @@ func main() {
 		MaxConnLifetime:   conf.MaxConnLifeTime,
 		MaxConnIdleTime:   conf.MaxConnIdleTime,
 		HealthCheckPeriod: conf.HealthCheckPeriod,
+		AppRole:           conf.DB_AppRole,
 	}
 
 	if err := dbConfig.Validate(); err != nil {
@@ -69,22 +84,65 @@ func main() {
 	queries := db.New(dbPool)
 
 	// Initialize JWT service
-	jwtService := auth.NewJWTService(conf.JWTSecret)
+	jwtKeys, err := auth.NewKeySet(conf.JWTAlgorithm, conf.JWTSecret, conf.JWTKeysDir)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load JWT signing keys")
+	}
+	jwtService := auth.NewJWTService(jwtKeys)
+
+	// Initialize mailer
+	mail, err := mailer.New(mailer.Config{
+		Driver:   conf.MailDriver,
+		From:     conf.MailFrom,
+		Dir:      conf.MailDir,
+		Host:     conf.MailHost,
+		Port:     conf.MailPort,
+		Username: conf.MailUsername,
+		Password: conf.MailPassword,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to initialize mailer")
+	}
 
 	// Initialize services
-	authService := auth.NewAuthService(dbPool, queries, jwtService)
+	authService := auth.NewAuthService(dbPool, queries, jwtService, mail, conf.AppURL, conf.RequireEmailVerification)
+	userService := auth.NewUserService(dbPool, queries, mail, conf.AppURL)
 	productService := products.NewProductService(dbPool, queries)
 	inventoryService := inventory.NewService(dbPool, queries)
 	supplierService := suppliers.NewSupplierService(dbPool, queries)
 	customerService := customers.NewCustomerService(dbPool, queries)
+	purchaseOrderService := purchaseorders.NewPurchaseOrderService(dbPool, queries)
+	salesOrderService := salesorders.NewSalesOrderService(dbPool, queries)
+	transferService := transfers.NewTransferService(dbPool, queries)
+	adjustmentService := adjustments.NewAdjustmentService(dbPool, queries, conf.AdjustmentApprovalThreshold)
+	stockCountService := stockcounts.NewStockCountService(dbPool, queries, adjustmentService)
+	reconciliationService := reconciliation.NewReconciliationService(dbPool, queries)
+	locationService := locations.NewLocationService(dbPool, queries)
+	reportService := reports.NewReportService(dbPool, queries, inventoryService)
+	auditService := audit.NewAuditService(dbPool, queries)
+	platformService := auth.NewPlatformService(dbPool, queries, jwtService)
 
 	// Initialize handlers
 	authHandler := handler.NewAuthHandler(authService)
+	userHandler := handler.NewUserHandler(userService)
+	twoFactorHandler := handler.NewTwoFactorHandler(authService)
+	apiKeyHandler := handler.NewAPIKeyHandler(userService)
 	productHandler := products.NewHandler(productService)
 	inventoryHandler := inventory.NewHandler(inventoryService)
 	supplierHandler := suppliers.NewHandler(supplierService)
 	customerHandler := customers.NewHandler(customerService)
+	purchaseOrderHandler := purchaseorders.NewHandler(purchaseOrderService)
+	salesOrderHandler := salesorders.NewHandler(salesOrderService)
+	transferHandler := transfers.NewHandler(transferService)
+	adjustmentHandler := adjustments.NewHandler(adjustmentService)
+	stockCountHandler := stockcounts.NewHandler(stockCountService)
+	reconciliationHandler := reconciliation.NewHandler(reconciliationService)
+	locationHandler := locations.NewHandler(locationService)
+	reportHandler := reports.NewHandler(reportService)
+	auditHandler := audit.NewHandler(auditService)
+	platformHandler := handler.NewPlatformHandler(platformService)
 	healthHandler := handler.NewHealthHandler(dbService)
+	jwksHandler := handler.NewJWKSHandler(jwtService)
 
 	// Initialize middleware
 	authMiddleware := auth.NewMiddleware(authService)
@@ -92,16 +150,30 @@ func main() {
 	// Setup Echo server
 	e := echo.New()
 
+	// Client IPs feed login throttling and the audit trail, so only trusted proxies may set them
+	ipExtractor, err := conf.IPExtractor()
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid configuration")
+	}
+	e.IPExtractor = ipExtractor
+
 	// Add global middleware
+	e.Use(middleware.RequestID())
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
 	e.Use(middleware.CORS())
+	e.Use(audit.RequestContext)
 
 	// Setup health check routes
 	healthHandler.RegisterRoutes(e)
 
+	// Setup public key discovery for services verifying our tokens
+	jwksHandler.RegisterRoutes(e)
+
 	// Setup public auth routes
 	authHandler.RegisterRoutes(e)
+	userHandler.RegisterRoutes(e)
+	twoFactorHandler.RegisterRoutes(e)
 
 	// Setup API routes
 	api := e.Group("/api")
@@ -112,12 +184,35 @@ func main() {
 
 	// Auth protected routes
 	authHandler.RegisterProtectedRoutes(protected)
+	userHandler.RegisterProtectedRoutes(protected)
+	twoFactorHandler.RegisterProtectedRoutes(protected)
+	apiKeyHandler.RegisterRoutes(protected)
 
 	// Business logic routes
 	productHandler.RegisterRoutes(protected)
 	inventoryHandler.RegisterRoutes(protected)
 	supplierHandler.RegisterRoutes(protected)
 	customerHandler.RegisterRoutes(protected)
+	purchaseOrderHandler.RegisterRoutes(protected)
+	salesOrderHandler.RegisterRoutes(protected)
+	transferHandler.RegisterRoutes(protected)
+	adjustmentHandler.RegisterRoutes(protected)
+	stockCountHandler.RegisterRoutes(protected)
+	reconciliationHandler.RegisterRoutes(protected)
+	locationHandler.RegisterRoutes(protected)
+	reportHandler.RegisterRoutes(protected)
+	auditHandler.RegisterRoutes(protected)
+
+	// Platform super admin routes
+	platformHandler.RegisterRoutes(protected)
+
+	// Start background jobs
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	if conf.ReconciliationInterval > 0 {
+		go reconciliationService.RunScheduled(jobsCtx, conf.ReconciliationInterval)
+	}
 
 	// Start server
 	quit := make(chan os.Signal, 1)
@@ -132,6 +227,7 @@ func main() {
 
 	<-quit
 	log.Info().Msg("server shutting down")
+	stopJobs()
 
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -9,35 +9,80 @@ import (
 	"github.com/spf13/viper"
 )
 
+// defaultJWTSecrets are the placeholder secrets shipped in defaults and .env.example
+var defaultJWTSecrets = []string{
+	"your-secret-key-change-in-production",
+	"your-super-secret-jwt-key-change-in-production",
+	"your-super-secret-jwt-key-change-in-production-minimum-32-characters",
+	"shared-secret-key",
+}
+
 type Config struct {
+	AppEnv            string        `mapstructure:"APP_ENV"`
 	AppPort           int           `mapstructure:"APP_APPPORT"`
 	DB_Host           string        `mapstructure:"APP_DB_HOST"`
 	DB_Port           int           `mapstructure:"APP_DB_PORT"`
 	DB_User           string        `mapstructure:"APP_DB_USER"`
 	DB_Password       string        `mapstructure:"APP_DB_PASSWORD"`
 	DB_Name           string        `mapstructure:"APP_DB_NAME"`
+	DB_AppRole        string        `mapstructure:"APP_DB_ROLE"`
 	JWTSecret         string        `mapstructure:"JWT_SECRET"`
+	JWTAlgorithm      string        `mapstructure:"JWT_ALGORITHM"`
+	JWTKeysDir        string        `mapstructure:"JWT_KEYS_DIR"`
 	MaxConns          int           `mapstructure:"MAX_CONNS"`
 	MinConns          int           `mapstructure:"MIN_CONNS"`
 	MaxConnLifeTime   time.Duration `mapstructure:"MAX_CONN_LIFE_TIME"`
 	MaxConnIdleTime   time.Duration `mapstructure:"MAX_CONN_IDLE_TIME"`
 	HealthCheckPeriod time.Duration `mapstructure:"HEALTH_CHECK_PERIOD"`
+	AppURL            string        `mapstructure:"APP_URL"`
+	MailDriver        string        `mapstructure:"MAIL_DRIVER"`
+	MailFrom          string        `mapstructure:"MAIL_FROM"`
+	MailDir           string        `mapstructure:"MAIL_DIR"`
+	MailHost          string        `mapstructure:"MAIL_HOST"`
+	MailPort          int           `mapstructure:"MAIL_PORT"`
+	MailUsername      string        `mapstructure:"MAIL_USERNAME"`
+	MailPassword      string        `mapstructure:"MAIL_PASSWORD"`
+	// RequireEmailVerification blocks login until the user has verified their email
+	RequireEmailVerification bool `mapstructure:"REQUIRE_EMAIL_VERIFICATION"`
+	// AdjustmentApprovalThreshold is the stock adjustment value above which a manager must approve
+	AdjustmentApprovalThreshold float64 `mapstructure:"ADJUSTMENT_APPROVAL_THRESHOLD"`
+	// ReconciliationInterval is how often inventory is reconciled with the ledger; 0 disables it
+	ReconciliationInterval time.Duration `mapstructure:"RECONCILIATION_INTERVAL"`
+	// TrustedProxies lists the CIDR ranges of reverse proxies whose X-Forwarded-For is believed
+	TrustedProxies string `mapstructure:"TRUSTED_PROXIES"`
 }
 
 func LoadConfig() (*Config, error) {
 	// Set default values
+	// Fail closed: an unset APP_ENV is production, so development must be asked for explicitly
+	viper.SetDefault("APP_ENV", "production")
 	viper.SetDefault("APP_APPPORT", 8080)
 	viper.SetDefault("APP_DB_HOST", "localhost")
 	viper.SetDefault("APP_DB_PORT", 5432)
 	viper.SetDefault("APP_DB_USER", "postgres")
 	viper.SetDefault("APP_DB_PASSWORD", "password")
 	viper.SetDefault("APP_DB_NAME", "agromart")
+	viper.SetDefault("APP_DB_ROLE", "agromart_app")
 	viper.SetDefault("JWT_SECRET", "your-secret-key-change-in-production")
+	viper.SetDefault("JWT_ALGORITHM", "HS256")
+	viper.SetDefault("JWT_KEYS_DIR", "")
 	viper.SetDefault("MAX_CONNS", 25)
 	viper.SetDefault("MIN_CONNS", 5)
 	viper.SetDefault("MAX_CONN_LIFE_TIME", "1h")
 	viper.SetDefault("MAX_CONN_IDLE_TIME", "30m")
 	viper.SetDefault("HEALTH_CHECK_PERIOD", "1m")
+	viper.SetDefault("APP_URL", "http://localhost:3000")
+	viper.SetDefault("MAIL_DRIVER", "log")
+	viper.SetDefault("MAIL_FROM", "AgroMart <no-reply@agromart.local>")
+	viper.SetDefault("MAIL_DIR", "./tmp/mail")
+	viper.SetDefault("MAIL_HOST", "localhost")
+	viper.SetDefault("MAIL_PORT", 1025)
+	viper.SetDefault("MAIL_USERNAME", "")
+	viper.SetDefault("MAIL_PASSWORD", "")
+	viper.SetDefault("REQUIRE_EMAIL_VERIFICATION", false)
+	viper.SetDefault("ADJUSTMENT_APPROVAL_THRESHOLD", 1000)
+	viper.SetDefault("RECONCILIATION_INTERVAL", "24h")
+	viper.SetDefault("TRUSTED_PROXIES", "")
 
 	// Try to read from .env file (optional)
 	viper.SetConfigName(".env")
@@ -85,5 +130,47 @@ func LoadConfig() (*Config, error) {
 		c.MaxConnIdleTime = duration
 	}
 
+	if reconciliationIntervalStr := viper.GetString("RECONCILIATION_INTERVAL"); reconciliationIntervalStr != "" {
+		duration, err := time.ParseDuration(reconciliationIntervalStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RECONCILIATION_INTERVAL duration: %w", err)
+		}
+		c.ReconciliationInterval = duration
+	}
+
 	return &c, nil
 }
+
+// IsDevelopment reports whether the server runs in a local development environment
+func (c *Config) IsDevelopment() bool {
+	switch strings.ToLower(c.AppEnv) {
+	case "development", "dev", "local":
+		return true
+	default:
+		return false
+	}
+}
+
+// Validate refuses settings that are only acceptable on a developer machine
+func (c *Config) Validate() error {
+	if c.IsDevelopment() {
+		return nil
+	}
+
+	switch c.JWTAlgorithm {
+	case "", "HS256":
+		for _, secret := range defaultJWTSecrets {
+			if c.JWTSecret == secret {
+				return fmt.Errorf("JWT_SECRET is still the default; set a unique secret or use JWT_ALGORITHM=RS256/EdDSA in %s", c.AppEnv)
+			}
+		}
+		if len(c.JWTSecret) < 32 {
+			return fmt.Errorf("JWT_SECRET must be at least 32 characters in %s", c.AppEnv)
+		}
+	default:
+		if c.JWTKeysDir == "" {
+			return fmt.Errorf("JWT_KEYS_DIR is required for JWT_ALGORITHM=%s", c.JWTAlgorithm)
+		}
+	}
+	return nil
+}
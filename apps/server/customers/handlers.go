@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"agromart2/internal/auth"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
@@ -29,13 +30,14 @@ func (h *Handler) CreateCustomer(c echo.Context) error {
 	}
 
 	customer, err := h.service.CreateCustomer(c.Request().Context(), CreateCustomerParams{
-		TenantID:      tenantID,
-		Name:          req.Name,
-		ContactPerson: req.ContactPerson,
-		Email:         req.Email,
-		Phone:         req.Phone,
-		Address:       req.Address,
-		PaymentMode:   req.PaymentMode,
+		TenantID:         tenantID,
+		Name:             req.Name,
+		ContactPerson:    req.ContactPerson,
+		Email:            req.Email,
+		Phone:            req.Phone,
+		Address:          req.Address,
+		PaymentMode:      req.PaymentMode,
+		MinShelfLifeDays: req.MinShelfLifeDays,
 	})
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
@@ -186,15 +188,16 @@ func (h *Handler) UpdateCustomer(c echo.Context) error {
 	}
 
 	customer, err := h.service.UpdateCustomer(c.Request().Context(), UpdateCustomerParams{
-		ID:            customerID,
-		TenantID:      tenantID,
-		Name:          req.Name,
-		ContactPerson: req.ContactPerson,
-		Email:         req.Email,
-		Phone:         req.Phone,
-		Address:       req.Address,
-		PaymentMode:   req.PaymentMode,
-		IsActive:      req.IsActive,
+		ID:               customerID,
+		TenantID:         tenantID,
+		Name:             req.Name,
+		ContactPerson:    req.ContactPerson,
+		Email:            req.Email,
+		Phone:            req.Phone,
+		Address:          req.Address,
+		PaymentMode:      req.PaymentMode,
+		IsActive:         req.IsActive,
+		MinShelfLifeDays: req.MinShelfLifeDays,
 	})
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
@@ -232,30 +235,32 @@ func (h *Handler) DeleteCustomer(c echo.Context) error {
 
 // RegisterRoutes registers all customer routes
 func (h *Handler) RegisterRoutes(g *echo.Group) {
-	g.POST("/customers", h.CreateCustomer)
-	g.GET("/customers", h.ListCustomers)
-	g.GET("/customers/search", h.SearchCustomers)
-	g.GET("/customers/:id", h.GetCustomer)
-	g.PUT("/customers/:id", h.UpdateCustomer)
-	g.DELETE("/customers/:id", h.DeleteCustomer)
+	g.POST("/customers", h.CreateCustomer, auth.RequirePermission(auth.PermCustomersManage))
+	g.GET("/customers", h.ListCustomers, auth.RequirePermission(auth.PermCustomersView))
+	g.GET("/customers/search", h.SearchCustomers, auth.RequirePermission(auth.PermCustomersView))
+	g.GET("/customers/:id", h.GetCustomer, auth.RequirePermission(auth.PermCustomersView))
+	g.PUT("/customers/:id", h.UpdateCustomer, auth.RequirePermission(auth.PermCustomersManage))
+	g.DELETE("/customers/:id", h.DeleteCustomer, auth.RequirePermission(auth.PermCustomersManage))
 }
 
 // Request/Response types
 type CreateCustomerRequest struct {
-	Name          string `json:"name" validate:"required"`
-	ContactPerson string `json:"contact_person"`
-	Email         string `json:"email"`
-	Phone         string `json:"phone"`
-	Address       string `json:"address"`
-	PaymentMode   string `json:"payment_mode"`
+	Name             string `json:"name" validate:"required"`
+	ContactPerson    string `json:"contact_person"`
+	Email            string `json:"email"`
+	Phone            string `json:"phone"`
+	Address          string `json:"address"`
+	PaymentMode      string `json:"payment_mode"`
+	MinShelfLifeDays int    `json:"min_shelf_life_days" validate:"min=0"`
 }
 
 type UpdateCustomerRequest struct {
-	Name          string `json:"name" validate:"required"`
-	ContactPerson string `json:"contact_person"`
-	Email         string `json:"email"`
-	Phone         string `json:"phone"`
-	Address       string `json:"address"`
-	PaymentMode   string `json:"payment_mode"`
-	IsActive      bool   `json:"is_active"`
-}
\ No newline at end of file
+	Name             string `json:"name" validate:"required"`
+	ContactPerson    string `json:"contact_person"`
+	Email            string `json:"email"`
+	Phone            string `json:"phone"`
+	Address          string `json:"address"`
+	PaymentMode      string `json:"payment_mode"`
+	IsActive         bool   `json:"is_active"`
+	MinShelfLifeDays int    `json:"min_shelf_life_days" validate:"min=0"`
+}
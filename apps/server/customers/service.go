@@ -24,37 +24,40 @@ func NewCustomerService(db *pgxpool.Pool, queries *db.Queries) *CustomerService
 }
 
 type CreateCustomerParams struct {
-	TenantID      uuid.UUID
-	Name          string
-	ContactPerson string
-	Email         string
-	Phone         string
-	Address       string
-	PaymentMode   string
+	TenantID         uuid.UUID
+	Name             string
+	ContactPerson    string
+	Email            string
+	Phone            string
+	Address          string
+	PaymentMode      string
+	MinShelfLifeDays int
 }
 
 type UpdateCustomerParams struct {
-	ID            uuid.UUID
-	TenantID      uuid.UUID
-	Name          string
-	ContactPerson string
-	Email         string
-	Phone         string
-	Address       string
-	PaymentMode   string
-	IsActive      bool
+	ID               uuid.UUID
+	TenantID         uuid.UUID
+	Name             string
+	ContactPerson    string
+	Email            string
+	Phone            string
+	Address          string
+	PaymentMode      string
+	IsActive         bool
+	MinShelfLifeDays int
 }
 
 // CreateCustomer creates a new customer
 func (s *CustomerService) CreateCustomer(ctx context.Context, params CreateCustomerParams) (db.Customer, error) {
 	args := db.CreateCustomerParams{
-		TenantID:      params.TenantID,
-		Name:          params.Name,
-		ContactPerson: utils.P.Text(params.ContactPerson),
-		Email:         utils.P.Text(params.Email),
-		Phone:         utils.P.Text(params.Phone),
-		Address:       utils.P.Text(params.Address),
-		PaymentMode:   utils.P.Text(params.PaymentMode),
+		TenantID:         params.TenantID,
+		Name:             params.Name,
+		ContactPerson:    utils.P.Text(params.ContactPerson),
+		Email:            utils.P.Text(params.Email),
+		Phone:            utils.P.Text(params.Phone),
+		Address:          utils.P.Text(params.Address),
+		PaymentMode:      utils.P.Text(params.PaymentMode),
+		MinShelfLifeDays: int32(params.MinShelfLifeDays),
 	}
 
 	customer, err := s.q.CreateCustomer(ctx, args)
@@ -119,15 +122,16 @@ func (s *CustomerService) ListActiveCustomers(ctx context.Context, tenantID uuid
 // UpdateCustomer updates a customer
 func (s *CustomerService) UpdateCustomer(ctx context.Context, params UpdateCustomerParams) (db.Customer, error) {
 	args := db.UpdateCustomerParams{
-		ID:            params.ID,
-		Name:          params.Name,
-		ContactPerson: utils.P.Text(params.ContactPerson),
-		Email:         utils.P.Text(params.Email),
-		Phone:         utils.P.Text(params.Phone),
-		Address:       utils.P.Text(params.Address),
-		PaymentMode:   utils.P.Text(params.PaymentMode),
-		IsActive:      utils.P.Bool(params.IsActive),
-		TenantID:      params.TenantID,
+		ID:               params.ID,
+		Name:             params.Name,
+		ContactPerson:    utils.P.Text(params.ContactPerson),
+		Email:            utils.P.Text(params.Email),
+		Phone:            utils.P.Text(params.Phone),
+		Address:          utils.P.Text(params.Address),
+		PaymentMode:      utils.P.Text(params.PaymentMode),
+		IsActive:         utils.P.Bool(params.IsActive),
+		TenantID:         params.TenantID,
+		MinShelfLifeDays: int32(params.MinShelfLifeDays),
 	}
 
 	customer, err := s.q.UpdateCustomer(ctx, args)
@@ -198,4 +202,4 @@ func (s *CustomerService) CheckCustomerExists(ctx context.Context, id, tenantID
 	}
 
 	return exists, nil
-}
\ No newline at end of file
+}
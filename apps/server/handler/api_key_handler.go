@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"agromart2/internal/auth"
+	"agromart2/internal/database"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type APIKeyHandler struct {
+	userService *auth.UserService
+}
+
+func NewAPIKeyHandler(userService *auth.UserService) *APIKeyHandler {
+	return &APIKeyHandler{
+		userService: userService,
+	}
+}
+
+// ListAPIKeys lists the tenant's API keys
+func (h *APIKeyHandler) ListAPIKeys(c echo.Context) error {
+	actor, err := actorFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	keys, err := h.userService.ListAPIKeys(c.Request().Context(), actor.TenantID)
+	if err != nil {
+		return apiKeyHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    keys,
+	})
+}
+
+// CreateAPIKey issues a new API key; the key itself is only in this response
+func (h *APIKeyHandler) CreateAPIKey(c echo.Context) error {
+	var req auth.CreateAPIKeyParams
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	actor, err := actorFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	key, err := h.userService.CreateAPIKey(c.Request().Context(), actor, req, clientInfo(c))
+	if err != nil {
+		return apiKeyHTTPError(err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    key,
+		"message": "API key created successfully, copy it now as it will not be shown again",
+	})
+}
+
+// RevokeAPIKey revokes one of the tenant's API keys
+func (h *APIKeyHandler) RevokeAPIKey(c echo.Context) error {
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid API key ID")
+	}
+
+	actor, err := actorFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.userService.RevokeAPIKey(c.Request().Context(), actor, keyID, clientInfo(c)); err != nil {
+		return apiKeyHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "API key revoked successfully",
+	})
+}
+
+// ListScopes lists the permissions an API key can be restricted to
+func (h *APIKeyHandler) ListScopes(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    auth.APIKeyScopes(),
+	})
+}
+
+// apiKeyHTTPError maps API key errors to HTTP errors
+func apiKeyHTTPError(err error) error {
+	switch {
+	case database.IsNotFound(err):
+		return echo.NewHTTPError(http.StatusNotFound, "API key not found")
+	case errors.Is(err, auth.ErrAPIKeyNameRequired), errors.Is(err, auth.ErrInvalidScope),
+		errors.Is(err, auth.ErrInvalidExpiry):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// RegisterRoutes registers API key management routes
+func (h *APIKeyHandler) RegisterRoutes(g *echo.Group) {
+	g.GET("/api-keys", h.ListAPIKeys, auth.RequirePermission(auth.PermUsersManage))
+	g.POST("/api-keys", h.CreateAPIKey, auth.RequirePermission(auth.PermUsersManage), auth.DenyImpersonation)
+	g.GET("/api-keys/scopes", h.ListScopes, auth.RequirePermission(auth.PermUsersManage))
+	g.DELETE("/api-keys/:id", h.RevokeAPIKey, auth.RequirePermission(auth.PermUsersManage))
+}
@@ -1,9 +1,13 @@
 package handler
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
 	"agromart2/internal/auth"
+	"agromart2/internal/database"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
@@ -30,15 +34,20 @@ func (h *AuthHandler) Register(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "missing required fields")
 	}
 
-	response, err := h.authService.Register(c.Request().Context(), req)
+	response, err := h.authService.Register(c.Request().Context(), req, clientInfo(c))
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
+	message := "User registered successfully"
+	if h.authService.RequiresVerifiedEmail() {
+		message = "User registered successfully, check your email to verify your address before logging in"
+	}
+
 	return c.JSON(http.StatusCreated, map[string]interface{}{
 		"success": true,
 		"data":    response,
-		"message": "User registered successfully",
+		"message": message,
 	})
 }
 
@@ -54,19 +63,33 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "email and password are required")
 	}
 
-	response, err := h.authService.Login(c.Request().Context(), req)
+	response, err := h.authService.Login(c.Request().Context(), req, clientInfo(c))
 	if err != nil {
+		var throttled *auth.LoginThrottledError
+		if errors.As(err, &throttled) {
+			c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+			return echo.NewHTTPError(http.StatusTooManyRequests, err.Error())
+		}
+		if errors.Is(err, auth.ErrEmailNotVerified) || errors.Is(err, auth.ErrTenantSuspended) ||
+			errors.Is(err, auth.ErrNotMember) {
+			return echo.NewHTTPError(http.StatusForbidden, err.Error())
+		}
 		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
 	}
 
+	message := "Login successful"
+	if response.TwoFactorRequired {
+		message = "Two-factor authentication required"
+	}
+
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
 		"data":    response,
-		"message": "Login successful",
+		"message": message,
 	})
 }
 
-// Me returns current user information
+// Me returns current user information for the tenant they are logged into
 func (h *AuthHandler) Me(c echo.Context) error {
 	userIDStr := c.Get("user_id").(string)
 	userID, err := uuid.Parse(userIDStr)
@@ -74,7 +97,12 @@ func (h *AuthHandler) Me(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusUnauthorized, "invalid user ID")
 	}
 
-	userWithTenant, err := h.authService.GetUserWithTenant(c.Request().Context(), userID)
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant ID")
+	}
+
+	userWithTenant, err := h.authService.GetUserWithTenant(c.Request().Context(), userID, tenantID)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusNotFound, "user not found")
 	}
@@ -99,9 +127,16 @@ func (h *AuthHandler) RefreshToken(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "refresh token is required")
 	}
 
-	response, err := h.authService.RefreshToken(c.Request().Context(), req.RefreshToken)
+	response, err := h.authService.RefreshToken(c.Request().Context(), req.RefreshToken, clientInfo(c))
 	if err != nil {
-		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+		if errors.Is(err, auth.ErrInvalidRefreshToken) || errors.Is(err, auth.ErrRefreshTokenReused) ||
+			errors.Is(err, auth.ErrAccountDeactivated) || errors.Is(err, auth.ErrNotMember) {
+			return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+		}
+		if errors.Is(err, auth.ErrTenantSuspended) {
+			return echo.NewHTTPError(http.StatusForbidden, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
@@ -132,24 +167,13 @@ func (h *AuthHandler) UpdatePassword(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusUnauthorized, "invalid user ID")
 	}
 
-	tenantIDStr := c.Get("tenant_id").(string)
-	tenantID, err := uuid.Parse(tenantIDStr)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant ID")
-	}
-
-	// First verify current password by attempting login
-	userEmail := c.Get("user_email").(string)
-	_, err = h.authService.Login(c.Request().Context(), auth.LoginRequest{
-		Email:    userEmail,
-		Password: req.CurrentPassword,
-	})
-	if err != nil {
+	// First verify current password
+	if err := h.authService.CheckPassword(c.Request().Context(), userID, req.CurrentPassword); err != nil {
 		return echo.NewHTTPError(http.StatusUnauthorized, "current password is incorrect")
 	}
 
 	// Update password
-	err = h.authService.UpdatePassword(c.Request().Context(), userID, tenantID, req.NewPassword)
+	err = h.authService.UpdatePassword(c.Request().Context(), userID, req.NewPassword, clientInfo(c))
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
@@ -160,16 +184,267 @@ func (h *AuthHandler) UpdatePassword(c echo.Context) error {
 	})
 }
 
-// Logout handles user logout (client-side token removal)
+// Logout ends the session the refresh token belongs to, which also invalidates the access
+// tokens issued for it
 func (h *AuthHandler) Logout(c echo.Context) error {
-	// In a stateless JWT system, logout is typically handled client-side
-	// by removing the token. However, you could implement token blacklisting here
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if req.RefreshToken == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "refresh token is required")
+	}
+
+	if err := h.authService.Logout(c.Request().Context(), req.RefreshToken); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
 		"message": "Logged out successfully",
 	})
 }
 
+// VerifyEmail redeems the token from a verification email
+func (h *AuthHandler) VerifyEmail(c echo.Context) error {
+	var req struct {
+		Token string `json:"token"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if err := h.authService.VerifyEmail(c.Request().Context(), req.Token, clientInfo(c)); err != nil {
+		if errors.Is(err, auth.ErrInvalidToken) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Email verified successfully",
+	})
+}
+
+// ResendVerification emails a new verification link. The response is the same whether or
+// not the address has an account.
+func (h *AuthHandler) ResendVerification(c echo.Context) error {
+	var req struct {
+		Email string `json:"email"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if req.Email == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "email is required")
+	}
+
+	if err := h.authService.RequestEmailVerification(c.Request().Context(), req.Email); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "If the address belongs to an unverified account, a verification email has been sent",
+	})
+}
+
+// ForgotPassword emails a password reset link. The response is the same whether or not the
+// address has an account.
+func (h *AuthHandler) ForgotPassword(c echo.Context) error {
+	var req struct {
+		Email string `json:"email"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if req.Email == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "email is required")
+	}
+
+	if err := h.authService.RequestPasswordReset(c.Request().Context(), req.Email, clientInfo(c)); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "If the address belongs to an account, a password reset email has been sent",
+	})
+}
+
+// ResetPassword sets a new password using the token from a reset email
+func (h *AuthHandler) ResetPassword(c echo.Context) error {
+	var req struct {
+		Token       string `json:"token"`
+		NewPassword string `json:"new_password"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if req.Token == "" || req.NewPassword == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "token and new password are required")
+	}
+
+	if err := h.authService.ResetPassword(c.Request().Context(), req.Token, req.NewPassword, clientInfo(c)); err != nil {
+		if errors.Is(err, auth.ErrInvalidToken) || errors.Is(err, auth.ErrPasswordTooShort) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Password reset successfully, please log in with your new password",
+	})
+}
+
+// ListSessions lists the current user's active sessions
+func (h *AuthHandler) ListSessions(c echo.Context) error {
+	userID, err := uuid.Parse(c.Get("user_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid user ID")
+	}
+
+	sessionID, _ := c.Get("session_id").(string)
+
+	sessions, err := h.authService.ListSessions(c.Request().Context(), userID, sessionID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    sessions,
+	})
+}
+
+// RevokeSession logs the current user out of one session
+func (h *AuthHandler) RevokeSession(c echo.Context) error {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid session ID")
+	}
+
+	userID, err := uuid.Parse(c.Get("user_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid user ID")
+	}
+
+	if err := h.authService.LogoutSession(c.Request().Context(), userID, sessionID); err != nil {
+		if database.IsNotFound(err) {
+			return echo.NewHTTPError(http.StatusNotFound, "session not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Session revoked successfully",
+	})
+}
+
+// LogoutAll logs the current user out on every device, including this one
+func (h *AuthHandler) LogoutAll(c echo.Context) error {
+	userID, err := uuid.Parse(c.Get("user_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid user ID")
+	}
+
+	if err := h.authService.LogoutAll(c.Request().Context(), userID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Logged out of all sessions",
+	})
+}
+
+// ListMemberships lists the tenants the current user belongs to
+func (h *AuthHandler) ListMemberships(c echo.Context) error {
+	userID, err := uuid.Parse(c.Get("user_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid user ID")
+	}
+
+	memberships, err := h.authService.ListMemberships(c.Request().Context(), userID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    memberships,
+	})
+}
+
+// SwitchTenant issues tokens for another of the current user's tenants
+func (h *AuthHandler) SwitchTenant(c echo.Context) error {
+	var req struct {
+		TenantID string `json:"tenant_id"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	tenantID, err := uuid.Parse(req.TenantID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid tenant ID")
+	}
+
+	userID, err := uuid.Parse(c.Get("user_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid user ID")
+	}
+
+	response, err := h.authService.SwitchTenant(c.Request().Context(), userID, tenantID, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, auth.ErrNotMember) || errors.Is(err, auth.ErrAccountDeactivated) ||
+			errors.Is(err, auth.ErrTenantSuspended) {
+			return echo.NewHTTPError(http.StatusForbidden, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    response,
+		"message": "Switched organization successfully",
+	})
+}
+
+// RolePermissions returns the role to permission matrix
+func (h *AuthHandler) RolePermissions(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"roles":       auth.Roles,
+			"permissions": auth.Permissions,
+			"matrix":      auth.RolePermissionMatrix(),
+		},
+	})
+}
+
+// clientInfo describes the device making the request, for the session list
+func clientInfo(c echo.Context) auth.ClientInfo {
+	return auth.ClientInfo{
+		UserAgent: c.Request().UserAgent(),
+		IPAddress: c.RealIP(),
+	}
+}
+
 // RegisterRoutes registers all auth routes
 func (h *AuthHandler) RegisterRoutes(e *echo.Echo) {
 	auth := e.Group("/api/auth")
@@ -178,10 +453,20 @@ func (h *AuthHandler) RegisterRoutes(e *echo.Echo) {
 	auth.POST("/login", h.Login)
 	auth.POST("/refresh", h.RefreshToken)
 	auth.POST("/logout", h.Logout)
+	auth.POST("/verify-email", h.VerifyEmail)
+	auth.POST("/verify-email/resend", h.ResendVerification)
+	auth.POST("/forgot-password", h.ForgotPassword)
+	auth.POST("/reset-password", h.ResetPassword)
 }
 
 // RegisterProtectedRoutes registers protected auth routes
 func (h *AuthHandler) RegisterProtectedRoutes(g *echo.Group) {
-	g.GET("/me", h.Me)
-	g.PUT("/password", h.UpdatePassword)
+	g.GET("/me", h.Me, auth.RequireUser)
+	g.PUT("/password", h.UpdatePassword, auth.RequireUser, auth.DenyImpersonation)
+	g.GET("/sessions", h.ListSessions, auth.RequireUser)
+	g.DELETE("/sessions", h.LogoutAll, auth.RequireUser, auth.DenyImpersonation)
+	g.DELETE("/sessions/:id", h.RevokeSession, auth.RequireUser, auth.DenyImpersonation)
+	g.GET("/memberships", h.ListMemberships, auth.RequireUser)
+	g.POST("/switch-tenant", h.SwitchTenant, auth.RequireUser, auth.DenyImpersonation)
+	g.GET("/admin/roles", h.RolePermissions, auth.RequirePermission(auth.PermUsersManage))
 }
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"net/http"
+
+	"agromart2/internal/auth"
+	"github.com/labstack/echo/v4"
+)
+
+type JWKSHandler struct {
+	jwtService *auth.JWTService
+}
+
+func NewJWKSHandler(jwtService *auth.JWTService) *JWKSHandler {
+	return &JWKSHandler{
+		jwtService: jwtService,
+	}
+}
+
+// JWKS serves the public keys access tokens can be verified with
+func (h *JWKSHandler) JWKS(c echo.Context) error {
+	// Short enough that verifiers pick up a scheduled key well before it starts signing
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.JSON(http.StatusOK, h.jwtService.JWKS())
+}
+
+// RegisterRoutes registers the JWKS route
+func (h *JWKSHandler) RegisterRoutes(e *echo.Echo) {
+	e.GET("/.well-known/jwks.json", h.JWKS)
+}
@@ -0,0 +1,246 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"agromart2/internal/auth"
+	"agromart2/internal/database"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type PlatformHandler struct {
+	platformService *auth.PlatformService
+}
+
+func NewPlatformHandler(platformService *auth.PlatformService) *PlatformHandler {
+	return &PlatformHandler{
+		platformService: platformService,
+	}
+}
+
+// ListTenants lists every tenant with its usage, with optional ?search= and ?active= filters
+func (h *PlatformHandler) ListTenants(c echo.Context) error {
+	filter := auth.TenantFilter{Search: c.QueryParam("search")}
+	if active := c.QueryParam("active"); active != "" {
+		isActive, err := strconv.ParseBool(active)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "active must be true or false")
+		}
+		filter.IsActive = &isActive
+	}
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offset := int32((page - 1) * limit)
+
+	tenants, err := h.platformService.ListTenants(c.Request().Context(), filter, int32(limit), offset)
+	if err != nil {
+		return platformHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    tenants,
+		"pagination": map[string]interface{}{
+			"page":  page,
+			"limit": limit,
+		},
+	})
+}
+
+// GetTenant returns one tenant with its usage
+func (h *PlatformHandler) GetTenant(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid tenant ID")
+	}
+
+	tenant, err := h.platformService.GetTenant(c.Request().Context(), tenantID)
+	if err != nil {
+		return platformHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    tenant,
+	})
+}
+
+// SuspendTenant blocks every user and API key of a tenant
+func (h *PlatformHandler) SuspendTenant(c echo.Context) error {
+	return h.setTenantActive(c, false, "Tenant suspended successfully")
+}
+
+// ReactivateTenant lifts a tenant's suspension
+func (h *PlatformHandler) ReactivateTenant(c echo.Context) error {
+	return h.setTenantActive(c, true, "Tenant reactivated successfully")
+}
+
+func (h *PlatformHandler) setTenantActive(c echo.Context, active bool, message string) error {
+	tenantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid tenant ID")
+	}
+
+	actor, err := actorFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	tenant, err := h.platformService.SetTenantActive(c.Request().Context(), actor, tenantID, active, clientInfo(c))
+	if err != nil {
+		return platformHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    tenant,
+		"message": message,
+	})
+}
+
+// Impersonate starts a time-boxed session acting as one of the tenant's admins
+func (h *PlatformHandler) Impersonate(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid tenant ID")
+	}
+
+	var req auth.StartImpersonationParams
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	actor, err := actorFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	impersonation, err := h.platformService.StartImpersonation(c.Request().Context(), actor, tenantID, req, clientInfo(c))
+	if err != nil {
+		return platformHTTPError(err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    impersonation,
+		"message": "Impersonation started, every change made with the token is audited",
+	})
+}
+
+// ListImpersonations lists impersonation sessions with optional ?tenant_id=,
+// ?impersonator_id= and ?active=true filters
+func (h *PlatformHandler) ListImpersonations(c echo.Context) error {
+	var filter auth.ImpersonationFilter
+	var err error
+	if filter.TenantID, err = uuidQueryParam(c, "tenant_id"); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid tenant ID")
+	}
+	if filter.ImpersonatorID, err = uuidQueryParam(c, "impersonator_id"); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid impersonator ID")
+	}
+	filter.ActiveOnly = c.QueryParam("active") == "true"
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offset := int32((page - 1) * limit)
+
+	sessions, err := h.platformService.ListImpersonations(c.Request().Context(), filter, int32(limit), offset)
+	if err != nil {
+		return platformHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    sessions,
+		"pagination": map[string]interface{}{
+			"page":  page,
+			"limit": limit,
+		},
+	})
+}
+
+// EndImpersonation ends an impersonation session before it expires
+func (h *PlatformHandler) EndImpersonation(c echo.Context) error {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid impersonation session ID")
+	}
+
+	actor, err := actorFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	session, err := h.platformService.EndImpersonation(c.Request().Context(), actor, sessionID, clientInfo(c))
+	if err != nil {
+		return platformHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    session,
+		"message": "Impersonation ended",
+	})
+}
+
+// uuidQueryParam returns nil when the query parameter is absent
+func uuidQueryParam(c echo.Context, name string) (*uuid.UUID, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// platformHTTPError maps platform service errors to HTTP errors
+func platformHTTPError(err error) error {
+	switch {
+	case database.IsNotFound(err):
+		return echo.NewHTTPError(http.StatusNotFound, "not found")
+	case errors.Is(err, auth.ErrReasonRequired), errors.Is(err, auth.ErrInvalidImpersonationTTL):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, auth.ErrCannotSuspendOwnTenant), errors.Is(err, auth.ErrImpersonationTarget),
+		errors.Is(err, auth.ErrTenantSuspended):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// RegisterRoutes registers the platform console. Every route works across tenants, so it is
+// closed to API keys and to tokens that are themselves impersonating someone.
+func (h *PlatformHandler) RegisterRoutes(g *echo.Group) {
+	platform := []echo.MiddlewareFunc{auth.RequireUser, auth.DenyImpersonation, auth.RequirePermission(auth.PermPlatformManage)}
+
+	g.GET("/platform/tenants", h.ListTenants, platform...)
+	g.GET("/platform/tenants/:id", h.GetTenant, platform...)
+	g.POST("/platform/tenants/:id/suspend", h.SuspendTenant, platform...)
+	g.POST("/platform/tenants/:id/reactivate", h.ReactivateTenant, platform...)
+	g.POST("/platform/tenants/:id/impersonate", h.Impersonate, platform...)
+	g.GET("/platform/impersonations", h.ListImpersonations, platform...)
+	g.DELETE("/platform/impersonations/:id", h.EndImpersonation, platform...)
+}
@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"agromart2/internal/auth"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type TwoFactorHandler struct {
+	authService *auth.AuthService
+}
+
+func NewTwoFactorHandler(authService *auth.AuthService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		authService: authService,
+	}
+}
+
+// Verify completes a login that returned a two-factor challenge
+func (h *TwoFactorHandler) Verify(c echo.Context) error {
+	var req auth.TwoFactorLoginRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if req.ChallengeToken == "" || req.Code == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "challenge token and code are required")
+	}
+
+	response, err := h.authService.VerifyTwoFactor(c.Request().Context(), req, clientInfo(c))
+	if err != nil {
+		var throttled *auth.LoginThrottledError
+		if errors.As(err, &throttled) {
+			c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+			return echo.NewHTTPError(http.StatusTooManyRequests, err.Error())
+		}
+		return twoFactorHTTPError(err)
+	}
+
+	message := "Login successful"
+	if len(response.RecoveryCodes) > 0 {
+		message = "Two-factor authentication enabled, store the recovery codes somewhere safe"
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    response,
+		"message": message,
+	})
+}
+
+// Setup starts the enrolment a login challenge asked for, before the user has a session
+func (h *TwoFactorHandler) Setup(c echo.Context) error {
+	var req struct {
+		ChallengeToken string `json:"challenge_token"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	enrolment, err := h.authService.BeginTwoFactorSetup(c.Request().Context(), req.ChallengeToken)
+	if err != nil {
+		return twoFactorHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    enrolment,
+		"message": "Scan the QR code with your authenticator app, then verify with a code",
+	})
+}
+
+// Status reports the current user's two-factor setup
+func (h *TwoFactorHandler) Status(c echo.Context) error {
+	userID, err := uuid.Parse(c.Get("user_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid user ID")
+	}
+
+	status, err := h.authService.TwoFactorStatus(c.Request().Context(), userID)
+	if err != nil {
+		return twoFactorHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    status,
+	})
+}
+
+// Enroll generates a new authenticator secret for the current user
+func (h *TwoFactorHandler) Enroll(c echo.Context) error {
+	userID, err := uuid.Parse(c.Get("user_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid user ID")
+	}
+
+	enrolment, err := h.authService.BeginTwoFactorEnrolment(c.Request().Context(), userID)
+	if err != nil {
+		return twoFactorHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    enrolment,
+		"message": "Scan the QR code with your authenticator app, then enable with a code",
+	})
+}
+
+// Enable confirms the enrolment with a code and returns the recovery codes
+func (h *TwoFactorHandler) Enable(c echo.Context) error {
+	var req struct {
+		Code string `json:"code"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if req.Code == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "code is required")
+	}
+
+	userID, err := uuid.Parse(c.Get("user_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid user ID")
+	}
+
+	codes, err := h.authService.EnableTwoFactor(c.Request().Context(), userID, req.Code, clientInfo(c))
+	if err != nil {
+		return twoFactorHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"recovery_codes": codes,
+		},
+		"message": "Two-factor authentication enabled, store the recovery codes somewhere safe",
+	})
+}
+
+// Disable turns two-factor off for the current user
+func (h *TwoFactorHandler) Disable(c echo.Context) error {
+	var req struct {
+		Password string `json:"password"`
+		Code     string `json:"code"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if req.Password == "" || req.Code == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "password and code are required")
+	}
+
+	userID, err := uuid.Parse(c.Get("user_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid user ID")
+	}
+
+	if err := h.authService.DisableTwoFactor(c.Request().Context(), userID, req.Password, req.Code, clientInfo(c)); err != nil {
+		return twoFactorHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Two-factor authentication disabled",
+	})
+}
+
+// RegenerateRecoveryCodes replaces the current user's recovery codes
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c echo.Context) error {
+	var req struct {
+		Code string `json:"code"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if req.Code == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "code is required")
+	}
+
+	userID, err := uuid.Parse(c.Get("user_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid user ID")
+	}
+
+	codes, err := h.authService.RegenerateRecoveryCodes(c.Request().Context(), userID, req.Code)
+	if err != nil {
+		return twoFactorHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"recovery_codes": codes,
+		},
+		"message": "Recovery codes regenerated, the old ones no longer work",
+	})
+}
+
+// twoFactorHTTPError maps two-factor errors to HTTP errors
+func twoFactorHTTPError(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrInvalidTwoFactorCode), errors.Is(err, auth.ErrInvalidToken):
+		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+	case errors.Is(err, auth.ErrInvalidCredentials):
+		return echo.NewHTTPError(http.StatusUnauthorized, "password is incorrect")
+	case errors.Is(err, auth.ErrTwoFactorRequired), errors.Is(err, auth.ErrTenantSuspended),
+		errors.Is(err, auth.ErrNotMember):
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	case errors.Is(err, auth.ErrTwoFactorAlreadyEnabled), errors.Is(err, auth.ErrTwoFactorNotEnabled),
+		errors.Is(err, auth.ErrTwoFactorNotStarted):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// RegisterRoutes registers the second login step, which runs before there is a session
+func (h *TwoFactorHandler) RegisterRoutes(e *echo.Echo) {
+	e.POST("/api/auth/2fa/verify", h.Verify)
+	e.POST("/api/auth/2fa/setup", h.Setup)
+}
+
+// RegisterProtectedRoutes registers the current user's two-factor settings
+func (h *TwoFactorHandler) RegisterProtectedRoutes(g *echo.Group) {
+	g.GET("/2fa", h.Status, auth.RequireUser)
+	g.POST("/2fa/enroll", h.Enroll, auth.RequireUser, auth.DenyImpersonation)
+	g.POST("/2fa/enable", h.Enable, auth.RequireUser, auth.DenyImpersonation)
+	g.POST("/2fa/disable", h.Disable, auth.RequireUser, auth.DenyImpersonation)
+	g.POST("/2fa/recovery-codes", h.RegenerateRecoveryCodes, auth.RequireUser, auth.DenyImpersonation)
+}
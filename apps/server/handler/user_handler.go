@@ -0,0 +1,451 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"agromart2/internal/auth"
+	"agromart2/internal/database"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type UserHandler struct {
+	userService *auth.UserService
+}
+
+func NewUserHandler(userService *auth.UserService) *UserHandler {
+	return &UserHandler{
+		userService: userService,
+	}
+}
+
+// ListUsers lists the tenant's users with an optional ?role= filter
+func (h *UserHandler) ListUsers(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offset := int32((page - 1) * limit)
+
+	users, err := h.userService.ListUsers(c.Request().Context(), tenantID, c.QueryParam("role"), int32(limit), offset)
+	if err != nil {
+		return userHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    users,
+		"pagination": map[string]interface{}{
+			"page":  page,
+			"limit": limit,
+		},
+	})
+}
+
+// InviteUser emails an invite to join the tenant
+func (h *UserHandler) InviteUser(c echo.Context) error {
+	var req struct {
+		Email string `json:"email"`
+		Role  string `json:"role"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	actor, err := actorFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	invite, err := h.userService.InviteUser(c.Request().Context(), actor, req.Email, req.Role)
+	if err != nil {
+		return userHTTPError(err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    invite,
+		"message": "Invite sent successfully",
+	})
+}
+
+// ListInvites lists invites that have not been accepted yet
+func (h *UserHandler) ListInvites(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	invites, err := h.userService.ListInvites(c.Request().Context(), tenantID)
+	if err != nil {
+		return userHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    invites,
+	})
+}
+
+// RevokeInvite cancels a pending invite
+func (h *UserHandler) RevokeInvite(c echo.Context) error {
+	inviteID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid invite ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	if err := h.userService.RevokeInvite(c.Request().Context(), tenantID, inviteID); err != nil {
+		if database.IsNotFound(err) {
+			return echo.NewHTTPError(http.StatusNotFound, "invite not found")
+		}
+		return userHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Invite revoked successfully",
+	})
+}
+
+// AcceptInvite creates the invited user's account with the password they chose, or adds
+// an existing account to the tenant when the password is theirs
+func (h *UserHandler) AcceptInvite(c echo.Context) error {
+	var req struct {
+		Token    string `json:"token"`
+		Name     string `json:"name"`
+		Phone    string `json:"phone"`
+		Password string `json:"password"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	user, err := h.userService.AcceptInvite(c.Request().Context(), auth.AcceptInviteParams{
+		Token:    req.Token,
+		Name:     req.Name,
+		Phone:    req.Phone,
+		Password: req.Password,
+	})
+	if err != nil {
+		return userHTTPError(err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    user,
+		"message": "Invite accepted, you can now log in",
+	})
+}
+
+// ChangeRole gives a user a new role
+func (h *UserHandler) ChangeRole(c echo.Context) error {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user ID")
+	}
+
+	var req struct {
+		Role string `json:"role"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	actor, err := actorFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.userService.ChangeRole(c.Request().Context(), actor, userID, req.Role)
+	if err != nil {
+		return userHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    user,
+		"message": "Role updated successfully",
+	})
+}
+
+// ActivateUser lets a deactivated user log in again
+func (h *UserHandler) ActivateUser(c echo.Context) error {
+	return h.setActive(c, true, "User activated successfully")
+}
+
+// DeactivateUser blocks a user from logging in and ends their current sessions
+func (h *UserHandler) DeactivateUser(c echo.Context) error {
+	return h.setActive(c, false, "User deactivated successfully")
+}
+
+func (h *UserHandler) setActive(c echo.Context, active bool, message string) error {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user ID")
+	}
+
+	actor, err := actorFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.userService.SetActive(c.Request().Context(), actor, userID, active)
+	if err != nil {
+		return userHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    user,
+		"message": message,
+	})
+}
+
+// RevokeSessions logs a user out on every device
+func (h *UserHandler) RevokeSessions(c echo.Context) error {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user ID")
+	}
+
+	actor, err := actorFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.userService.RevokeSessions(c.Request().Context(), actor, userID); err != nil {
+		return userHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "User logged out of all sessions",
+	})
+}
+
+// UnlockUser lifts a user's login lockout
+func (h *UserHandler) UnlockUser(c echo.Context) error {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user ID")
+	}
+
+	actor, err := actorFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.userService.UnlockUser(c.Request().Context(), actor, userID, clientInfo(c)); err != nil {
+		return userHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "User unlocked successfully",
+	})
+}
+
+// ResetTwoFactor removes another user's authenticator so they can enrol again
+func (h *UserHandler) ResetTwoFactor(c echo.Context) error {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user ID")
+	}
+
+	actor, err := actorFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.userService.ResetTwoFactor(c.Request().Context(), actor, userID, clientInfo(c)); err != nil {
+		return userHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Two-factor authentication reset successfully",
+	})
+}
+
+// GetTwoFactorPolicy returns whether the tenant requires two-factor for privileged roles
+func (h *UserHandler) GetTwoFactorPolicy(c echo.Context) error {
+	actor, err := actorFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	policy, err := h.userService.GetTwoFactorPolicy(c.Request().Context(), actor.TenantID)
+	if err != nil {
+		return userHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    policy,
+	})
+}
+
+// SetTwoFactorPolicy makes two-factor mandatory or optional for privileged roles
+func (h *UserHandler) SetTwoFactorPolicy(c echo.Context) error {
+	var req struct {
+		Required *bool `json:"required"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if req.Required == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "required is required")
+	}
+
+	actor, err := actorFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	policy, err := h.userService.SetTwoFactorPolicy(c.Request().Context(), actor, *req.Required, clientInfo(c))
+	if err != nil {
+		return userHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    policy,
+		"message": "Two-factor policy updated successfully",
+	})
+}
+
+// ListSecurityEvents lists the tenant's security events with optional
+// ?event_type=, ?user_id=, ?ip_address= and ?since= (RFC 3339) filters
+func (h *UserHandler) ListSecurityEvents(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	filter := auth.SecurityEventFilter{
+		EventType: c.QueryParam("event_type"),
+		IPAddress: c.QueryParam("ip_address"),
+	}
+	if userIDStr := c.QueryParam("user_id"); userIDStr != "" {
+		id, err := uuid.Parse(userIDStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid user ID")
+		}
+		filter.UserID = &id
+	}
+	if sinceStr := c.QueryParam("since"); sinceStr != "" {
+		since, err := time.Parse(time.RFC3339, sinceStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "since must be an RFC 3339 timestamp")
+		}
+		filter.Since = &since
+	}
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offset := int32((page - 1) * limit)
+
+	events, err := h.userService.ListSecurityEvents(c.Request().Context(), tenantID, filter, int32(limit), offset)
+	if err != nil {
+		return userHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    events,
+		"pagination": map[string]interface{}{
+			"page":  page,
+			"limit": limit,
+		},
+	})
+}
+
+// RegisterRoutes registers public user routes
+func (h *UserHandler) RegisterRoutes(e *echo.Echo) {
+	e.POST("/api/auth/accept-invite", h.AcceptInvite)
+}
+
+// RegisterProtectedRoutes registers tenant user management routes
+func (h *UserHandler) RegisterProtectedRoutes(g *echo.Group) {
+	g.GET("/users", h.ListUsers, auth.RequirePermission(auth.PermUsersManage))
+	g.POST("/users/invite", h.InviteUser, auth.RequirePermission(auth.PermUsersManage))
+	g.GET("/users/invites", h.ListInvites, auth.RequirePermission(auth.PermUsersManage))
+	g.DELETE("/users/invites/:id", h.RevokeInvite, auth.RequirePermission(auth.PermUsersManage))
+	g.PUT("/users/:id/role", h.ChangeRole, auth.RequirePermission(auth.PermUsersManage))
+	g.POST("/users/:id/activate", h.ActivateUser, auth.RequirePermission(auth.PermUsersManage))
+	g.POST("/users/:id/deactivate", h.DeactivateUser, auth.RequirePermission(auth.PermUsersManage))
+	g.DELETE("/users/:id/sessions", h.RevokeSessions, auth.RequirePermission(auth.PermUsersManage))
+	g.POST("/users/:id/unlock", h.UnlockUser, auth.RequirePermission(auth.PermUsersManage))
+	g.POST("/users/:id/2fa/reset", h.ResetTwoFactor, auth.RequirePermission(auth.PermUsersManage))
+	g.GET("/security-events", h.ListSecurityEvents, auth.RequirePermission(auth.PermUsersManage))
+	g.GET("/security/two-factor-policy", h.GetTwoFactorPolicy, auth.RequirePermission(auth.PermUsersManage))
+	g.PUT("/security/two-factor-policy", h.SetTwoFactorPolicy, auth.RequirePermission(auth.PermUsersManage))
+}
+
+// actorFromContext builds the acting user from the values set by RequireAuth
+func actorFromContext(c echo.Context) (auth.Actor, error) {
+	userID, err := uuid.Parse(c.Get("user_id").(string))
+	if err != nil {
+		return auth.Actor{}, echo.NewHTTPError(http.StatusUnauthorized, "invalid user ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return auth.Actor{}, echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	role, _ := c.Get("user_role").(string)
+
+	return auth.Actor{UserID: userID, TenantID: tenantID, Role: role}, nil
+}
+
+// userHTTPError maps user service errors to HTTP errors
+func userHTTPError(err error) error {
+	switch {
+	case database.IsNotFound(err):
+		return echo.NewHTTPError(http.StatusNotFound, "user not found")
+	case errors.Is(err, auth.ErrRoleNotAllowed), errors.Is(err, auth.ErrUserOutranks),
+		errors.Is(err, auth.ErrCannotModifySelf), errors.Is(err, auth.ErrForeignAccount),
+		errors.Is(err, auth.ErrAccountDeactivated):
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	case errors.Is(err, auth.ErrInvalidCredentials):
+		return echo.NewHTTPError(http.StatusUnauthorized, "password is incorrect")
+	case errors.Is(err, auth.ErrEmailTaken), errors.Is(err, auth.ErrAlreadyMember):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, auth.ErrInvalidEmail), errors.Is(err, auth.ErrInvalidRole),
+		errors.Is(err, auth.ErrInvalidInvite), errors.Is(err, auth.ErrPasswordTooShort),
+		errors.Is(err, auth.ErrNameRequired):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
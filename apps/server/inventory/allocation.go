@@ -0,0 +1,218 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"agromart2/db"
+	"agromart2/internal/utils"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidAllocation = errors.New("allocation requires a location, product and positive quantity")
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrFractionalStock   = errors.New("batch holds a fractional quantity and cannot be allocated in whole units")
+)
+
+// AllocationRequest asks for a quantity of a product to be taken from stock at a location
+type AllocationRequest struct {
+	LocationID uuid.UUID
+	ProductID  uuid.UUID
+	Quantity   int
+	// MinShelfLifeDays skips batches expiring sooner than this many days from AsOf
+	MinShelfLifeDays int
+	// AsOf is the date expiry is measured from; zero means today
+	AsOf time.Time
+	// ExcludeReserved keeps stock promised to approved sales orders at the location out of
+	// the plan. Shipping one of those orders must leave it unset, as its own reservation
+	// is what it ships.
+	ExcludeReserved bool
+	// BatchID limits the allocation to one batch, which must still pass the expiry cutoff;
+	// zero allows any batch
+	BatchID uuid.UUID
+}
+
+// BatchAllocation is the quantity taken from a single batch
+type BatchAllocation struct {
+	BatchID     uuid.UUID `json:"batch_id"`
+	BatchNumber string    `json:"batch_number"`
+	ExpiryDate  time.Time `json:"expiry_date"`
+	Quantity    int       `json:"quantity"`
+}
+
+// AllocationPlan splits a requested quantity across batches, earliest expiry first
+type AllocationPlan struct {
+	LocationID  uuid.UUID         `json:"location_id"`
+	ProductID   uuid.UUID         `json:"product_id"`
+	Quantity    int               `json:"quantity"`
+	Allocations []BatchAllocation `json:"allocations"`
+}
+
+// PlanFEFO splits quantity across batches in the order given, which must be earliest
+// expiry first. It fails with ErrInsufficientStock when the batches cannot cover it, and with
+// ErrFractionalStock when it reaches a batch holding part of a unit.
+func PlanFEFO(batches []db.ListAllocatableBatchesRow, quantity int) ([]BatchAllocation, error) {
+	allocations := []BatchAllocation{}
+	remaining := quantity
+	for _, batch := range batches {
+		if remaining == 0 {
+			break
+		}
+		whole, err := batch.Quantity.Int64Value()
+		if err != nil {
+			return nil, fmt.Errorf("%w: batch %s holds %g", ErrFractionalStock, batch.BatchNumber, utils.PgNumericToFloat64(batch.Quantity))
+		}
+		available := int(whole.Int64)
+		if available <= 0 {
+			continue
+		}
+		take := min(available, remaining)
+		allocations = append(allocations, BatchAllocation{
+			BatchID:     batch.BatchID,
+			BatchNumber: batch.BatchNumber,
+			ExpiryDate:  batch.ExpiryDate,
+			Quantity:    take,
+		})
+		remaining -= take
+	}
+	if remaining > 0 {
+		return nil, fmt.Errorf("%w: requested %d, available %d", ErrInsufficientStock, quantity, quantity-remaining)
+	}
+	return allocations, nil
+}
+
+// AllocateFEFO builds a FEFO allocation plan using q. When q is bound to a transaction the
+// product's inventory rows stay locked until it ends, so the plan can be applied safely.
+func AllocateFEFO(ctx context.Context, q *db.Queries, tenantID uuid.UUID, req AllocationRequest) (*AllocationPlan, error) {
+	if req.LocationID == uuid.Nil || req.ProductID == uuid.Nil || req.Quantity <= 0 || req.MinShelfLifeDays < 0 {
+		return nil, ErrInvalidAllocation
+	}
+
+	asOf := req.AsOf
+	if asOf.IsZero() {
+		asOf = time.Now()
+	}
+	y, m, d := asOf.Date()
+	cutoff := time.Date(y, m, d, 0, 0, 0, 0, time.UTC).AddDate(0, 0, req.MinShelfLifeDays)
+
+	if err := q.LockProductInventory(ctx, db.LockProductInventoryParams{
+		TenantID:   tenantID,
+		ProductID:  req.ProductID,
+		LocationID: req.LocationID,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to lock inventory: %w", err)
+	}
+
+	if req.ExcludeReserved {
+		if err := EnsureUnreserved(ctx, q, tenantID, req.LocationID, req.ProductID, req.Quantity); err != nil {
+			return nil, err
+		}
+	}
+
+	batches, err := q.ListAllocatableBatches(ctx, db.ListAllocatableBatchesParams{
+		TenantID:   tenantID,
+		ProductID:  req.ProductID,
+		LocationID: req.LocationID,
+		ExpiryDate: cutoff,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list batches: %w", err)
+	}
+	if req.BatchID != uuid.Nil {
+		batches = slices.DeleteFunc(batches, func(b db.ListAllocatableBatchesRow) bool { return b.BatchID != req.BatchID })
+	}
+
+	allocations, err := PlanFEFO(batches, req.Quantity)
+	if err != nil {
+		return nil, fmt.Errorf("product %s: %w", req.ProductID, err)
+	}
+
+	return &AllocationPlan{
+		LocationID:  req.LocationID,
+		ProductID:   req.ProductID,
+		Quantity:    req.Quantity,
+		Allocations: allocations,
+	}, nil
+}
+
+// EnsureUnreserved fails with ErrInsufficientStock unless quantity of a product at a location
+// is free of what approved sales orders still have to ship. Lock the product's inventory at
+// the location first so the answer holds until the stock is taken.
+func EnsureUnreserved(ctx context.Context, q *db.Queries, tenantID, locationID, productID uuid.UUID, quantity int) error {
+	position, err := q.GetProductStockPosition(ctx, db.GetProductStockPositionParams{
+		TenantID:   tenantID,
+		ProductID:  productID,
+		LocationID: locationID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get stock position: %w", err)
+	}
+	if free := position.OnHand - position.Reserved; int64(quantity) > free {
+		return fmt.Errorf("product %s: %w: requested %d, available %d after %d reserved by approved sales orders",
+			productID, ErrInsufficientStock, quantity, max(free, 0), position.Reserved)
+	}
+	return nil
+}
+
+// ApplyAllocation takes each allocated quantity out of stock and logs it against referenceID,
+// which is nil for manual issues
+func ApplyAllocation(ctx context.Context, q *db.Queries, tenantID uuid.UUID, plan *AllocationPlan, transactionType string, referenceID *uuid.UUID, notes string) error {
+	for _, a := range plan.Allocations {
+		if _, err := Decrement(ctx, q, tenantID, plan.LocationID, plan.ProductID, a.BatchID, a.Quantity); err != nil {
+			return fmt.Errorf("batch %s: %w", a.BatchNumber, err)
+		}
+
+		err := q.CreateInventoryLog(ctx, db.CreateInventoryLogParams{
+			TenantID:        tenantID,
+			ProductID:       plan.ProductID,
+			BatchID:         a.BatchID,
+			LocationID:      plan.LocationID,
+			TransactionType: transactionType,
+			QuantityChange:  utils.P.Numeric(-a.Quantity),
+			ReferenceID:     utils.P.UUIDPtr(referenceID),
+			Notes:           utils.P.Text(notes),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to log allocation from batch %s: %w", a.BatchNumber, err)
+		}
+	}
+	return nil
+}
+
+// PlanAllocation previews a FEFO allocation of unreserved stock without changing it
+func (s *InventoryService) PlanAllocation(ctx context.Context, tenantID uuid.UUID, req AllocationRequest) (*AllocationPlan, error) {
+	req.ExcludeReserved = true
+	return AllocateFEFO(ctx, s.queries, tenantID, req)
+}
+
+// IssueStock takes stock out FEFO for a manual issue and returns the batches used
+func (s *InventoryService) IssueStock(ctx context.Context, tenantID uuid.UUID, req AllocationRequest, notes string) (*AllocationPlan, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	// A manual issue must not take stock already promised to an approved sales order
+	req.ExcludeReserved = true
+	plan, err := AllocateFEFO(ctx, qtx, tenantID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ApplyAllocation(ctx, qtx, tenantID, plan, TxIssue, nil, notes); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return plan, nil
+}
@@ -0,0 +1,105 @@
+package inventory_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"agromart2/apps/server/inventory"
+	"agromart2/db"
+	"agromart2/internal/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestPlanFEFO(t *testing.T) {
+	day := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	batch := func(number string, expiresInDays int, quantity pgtype.Numeric) db.ListAllocatableBatchesRow {
+		return db.ListAllocatableBatchesRow{
+			BatchID:     uuid.NewSHA1(uuid.NameSpaceOID, []byte(number)),
+			BatchNumber: number,
+			ExpiryDate:  day.AddDate(0, 0, expiresInDays),
+			Quantity:    quantity,
+		}
+	}
+	take := func(b db.ListAllocatableBatchesRow, quantity int) inventory.BatchAllocation {
+		return inventory.BatchAllocation{BatchID: b.BatchID, BatchNumber: b.BatchNumber, ExpiryDate: b.ExpiryDate, Quantity: quantity}
+	}
+
+	// Batches as ListAllocatableBatches returns them: earliest expiry first
+	early := batch("EARLY", 10, utils.P.Numeric(4))
+	empty := batch("EMPTY", 20, utils.P.Numeric(0))
+	mid := batch("MID", 30, utils.P.Numeric(5))
+	late := batch("LATE", 60, utils.P.Numeric(10))
+	fractional := batch("HALF", 40, utils.Float64ToPgNumeric(2.5))
+
+	tests := []struct {
+		name     string
+		batches  []db.ListAllocatableBatchesRow
+		quantity int
+		want     []inventory.BatchAllocation
+		wantErr  error
+		errText  string
+	}{
+		{
+			name:     "takes from the earliest expiring batch first",
+			batches:  []db.ListAllocatableBatchesRow{early, mid, late},
+			quantity: 3,
+			want:     []inventory.BatchAllocation{take(early, 3)},
+		},
+		{
+			name:     "spills into later batches in expiry order",
+			batches:  []db.ListAllocatableBatchesRow{early, mid, late},
+			quantity: 12,
+			want:     []inventory.BatchAllocation{take(early, 4), take(mid, 5), take(late, 3)},
+		},
+		{
+			name:     "skips batches with nothing left",
+			batches:  []db.ListAllocatableBatchesRow{early, empty, mid},
+			quantity: 6,
+			want:     []inventory.BatchAllocation{take(early, 4), take(mid, 2)},
+		},
+		{
+			name:     "stops before batches it does not need",
+			batches:  []db.ListAllocatableBatchesRow{early, fractional},
+			quantity: 4,
+			want:     []inventory.BatchAllocation{take(early, 4)},
+		},
+		{
+			name:     "reports the shortfall",
+			batches:  []db.ListAllocatableBatchesRow{early, empty, mid},
+			quantity: 20,
+			wantErr:  inventory.ErrInsufficientStock,
+			errText:  "insufficient stock: requested 20, available 9",
+		},
+		{
+			name:     "refuses fractional stock",
+			batches:  []db.ListAllocatableBatchesRow{early, fractional, late},
+			quantity: 8,
+			wantErr:  inventory.ErrFractionalStock,
+			errText:  inventory.ErrFractionalStock.Error() + ": batch HALF holds 2.5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := inventory.PlanFEFO(tt.batches, tt.quantity)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				if err.Error() != tt.errText {
+					t.Fatalf("got error %q, want %q", err.Error(), tt.errText)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
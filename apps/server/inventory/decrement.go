@@ -0,0 +1,70 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"agromart2/db"
+	"agromart2/internal/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrInvalidQuantity = errors.New("quantity must be greater than zero")
+
+// InsufficientStockError reports a decrement larger than what a batch holds at a location.
+// It matches ErrInsufficientStock with errors.Is.
+type InsufficientStockError struct {
+	BatchID   uuid.UUID `json:"batch_id"`
+	Requested int       `json:"requested"`
+	Available int       `json:"available"`
+}
+
+func (e *InsufficientStockError) Error() string {
+	return fmt.Sprintf("%s: batch %s has %d, requested %d", ErrInsufficientStock, e.BatchID, e.Available, e.Requested)
+}
+
+func (e *InsufficientStockError) Is(target error) bool {
+	return target == ErrInsufficientStock
+}
+
+// Decrement takes quantity out of a batch at a location and returns what is left. The
+// availability check and the update are one statement, so concurrent decrements can never
+// take stock below zero; a batch may be taken down to exactly zero. When the batch holds
+// less than quantity nothing changes and an *InsufficientStockError is returned.
+func Decrement(ctx context.Context, q *db.Queries, tenantID, locationID, productID, batchID uuid.UUID, quantity int) (int, error) {
+	if quantity <= 0 {
+		return 0, ErrInvalidQuantity
+	}
+
+	remaining, err := q.ReduceInventoryQuantity(ctx, db.ReduceInventoryQuantityParams{
+		Quantity:   utils.P.Numeric(quantity),
+		TenantID:   tenantID,
+		ProductID:  productID,
+		BatchID:    batchID,
+		LocationID: locationID,
+	})
+	if err == nil {
+		return utils.PgNumericToInt(remaining), nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("failed to reduce inventory: %w", err)
+	}
+
+	// Nothing was updated: the batch is missing or short. Read what it holds for the error.
+	available := 0
+	inv, err := q.GetInventoryByProductBatch(ctx, db.GetInventoryByProductBatchParams{
+		TenantID:   tenantID,
+		ProductID:  productID,
+		BatchID:    batchID,
+		LocationID: locationID,
+	})
+	if err == nil {
+		available = utils.PgNumericToInt(inv.Quantity)
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("failed to read inventory: %w", err)
+	}
+
+	return 0, &InsufficientStockError{BatchID: batchID, Requested: quantity, Available: available}
+}
@@ -1,10 +1,12 @@
 package inventory
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
+	"agromart2/internal/auth"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
@@ -76,19 +78,9 @@ func (h *Handler) AddInventory(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
 	}
 
-	err = h.service.AddInventoryQuantity(c.Request().Context(), tenantID, req.ProductID, req.BatchID, req.Quantity)
+	err = h.service.AddInventoryQuantity(c.Request().Context(), tenantID, req.LocationID, req.ProductID, req.BatchID, req.Quantity, req.Notes)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-
-	// Log the inventory change
-	userID := c.Get("user_id").(string)
-	refID, _ := uuid.Parse(userID)
-	
-	err = h.service.CreateInventoryLog(c.Request().Context(), tenantID, req.ProductID, req.BatchID, refID, "ADD", req.Quantity, req.Notes)
-	if err != nil {
-		// Log but don't fail the request
-		// In production, you might want to use a proper logging system
+		return stockHTTPError(err)
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
@@ -109,22 +101,16 @@ func (h *Handler) ReduceInventory(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
 	}
 
-	err = h.service.ReduceInventoryQuantity(c.Request().Context(), tenantID, req.ProductID, req.BatchID, req.Quantity)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-
-	// Log the inventory change
-	userID := c.Get("user_id").(string)
-	refID, _ := uuid.Parse(userID)
-	
-	err = h.service.CreateInventoryLog(c.Request().Context(), tenantID, req.ProductID, req.BatchID, refID, "REDUCE", req.Quantity, req.Notes)
+	remaining, err := h.service.ReduceInventoryQuantity(c.Request().Context(), tenantID, req.LocationID, req.ProductID, req.BatchID, req.Quantity, req.Notes)
 	if err != nil {
-		// Log but don't fail the request
+		return stockHTTPError(err)
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
+		"data": map[string]interface{}{
+			"remaining_quantity": remaining,
+		},
 		"message": "Inventory reduced successfully",
 	})
 }
@@ -141,7 +127,12 @@ func (h *Handler) GetInventoryByProduct(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
 	}
 
-	inventory, err := h.service.GetProductInventoryDetails(c.Request().Context(), tenantID, productID)
+	locationID, err := locationParam(c)
+	if err != nil {
+		return err
+	}
+
+	inventory, err := h.service.GetProductInventoryDetails(c.Request().Context(), tenantID, productID, locationID)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
@@ -171,7 +162,12 @@ func (h *Handler) ListAllInventory(c echo.Context) error {
 
 	offset := int32((page - 1) * limit)
 
-	inventory, err := h.service.ListAllInventory(c.Request().Context(), tenantID, int32(limit), offset)
+	locationID, err := locationParam(c)
+	if err != nil {
+		return err
+	}
+
+	inventory, err := h.service.ListAllInventory(c.Request().Context(), tenantID, locationID, int32(limit), offset)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
@@ -198,7 +194,12 @@ func (h *Handler) GetLowStockReport(c echo.Context) error {
 		threshold = 10 // Default threshold
 	}
 
-	report, err := h.service.GetLowStockReport(c.Request().Context(), tenantID, threshold)
+	locationID, err := locationParam(c)
+	if err != nil {
+		return err
+	}
+
+	report, err := h.service.GetLowStockReport(c.Request().Context(), tenantID, locationID, threshold)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
@@ -210,6 +211,81 @@ func (h *Handler) GetLowStockReport(c echo.Context) error {
 	})
 }
 
+// GetExpiringBatches lists batches expiring within the given number of days
+func (h *Handler) GetExpiringBatches(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	days, _ := strconv.Atoi(c.QueryParam("days"))
+	if days <= 0 {
+		days = 30
+	}
+
+	locationID, err := locationParam(c)
+	if err != nil {
+		return err
+	}
+
+	batches, err := h.service.GetExpiringBatches(c.Request().Context(), tenantID, locationID, days)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    batches,
+		"days":    days,
+	})
+}
+
+// GetInventorySummary gets product count, low stock, valuation and expiry totals
+func (h *Handler) GetInventorySummary(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	locationID, err := locationParam(c)
+	if err != nil {
+		return err
+	}
+
+	summary, err := h.service.GetInventorySummary(c.Request().Context(), tenantID, locationID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    summary,
+	})
+}
+
+// GetInTransitStock lists stock currently in transit between locations
+func (h *Handler) GetInTransitStock(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	locationID, err := locationParam(c)
+	if err != nil {
+		return err
+	}
+
+	rows, err := h.service.GetInTransitStock(c.Request().Context(), tenantID, locationID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    rows,
+	})
+}
+
 // GetInventoryLogs gets inventory transaction logs
 func (h *Handler) GetInventoryLogs(c echo.Context) error {
 	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
@@ -276,18 +352,213 @@ func (h *Handler) GetInventoryLogs(c echo.Context) error {
 	})
 }
 
+// GetStockAsOf reconstructs stock levels at the end of a given day from the ledger
+func (h *Handler) GetStockAsOf(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	date, err := dateParam(c, "date")
+	if err != nil {
+		return err
+	}
+
+	filter, err := ledgerFilterParams(c)
+	if err != nil {
+		return err
+	}
+
+	rows, err := h.service.StockAsOf(c.Request().Context(), tenantID, date, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    rows,
+	})
+}
+
+// GetStockStatement returns opening balance, movements and closing balance for a period
+func (h *Handler) GetStockStatement(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	from, err := dateParam(c, "from")
+	if err != nil {
+		return err
+	}
+
+	to, err := dateParam(c, "to")
+	if err != nil {
+		return err
+	}
+
+	filter, err := ledgerFilterParams(c)
+	if err != nil {
+		return err
+	}
+
+	statement, err := h.service.Statement(c.Request().Context(), tenantID, from, to, filter)
+	if err != nil {
+		if errors.Is(err, ErrInvalidPeriod) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    statement,
+	})
+}
+
+// PlanAllocation previews which batches a quantity would be taken from, earliest expiry first
+func (h *Handler) PlanAllocation(c echo.Context) error {
+	var req AllocationRequestBody
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	plan, err := h.service.PlanAllocation(c.Request().Context(), tenantID, req.allocationRequest())
+	if err != nil {
+		return allocationHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    plan,
+	})
+}
+
+// IssueStock issues stock from the earliest expiring batches
+func (h *Handler) IssueStock(c echo.Context) error {
+	var req AllocationRequestBody
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	plan, err := h.service.IssueStock(c.Request().Context(), tenantID, req.allocationRequest(), req.Notes)
+	if err != nil {
+		return allocationHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    plan,
+		"message": "Stock issued successfully",
+	})
+}
+
+// locationParam reads the optional location_id query filter
+func locationParam(c echo.Context) (*uuid.UUID, error) {
+	locationIDStr := c.QueryParam("location_id")
+	if locationIDStr == "" {
+		return nil, nil
+	}
+	locationID, err := uuid.Parse(locationIDStr)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "invalid location ID")
+	}
+	return &locationID, nil
+}
+
+// dateParam reads a required YYYY-MM-DD query parameter
+func dateParam(c echo.Context, name string) (time.Time, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return time.Time{}, echo.NewHTTPError(http.StatusBadRequest, name+" is required")
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, echo.NewHTTPError(http.StatusBadRequest, "invalid "+name+", expected YYYY-MM-DD")
+	}
+	return date, nil
+}
+
+// ledgerFilterParams reads the optional product_id, batch_id and location_id query filters
+func ledgerFilterParams(c echo.Context) (LedgerFilter, error) {
+	var filter LedgerFilter
+	for name, dst := range map[string]**uuid.UUID{
+		"product_id": &filter.ProductID,
+		"batch_id":   &filter.BatchID,
+	} {
+		if value := c.QueryParam(name); value != "" {
+			id, err := uuid.Parse(value)
+			if err != nil {
+				return filter, echo.NewHTTPError(http.StatusBadRequest, "invalid "+name)
+			}
+			*dst = &id
+		}
+	}
+	locationID, err := locationParam(c)
+	if err != nil {
+		return filter, err
+	}
+	filter.LocationID = locationID
+	return filter, nil
+}
+
+func allocationHTTPError(err error) error {
+	if errors.Is(err, ErrInvalidAllocation) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return stockHTTPError(err)
+}
+
+// stockHTTPError maps decrement errors to HTTP errors. A short batch is a 409 whose body
+// carries the quantity that was available, so clients can retry with what is left.
+func stockHTTPError(err error) error {
+	var short *InsufficientStockError
+	switch {
+	case errors.As(err, &short):
+		return echo.NewHTTPError(http.StatusConflict, map[string]interface{}{
+			"message":   err.Error(),
+			"batch_id":  short.BatchID,
+			"requested": short.Requested,
+			"available": short.Available,
+		})
+	case errors.Is(err, ErrInsufficientStock), errors.Is(err, ErrFractionalStock):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, ErrInvalidQuantity):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
+
 // RegisterRoutes registers all inventory routes
 func (h *Handler) RegisterRoutes(g *echo.Group) {
-	g.POST("/batches", h.CreateBatch)
-	g.GET("/batches/:id", h.GetBatch)
+	g.POST("/batches", h.CreateBatch, auth.RequirePermission(auth.PermInventoryAdjust))
+	g.GET("/batches/:id", h.GetBatch, auth.RequirePermission(auth.PermInventoryView))
 	
-	g.POST("/inventory/add", h.AddInventory)
-	g.POST("/inventory/reduce", h.ReduceInventory)
-	g.GET("/inventory", h.ListAllInventory)
-	g.GET("/inventory/product/:productId", h.GetInventoryByProduct)
-	g.GET("/inventory/logs", h.GetInventoryLogs)
+	g.POST("/inventory/add", h.AddInventory, auth.RequirePermission(auth.PermInventoryAdjust))
+	g.POST("/inventory/reduce", h.ReduceInventory, auth.RequirePermission(auth.PermInventoryAdjust))
+	g.GET("/inventory", h.ListAllInventory, auth.RequirePermission(auth.PermInventoryView))
+	g.GET("/inventory/product/:productId", h.GetInventoryByProduct, auth.RequirePermission(auth.PermInventoryView))
+	g.GET("/inventory/logs", h.GetInventoryLogs, auth.RequirePermission(auth.PermInventoryView))
+	g.GET("/inventory/stock-at", h.GetStockAsOf, auth.RequirePermission(auth.PermInventoryView))
+	g.GET("/inventory/statement", h.GetStockStatement, auth.RequirePermission(auth.PermInventoryView))
+	g.GET("/inventory/expiring", h.GetExpiringBatches, auth.RequirePermission(auth.PermInventoryView))
+	g.GET("/inventory/summary", h.GetInventorySummary, auth.RequirePermission(auth.PermInventoryView))
+	g.GET("/inventory/in-transit", h.GetInTransitStock, auth.RequirePermission(auth.PermInventoryView))
+	g.POST("/inventory/allocate", h.PlanAllocation, auth.RequirePermission(auth.PermInventoryView))
+	g.POST("/inventory/issue", h.IssueStock, auth.RequirePermission(auth.PermInventoryAdjust))
 	
-	g.GET("/reports/low-stock", h.GetLowStockReport)
+	g.GET("/reports/low-stock", h.GetLowStockReport, auth.RequirePermission(auth.PermReportsView))
 }
 
 // Request types
@@ -299,15 +570,34 @@ type CreateBatchRequest struct {
 }
 
 type AddInventoryRequest struct {
-	ProductID uuid.UUID `json:"product_id" validate:"required"`
-	BatchID   uuid.UUID `json:"batch_id" validate:"required"`
-	Quantity  int       `json:"quantity" validate:"required,min=1"`
-	Notes     string    `json:"notes"`
+	LocationID uuid.UUID `json:"location_id" validate:"required"`
+	ProductID  uuid.UUID `json:"product_id" validate:"required"`
+	BatchID    uuid.UUID `json:"batch_id" validate:"required"`
+	Quantity   int       `json:"quantity" validate:"required,min=1"`
+	Notes      string    `json:"notes"`
 }
 
 type ReduceInventoryRequest struct {
-	ProductID uuid.UUID `json:"product_id" validate:"required"`
-	BatchID   uuid.UUID `json:"batch_id" validate:"required"`
-	Quantity  int       `json:"quantity" validate:"required,min=1"`
-	Notes     string    `json:"notes"`
+	LocationID uuid.UUID `json:"location_id" validate:"required"`
+	ProductID  uuid.UUID `json:"product_id" validate:"required"`
+	BatchID    uuid.UUID `json:"batch_id" validate:"required"`
+	Quantity   int       `json:"quantity" validate:"required,min=1"`
+	Notes      string    `json:"notes"`
+}
+
+type AllocationRequestBody struct {
+	LocationID       uuid.UUID `json:"location_id" validate:"required"`
+	ProductID        uuid.UUID `json:"product_id" validate:"required"`
+	Quantity         int       `json:"quantity" validate:"required,min=1"`
+	MinShelfLifeDays int       `json:"min_shelf_life_days" validate:"min=0"`
+	Notes            string    `json:"notes"`
+}
+
+func (r AllocationRequestBody) allocationRequest() AllocationRequest {
+	return AllocationRequest{
+		LocationID:       r.LocationID,
+		ProductID:        r.ProductID,
+		Quantity:         r.Quantity,
+		MinShelfLifeDays: r.MinShelfLifeDays,
+	}
 }
@@ -0,0 +1,126 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"agromart2/db"
+	"agromart2/internal/utils"
+	"github.com/google/uuid"
+)
+
+// Ledger transaction types, as enforced by inventory_log's check constraints. Inbound
+// types are logged with a positive quantity change, outbound types with a negative one,
+// and adjustments and reconciliations with either.
+const (
+	TxPurchase    = "PURCHASE"
+	TxTransferIn  = "TRANSFER_IN"
+	TxAdd         = "ADD"
+	TxSale        = "SALE"
+	TxIssue       = "ISSUE"
+	TxTransferOut = "TRANSFER_OUT"
+	TxReduce      = "REDUCE"
+	TxAdjustment  = "ADJUSTMENT"
+	// TxReconciliation brings the ledger back in line with stock changed without being logged
+	TxReconciliation = "RECONCILIATION"
+)
+
+// TransactionTypes lists every ledger transaction type
+var TransactionTypes = []string{TxPurchase, TxTransferIn, TxAdd, TxSale, TxIssue, TxTransferOut, TxReduce, TxAdjustment, TxReconciliation}
+
+var ErrInvalidPeriod = errors.New("statement period must start on or before it ends")
+
+// LedgerFilter narrows ledger queries to a product, batch or location; nil matches all
+type LedgerFilter struct {
+	ProductID  *uuid.UUID
+	BatchID    *uuid.UUID
+	LocationID *uuid.UUID
+}
+
+// StockStatement is the stock movement between two dates with its opening and closing balances
+type StockStatement struct {
+	From           time.Time                 `json:"from"`
+	To             time.Time                 `json:"to"`
+	OpeningBalance int                       `json:"opening_balance"`
+	QuantityIn     int                       `json:"quantity_in"`
+	QuantityOut    int                       `json:"quantity_out"`
+	ClosingBalance int                       `json:"closing_balance"`
+	Entries        []db.ListLedgerEntriesRow `json:"entries"`
+}
+
+// StockAsOf returns the stock of every batch and location matching the filter as it stood
+// at the end of the given day: the sum of the ledger entries dated before then. Running
+// balances follow entry_no, which a long transaction can assign after a later-dated entry,
+// so they are not used to read a past date.
+func (s *InventoryService) StockAsOf(ctx context.Context, tenantID uuid.UUID, date time.Time, filter LedgerFilter) ([]db.ListStockAsOfRow, error) {
+	rows, err := s.queries.ListStockAsOf(ctx, db.ListStockAsOfParams{
+		TenantID:   tenantID,
+		AsOf:       endOfDay(date),
+		ProductID:  utils.P.UUIDPtr(filter.ProductID),
+		BatchID:    utils.P.UUIDPtr(filter.BatchID),
+		LocationID: utils.P.UUIDPtr(filter.LocationID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock as of %s: %w", date.Format("2006-01-02"), err)
+	}
+	return rows, nil
+}
+
+// Statement lists the ledger entries matching the filter from the start of one day to the
+// end of another, with the balance before the first and after the last
+func (s *InventoryService) Statement(ctx context.Context, tenantID uuid.UUID, from, to time.Time, filter LedgerFilter) (*StockStatement, error) {
+	if from.After(to) {
+		return nil, ErrInvalidPeriod
+	}
+
+	opening, err := s.queries.GetLedgerBalanceAsOf(ctx, db.GetLedgerBalanceAsOfParams{
+		TenantID:   tenantID,
+		AsOf:       startOfDay(from),
+		ProductID:  utils.P.UUIDPtr(filter.ProductID),
+		BatchID:    utils.P.UUIDPtr(filter.BatchID),
+		LocationID: utils.P.UUIDPtr(filter.LocationID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get opening balance: %w", err)
+	}
+
+	entries, err := s.queries.ListLedgerEntries(ctx, db.ListLedgerEntriesParams{
+		TenantID:   tenantID,
+		FromDate:   startOfDay(from),
+		ToDate:     endOfDay(to),
+		ProductID:  utils.P.UUIDPtr(filter.ProductID),
+		BatchID:    utils.P.UUIDPtr(filter.BatchID),
+		LocationID: utils.P.UUIDPtr(filter.LocationID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ledger entries: %w", err)
+	}
+
+	statement := &StockStatement{
+		From:           startOfDay(from),
+		To:             startOfDay(to),
+		OpeningBalance: utils.PgNumericToInt(opening),
+		Entries:        entries,
+	}
+	for _, entry := range entries {
+		if change := utils.PgNumericToInt(entry.QuantityChange); change > 0 {
+			statement.QuantityIn += change
+		} else {
+			statement.QuantityOut -= change
+		}
+	}
+	statement.ClosingBalance = statement.OpeningBalance + statement.QuantityIn - statement.QuantityOut
+
+	return statement, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// endOfDay is the first instant of the following day, used as an exclusive bound
+func endOfDay(t time.Time) time.Time {
+	return startOfDay(t).AddDate(0, 0, 1)
+}
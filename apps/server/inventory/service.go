@@ -24,16 +24,40 @@ func NewService(db *pgxpool.Pool, q *db.Queries) *InventoryService {
 	}
 }
 
-func (s *InventoryService) AddInventoryQuantity(ctx context.Context, tenantID, productID, batchID uuid.UUID, quantity int) error {
-	args := db.AddInventoryQuantityParams{
-		TenantID:  tenantID,
-		ProductID: productID,
-		BatchID:   batchID,
-		Quantity:  utils.P.Numeric(quantity),
+// AddInventoryQuantity adds quantity to a batch at a location and logs it as an ADD entry in
+// the same transaction, so stock and ledger never disagree
+func (s *InventoryService) AddInventoryQuantity(ctx context.Context, tenantID, locationID, productID, batchID uuid.UUID, quantity int, notes string) error {
+	if quantity <= 0 {
+		return ErrInvalidQuantity
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	err = qtx.AddInventoryQuantity(ctx, db.AddInventoryQuantityParams{
+		TenantID:   tenantID,
+		ProductID:  productID,
+		BatchID:    batchID,
+		Quantity:   utils.P.Numeric(quantity),
+		LocationID: locationID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add inventory: %w", err)
+	}
+
+	if err := logManualMovement(ctx, qtx, tenantID, locationID, productID, batchID, TxAdd, quantity, notes); err != nil {
+		return err
 	}
-	err := s.queries.AddInventoryQuantity(ctx, args)
 
-	return err
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
 }
 
 func (s *InventoryService) CreateBatch(ctx context.Context, tenantID, productID uuid.UUID, batchNumber string, expiryDate time.Time, cost int) (db.Batch, error) {
@@ -56,50 +80,79 @@ func (s *InventoryService) GetBatchByID(ctx context.Context, id, tenantID uuid.U
 	return s.queries.GetBatchByID(ctx, args)
 }
 
-func (s *InventoryService) GetInventoryByProductBatch(ctx context.Context, tenantID, productID, batchID uuid.UUID) (db.Inventory, error) {
+func (s *InventoryService) GetInventoryByProductBatch(ctx context.Context, tenantID, locationID, productID, batchID uuid.UUID) (db.Inventory, error) {
 	args := db.GetInventoryByProductBatchParams{
-		TenantID:  tenantID,
-		ProductID: productID,
-		BatchID:   batchID,
+		TenantID:   tenantID,
+		ProductID:  productID,
+		BatchID:    batchID,
+		LocationID: locationID,
 	}
 	return s.queries.GetInventoryByProductBatch(ctx, args)
 }
 
-func (s *InventoryService) GetProductQuantity(ctx context.Context, tenantID, productID uuid.UUID) (interface{}, error) {
+// GetProductQuantity returns the quantity on hand, at one location when locationID is set
+func (s *InventoryService) GetProductQuantity(ctx context.Context, tenantID, productID uuid.UUID, locationID *uuid.UUID) (interface{}, error) {
 	args := db.GetProductQuantityParams{
-		TenantID:  tenantID,
-		ProductID: productID,
+		TenantID:   tenantID,
+		ProductID:  productID,
+		LocationID: utils.P.UUIDPtr(locationID),
 	}
 	return s.queries.GetProductQuantity(ctx, args)
 }
 
-func (s *InventoryService) ListAllInventory(ctx context.Context, tenantID uuid.UUID, limit, offset int32) ([]db.ListAllInventoryRow, error) {
+func (s *InventoryService) ListAllInventory(ctx context.Context, tenantID uuid.UUID, locationID *uuid.UUID, limit, offset int32) ([]db.ListAllInventoryRow, error) {
 	args := db.ListAllInventoryParams{
-		TenantID: tenantID,
-		Limit:    limit,
-		Offset:   offset,
+		TenantID:   tenantID,
+		LocationID: utils.P.UUIDPtr(locationID),
+		Limit:      limit,
+		Offset:     offset,
 	}
 	return s.queries.ListAllInventory(ctx, args)
 }
 
-func (s *InventoryService) ReduceInventoryQuantity(ctx context.Context, tenantID, productID, batchID uuid.UUID, quantity int) error {
-	args := db.ReduceInventoryQuantityParams{
-		Quantity:  utils.P.Numeric(quantity),
-		TenantID:  tenantID,
-		ProductID: productID,
-		BatchID:   batchID,
+// ReduceInventoryQuantity takes quantity out of a batch at a location and logs it as a REDUCE
+// entry in the same transaction. It fails with an *InsufficientStockError rather than going
+// below zero, and returns what is left.
+func (s *InventoryService) ReduceInventoryQuantity(ctx context.Context, tenantID, locationID, productID, batchID uuid.UUID, quantity int, notes string) (int, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	remaining, err := Decrement(ctx, qtx, tenantID, locationID, productID, batchID, quantity)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := logManualMovement(ctx, qtx, tenantID, locationID, productID, batchID, TxReduce, -quantity, notes); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
 	}
-	return s.queries.ReduceInventoryQuantity(ctx, args)
+	return remaining, nil
 }
 
-func (s *InventoryService) SetInventoryQuantity(ctx context.Context, tenantID, productID, batchID uuid.UUID, quantity int) error {
-	args := db.SetInventoryQuantityParams{
-		Quantity:  utils.P.Numeric(quantity),
-		TenantID:  tenantID,
-		ProductID: productID,
-		BatchID:   batchID,
+// logManualMovement logs a stock change made directly rather than through a document. It has
+// no reference; who made it is recorded in the audit trail.
+func logManualMovement(ctx context.Context, q *db.Queries, tenantID, locationID, productID, batchID uuid.UUID, transactionType string, quantityChange int, notes string) error {
+	err := q.CreateInventoryLog(ctx, db.CreateInventoryLogParams{
+		TenantID:        tenantID,
+		LocationID:      locationID,
+		ProductID:       productID,
+		BatchID:         batchID,
+		TransactionType: transactionType,
+		QuantityChange:  utils.P.Numeric(quantityChange),
+		Notes:           utils.P.Text(notes),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to log inventory change: %w", err)
 	}
-	return s.queries.SetInventoryQuantity(ctx, args)
+	return nil
 }
 
 func (s *InventoryService) UpdateBatch(ctx context.Context, id, tenantID uuid.UUID, batchNumber string, expiryDate time.Time, cost int) (db.Batch, error) {
@@ -113,18 +166,20 @@ func (s *InventoryService) UpdateBatch(ctx context.Context, id, tenantID uuid.UU
 	return s.queries.UpdateBatch(ctx, args)
 }
 
-func (s *InventoryService) GetProductInventoryDetails(ctx context.Context, tenantID, productID uuid.UUID) ([]db.GetProductInventoryDetailsRow, error) {
+func (s *InventoryService) GetProductInventoryDetails(ctx context.Context, tenantID, productID uuid.UUID, locationID *uuid.UUID) ([]db.GetProductInventoryDetailsRow, error) {
 	args := db.GetProductInventoryDetailsParams{
-		TenantID:  tenantID,
-		ProductID: productID,
+		TenantID:   tenantID,
+		ProductID:  productID,
+		LocationID: utils.P.UUIDPtr(locationID),
 	}
 	return s.queries.GetProductInventoryDetails(ctx, args)
 }
 
-func (s *InventoryService) GetLowStockReport(ctx context.Context, tenantID uuid.UUID, threshold int) ([]db.GetLowStockReportRow, error) {
+func (s *InventoryService) GetLowStockReport(ctx context.Context, tenantID uuid.UUID, locationID *uuid.UUID, threshold int) ([]db.GetLowStockReportRow, error) {
 	args := db.GetLowStockReportParams{
-		TenantID: tenantID,
-		Quantity: utils.P.Numeric(threshold),
+		TenantID:   tenantID,
+		LocationID: utils.P.UUIDPtr(locationID),
+		Threshold:  utils.P.Numeric(threshold),
 	}
 	return s.queries.GetLowStockReport(ctx, args)
 }
@@ -149,37 +204,28 @@ func (s *InventoryService) GetInventoryLogByBatch(ctx context.Context, tenantID,
 	return s.queries.GetInventoryLogByBatch(ctx, args)
 }
 
-func (s *InventoryService) CreateInventoryLog(ctx context.Context, tenantID, productID, batchID, referenceID uuid.UUID, transactionType string, quantityChange int, notes string) error {
-	args := db.CreateInventoryLogParams{
-		TenantID:        tenantID,
-		ProductID:       productID,
-		BatchID:         batchID,
-		TransactionType: transactionType,
-		QuantityChange:  utils.P.Numeric(quantityChange),
-		ReferenceID:     utils.P.UUID(referenceID),
-		Notes:           utils.P.Text(notes),
-	}
-	return s.queries.CreateInventoryLog(ctx, args)
-}
-
 // GetExpiringBatches gets batches that are expiring within specified days
-func (s *InventoryService) GetExpiringBatches(ctx context.Context, tenantID uuid.UUID, days int) ([]db.GetExpiringBatchesRow, error) {
+func (s *InventoryService) GetExpiringBatches(ctx context.Context, tenantID uuid.UUID, locationID *uuid.UUID, days int) ([]db.GetExpiringBatchesRow, error) {
 	expiryDate := time.Now().AddDate(0, 0, days)
 	
 	args := db.GetExpiringBatchesParams{
 		TenantID:   tenantID,
 		ExpiryDate: expiryDate,
+		LocationID: utils.P.UUIDPtr(locationID),
 	}
 	return s.queries.GetExpiringBatches(ctx, args)
 }
 
-// GetInventoryValue calculates total inventory value for a tenant
-func (s *InventoryService) GetInventoryValue(ctx context.Context, tenantID uuid.UUID) (interface{}, error) {
-	return s.queries.GetInventoryValue(ctx, tenantID)
+// GetInventoryValue calculates total inventory value for a tenant, optionally for one location
+func (s *InventoryService) GetInventoryValue(ctx context.Context, tenantID uuid.UUID, locationID *uuid.UUID) (interface{}, error) {
+	return s.queries.GetInventoryValue(ctx, db.GetInventoryValueParams{
+		TenantID:   tenantID,
+		LocationID: utils.P.UUIDPtr(locationID),
+	})
 }
 
-// TransferInventory transfers inventory between batches (for batch corrections)
-func (s *InventoryService) TransferInventory(ctx context.Context, tenantID, productID, fromBatchID, toBatchID uuid.UUID, quantity int, referenceID uuid.UUID, notes string) error {
+// TransferInventory transfers inventory between batches at a location (for batch corrections)
+func (s *InventoryService) TransferInventory(ctx context.Context, tenantID, locationID, productID, fromBatchID, toBatchID uuid.UUID, quantity int, referenceID uuid.UUID, notes string) error {
 	// Start transaction
 	tx, err := s.db.Begin(ctx)
 	if err != nil {
@@ -190,22 +236,17 @@ func (s *InventoryService) TransferInventory(ctx context.Context, tenantID, prod
 	qtx := s.queries.WithTx(tx)
 
 	// Reduce from source batch
-	err = qtx.ReduceInventoryQuantity(ctx, db.ReduceInventoryQuantityParams{
-		Quantity:  utils.P.Numeric(quantity),
-		TenantID:  tenantID,
-		ProductID: productID,
-		BatchID:   fromBatchID,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to reduce from source batch: %w", err)
+	if _, err := Decrement(ctx, qtx, tenantID, locationID, productID, fromBatchID, quantity); err != nil {
+		return fmt.Errorf("source batch: %w", err)
 	}
 
 	// Add to destination batch
 	err = qtx.AddInventoryQuantity(ctx, db.AddInventoryQuantityParams{
-		TenantID:  tenantID,
-		ProductID: productID,
-		BatchID:   toBatchID,
-		Quantity:  utils.P.Numeric(quantity),
+		TenantID:   tenantID,
+		ProductID:  productID,
+		BatchID:    toBatchID,
+		Quantity:   utils.P.Numeric(quantity),
+		LocationID: locationID,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to add to destination batch: %w", err)
@@ -216,8 +257,9 @@ func (s *InventoryService) TransferInventory(ctx context.Context, tenantID, prod
 		TenantID:        tenantID,
 		ProductID:       productID,
 		BatchID:         fromBatchID,
-		TransactionType: "TRANSFER_OUT",
-		QuantityChange:  utils.P.Numeric(quantity),
+		LocationID:      locationID,
+		TransactionType: TxTransferOut,
+		QuantityChange:  utils.P.Numeric(-quantity),
 		ReferenceID:     utils.P.UUID(referenceID),
 		Notes:           utils.P.Text(fmt.Sprintf("Transfer to batch %s: %s", toBatchID, notes)),
 	})
@@ -229,7 +271,8 @@ func (s *InventoryService) TransferInventory(ctx context.Context, tenantID, prod
 		TenantID:        tenantID,
 		ProductID:       productID,
 		BatchID:         toBatchID,
-		TransactionType: "TRANSFER_IN",
+		LocationID:      locationID,
+		TransactionType: TxTransferIn,
 		QuantityChange:  utils.P.Numeric(quantity),
 		ReferenceID:     utils.P.UUID(referenceID),
 		Notes:           utils.P.Text(fmt.Sprintf("Transfer from batch %s: %s", fromBatchID, notes)),
@@ -249,8 +292,8 @@ func (s *InventoryService) TransferInventory(ctx context.Context, tenantID, prod
 }
 
 // CheckInventoryAvailability checks if enough inventory is available for a specific product and batch
-func (s *InventoryService) CheckInventoryAvailability(ctx context.Context, tenantID, productID, batchID uuid.UUID, requiredQuantity int) (bool, error) {
-	inventory, err := s.GetInventoryByProductBatch(ctx, tenantID, productID, batchID)
+func (s *InventoryService) CheckInventoryAvailability(ctx context.Context, tenantID, locationID, productID, batchID uuid.UUID, requiredQuantity int) (bool, error) {
+	inventory, err := s.GetInventoryByProductBatch(ctx, tenantID, locationID, productID, batchID)
 	if err != nil {
 		return false, fmt.Errorf("failed to get inventory: %w", err)
 	}
@@ -260,8 +303,21 @@ func (s *InventoryService) CheckInventoryAvailability(ctx context.Context, tenan
 	return currentQuantity.Float64 >= float64(requiredQuantity), nil
 }
 
-// GetInventorySummary gets a summary of inventory for dashboard
-func (s *InventoryService) GetInventorySummary(ctx context.Context, tenantID uuid.UUID) (map[string]interface{}, error) {
+// GetInTransitStock lists stock dispatched on transfers but not yet received, optionally
+// only stock inbound to or outbound from one location
+func (s *InventoryService) GetInTransitStock(ctx context.Context, tenantID uuid.UUID, locationID *uuid.UUID) ([]db.ListInTransitStockRow, error) {
+	rows, err := s.queries.ListInTransitStock(ctx, db.ListInTransitStockParams{
+		TenantID:   tenantID,
+		LocationID: utils.P.UUIDPtr(locationID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list in-transit stock: %w", err)
+	}
+	return rows, nil
+}
+
+// GetInventorySummary gets a summary of inventory for dashboard, optionally for one location
+func (s *InventoryService) GetInventorySummary(ctx context.Context, tenantID uuid.UUID, locationID *uuid.UUID) (map[string]interface{}, error) {
 	// Get total products
 	totalProducts, err := s.queries.CountProductsByTenant(ctx, tenantID)
 	if err != nil {
@@ -269,27 +325,37 @@ func (s *InventoryService) GetInventorySummary(ctx context.Context, tenantID uui
 	}
 
 	// Get low stock count
-	lowStockProducts, err := s.GetLowStockReport(ctx, tenantID, 10)
+	lowStockProducts, err := s.GetLowStockReport(ctx, tenantID, locationID, 10)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get low stock count: %w", err)
 	}
 
 	// Get total inventory value
-	totalValue, err := s.GetInventoryValue(ctx, tenantID)
+	totalValue, err := s.GetInventoryValue(ctx, tenantID, locationID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get inventory value: %w", err)
 	}
 
 	// Get expiring batches (within 30 days)
-	expiringBatches, err := s.GetExpiringBatches(ctx, tenantID, 30)
+	expiringBatches, err := s.GetExpiringBatches(ctx, tenantID, locationID, 30)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get expiring batches: %w", err)
 	}
 
+	// Get stock dispatched towards the location (or anywhere) but not yet received
+	inTransit, err := s.queries.GetInTransitQuantity(ctx, db.GetInTransitQuantityParams{
+		TenantID:   tenantID,
+		LocationID: utils.P.UUIDPtr(locationID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get in-transit quantity: %w", err)
+	}
+
 	return map[string]interface{}{
 		"total_products":     totalProducts,
 		"low_stock_count":    len(lowStockProducts),
 		"total_value":        totalValue,
 		"expiring_batches":   len(expiringBatches),
+		"in_transit_quantity": inTransit,
 	}, nil
 }
@@ -0,0 +1,312 @@
+package locations
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"agromart2/internal/auth"
+	"agromart2/internal/database"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	service *LocationService
+}
+
+func NewHandler(service *LocationService) *Handler {
+	return &Handler{service: service}
+}
+
+// CreateLocation creates a new location
+func (h *Handler) CreateLocation(c echo.Context) error {
+	var req LocationRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	// New locations are active unless explicitly created inactive
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	location, err := h.service.CreateLocation(c.Request().Context(), req.toParams(tenantID, isActive))
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    location,
+		"message": "Location created successfully",
+	})
+}
+
+// GetLocation retrieves a location by ID
+func (h *Handler) GetLocation(c echo.Context) error {
+	locationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid location ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	location, err := h.service.GetLocation(c.Request().Context(), locationID, tenantID)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    location,
+	})
+}
+
+// ListLocations lists locations with optional location_type/active filters
+func (h *Handler) ListLocations(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	var isActive *bool
+	if activeStr := c.QueryParam("active"); activeStr != "" {
+		active, err := strconv.ParseBool(activeStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "active must be true or false")
+		}
+		isActive = &active
+	}
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offset := int32((page - 1) * limit)
+
+	locations, err := h.service.ListLocations(c.Request().Context(), tenantID, c.QueryParam("location_type"), isActive, int32(limit), offset)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    locations,
+		"pagination": map[string]interface{}{
+			"page":  page,
+			"limit": limit,
+		},
+	})
+}
+
+// UpdateLocation updates a location
+func (h *Handler) UpdateLocation(c echo.Context) error {
+	locationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid location ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	var req LocationRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	// Leaving is_active out of an update keeps the current status
+	current, err := h.service.GetLocation(c.Request().Context(), locationID, tenantID)
+	if err != nil {
+		return toHTTPError(err)
+	}
+	isActive := current.IsActive
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	location, err := h.service.UpdateLocation(c.Request().Context(), locationID, req.toParams(tenantID, isActive))
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    location,
+		"message": "Location updated successfully",
+	})
+}
+
+// ActivateLocation marks a location as active
+func (h *Handler) ActivateLocation(c echo.Context) error {
+	return h.setActive(c, true, "Location activated successfully")
+}
+
+// DeactivateLocation marks a location as inactive
+func (h *Handler) DeactivateLocation(c echo.Context) error {
+	return h.setActive(c, false, "Location deactivated successfully")
+}
+
+func (h *Handler) setActive(c echo.Context, active bool, message string) error {
+	locationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid location ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	location, err := h.service.SetActive(c.Request().Context(), locationID, tenantID, active)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    location,
+		"message": message,
+	})
+}
+
+// DeleteLocation deletes a location that holds no stock or history
+func (h *Handler) DeleteLocation(c echo.Context) error {
+	locationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid location ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	if err := h.service.DeleteLocation(c.Request().Context(), locationID, tenantID); err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Location deleted successfully",
+	})
+}
+
+// GetStockSummaries gets stock totals for every location
+func (h *Handler) GetStockSummaries(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	summaries, err := h.service.GetStockSummaries(c.Request().Context(), tenantID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    summaries,
+	})
+}
+
+// GetStockSummary gets stock totals for one location
+func (h *Handler) GetStockSummary(c echo.Context) error {
+	locationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid location ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	summary, err := h.service.GetStockSummary(c.Request().Context(), locationID, tenantID)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    summary,
+	})
+}
+
+// RegisterRoutes registers all location routes
+func (h *Handler) RegisterRoutes(g *echo.Group) {
+	g.POST("/locations", h.CreateLocation, auth.RequirePermission(auth.PermLocationsManage))
+	g.GET("/locations", h.ListLocations, auth.RequirePermission(auth.PermLocationsView))
+	g.GET("/locations/stock-summary", h.GetStockSummaries, auth.RequirePermission(auth.PermInventoryView))
+	g.GET("/locations/:id", h.GetLocation, auth.RequirePermission(auth.PermLocationsView))
+	g.PUT("/locations/:id", h.UpdateLocation, auth.RequirePermission(auth.PermLocationsManage))
+	g.DELETE("/locations/:id", h.DeleteLocation, auth.RequirePermission(auth.PermLocationsManage))
+	g.POST("/locations/:id/activate", h.ActivateLocation, auth.RequirePermission(auth.PermLocationsManage))
+	g.POST("/locations/:id/deactivate", h.DeactivateLocation, auth.RequirePermission(auth.PermLocationsManage))
+	g.GET("/locations/:id/stock-summary", h.GetStockSummary, auth.RequirePermission(auth.PermInventoryView))
+}
+
+// toHTTPError maps service errors to HTTP errors
+func toHTTPError(err error) error {
+	switch {
+	case database.IsNotFound(err):
+		return echo.NewHTTPError(http.StatusNotFound, "location not found")
+	case errors.Is(err, ErrNameRequired), errors.Is(err, ErrInvalidLocationType):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case database.IsDuplicateKey(err):
+		return echo.NewHTTPError(http.StatusConflict, "a location with this name already exists")
+	case database.IsForeignKeyViolation(err):
+		return echo.NewHTTPError(http.StatusConflict, "location is in use by stock, orders or transfers; deactivate it instead")
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// Request types
+type LocationRequest struct {
+	Name         string `json:"name" validate:"required"`
+	Address      string `json:"address"`
+	City         string `json:"city"`
+	State        string `json:"state"`
+	PostalCode   string `json:"postal_code"`
+	Country      string `json:"country"`
+	Phone        string `json:"phone"`
+	Email        string `json:"email"`
+	LocationType string `json:"location_type"`
+	IsActive     *bool  `json:"is_active"`
+	Notes        string `json:"notes"`
+}
+
+func (r LocationRequest) toParams(tenantID uuid.UUID, isActive bool) LocationParams {
+	return LocationParams{
+		TenantID:     tenantID,
+		Name:         r.Name,
+		Address:      r.Address,
+		City:         r.City,
+		State:        r.State,
+		PostalCode:   r.PostalCode,
+		Country:      r.Country,
+		Phone:        r.Phone,
+		Email:        r.Email,
+		LocationType: r.LocationType,
+		IsActive:     isActive,
+		Notes:        r.Notes,
+	}
+}
@@ -0,0 +1,224 @@
+package locations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"agromart2/db"
+	"agromart2/internal/database"
+	"agromart2/internal/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// Location types as stored in locations.location_type
+const (
+	TypeWarehouse          = "WAREHOUSE"
+	TypeStore              = "STORE"
+	TypeOffice             = "OFFICE"
+	TypeDistributionCenter = "DISTRIBUTION_CENTER"
+)
+
+var (
+	ErrNameRequired        = errors.New("location name is required")
+	ErrInvalidLocationType = errors.New("location_type must be one of WAREHOUSE, STORE, OFFICE, DISTRIBUTION_CENTER")
+)
+
+// ValidLocationType reports whether t is a known location type
+func ValidLocationType(t string) bool {
+	switch t {
+	case TypeWarehouse, TypeStore, TypeOffice, TypeDistributionCenter:
+		return true
+	}
+	return false
+}
+
+type LocationService struct {
+	db *pgxpool.Pool
+	q  *db.Queries
+}
+
+func NewLocationService(db *pgxpool.Pool, queries *db.Queries) *LocationService {
+	return &LocationService{
+		db: db,
+		q:  queries,
+	}
+}
+
+type LocationParams struct {
+	TenantID     uuid.UUID
+	Name         string
+	Address      string
+	City         string
+	State        string
+	PostalCode   string
+	Country      string
+	Phone        string
+	Email        string
+	LocationType string
+	IsActive     bool
+	Notes        string
+}
+
+// normalize trims the name and upper-cases the type, defaulting it to WAREHOUSE
+func (p *LocationParams) normalize() error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return ErrNameRequired
+	}
+	p.LocationType = strings.ToUpper(strings.TrimSpace(p.LocationType))
+	if p.LocationType == "" {
+		p.LocationType = TypeWarehouse
+	}
+	if !ValidLocationType(p.LocationType) {
+		return ErrInvalidLocationType
+	}
+	return nil
+}
+
+// CreateLocation creates a new location
+func (s *LocationService) CreateLocation(ctx context.Context, params LocationParams) (db.Location, error) {
+	if err := params.normalize(); err != nil {
+		return db.Location{}, err
+	}
+
+	location, err := s.q.CreateLocation(ctx, db.CreateLocationParams{
+		TenantID:     params.TenantID,
+		Name:         params.Name,
+		Address:      utils.P.Text(params.Address),
+		City:         utils.P.Text(params.City),
+		State:        utils.P.Text(params.State),
+		PostalCode:   utils.P.Text(params.PostalCode),
+		Country:      utils.P.Text(params.Country),
+		Phone:        utils.P.Text(params.Phone),
+		Email:        utils.P.Text(params.Email),
+		LocationType: params.LocationType,
+		IsActive:     params.IsActive,
+		Notes:        utils.P.Text(params.Notes),
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("failed to create location")
+		return db.Location{}, database.WrapError(err, "failed to create location")
+	}
+
+	return location, nil
+}
+
+// GetLocation retrieves a location by ID
+func (s *LocationService) GetLocation(ctx context.Context, id, tenantID uuid.UUID) (db.Location, error) {
+	location, err := s.q.GetLocationByID(ctx, db.GetLocationByIDParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return db.Location{}, database.WrapError(err, "failed to get location")
+	}
+
+	return location, nil
+}
+
+// ListLocations lists locations, optionally filtered by type and active flag
+func (s *LocationService) ListLocations(ctx context.Context, tenantID uuid.UUID, locationType string, isActive *bool, limit, offset int32) ([]db.Location, error) {
+	locationType = strings.ToUpper(locationType)
+	if locationType != "" && !ValidLocationType(locationType) {
+		return nil, ErrInvalidLocationType
+	}
+
+	locations, err := s.q.ListLocations(ctx, db.ListLocationsParams{
+		TenantID:     tenantID,
+		LocationType: utils.P.Text(locationType),
+		IsActive:     utils.P.BoolPtr(isActive),
+		Limit:        limit,
+		Offset:       offset,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("failed to list locations")
+		return []db.Location{}, fmt.Errorf("failed to list locations: %w", err)
+	}
+
+	return locations, nil
+}
+
+// UpdateLocation updates a location
+func (s *LocationService) UpdateLocation(ctx context.Context, id uuid.UUID, params LocationParams) (db.Location, error) {
+	if err := params.normalize(); err != nil {
+		return db.Location{}, err
+	}
+
+	location, err := s.q.UpdateLocation(ctx, db.UpdateLocationParams{
+		ID:           id,
+		Name:         params.Name,
+		Address:      utils.P.Text(params.Address),
+		City:         utils.P.Text(params.City),
+		State:        utils.P.Text(params.State),
+		PostalCode:   utils.P.Text(params.PostalCode),
+		Country:      utils.P.Text(params.Country),
+		Phone:        utils.P.Text(params.Phone),
+		Email:        utils.P.Text(params.Email),
+		LocationType: params.LocationType,
+		IsActive:     params.IsActive,
+		Notes:        utils.P.Text(params.Notes),
+		TenantID:     params.TenantID,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("failed to update location")
+		return db.Location{}, database.WrapError(err, "failed to update location")
+	}
+
+	return location, nil
+}
+
+// SetActive activates or deactivates a location
+func (s *LocationService) SetActive(ctx context.Context, id, tenantID uuid.UUID, active bool) (db.Location, error) {
+	location, err := s.q.SetLocationActive(ctx, db.SetLocationActiveParams{
+		ID:       id,
+		TenantID: tenantID,
+		IsActive: active,
+	})
+	if err != nil {
+		return db.Location{}, database.WrapError(err, "failed to update location status")
+	}
+
+	return location, nil
+}
+
+// DeleteLocation permanently deletes a location. Locations still referenced by stock,
+// orders or transfers cannot be deleted and should be deactivated instead.
+func (s *LocationService) DeleteLocation(ctx context.Context, id, tenantID uuid.UUID) error {
+	if _, err := s.GetLocation(ctx, id, tenantID); err != nil {
+		return err
+	}
+
+	if err := s.q.DeleteLocation(ctx, db.DeleteLocationParams{ID: id, TenantID: tenantID}); err != nil {
+		log.Error().Err(err).Msg("failed to delete location")
+		return database.WrapError(err, "failed to delete location")
+	}
+
+	return nil
+}
+
+// GetStockSummaries returns product, batch, quantity and value totals for every location
+func (s *LocationService) GetStockSummaries(ctx context.Context, tenantID uuid.UUID) ([]db.ListLocationStockSummaryRow, error) {
+	summaries, err := s.q.ListLocationStockSummary(ctx, db.ListLocationStockSummaryParams{TenantID: tenantID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get location stock summary: %w", err)
+	}
+
+	return summaries, nil
+}
+
+// GetStockSummary returns stock totals for a single location
+func (s *LocationService) GetStockSummary(ctx context.Context, id, tenantID uuid.UUID) (db.ListLocationStockSummaryRow, error) {
+	summaries, err := s.q.ListLocationStockSummary(ctx, db.ListLocationStockSummaryParams{
+		TenantID:   tenantID,
+		LocationID: utils.P.UUID(id),
+	})
+	if err != nil {
+		return db.ListLocationStockSummaryRow{}, fmt.Errorf("failed to get location stock summary: %w", err)
+	}
+	if len(summaries) == 0 {
+		return db.ListLocationStockSummaryRow{}, database.ErrNotFound
+	}
+
+	return summaries[0], nil
+}
@@ -13,6 +13,7 @@ import (
 	"agromart2/internal/db"
 	"agromart2/apps/server/products"
 	"agromart2/apps/server/inventory"
+	"agromart2/apps/server/reports"
 )
 
 func main() {
@@ -35,10 +36,12 @@ func main() {
 	authService := auth.NewAuthService(queries, database)
 	productService := products.NewService(queries)
 	inventoryService := inventory.NewService(nil, queries) // Note: pgxpool not used in current service
+	reportService := reports.NewReportService(nil, queries, inventoryService)
 
 	// Initialize handlers
 	productHandler := products.NewHandler(productService)
 	inventoryHandler := inventory.NewHandler(inventoryService)
+	reportHandler := reports.NewHandler(reportService)
 
 	// Initialize Echo
 	e := echo.New()
@@ -100,35 +103,7 @@ func main() {
 	inventoryHandler.RegisterRoutes(inventoryGroup)
 
 	// Reports routes
-	reportsGroup := protected.Group("/reports")
-	reportsGroup.GET("/dashboard-stats", func(c echo.Context) error {
-		// TODO: Implement dashboard stats
-		return c.JSON(200, map[string]interface{}{
-			"success": true,
-			"data": map[string]interface{}{
-				"total_products":     150,
-				"low_stock_count":    12,
-				"total_value":        45000,
-				"expiring_batches":   8,
-			},
-		})
-	})
-	
-	reportsGroup.GET("/low-stock", func(c echo.Context) error {
-		// TODO: Implement low stock report
-		return c.JSON(200, map[string]interface{}{
-			"success": true,
-			"data":    []interface{}{},
-		})
-	})
-	
-	reportsGroup.GET("/expiring-batches", func(c echo.Context) error {
-		// TODO: Implement expiring batches report
-		return c.JSON(200, map[string]interface{}{
-			"success": true,
-			"data":    []interface{}{},
-		})
-	})
+	reportHandler.RegisterRoutes(protected)
 
 	// Start server
 	port := getPort()
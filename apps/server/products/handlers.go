@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"agromart2/internal/auth"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
@@ -225,14 +226,14 @@ func (h *Handler) ListUnits(c echo.Context) error {
 
 // RegisterRoutes registers all product routes
 func (h *Handler) RegisterRoutes(g *echo.Group) {
-	g.POST("/products", h.CreateProduct)
-	g.GET("/products", h.ListProducts)
-	g.GET("/products/search", h.SearchProducts)
-	g.GET("/products/:id", h.GetProduct)
-	g.PUT("/products/:id", h.UpdateProduct)
+	g.POST("/products", h.CreateProduct, auth.RequirePermission(auth.PermProductsManage))
+	g.GET("/products", h.ListProducts, auth.RequirePermission(auth.PermProductsView))
+	g.GET("/products/search", h.SearchProducts, auth.RequirePermission(auth.PermProductsView))
+	g.GET("/products/:id", h.GetProduct, auth.RequirePermission(auth.PermProductsView))
+	g.PUT("/products/:id", h.UpdateProduct, auth.RequirePermission(auth.PermProductsManage))
 	
-	g.POST("/units", h.CreateUnit)
-	g.GET("/units", h.ListUnits)
+	g.POST("/units", h.CreateUnit, auth.RequirePermission(auth.PermProductsManage))
+	g.GET("/units", h.ListUnits, auth.RequirePermission(auth.PermProductsView))
 }
 
 // Request/Response types
@@ -0,0 +1,368 @@
+package purchaseorders
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"agromart2/internal/auth"
+	"agromart2/internal/database"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	service *PurchaseOrderService
+}
+
+func NewHandler(service *PurchaseOrderService) *Handler {
+	return &Handler{service: service}
+}
+
+// CreatePurchaseOrder creates a new purchase order with line items
+func (h *Handler) CreatePurchaseOrder(c echo.Context) error {
+	var req PurchaseOrderRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if req.SupplierID == uuid.Nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "supplier_id is required")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	userID, err := uuid.Parse(c.Get("user_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid user ID")
+	}
+
+	po, err := h.service.CreatePurchaseOrder(c.Request().Context(), CreatePurchaseOrderParams{
+		TenantID:             tenantID,
+		PONumber:             req.PONumber,
+		SupplierID:           req.SupplierID,
+		LocationID:           req.LocationID,
+		ExpectedDeliveryDate: req.ExpectedDeliveryDate,
+		Notes:                req.Notes,
+		CreatedBy:            userID,
+		Items:                req.lineItems(),
+	})
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    po,
+		"message": "Purchase order created successfully",
+	})
+}
+
+// GetPurchaseOrder retrieves a purchase order with its line items
+func (h *Handler) GetPurchaseOrder(c echo.Context) error {
+	poID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid purchase order ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	po, err := h.service.GetPurchaseOrder(c.Request().Context(), poID, tenantID)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    po,
+	})
+}
+
+// ListPurchaseOrders lists purchase orders with optional status/supplier filters
+func (h *Handler) ListPurchaseOrders(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	var supplierID *uuid.UUID
+	if supplierIDStr := c.QueryParam("supplier_id"); supplierIDStr != "" {
+		id, err := uuid.Parse(supplierIDStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid supplier ID")
+		}
+		supplierID = &id
+	}
+
+	status := strings.ToUpper(c.QueryParam("status"))
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offset := int32((page - 1) * limit)
+
+	orders, err := h.service.ListPurchaseOrders(c.Request().Context(), tenantID, status, supplierID, int32(limit), offset)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    orders,
+		"pagination": map[string]interface{}{
+			"page":  page,
+			"limit": limit,
+		},
+	})
+}
+
+// UpdatePurchaseOrder edits a pending purchase order
+func (h *Handler) UpdatePurchaseOrder(c echo.Context) error {
+	poID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid purchase order ID")
+	}
+
+	var req PurchaseOrderRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if req.SupplierID == uuid.Nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "supplier_id is required")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	po, err := h.service.UpdatePurchaseOrder(c.Request().Context(), UpdatePurchaseOrderParams{
+		ID:                   poID,
+		TenantID:             tenantID,
+		SupplierID:           req.SupplierID,
+		LocationID:           req.LocationID,
+		ExpectedDeliveryDate: req.ExpectedDeliveryDate,
+		Notes:                req.Notes,
+		Items:                req.lineItems(),
+	})
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    po,
+		"message": "Purchase order updated successfully",
+	})
+}
+
+// ApprovePurchaseOrder approves a pending purchase order
+func (h *Handler) ApprovePurchaseOrder(c echo.Context) error {
+	poID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid purchase order ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	userID, err := uuid.Parse(c.Get("user_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid user ID")
+	}
+
+	po, err := h.service.ApprovePurchaseOrder(c.Request().Context(), poID, tenantID, userID)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    po,
+		"message": "Purchase order approved successfully",
+	})
+}
+
+// MarkOrdered marks an approved purchase order as sent to the supplier
+func (h *Handler) MarkOrdered(c echo.Context) error {
+	poID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid purchase order ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	po, err := h.service.MarkOrdered(c.Request().Context(), poID, tenantID)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    po,
+		"message": "Purchase order marked as ordered",
+	})
+}
+
+// CancelPurchaseOrder cancels a purchase order
+func (h *Handler) CancelPurchaseOrder(c echo.Context) error {
+	poID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid purchase order ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	po, err := h.service.CancelPurchaseOrder(c.Request().Context(), poID, tenantID)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    po,
+		"message": "Purchase order cancelled successfully",
+	})
+}
+
+// ReceiveGoods books a goods receipt against an ordered purchase order
+func (h *Handler) ReceiveGoods(c echo.Context) error {
+	poID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid purchase order ID")
+	}
+
+	var req ReceiveGoodsRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	lines := make([]ReceiptLine, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		lines = append(lines, ReceiptLine{
+			ItemID:      line.ItemID,
+			Quantity:    line.Quantity,
+			BatchNumber: line.BatchNumber,
+			ExpiryDate:  line.ExpiryDate,
+		})
+	}
+
+	po, err := h.service.ReceiveGoods(c.Request().Context(), ReceiveGoodsParams{
+		PurchaseOrderID: poID,
+		TenantID:        tenantID,
+		Lines:           lines,
+		Notes:           req.Notes,
+	})
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    po,
+		"message": "Goods received successfully",
+	})
+}
+
+// RegisterRoutes registers all purchase order routes
+func (h *Handler) RegisterRoutes(g *echo.Group) {
+	g.POST("/purchase-orders", h.CreatePurchaseOrder, auth.RequirePermission(auth.PermPOCreate))
+	g.GET("/purchase-orders", h.ListPurchaseOrders, auth.RequirePermission(auth.PermPOView))
+	g.GET("/purchase-orders/:id", h.GetPurchaseOrder, auth.RequirePermission(auth.PermPOView))
+	g.PUT("/purchase-orders/:id", h.UpdatePurchaseOrder, auth.RequirePermission(auth.PermPOCreate))
+	g.POST("/purchase-orders/:id/approve", h.ApprovePurchaseOrder, auth.RequirePermission(auth.PermPOApprove))
+	g.POST("/purchase-orders/:id/order", h.MarkOrdered, auth.RequirePermission(auth.PermPOApprove))
+	g.POST("/purchase-orders/:id/cancel", h.CancelPurchaseOrder, auth.RequirePermission(auth.PermPOApprove))
+	g.POST("/purchase-orders/:id/receive", h.ReceiveGoods, auth.RequirePermission(auth.PermPOReceive))
+}
+
+// toHTTPError maps service errors to HTTP errors
+func toHTTPError(err error) error {
+	switch {
+	case database.IsNotFound(err):
+		return echo.NewHTTPError(http.StatusNotFound, "purchase order not found")
+	case errors.Is(err, ErrInvalidStatusTransition), errors.Is(err, ErrNotEditable),
+		errors.Is(err, ErrNotReceivable), errors.Is(err, ErrPartiallyReceived),
+		errors.Is(err, ErrOverReceipt), errors.Is(err, ErrBatchExpiryMismatch):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, ErrNoLineItems), errors.Is(err, ErrInvalidLineItem), errors.Is(err, ErrLocationRequired),
+		errors.Is(err, ErrNoReceiptLines), errors.Is(err, ErrInvalidReceiptLine),
+		errors.Is(err, ErrUnknownItem):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case database.IsDuplicateKey(err):
+		return echo.NewHTTPError(http.StatusConflict, "purchase order number already exists")
+	case database.IsForeignKeyViolation(err):
+		return echo.NewHTTPError(http.StatusBadRequest, "referenced supplier, location or product does not exist")
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// Request types
+type PurchaseOrderRequest struct {
+	PONumber             string            `json:"po_number"`
+	SupplierID           uuid.UUID         `json:"supplier_id" validate:"required"`
+	LocationID           uuid.UUID         `json:"location_id" validate:"required"`
+	ExpectedDeliveryDate *time.Time        `json:"expected_delivery_date"`
+	Notes                string            `json:"notes"`
+	Items                []LineItemRequest `json:"items" validate:"required,min=1"`
+}
+
+type LineItemRequest struct {
+	ProductID uuid.UUID `json:"product_id" validate:"required"`
+	Quantity  int       `json:"quantity" validate:"required,min=1"`
+	UnitCost  int       `json:"unit_cost" validate:"min=0"`
+}
+
+type ReceiveGoodsRequest struct {
+	Lines []ReceiptLineRequest `json:"lines" validate:"required,min=1"`
+	Notes string               `json:"notes"`
+}
+
+type ReceiptLineRequest struct {
+	ItemID      uuid.UUID `json:"item_id" validate:"required"`
+	Quantity    int       `json:"quantity" validate:"required,min=1"`
+	BatchNumber string    `json:"batch_number" validate:"required"`
+	ExpiryDate  time.Time `json:"expiry_date" validate:"required"`
+}
+
+func (r PurchaseOrderRequest) lineItems() []LineItemParams {
+	items := make([]LineItemParams, 0, len(r.Items))
+	for _, item := range r.Items {
+		items = append(items, LineItemParams{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			UnitCost:  item.UnitCost,
+		})
+	}
+	return items
+}
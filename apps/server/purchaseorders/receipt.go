@@ -0,0 +1,232 @@
+package purchaseorders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"agromart2/apps/server/inventory"
+	"agromart2/db"
+	"agromart2/internal/database"
+	"agromart2/internal/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrNotReceivable       = errors.New("goods can only be received against an ordered purchase order")
+	ErrNoReceiptLines      = errors.New("receipt must have at least one line")
+	ErrInvalidReceiptLine  = errors.New("receipt line requires an item, positive quantity, batch number and expiry date")
+	ErrUnknownItem         = errors.New("receipt line does not belong to this purchase order")
+	ErrOverReceipt         = errors.New("received quantity exceeds quantity ordered")
+	ErrBatchExpiryMismatch = errors.New("existing batch has a different expiry date")
+	ErrPartiallyReceived   = errors.New("purchase order has received goods and cannot be cancelled")
+)
+
+// ReceiptLine is the quantity of one purchase order item received into a batch
+type ReceiptLine struct {
+	ItemID      uuid.UUID
+	Quantity    int
+	BatchNumber string
+	ExpiryDate  time.Time
+}
+
+type ReceiveGoodsParams struct {
+	PurchaseOrderID uuid.UUID
+	TenantID        uuid.UUID
+	Lines           []ReceiptLine
+	Notes           string
+}
+
+// ReceiveGoods books a (possibly partial) goods receipt against an ordered purchase order.
+// Batches are created or reused, stock is added at the order's location and a PURCHASE log
+// row is written per line, all in one transaction. The order moves to RECEIVED once every
+// line is fully received.
+func (s *PurchaseOrderService) ReceiveGoods(ctx context.Context, params ReceiveGoodsParams) (*PurchaseOrderWithItems, error) {
+	if len(params.Lines) == 0 {
+		return nil, ErrNoReceiptLines
+	}
+	for _, line := range params.Lines {
+		if line.ItemID == uuid.Nil || line.Quantity <= 0 || line.BatchNumber == "" || line.ExpiryDate.IsZero() {
+			return nil, ErrInvalidReceiptLine
+		}
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	// Lock the order so concurrent receipts against it are serialized
+	po, err := qtx.GetPurchaseOrderForUpdate(ctx, db.GetPurchaseOrderForUpdateParams{
+		ID:       params.PurchaseOrderID,
+		TenantID: params.TenantID,
+	})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get purchase order")
+	}
+	if po.Status != StatusOrdered {
+		return nil, ErrNotReceivable
+	}
+	if !po.LocationID.Valid {
+		return nil, ErrLocationRequired
+	}
+	locationID := uuid.UUID(po.LocationID.Bytes)
+
+	items, err := qtx.GetPurchaseOrderItems(ctx, db.GetPurchaseOrderItemsParams{
+		PurchaseOrderID: po.ID,
+		TenantID:        params.TenantID,
+	})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get purchase order items")
+	}
+
+	byID := make(map[uuid.UUID]*db.PurchaseOrderItem, len(items))
+	for i := range items {
+		byID[items[i].ID] = &items[i]
+	}
+
+	for _, line := range params.Lines {
+		item, ok := byID[line.ItemID]
+		if !ok {
+			return nil, ErrUnknownItem
+		}
+
+		received := utils.PgNumericToInt(item.QuantityReceived) + line.Quantity
+		if received > utils.PgNumericToInt(item.QuantityOrdered) {
+			return nil, fmt.Errorf("%w: item %s", ErrOverReceipt, item.ID)
+		}
+
+		batch, err := s.findOrCreateBatch(ctx, qtx, params.TenantID, item, line)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := qtx.AddInventoryQuantity(ctx, db.AddInventoryQuantityParams{
+			TenantID:   params.TenantID,
+			ProductID:  item.ProductID,
+			BatchID:    batch.ID,
+			Quantity:   utils.P.Numeric(line.Quantity),
+			LocationID: locationID,
+		}); err != nil {
+			return nil, database.WrapError(err, "failed to add inventory")
+		}
+
+		updated, err := qtx.UpdatePurchaseOrderItemQuantityReceived(ctx, db.UpdatePurchaseOrderItemQuantityReceivedParams{
+			ID:               item.ID,
+			QuantityReceived: utils.P.Numeric(received),
+			TenantID:         params.TenantID,
+		})
+		if err != nil {
+			return nil, database.WrapError(err, "failed to update quantity received")
+		}
+
+		if err := qtx.SetPurchaseOrderItemBatch(ctx, db.SetPurchaseOrderItemBatchParams{
+			ID:       item.ID,
+			BatchID:  utils.P.UUID(batch.ID),
+			TenantID: params.TenantID,
+		}); err != nil {
+			return nil, database.WrapError(err, "failed to link batch to purchase order item")
+		}
+
+		notes := fmt.Sprintf("Received against %s", po.PoNumber)
+		if params.Notes != "" {
+			notes = fmt.Sprintf("%s: %s", notes, params.Notes)
+		}
+		if err := qtx.CreateInventoryLog(ctx, db.CreateInventoryLogParams{
+			TenantID:        params.TenantID,
+			ProductID:       item.ProductID,
+			BatchID:         batch.ID,
+			LocationID:      locationID,
+			TransactionType: inventory.TxPurchase,
+			QuantityChange:  utils.P.Numeric(line.Quantity),
+			ReferenceID:     utils.P.UUID(po.ID),
+			Notes:           utils.P.Text(notes),
+		}); err != nil {
+			return nil, database.WrapError(err, "failed to log goods receipt")
+		}
+
+		updated.BatchID = utils.P.UUID(batch.ID)
+		*item = updated
+	}
+
+	if fullyReceived(items) {
+		if _, err := s.transition(ctx, qtx, po.ID, params.TenantID, StatusReceived); err != nil {
+			return nil, err
+		}
+		if err := qtx.SetPurchaseOrderDeliveryDate(ctx, db.SetPurchaseOrderDeliveryDateParams{
+			ID:       po.ID,
+			TenantID: params.TenantID,
+		}); err != nil {
+			return nil, database.WrapError(err, "failed to set delivery date")
+		}
+	}
+
+	po, err = qtx.GetPurchaseOrder(ctx, db.GetPurchaseOrderParams{ID: po.ID, TenantID: params.TenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to reload purchase order")
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Info().
+		Str("purchase_order_id", po.ID.String()).
+		Str("status", po.Status).
+		Int("lines", len(params.Lines)).
+		Msg("goods received")
+
+	return &PurchaseOrderWithItems{PurchaseOrder: po, Items: items}, nil
+}
+
+// findOrCreateBatch reuses an existing batch with the same number for the product or creates it,
+// costing new batches at the line's unit cost
+func (s *PurchaseOrderService) findOrCreateBatch(ctx context.Context, q *db.Queries, tenantID uuid.UUID, item *db.PurchaseOrderItem, line ReceiptLine) (db.Batch, error) {
+	batch, err := q.GetBatchByNumber(ctx, db.GetBatchByNumberParams{
+		TenantID:    tenantID,
+		ProductID:   item.ProductID,
+		BatchNumber: line.BatchNumber,
+	})
+	if err == nil {
+		if !sameDay(batch.ExpiryDate, line.ExpiryDate) {
+			return db.Batch{}, fmt.Errorf("%w: batch %s", ErrBatchExpiryMismatch, line.BatchNumber)
+		}
+		return batch, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return db.Batch{}, database.WrapError(err, "failed to look up batch")
+	}
+
+	batch, err = q.CreateBatch(ctx, db.CreateBatchParams{
+		TenantID:    tenantID,
+		ProductID:   item.ProductID,
+		BatchNumber: line.BatchNumber,
+		ExpiryDate:  line.ExpiryDate,
+		Cost:        item.UnitCost,
+	})
+	if err != nil {
+		return db.Batch{}, database.WrapError(err, "failed to create batch")
+	}
+	return batch, nil
+}
+
+func fullyReceived(items []db.PurchaseOrderItem) bool {
+	for _, item := range items {
+		if utils.PgNumericToInt(item.QuantityReceived) < utils.PgNumericToInt(item.QuantityOrdered) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
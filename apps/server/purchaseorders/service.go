@@ -0,0 +1,388 @@
+package purchaseorders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"agromart2/db"
+	"agromart2/internal/database"
+	"agromart2/internal/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrInvalidStatusTransition = errors.New("invalid purchase order status transition")
+	ErrNotEditable             = errors.New("purchase order can only be edited while pending")
+	ErrNoLineItems             = errors.New("purchase order must have at least one line item")
+	ErrInvalidLineItem         = errors.New("line item quantity must be positive and unit cost non-negative")
+	ErrLocationRequired        = errors.New("purchase order requires a receiving location")
+)
+
+type PurchaseOrderService struct {
+	db *pgxpool.Pool
+	q  *db.Queries
+}
+
+func NewPurchaseOrderService(db *pgxpool.Pool, queries *db.Queries) *PurchaseOrderService {
+	return &PurchaseOrderService{
+		db: db,
+		q:  queries,
+	}
+}
+
+type LineItemParams struct {
+	ProductID uuid.UUID
+	Quantity  int
+	UnitCost  int
+}
+
+type CreatePurchaseOrderParams struct {
+	TenantID             uuid.UUID
+	PONumber             string
+	SupplierID           uuid.UUID
+	LocationID           uuid.UUID
+	ExpectedDeliveryDate *time.Time
+	Notes                string
+	CreatedBy            uuid.UUID
+	Items                []LineItemParams
+}
+
+type UpdatePurchaseOrderParams struct {
+	ID                   uuid.UUID
+	TenantID             uuid.UUID
+	SupplierID           uuid.UUID
+	LocationID           uuid.UUID
+	ExpectedDeliveryDate *time.Time
+	Notes                string
+	Items                []LineItemParams
+}
+
+// PurchaseOrderWithItems is a purchase order together with its line items
+type PurchaseOrderWithItems struct {
+	db.PurchaseOrder
+	Items []db.PurchaseOrderItem `json:"items"`
+}
+
+// CreatePurchaseOrder creates a pending purchase order with its line items
+func (s *PurchaseOrderService) CreatePurchaseOrder(ctx context.Context, params CreatePurchaseOrderParams) (*PurchaseOrderWithItems, error) {
+	if params.LocationID == uuid.Nil {
+		return nil, ErrLocationRequired
+	}
+	if err := validateItems(params.Items); err != nil {
+		return nil, err
+	}
+
+	poNumber := params.PONumber
+	if poNumber == "" {
+		poNumber = generatePONumber()
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	po, err := qtx.CreatePurchaseOrder(ctx, db.CreatePurchaseOrderParams{
+		TenantID:             params.TenantID,
+		PoNumber:             poNumber,
+		SupplierID:           params.SupplierID,
+		LocationID:           utils.P.UUID(params.LocationID),
+		CreatedBy:            utils.P.UUID(params.CreatedBy),
+		ExpectedDeliveryDate: utils.P.DatePtr(params.ExpectedDeliveryDate),
+		Notes:                utils.P.Text(params.Notes),
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("failed to create purchase order")
+		return nil, database.WrapError(err, "failed to create purchase order")
+	}
+
+	items, err := s.replaceItems(ctx, qtx, po.ID, params.TenantID, params.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	po, err = qtx.GetPurchaseOrder(ctx, db.GetPurchaseOrderParams{ID: po.ID, TenantID: params.TenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to reload purchase order")
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &PurchaseOrderWithItems{PurchaseOrder: po, Items: items}, nil
+}
+
+// GetPurchaseOrder retrieves a purchase order and its line items
+func (s *PurchaseOrderService) GetPurchaseOrder(ctx context.Context, id, tenantID uuid.UUID) (*PurchaseOrderWithItems, error) {
+	po, err := s.q.GetPurchaseOrder(ctx, db.GetPurchaseOrderParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get purchase order")
+	}
+
+	items, err := s.q.GetPurchaseOrderItems(ctx, db.GetPurchaseOrderItemsParams{
+		PurchaseOrderID: id,
+		TenantID:        tenantID,
+	})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get purchase order items")
+	}
+
+	return &PurchaseOrderWithItems{PurchaseOrder: po, Items: items}, nil
+}
+
+// ListPurchaseOrders lists purchase orders, optionally filtered by status or supplier
+func (s *PurchaseOrderService) ListPurchaseOrders(ctx context.Context, tenantID uuid.UUID, status string, supplierID *uuid.UUID, limit, offset int32) ([]db.PurchaseOrder, error) {
+	var (
+		orders []db.PurchaseOrder
+		err    error
+	)
+
+	switch {
+	case supplierID != nil:
+		orders, err = s.q.ListPurchaseOrdersBySupplier(ctx, db.ListPurchaseOrdersBySupplierParams{
+			TenantID:   tenantID,
+			SupplierID: *supplierID,
+			Limit:      limit,
+			Offset:     offset,
+		})
+	case status != "":
+		orders, err = s.q.ListPurchaseOrdersByStatus(ctx, db.ListPurchaseOrdersByStatusParams{
+			TenantID: tenantID,
+			Status:   status,
+			Limit:    limit,
+			Offset:   offset,
+		})
+	default:
+		orders, err = s.q.ListPurchaseOrders(ctx, db.ListPurchaseOrdersParams{
+			TenantID: tenantID,
+			Limit:    limit,
+			Offset:   offset,
+		})
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("failed to list purchase orders")
+		return []db.PurchaseOrder{}, fmt.Errorf("failed to list purchase orders: %w", err)
+	}
+
+	return orders, nil
+}
+
+// UpdatePurchaseOrder replaces the header fields and line items of a pending purchase order
+func (s *PurchaseOrderService) UpdatePurchaseOrder(ctx context.Context, params UpdatePurchaseOrderParams) (*PurchaseOrderWithItems, error) {
+	if params.LocationID == uuid.Nil {
+		return nil, ErrLocationRequired
+	}
+	if err := validateItems(params.Items); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	current, err := qtx.GetPurchaseOrder(ctx, db.GetPurchaseOrderParams{ID: params.ID, TenantID: params.TenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get purchase order")
+	}
+	if current.Status != StatusPending {
+		return nil, ErrNotEditable
+	}
+
+	po, err := qtx.UpdatePurchaseOrderDetails(ctx, db.UpdatePurchaseOrderDetailsParams{
+		ID:                   params.ID,
+		SupplierID:           params.SupplierID,
+		LocationID:           utils.P.UUID(params.LocationID),
+		ExpectedDeliveryDate: utils.P.DatePtr(params.ExpectedDeliveryDate),
+		Notes:                utils.P.Text(params.Notes),
+		TenantID:             params.TenantID,
+	})
+	if err != nil {
+		// The status guard in the query matched nothing, so it changed underneath us
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotEditable
+		}
+		return nil, database.WrapError(err, "failed to update purchase order")
+	}
+
+	if err := qtx.DeletePurchaseOrderItems(ctx, db.DeletePurchaseOrderItemsParams{
+		PurchaseOrderID: params.ID,
+		TenantID:        params.TenantID,
+	}); err != nil {
+		return nil, database.WrapError(err, "failed to remove purchase order items")
+	}
+
+	items, err := s.replaceItems(ctx, qtx, po.ID, params.TenantID, params.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	po, err = qtx.GetPurchaseOrder(ctx, db.GetPurchaseOrderParams{ID: po.ID, TenantID: params.TenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to reload purchase order")
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &PurchaseOrderWithItems{PurchaseOrder: po, Items: items}, nil
+}
+
+// ApprovePurchaseOrder moves a pending purchase order to APPROVED and records the approver
+func (s *PurchaseOrderService) ApprovePurchaseOrder(ctx context.Context, id, tenantID, approvedBy uuid.UUID) (db.PurchaseOrder, error) {
+	current, err := s.q.GetPurchaseOrder(ctx, db.GetPurchaseOrderParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return db.PurchaseOrder{}, database.WrapError(err, "failed to get purchase order")
+	}
+	if !CanTransition(current.Status, StatusApproved) {
+		return db.PurchaseOrder{}, fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, current.Status, StatusApproved)
+	}
+
+	po, err := s.q.ApprovePurchaseOrder(ctx, db.ApprovePurchaseOrderParams{
+		ApprovedBy: utils.P.UUID(approvedBy),
+		ID:         id,
+		TenantID:   tenantID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.PurchaseOrder{}, fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, current.Status, StatusApproved)
+		}
+		return db.PurchaseOrder{}, database.WrapError(err, "failed to approve purchase order")
+	}
+
+	return po, nil
+}
+
+// MarkOrdered moves an approved purchase order to ORDERED once it has been sent to the supplier
+func (s *PurchaseOrderService) MarkOrdered(ctx context.Context, id, tenantID uuid.UUID) (db.PurchaseOrder, error) {
+	return s.transition(ctx, s.q, id, tenantID, StatusOrdered)
+}
+
+// CancelPurchaseOrder cancels a purchase order that has not yet been received
+func (s *PurchaseOrderService) CancelPurchaseOrder(ctx context.Context, id, tenantID uuid.UUID) (db.PurchaseOrder, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return db.PurchaseOrder{}, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	// Lock the order so a receipt cannot land between the check and the cancel
+	if _, err := qtx.GetPurchaseOrderForUpdate(ctx, db.GetPurchaseOrderForUpdateParams{ID: id, TenantID: tenantID}); err != nil {
+		return db.PurchaseOrder{}, database.WrapError(err, "failed to get purchase order")
+	}
+
+	items, err := qtx.GetPurchaseOrderItems(ctx, db.GetPurchaseOrderItemsParams{PurchaseOrderID: id, TenantID: tenantID})
+	if err != nil {
+		return db.PurchaseOrder{}, database.WrapError(err, "failed to get purchase order items")
+	}
+	for _, item := range items {
+		if utils.PgNumericToInt(item.QuantityReceived) > 0 {
+			return db.PurchaseOrder{}, ErrPartiallyReceived
+		}
+	}
+
+	po, err := s.transition(ctx, qtx, id, tenantID, StatusCancelled)
+	if err != nil {
+		return db.PurchaseOrder{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return db.PurchaseOrder{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return po, nil
+}
+
+// transition moves a purchase order to a new status if the transition table allows it.
+// The update is guarded on the current status so concurrent transitions cannot both win.
+func (s *PurchaseOrderService) transition(ctx context.Context, q *db.Queries, id, tenantID uuid.UUID, to string) (db.PurchaseOrder, error) {
+	current, err := q.GetPurchaseOrder(ctx, db.GetPurchaseOrderParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return db.PurchaseOrder{}, database.WrapError(err, "failed to get purchase order")
+	}
+	if !CanTransition(current.Status, to) {
+		return db.PurchaseOrder{}, fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, current.Status, to)
+	}
+
+	po, err := q.TransitionPurchaseOrderStatus(ctx, db.TransitionPurchaseOrderStatusParams{
+		NewStatus:     to,
+		ID:            id,
+		TenantID:      tenantID,
+		CurrentStatus: current.Status,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.PurchaseOrder{}, fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, current.Status, to)
+		}
+		return db.PurchaseOrder{}, database.WrapError(err, "failed to update purchase order status")
+	}
+
+	return po, nil
+}
+
+// replaceItems inserts line items for a purchase order and refreshes its totals
+func (s *PurchaseOrderService) replaceItems(ctx context.Context, q *db.Queries, poID, tenantID uuid.UUID, params []LineItemParams) ([]db.PurchaseOrderItem, error) {
+	items := make([]db.PurchaseOrderItem, 0, len(params))
+	total := 0
+
+	for _, item := range params {
+		lineTotal := item.Quantity * item.UnitCost
+		created, err := q.CreatePurchaseOrderItem(ctx, db.CreatePurchaseOrderItemParams{
+			TenantID:        tenantID,
+			PurchaseOrderID: poID,
+			ProductID:       item.ProductID,
+			QuantityOrdered: utils.P.Numeric(item.Quantity),
+			UnitCost:        utils.P.Numeric(item.UnitCost),
+			TotalCost:       utils.P.Numeric(lineTotal),
+		})
+		if err != nil {
+			log.Error().Err(err).Msg("failed to create purchase order item")
+			return nil, database.WrapError(err, "failed to create purchase order item")
+		}
+		items = append(items, created)
+		total += lineTotal
+	}
+
+	if err := q.UpdatePurchaseOrderTotals(ctx, db.UpdatePurchaseOrderTotalsParams{
+		ID:          poID,
+		TotalAmount: utils.P.Numeric(total),
+		FinalAmount: utils.P.Numeric(total),
+		TenantID:    tenantID,
+	}); err != nil {
+		return nil, database.WrapError(err, "failed to update purchase order totals")
+	}
+
+	return items, nil
+}
+
+func validateItems(items []LineItemParams) error {
+	if len(items) == 0 {
+		return ErrNoLineItems
+	}
+	for _, item := range items {
+		if item.Quantity <= 0 || item.UnitCost < 0 {
+			return ErrInvalidLineItem
+		}
+	}
+	return nil
+}
+
+func generatePONumber() string {
+	return fmt.Sprintf("PO-%s-%s", time.Now().Format("20060102"), strings.ToUpper(uuid.NewString()[:8]))
+}
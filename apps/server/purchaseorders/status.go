@@ -0,0 +1,27 @@
+package purchaseorders
+
+// Purchase order statuses as stored in purchase_orders.status
+const (
+	StatusPending   = "PENDING"
+	StatusApproved  = "APPROVED"
+	StatusOrdered   = "ORDERED"
+	StatusReceived  = "RECEIVED"
+	StatusCancelled = "CANCELLED"
+)
+
+// transitions lists the statuses each status may move to
+var transitions = map[string][]string{
+	StatusPending:  {StatusApproved, StatusCancelled},
+	StatusApproved: {StatusOrdered, StatusCancelled},
+	StatusOrdered:  {StatusReceived, StatusCancelled},
+}
+
+// CanTransition reports whether a purchase order may move from one status to another
+func CanTransition(from, to string) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+package reconciliation
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"agromart2/internal/auth"
+	"agromart2/internal/database"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	service *ReconciliationService
+}
+
+func NewHandler(service *ReconciliationService) *Handler {
+	return &Handler{service: service}
+}
+
+// RunReconciliation compares inventory with the ledger now and records the discrepancies
+func (h *Handler) RunReconciliation(c echo.Context) error {
+	var req RunRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	userID, err := uuid.Parse(c.Get("user_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid user ID")
+	}
+
+	reconciliation, err := h.service.Run(c.Request().Context(), RunParams{
+		TenantID:   tenantID,
+		LocationID: req.LocationID,
+		Source:     SourceManual,
+		CreatedBy:  &userID,
+	})
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    reconciliation,
+		"message": "Inventory reconciliation completed",
+	})
+}
+
+// GetReconciliation retrieves a reconciliation with its discrepancies
+func (h *Handler) GetReconciliation(c echo.Context) error {
+	reconciliationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid reconciliation ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	reconciliation, err := h.service.GetReconciliation(c.Request().Context(), reconciliationID, tenantID)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    reconciliation,
+	})
+}
+
+// ListReconciliations lists reconciliations with an optional status filter
+func (h *Handler) ListReconciliations(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	status := strings.ToUpper(c.QueryParam("status"))
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offset := int32((page - 1) * limit)
+
+	reconciliations, err := h.service.ListReconciliations(c.Request().Context(), tenantID, status, int32(limit), offset)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    reconciliations,
+		"pagination": map[string]interface{}{
+			"page":  page,
+			"limit": limit,
+		},
+	})
+}
+
+// PostReconciliation confirms a reconciliation and logs correcting ledger entries
+func (h *Handler) PostReconciliation(c echo.Context) error {
+	reconciliationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid reconciliation ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	userID, err := uuid.Parse(c.Get("user_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid user ID")
+	}
+
+	reconciliation, err := h.service.PostReconciliation(c.Request().Context(), reconciliationID, tenantID, userID)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    reconciliation,
+		"message": "Inventory reconciliation posted successfully",
+	})
+}
+
+// DismissReconciliation closes a reconciliation without posting it
+func (h *Handler) DismissReconciliation(c echo.Context) error {
+	reconciliationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid reconciliation ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	userID, err := uuid.Parse(c.Get("user_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid user ID")
+	}
+
+	reconciliation, err := h.service.DismissReconciliation(c.Request().Context(), reconciliationID, tenantID, userID)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    reconciliation,
+		"message": "Inventory reconciliation dismissed",
+	})
+}
+
+// RegisterRoutes registers all inventory reconciliation routes
+func (h *Handler) RegisterRoutes(g *echo.Group) {
+	g.POST("/inventory/reconciliations", h.RunReconciliation, auth.RequirePermission(auth.PermInventoryAdjust))
+	g.GET("/inventory/reconciliations", h.ListReconciliations, auth.RequirePermission(auth.PermInventoryView))
+	g.GET("/inventory/reconciliations/:id", h.GetReconciliation, auth.RequirePermission(auth.PermInventoryView))
+	g.POST("/inventory/reconciliations/:id/post", h.PostReconciliation, auth.RequirePermission(auth.PermInventoryApprove))
+	g.POST("/inventory/reconciliations/:id/dismiss", h.DismissReconciliation, auth.RequirePermission(auth.PermInventoryApprove))
+}
+
+// toHTTPError maps service errors to HTTP errors
+func toHTTPError(err error) error {
+	switch {
+	case database.IsNotFound(err):
+		return echo.NewHTTPError(http.StatusNotFound, "inventory reconciliation not found")
+	case errors.Is(err, ErrInvalidStatusTransition), errors.Is(err, ErrStaleReconciliation):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case database.IsForeignKeyViolation(err):
+		return echo.NewHTTPError(http.StatusBadRequest, "referenced location does not exist")
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// Request types
+type RunRequest struct {
+	// LocationID limits the reconciliation to one location; omit it to cover all of them
+	LocationID *uuid.UUID `json:"location_id"`
+}
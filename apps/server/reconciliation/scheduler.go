@@ -0,0 +1,58 @@
+package reconciliation
+
+import (
+	"context"
+	"time"
+
+	"agromart2/internal/database"
+	"github.com/rs/zerolog/log"
+)
+
+// RunScheduled reconciles every active tenant once per interval until ctx is cancelled.
+// Tenants with discrepancies get an open SCHEDULED reconciliation for a manager to review;
+// while one is still open, later ticks leave the tenant alone rather than report the same drift again.
+func (s *ReconciliationService) RunScheduled(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.reconcileTenants(ctx)
+		}
+	}
+}
+
+// reconcileTenants runs a scheduled reconciliation for each active tenant, each scoped to
+// that tenant so row-level security applies as it would to a request
+func (s *ReconciliationService) reconcileTenants(ctx context.Context) {
+	tenantIDs, err := s.q.ListActiveTenantIDs(database.WithSystem(ctx))
+	if err != nil {
+		log.Error().Err(err).Msg("failed to list tenants to reconcile")
+		return
+	}
+
+	for _, tenantID := range tenantIDs {
+		if ctx.Err() != nil {
+			return
+		}
+
+		reconciliation, err := s.Run(database.WithTenant(ctx, tenantID), RunParams{
+			TenantID: tenantID,
+			Source:   SourceScheduled,
+		})
+		if err != nil {
+			log.Error().Err(err).Str("tenant_id", tenantID.String()).Msg("scheduled inventory reconciliation failed")
+			continue
+		}
+		if reconciliation != nil {
+			log.Warn().
+				Str("tenant_id", tenantID.String()).
+				Str("reconciliation_id", reconciliation.ID.String()).
+				Int32("discrepancies", reconciliation.DiscrepancyCount).
+				Msg("inventory does not match the ledger")
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package reconciliation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"agromart2/apps/server/inventory"
+	"agromart2/db"
+	"agromart2/internal/database"
+	"agromart2/internal/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrInvalidStatusTransition = errors.New("invalid reconciliation status transition")
+	ErrStaleReconciliation     = errors.New("inventory or ledger changed since the reconciliation ran; run it again")
+)
+
+// ReconciliationService compares the inventory table with the balances in the inventory
+// ledger. Stock changed without a ledger entry shows up as a discrepancy; posting a
+// reconciliation logs the missing movements so the ledger agrees with inventory again.
+type ReconciliationService struct {
+	db *pgxpool.Pool
+	q  *db.Queries
+}
+
+func NewReconciliationService(db *pgxpool.Pool, queries *db.Queries) *ReconciliationService {
+	return &ReconciliationService{
+		db: db,
+		q:  queries,
+	}
+}
+
+type RunParams struct {
+	TenantID uuid.UUID
+	// LocationID limits the reconciliation to one location; nil covers all of them
+	LocationID *uuid.UUID
+	Source     string
+	CreatedBy  *uuid.UUID
+}
+
+// ReconciliationWithLines is a reconciliation with the discrepancies it found
+type ReconciliationWithLines struct {
+	db.InventoryReconciliation
+	Lines []db.ListInventoryReconciliationLinesRow `json:"lines"`
+}
+
+// Run compares inventory with the ledger and records every batch whose quantities differ,
+// valued at batch cost. Manual runs are always recorded so the caller gets a report; a
+// scheduled run records nothing and returns nil when it finds nothing or when the same scope
+// already has an open reconciliation waiting for review.
+func (s *ReconciliationService) Run(ctx context.Context, params RunParams) (*ReconciliationWithLines, error) {
+	source := params.Source
+	if source == "" {
+		source = SourceManual
+	}
+
+	if source == SourceScheduled {
+		open, err := s.q.HasOpenInventoryReconciliation(ctx, db.HasOpenInventoryReconciliationParams{
+			TenantID:   params.TenantID,
+			LocationID: utils.P.UUIDPtr(params.LocationID),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for open reconciliations: %w", err)
+		}
+		if open {
+			return nil, nil
+		}
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	discrepancies, err := qtx.ListInventoryDiscrepancies(ctx, db.ListInventoryDiscrepanciesParams{
+		TenantID:   params.TenantID,
+		LocationID: utils.P.UUIDPtr(params.LocationID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare inventory with the ledger: %w", err)
+	}
+	if len(discrepancies) == 0 && source == SourceScheduled {
+		return nil, nil
+	}
+
+	reconciliation, err := qtx.CreateInventoryReconciliation(ctx, db.CreateInventoryReconciliationParams{
+		TenantID:   params.TenantID,
+		Source:     source,
+		LocationID: utils.P.UUIDPtr(params.LocationID),
+		CreatedBy:  utils.P.UUIDPtr(params.CreatedBy),
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("failed to create inventory reconciliation")
+		return nil, database.WrapError(err, "failed to create inventory reconciliation")
+	}
+
+	for _, d := range discrepancies {
+		if err := qtx.CreateInventoryReconciliationLine(ctx, db.CreateInventoryReconciliationLineParams{
+			TenantID:          params.TenantID,
+			ReconciliationID:  reconciliation.ID,
+			LocationID:        d.LocationID,
+			ProductID:         d.ProductID,
+			BatchID:           d.BatchID,
+			InventoryQuantity: d.InventoryQuantity,
+			LedgerQuantity:    d.LedgerQuantity,
+			Difference:        d.Difference,
+			UnitCost:          d.UnitCost,
+			Value:             d.Value,
+		}); err != nil {
+			return nil, database.WrapError(err, "failed to record reconciliation line")
+		}
+	}
+
+	if _, err := qtx.SetInventoryReconciliationTotals(ctx, db.SetInventoryReconciliationTotalsParams{
+		ID:       reconciliation.ID,
+		TenantID: params.TenantID,
+	}); err != nil {
+		return nil, database.WrapError(err, "failed to total inventory reconciliation")
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return s.GetReconciliation(ctx, reconciliation.ID, params.TenantID)
+}
+
+// GetReconciliation retrieves a reconciliation with its discrepancies
+func (s *ReconciliationService) GetReconciliation(ctx context.Context, id, tenantID uuid.UUID) (*ReconciliationWithLines, error) {
+	reconciliation, err := s.q.GetInventoryReconciliation(ctx, db.GetInventoryReconciliationParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get inventory reconciliation")
+	}
+
+	lines, err := s.q.ListInventoryReconciliationLines(ctx, db.ListInventoryReconciliationLinesParams{
+		ReconciliationID: id,
+		TenantID:         tenantID,
+	})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get reconciliation lines")
+	}
+
+	return &ReconciliationWithLines{InventoryReconciliation: reconciliation, Lines: lines}, nil
+}
+
+// ListReconciliations lists reconciliations, newest first, optionally filtered by status
+func (s *ReconciliationService) ListReconciliations(ctx context.Context, tenantID uuid.UUID, status string, limit, offset int32) ([]db.InventoryReconciliation, error) {
+	return s.q.ListInventoryReconciliations(ctx, db.ListInventoryReconciliationsParams{
+		TenantID: tenantID,
+		Status:   utils.P.Text(status),
+		Limit:    limit,
+		Offset:   offset,
+	})
+}
+
+// PostReconciliation confirms an open reconciliation and logs a RECONCILIATION ledger entry
+// for each discrepancy, referencing the reconciliation. Inventory in scope is locked and
+// compared again first: if any discrepancy is no longer what the reconciliation recorded,
+// nothing is posted and ErrStaleReconciliation is returned.
+func (s *ReconciliationService) PostReconciliation(ctx context.Context, id, tenantID, userID uuid.UUID) (*ReconciliationWithLines, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	reconciliation, err := qtx.GetInventoryReconciliationForUpdate(ctx, db.GetInventoryReconciliationForUpdateParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get inventory reconciliation")
+	}
+	if !CanTransition(reconciliation.Status, StatusPosted) {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, reconciliation.Status, StatusPosted)
+	}
+
+	if err := qtx.LockLocationInventory(ctx, db.LockLocationInventoryParams{
+		TenantID:   tenantID,
+		LocationID: reconciliation.LocationID,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to lock inventory: %w", err)
+	}
+
+	current, err := qtx.ListInventoryDiscrepancies(ctx, db.ListInventoryDiscrepanciesParams{
+		TenantID:   tenantID,
+		LocationID: reconciliation.LocationID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare inventory with the ledger: %w", err)
+	}
+
+	type stockKey struct{ locationID, batchID uuid.UUID }
+	differences := make(map[stockKey]pgtype.Numeric, len(current))
+	for _, d := range current {
+		differences[stockKey{d.LocationID, d.BatchID}] = d.Difference
+	}
+
+	lines, err := qtx.ListInventoryReconciliationLines(ctx, db.ListInventoryReconciliationLinesParams{
+		ReconciliationID: id,
+		TenantID:         tenantID,
+	})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get reconciliation lines")
+	}
+
+	for _, line := range lines {
+		difference, ok := differences[stockKey{line.LocationID, line.BatchID}]
+		if !ok || !utils.PgNumericEqual(difference, line.Difference) {
+			return nil, fmt.Errorf("%w: batch %s at %s", ErrStaleReconciliation, line.BatchNumber, line.LocationName)
+		}
+
+		if err := qtx.CreateInventoryLog(ctx, db.CreateInventoryLogParams{
+			TenantID:        tenantID,
+			ProductID:       line.ProductID,
+			BatchID:         line.BatchID,
+			TransactionType: inventory.TxReconciliation,
+			QuantityChange:  line.Difference,
+			ReferenceID:     utils.P.UUID(reconciliation.ID),
+			Notes:           utils.P.Text("Inventory reconciliation"),
+			LocationID:      line.LocationID,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to log reconciliation entry: %w", err)
+		}
+	}
+
+	if _, err := s.resolve(ctx, qtx, reconciliation, StatusPosted, userID); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return s.GetReconciliation(ctx, id, tenantID)
+}
+
+// DismissReconciliation closes an open reconciliation without posting anything
+func (s *ReconciliationService) DismissReconciliation(ctx context.Context, id, tenantID, userID uuid.UUID) (db.InventoryReconciliation, error) {
+	reconciliation, err := s.q.GetInventoryReconciliation(ctx, db.GetInventoryReconciliationParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return db.InventoryReconciliation{}, database.WrapError(err, "failed to get inventory reconciliation")
+	}
+
+	return s.resolve(ctx, s.q, reconciliation, StatusDismissed, userID)
+}
+
+// resolve moves an open reconciliation to a final status, guarding against a concurrent change
+func (s *ReconciliationService) resolve(ctx context.Context, q *db.Queries, current db.InventoryReconciliation, to string, userID uuid.UUID) (db.InventoryReconciliation, error) {
+	if !CanTransition(current.Status, to) {
+		return db.InventoryReconciliation{}, fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, current.Status, to)
+	}
+
+	reconciliation, err := q.ResolveInventoryReconciliation(ctx, db.ResolveInventoryReconciliationParams{
+		NewStatus:     to,
+		ResolvedBy:    utils.P.UUID(userID),
+		ID:            current.ID,
+		TenantID:      current.TenantID,
+		CurrentStatus: current.Status,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.InventoryReconciliation{}, fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, current.Status, to)
+		}
+		return db.InventoryReconciliation{}, database.WrapError(err, "failed to update inventory reconciliation status")
+	}
+
+	return reconciliation, nil
+}
@@ -0,0 +1,29 @@
+package reconciliation
+
+// Reconciliation statuses as stored in inventory_reconciliations.status
+const (
+	StatusOpen      = "OPEN"
+	StatusPosted    = "POSTED"
+	StatusDismissed = "DISMISSED"
+)
+
+// What started a reconciliation, as stored in inventory_reconciliations.source
+const (
+	SourceManual    = "MANUAL"
+	SourceScheduled = "SCHEDULED"
+)
+
+// transitions lists the statuses each status may move to
+var transitions = map[string][]string{
+	StatusOpen: {StatusPosted, StatusDismissed},
+}
+
+// CanTransition reports whether a reconciliation may move from one status to another
+func CanTransition(from, to string) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,251 @@
+package reports
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"agromart2/internal/auth"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const dateLayout = "2006-01-02"
+
+type Handler struct {
+	service *ReportService
+}
+
+func NewHandler(service *ReportService) *Handler {
+	return &Handler{service: service}
+}
+
+// GetDashboardStats gets inventory, sales and purchase totals for the dashboard
+func (h *Handler) GetDashboardStats(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	locationID, err := locationParam(c)
+	if err != nil {
+		return err
+	}
+
+	dateRange, err := dateRangeParams(c)
+	if err != nil {
+		return err
+	}
+
+	stats, err := h.service.GetDashboardStats(c.Request().Context(), tenantID, locationID, dateRange)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    stats,
+	})
+}
+
+// GetExpiringBatches gets batches in stock expiring within ?days= (default 30)
+func (h *Handler) GetExpiringBatches(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	locationID, err := locationParam(c)
+	if err != nil {
+		return err
+	}
+
+	days := 30
+	if daysStr := c.QueryParam("days"); daysStr != "" {
+		days, err = strconv.Atoi(daysStr)
+		if err != nil || days < 1 || days > 365 {
+			return echo.NewHTTPError(http.StatusBadRequest, "days must be between 1 and 365")
+		}
+	}
+
+	batches, err := h.service.GetExpiringBatches(c.Request().Context(), tenantID, locationID, days)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    batches,
+		"days":    days,
+	})
+}
+
+// GetValuation gets the value of stock on hand
+func (h *Handler) GetValuation(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	locationID, err := locationParam(c)
+	if err != nil {
+		return err
+	}
+
+	valuation, err := h.service.GetValuation(c.Request().Context(), tenantID, locationID)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    valuation,
+	})
+}
+
+// GetProductMovement gets purchased and sold quantities per product
+func (h *Handler) GetProductMovement(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	dateRange, err := dateRangeParams(c)
+	if err != nil {
+		return err
+	}
+
+	rows, err := h.service.GetProductMovement(c.Request().Context(), tenantID, dateRange)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return rangeResponse(c, rows, dateRange)
+}
+
+// GetSupplierPurchases gets purchase totals per supplier
+func (h *Handler) GetSupplierPurchases(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	dateRange, err := dateRangeParams(c)
+	if err != nil {
+		return err
+	}
+
+	rows, err := h.service.GetSupplierPurchaseSummary(c.Request().Context(), tenantID, dateRange)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return rangeResponse(c, rows, dateRange)
+}
+
+// GetCustomerSales gets sales totals per customer
+func (h *Handler) GetCustomerSales(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	dateRange, err := dateRangeParams(c)
+	if err != nil {
+		return err
+	}
+
+	rows, err := h.service.GetCustomerSalesSummary(c.Request().Context(), tenantID, dateRange)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return rangeResponse(c, rows, dateRange)
+}
+
+// GetSalesByDate gets sales totals per order date
+func (h *Handler) GetSalesByDate(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	dateRange, err := dateRangeParams(c)
+	if err != nil {
+		return err
+	}
+
+	rows, err := h.service.GetSalesByDate(c.Request().Context(), tenantID, dateRange)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return rangeResponse(c, rows, dateRange)
+}
+
+// RegisterRoutes registers all report routes
+func (h *Handler) RegisterRoutes(g *echo.Group) {
+	g.GET("/reports/dashboard-stats", h.GetDashboardStats, auth.RequirePermission(auth.PermReportsView))
+	g.GET("/reports/expiring-batches", h.GetExpiringBatches, auth.RequirePermission(auth.PermReportsView))
+	g.GET("/reports/valuation", h.GetValuation, auth.RequirePermission(auth.PermReportsView))
+	g.GET("/reports/product-movement", h.GetProductMovement, auth.RequirePermission(auth.PermReportsView))
+	g.GET("/reports/supplier-purchases", h.GetSupplierPurchases, auth.RequirePermission(auth.PermReportsView))
+	g.GET("/reports/customer-sales", h.GetCustomerSales, auth.RequirePermission(auth.PermReportsView))
+	g.GET("/reports/sales-by-date", h.GetSalesByDate, auth.RequirePermission(auth.PermReportsView))
+}
+
+func rangeResponse(c echo.Context, data interface{}, dateRange DateRange) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+		"from":    dateRange.From.Format(dateLayout),
+		"to":      dateRange.To.Format(dateLayout),
+	})
+}
+
+// dateRangeParams reads ?from= and ?to= (YYYY-MM-DD), defaulting to the last 30 days
+func dateRangeParams(c echo.Context) (DateRange, error) {
+	dateRange := DefaultDateRange()
+	if fromStr := c.QueryParam("from"); fromStr != "" {
+		from, err := time.Parse(dateLayout, fromStr)
+		if err != nil {
+			return DateRange{}, echo.NewHTTPError(http.StatusBadRequest, "from must be a date in YYYY-MM-DD format")
+		}
+		dateRange.From = from
+	}
+	if toStr := c.QueryParam("to"); toStr != "" {
+		to, err := time.Parse(dateLayout, toStr)
+		if err != nil {
+			return DateRange{}, echo.NewHTTPError(http.StatusBadRequest, "to must be a date in YYYY-MM-DD format")
+		}
+		dateRange.To = to
+	}
+	if dateRange.From.After(dateRange.To) {
+		return DateRange{}, echo.NewHTTPError(http.StatusBadRequest, ErrInvalidDateRange.Error())
+	}
+	return dateRange, nil
+}
+
+// locationParam reads the optional ?location_id= filter
+func locationParam(c echo.Context) (*uuid.UUID, error) {
+	locationIDStr := c.QueryParam("location_id")
+	if locationIDStr == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(locationIDStr)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "invalid location ID")
+	}
+	return &id, nil
+}
+
+// toHTTPError maps service errors to HTTP errors
+func toHTTPError(err error) error {
+	switch {
+	case errors.Is(err, ErrInvalidDateRange):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
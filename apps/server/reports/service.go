@@ -0,0 +1,198 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"agromart2/apps/server/inventory"
+	"agromart2/db"
+	"agromart2/internal/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DefaultRangeDays is the length of the date range used when a report is requested without one
+const DefaultRangeDays = 30
+
+var ErrInvalidDateRange = errors.New("from date must not be after to date")
+
+type ReportService struct {
+	db        *pgxpool.Pool
+	q         *db.Queries
+	inventory *inventory.InventoryService
+}
+
+func NewReportService(db *pgxpool.Pool, queries *db.Queries, inventoryService *inventory.InventoryService) *ReportService {
+	return &ReportService{
+		db:        db,
+		q:         queries,
+		inventory: inventoryService,
+	}
+}
+
+// DateRange is an inclusive range of calendar dates
+type DateRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// DefaultDateRange returns the last DefaultRangeDays days, ending today
+func DefaultDateRange() DateRange {
+	y, m, d := time.Now().Date()
+	to := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	return DateRange{From: to.AddDate(0, 0, -(DefaultRangeDays - 1)), To: to}
+}
+
+func (r DateRange) validate() error {
+	if r.From.After(r.To) {
+		return ErrInvalidDateRange
+	}
+	return nil
+}
+
+// ValuationReport is the value of stock on hand, in total and per product
+type ValuationReport struct {
+	TotalValue interface{}                   `json:"total_value"`
+	Products   []db.GetInventoryValuationRow `json:"products"`
+}
+
+// GetDashboardStats combines the inventory summary with sales and purchase totals for the range
+func (s *ReportService) GetDashboardStats(ctx context.Context, tenantID uuid.UUID, locationID *uuid.UUID, r DateRange) (map[string]interface{}, error) {
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+
+	stats, err := s.inventory.GetInventorySummary(ctx, tenantID, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	sales, err := s.GetSalesByDate(ctx, tenantID, r)
+	if err != nil {
+		return nil, err
+	}
+	var salesOrders int64
+	var salesAmount float64
+	for _, day := range sales {
+		salesOrders += day.TotalOrders
+		salesAmount += utils.PgNumericToFloat64(day.TotalSalesAmount)
+	}
+
+	purchases, err := s.GetSupplierPurchaseSummary(ctx, tenantID, r)
+	if err != nil {
+		return nil, err
+	}
+	var purchaseOrders int64
+	var purchaseAmount float64
+	for _, supplier := range purchases {
+		purchaseOrders += supplier.TotalOrders
+		purchaseAmount += utils.PgNumericToFloat64(supplier.TotalPurchasedAmount)
+	}
+
+	stats["sales_orders"] = salesOrders
+	stats["sales_amount"] = salesAmount
+	stats["purchase_orders"] = purchaseOrders
+	stats["purchase_amount"] = purchaseAmount
+	stats["from"] = r.From
+	stats["to"] = r.To
+
+	return stats, nil
+}
+
+// GetExpiringBatches lists batches in stock that expire within the given number of days
+func (s *ReportService) GetExpiringBatches(ctx context.Context, tenantID uuid.UUID, locationID *uuid.UUID, days int) ([]db.GetExpiringBatchesRow, error) {
+	batches, err := s.inventory.GetExpiringBatches(ctx, tenantID, locationID, days)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expiring batches: %w", err)
+	}
+	return batches, nil
+}
+
+// GetValuation values stock on hand at batch cost, optionally for one location
+func (s *ReportService) GetValuation(ctx context.Context, tenantID uuid.UUID, locationID *uuid.UUID) (*ValuationReport, error) {
+	total, err := s.inventory.GetInventoryValue(ctx, tenantID, locationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inventory value: %w", err)
+	}
+
+	products, err := s.q.GetInventoryValuation(ctx, db.GetInventoryValuationParams{
+		TenantID:   tenantID,
+		LocationID: utils.P.UUIDPtr(locationID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inventory valuation: %w", err)
+	}
+
+	return &ValuationReport{TotalValue: total, Products: products}, nil
+}
+
+// GetProductMovement reports quantities purchased, received, sold and shipped per product
+// on orders placed within the range
+func (s *ReportService) GetProductMovement(ctx context.Context, tenantID uuid.UUID, r DateRange) ([]db.GetProductMovementReportRow, error) {
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.q.GetProductMovementReport(ctx, db.GetProductMovementReportParams{
+		TenantID:  tenantID,
+		StartDate: r.From,
+		EndDate:   r.To,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product movement report: %w", err)
+	}
+	return rows, nil
+}
+
+// GetSupplierPurchaseSummary totals purchase orders per supplier within the range
+func (s *ReportService) GetSupplierPurchaseSummary(ctx context.Context, tenantID uuid.UUID, r DateRange) ([]db.GetSupplierPurchaseSummaryRow, error) {
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.q.GetSupplierPurchaseSummary(ctx, db.GetSupplierPurchaseSummaryParams{
+		TenantID:  tenantID,
+		StartDate: r.From,
+		EndDate:   r.To,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get supplier purchase summary: %w", err)
+	}
+	return rows, nil
+}
+
+// GetCustomerSalesSummary totals sales orders per customer within the range
+func (s *ReportService) GetCustomerSalesSummary(ctx context.Context, tenantID uuid.UUID, r DateRange) ([]db.GetCustomerSalesSummaryRow, error) {
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.q.GetCustomerSalesSummary(ctx, db.GetCustomerSalesSummaryParams{
+		TenantID:  tenantID,
+		StartDate: r.From,
+		EndDate:   r.To,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer sales summary: %w", err)
+	}
+	return rows, nil
+}
+
+// GetSalesByDate totals sales orders per order date within the range
+func (s *ReportService) GetSalesByDate(ctx context.Context, tenantID uuid.UUID, r DateRange) ([]db.GetSalesReportByDateRow, error) {
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.q.GetSalesReportByDate(ctx, db.GetSalesReportByDateParams{
+		TenantID:  tenantID,
+		StartDate: r.From,
+		EndDate:   r.To,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sales by date: %w", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,322 @@
+package salesorders
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"agromart2/apps/server/inventory"
+	"agromart2/internal/auth"
+	"agromart2/internal/database"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	service *SalesOrderService
+}
+
+func NewHandler(service *SalesOrderService) *Handler {
+	return &Handler{service: service}
+}
+
+// CreateSalesOrder creates a new sales order with line items
+func (h *Handler) CreateSalesOrder(c echo.Context) error {
+	var req SalesOrderRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if req.CustomerID == uuid.Nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "customer_id is required")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	userID, err := uuid.Parse(c.Get("user_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid user ID")
+	}
+
+	items := make([]LineItemParams, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, LineItemParams{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+		})
+	}
+
+	so, err := h.service.CreateSalesOrder(c.Request().Context(), CreateSalesOrderParams{
+		TenantID:             tenantID,
+		SONumber:             req.SONumber,
+		CustomerID:           req.CustomerID,
+		LocationID:           req.LocationID,
+		ExpectedDeliveryDate: req.ExpectedDeliveryDate,
+		Notes:                req.Notes,
+		CreatedBy:            userID,
+		Items:                items,
+	})
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    so,
+		"message": "Sales order created successfully",
+	})
+}
+
+// GetSalesOrder retrieves a sales order with its line items
+func (h *Handler) GetSalesOrder(c echo.Context) error {
+	soID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid sales order ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	so, err := h.service.GetSalesOrder(c.Request().Context(), soID, tenantID)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    so,
+	})
+}
+
+// ListSalesOrders lists sales orders with optional status/customer filters
+func (h *Handler) ListSalesOrders(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	var customerID *uuid.UUID
+	if customerIDStr := c.QueryParam("customer_id"); customerIDStr != "" {
+		id, err := uuid.Parse(customerIDStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid customer ID")
+		}
+		customerID = &id
+	}
+
+	status := strings.ToUpper(c.QueryParam("status"))
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offset := int32((page - 1) * limit)
+
+	orders, err := h.service.ListSalesOrders(c.Request().Context(), tenantID, status, customerID, int32(limit), offset)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    orders,
+		"pagination": map[string]interface{}{
+			"page":  page,
+			"limit": limit,
+		},
+	})
+}
+
+// ApproveSalesOrder approves a pending sales order and reserves its stock
+func (h *Handler) ApproveSalesOrder(c echo.Context) error {
+	soID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid sales order ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	userID, err := uuid.Parse(c.Get("user_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid user ID")
+	}
+
+	so, err := h.service.ApproveSalesOrder(c.Request().Context(), soID, tenantID, userID)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    so,
+		"message": "Sales order approved successfully",
+	})
+}
+
+// ShipSalesOrder books a shipment against an approved sales order
+func (h *Handler) ShipSalesOrder(c echo.Context) error {
+	soID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid sales order ID")
+	}
+
+	var req ShipSalesOrderRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	lines := make([]ShipmentLine, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		lines = append(lines, ShipmentLine{
+			ItemID:   line.ItemID,
+			BatchID:  line.BatchID,
+			Quantity: line.Quantity,
+		})
+	}
+
+	so, err := h.service.ShipSalesOrder(c.Request().Context(), ShipSalesOrderParams{
+		SalesOrderID: soID,
+		TenantID:     tenantID,
+		Lines:        lines,
+		Notes:        req.Notes,
+	})
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    so,
+		"message": "Goods shipped successfully",
+	})
+}
+
+// MarkDelivered marks a shipped sales order as delivered
+func (h *Handler) MarkDelivered(c echo.Context) error {
+	soID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid sales order ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	so, err := h.service.MarkDelivered(c.Request().Context(), soID, tenantID)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    so,
+		"message": "Sales order marked as delivered",
+	})
+}
+
+// CancelSalesOrder cancels a sales order and releases its reservation
+func (h *Handler) CancelSalesOrder(c echo.Context) error {
+	soID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid sales order ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	so, err := h.service.CancelSalesOrder(c.Request().Context(), soID, tenantID)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    so,
+		"message": "Sales order cancelled successfully",
+	})
+}
+
+// RegisterRoutes registers all sales order routes
+func (h *Handler) RegisterRoutes(g *echo.Group) {
+	g.POST("/sales-orders", h.CreateSalesOrder, auth.RequirePermission(auth.PermSOCreate))
+	g.GET("/sales-orders", h.ListSalesOrders, auth.RequirePermission(auth.PermSOView))
+	g.GET("/sales-orders/:id", h.GetSalesOrder, auth.RequirePermission(auth.PermSOView))
+	g.POST("/sales-orders/:id/approve", h.ApproveSalesOrder, auth.RequirePermission(auth.PermSOApprove))
+	g.POST("/sales-orders/:id/ship", h.ShipSalesOrder, auth.RequirePermission(auth.PermSOShip))
+	g.POST("/sales-orders/:id/deliver", h.MarkDelivered, auth.RequirePermission(auth.PermSOShip))
+	g.POST("/sales-orders/:id/cancel", h.CancelSalesOrder, auth.RequirePermission(auth.PermSOApprove))
+}
+
+// toHTTPError maps service errors to HTTP errors
+func toHTTPError(err error) error {
+	switch {
+	case database.IsNotFound(err):
+		return echo.NewHTTPError(http.StatusNotFound, "sales order not found")
+	case errors.Is(err, ErrInvalidStatusTransition), errors.Is(err, ErrInsufficientStock),
+		errors.Is(err, ErrNotShippable), errors.Is(err, ErrPartiallyShipped),
+		errors.Is(err, ErrOverShipment), errors.Is(err, inventory.ErrFractionalStock):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, ErrNoLineItems), errors.Is(err, ErrInvalidLineItem), errors.Is(err, ErrLocationRequired),
+		errors.Is(err, ErrNoShipmentLines), errors.Is(err, ErrInvalidShipmentLine),
+		errors.Is(err, ErrUnknownItem), errors.Is(err, inventory.ErrInvalidAllocation):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case database.IsDuplicateKey(err):
+		return echo.NewHTTPError(http.StatusConflict, "sales order number already exists")
+	case database.IsForeignKeyViolation(err):
+		return echo.NewHTTPError(http.StatusBadRequest, "referenced customer, location, product or batch does not exist")
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// Request types
+type SalesOrderRequest struct {
+	SONumber             string            `json:"so_number"`
+	CustomerID           uuid.UUID         `json:"customer_id" validate:"required"`
+	LocationID           uuid.UUID         `json:"location_id" validate:"required"`
+	ExpectedDeliveryDate *time.Time        `json:"expected_delivery_date"`
+	Notes                string            `json:"notes"`
+	Items                []LineItemRequest `json:"items" validate:"required,min=1"`
+}
+
+type LineItemRequest struct {
+	ProductID uuid.UUID `json:"product_id" validate:"required"`
+	Quantity  int       `json:"quantity" validate:"required,min=1"`
+	UnitPrice int       `json:"unit_price" validate:"min=0"`
+}
+
+type ShipSalesOrderRequest struct {
+	Lines []ShipmentLineRequest `json:"lines" validate:"required,min=1"`
+	Notes string                `json:"notes"`
+}
+
+type ShipmentLineRequest struct {
+	ItemID   uuid.UUID `json:"item_id" validate:"required"`
+	BatchID  uuid.UUID `json:"batch_id"`
+	Quantity int       `json:"quantity" validate:"required,min=1"`
+}
@@ -0,0 +1,376 @@
+package salesorders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"agromart2/apps/server/inventory"
+	"agromart2/db"
+	"agromart2/internal/database"
+	"agromart2/internal/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrInvalidStatusTransition = errors.New("invalid sales order status transition")
+	ErrNoLineItems             = errors.New("sales order must have at least one line item")
+	ErrInvalidLineItem         = errors.New("line item quantity must be positive and unit price non-negative")
+	ErrInsufficientStock       = inventory.ErrInsufficientStock
+	ErrPartiallyShipped        = errors.New("sales order has shipped goods and cannot be cancelled")
+	ErrLocationRequired        = errors.New("sales order requires a shipping location")
+)
+
+type SalesOrderService struct {
+	db *pgxpool.Pool
+	q  *db.Queries
+}
+
+func NewSalesOrderService(db *pgxpool.Pool, queries *db.Queries) *SalesOrderService {
+	return &SalesOrderService{
+		db: db,
+		q:  queries,
+	}
+}
+
+type LineItemParams struct {
+	ProductID uuid.UUID
+	Quantity  int
+	UnitPrice int
+}
+
+type CreateSalesOrderParams struct {
+	TenantID             uuid.UUID
+	SONumber             string
+	CustomerID           uuid.UUID
+	LocationID           uuid.UUID
+	ExpectedDeliveryDate *time.Time
+	Notes                string
+	CreatedBy            uuid.UUID
+	Items                []LineItemParams
+}
+
+// SalesOrderWithItems is a sales order together with its line items
+type SalesOrderWithItems struct {
+	db.SalesOrder
+	Items []db.SalesOrderItem `json:"items"`
+}
+
+// CreateSalesOrder creates a pending sales order with its line items
+func (s *SalesOrderService) CreateSalesOrder(ctx context.Context, params CreateSalesOrderParams) (*SalesOrderWithItems, error) {
+	if params.LocationID == uuid.Nil {
+		return nil, ErrLocationRequired
+	}
+	if len(params.Items) == 0 {
+		return nil, ErrNoLineItems
+	}
+	for _, item := range params.Items {
+		if item.Quantity <= 0 || item.UnitPrice < 0 {
+			return nil, ErrInvalidLineItem
+		}
+	}
+
+	soNumber := params.SONumber
+	if soNumber == "" {
+		soNumber = fmt.Sprintf("SO-%s-%s", time.Now().Format("20060102"), strings.ToUpper(uuid.NewString()[:8]))
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	so, err := qtx.CreateSalesOrder(ctx, db.CreateSalesOrderParams{
+		TenantID:             params.TenantID,
+		SoNumber:             soNumber,
+		CustomerID:           params.CustomerID,
+		LocationID:           utils.P.UUID(params.LocationID),
+		CreatedBy:            utils.P.UUID(params.CreatedBy),
+		ExpectedDeliveryDate: utils.P.DatePtr(params.ExpectedDeliveryDate),
+		Notes:                utils.P.Text(params.Notes),
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("failed to create sales order")
+		return nil, database.WrapError(err, "failed to create sales order")
+	}
+
+	items := make([]db.SalesOrderItem, 0, len(params.Items))
+	total := 0
+	for _, item := range params.Items {
+		lineTotal := item.Quantity * item.UnitPrice
+		created, err := qtx.CreateSalesOrderItem(ctx, db.CreateSalesOrderItemParams{
+			TenantID:        params.TenantID,
+			SalesOrderID:    so.ID,
+			ProductID:       item.ProductID,
+			QuantityOrdered: utils.P.Numeric(item.Quantity),
+			UnitPrice:       utils.P.Numeric(item.UnitPrice),
+			TotalPrice:      utils.P.Numeric(lineTotal),
+		})
+		if err != nil {
+			log.Error().Err(err).Msg("failed to create sales order item")
+			return nil, database.WrapError(err, "failed to create sales order item")
+		}
+		items = append(items, created)
+		total += lineTotal
+	}
+
+	if err := qtx.UpdateSalesOrderTotals(ctx, db.UpdateSalesOrderTotalsParams{
+		ID:          so.ID,
+		TotalAmount: utils.P.Numeric(total),
+		FinalAmount: utils.P.Numeric(total),
+		TenantID:    params.TenantID,
+	}); err != nil {
+		return nil, database.WrapError(err, "failed to update sales order totals")
+	}
+
+	so, err = qtx.GetSalesOrder(ctx, db.GetSalesOrderParams{ID: so.ID, TenantID: params.TenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to reload sales order")
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &SalesOrderWithItems{SalesOrder: so, Items: items}, nil
+}
+
+// GetSalesOrder retrieves a sales order and its line items
+func (s *SalesOrderService) GetSalesOrder(ctx context.Context, id, tenantID uuid.UUID) (*SalesOrderWithItems, error) {
+	so, err := s.q.GetSalesOrder(ctx, db.GetSalesOrderParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get sales order")
+	}
+
+	items, err := s.q.GetSalesOrderItems(ctx, db.GetSalesOrderItemsParams{
+		SalesOrderID: id,
+		TenantID:     tenantID,
+	})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get sales order items")
+	}
+
+	return &SalesOrderWithItems{SalesOrder: so, Items: items}, nil
+}
+
+// ListSalesOrders lists sales orders, optionally filtered by status or customer
+func (s *SalesOrderService) ListSalesOrders(ctx context.Context, tenantID uuid.UUID, status string, customerID *uuid.UUID, limit, offset int32) ([]db.SalesOrder, error) {
+	var (
+		orders []db.SalesOrder
+		err    error
+	)
+
+	switch {
+	case customerID != nil:
+		orders, err = s.q.ListSalesOrdersByCustomer(ctx, db.ListSalesOrdersByCustomerParams{
+			TenantID:   tenantID,
+			CustomerID: *customerID,
+			Limit:      limit,
+			Offset:     offset,
+		})
+	case status != "":
+		orders, err = s.q.ListSalesOrdersByStatus(ctx, db.ListSalesOrdersByStatusParams{
+			TenantID: tenantID,
+			Status:   status,
+			Limit:    limit,
+			Offset:   offset,
+		})
+	default:
+		orders, err = s.q.ListSalesOrders(ctx, db.ListSalesOrdersParams{
+			TenantID: tenantID,
+			Limit:    limit,
+			Offset:   offset,
+		})
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("failed to list sales orders")
+		return []db.SalesOrder{}, fmt.Errorf("failed to list sales orders: %w", err)
+	}
+
+	return orders, nil
+}
+
+// ApproveSalesOrder approves a pending sales order, reserving its stock.
+// Stock counts as reserved at the order's location while an order is APPROVED and not yet
+// fully shipped, so approval fails unless on-hand stock there minus existing reservations
+// covers every line.
+func (s *SalesOrderService) ApproveSalesOrder(ctx context.Context, id, tenantID, approvedBy uuid.UUID) (db.SalesOrder, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return db.SalesOrder{}, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	current, err := qtx.GetSalesOrderForUpdate(ctx, db.GetSalesOrderForUpdateParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return db.SalesOrder{}, database.WrapError(err, "failed to get sales order")
+	}
+	if !CanTransition(current.Status, StatusApproved) {
+		return db.SalesOrder{}, fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, current.Status, StatusApproved)
+	}
+	if !current.LocationID.Valid {
+		return db.SalesOrder{}, ErrLocationRequired
+	}
+	locationID := uuid.UUID(current.LocationID.Bytes)
+
+	items, err := qtx.GetSalesOrderItems(ctx, db.GetSalesOrderItemsParams{SalesOrderID: id, TenantID: tenantID})
+	if err != nil {
+		return db.SalesOrder{}, database.WrapError(err, "failed to get sales order items")
+	}
+
+	demand := make(map[uuid.UUID]int64)
+	for _, item := range items {
+		demand[item.ProductID] += int64(utils.PgNumericToInt(item.QuantityOrdered))
+	}
+
+	// Lock in a stable order so concurrent approvals cannot deadlock
+	productIDs := make([]uuid.UUID, 0, len(demand))
+	for productID := range demand {
+		productIDs = append(productIDs, productID)
+	}
+	sort.Slice(productIDs, func(i, j int) bool { return productIDs[i].String() < productIDs[j].String() })
+
+	for _, productID := range productIDs {
+		if err := qtx.LockProductInventory(ctx, db.LockProductInventoryParams{
+			TenantID:   tenantID,
+			ProductID:  productID,
+			LocationID: locationID,
+		}); err != nil {
+			return db.SalesOrder{}, database.WrapError(err, "failed to lock inventory")
+		}
+
+		position, err := qtx.GetProductStockPosition(ctx, db.GetProductStockPositionParams{
+			TenantID:   tenantID,
+			ProductID:  productID,
+			LocationID: locationID,
+		})
+		if err != nil {
+			return db.SalesOrder{}, database.WrapError(err, "failed to get stock position")
+		}
+
+		available := position.OnHand - position.Reserved
+		if demand[productID] > available {
+			return db.SalesOrder{}, fmt.Errorf("%w: product %s requires %d, available %d",
+				ErrInsufficientStock, productID, demand[productID], available)
+		}
+	}
+
+	so, err := qtx.ApproveSalesOrder(ctx, db.ApproveSalesOrderParams{
+		ApprovedBy: utils.P.UUID(approvedBy),
+		ID:         id,
+		TenantID:   tenantID,
+	})
+	if err != nil {
+		return db.SalesOrder{}, database.WrapError(err, "failed to approve sales order")
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return db.SalesOrder{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return so, nil
+}
+
+// MarkDelivered marks a shipped sales order as delivered
+func (s *SalesOrderService) MarkDelivered(ctx context.Context, id, tenantID uuid.UUID) (db.SalesOrder, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return db.SalesOrder{}, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	if _, err := s.transition(ctx, qtx, id, tenantID, StatusDelivered); err != nil {
+		return db.SalesOrder{}, err
+	}
+
+	if err := qtx.SetSalesOrderDeliveryDate(ctx, db.SetSalesOrderDeliveryDateParams{ID: id, TenantID: tenantID}); err != nil {
+		return db.SalesOrder{}, database.WrapError(err, "failed to set delivery date")
+	}
+
+	so, err := qtx.GetSalesOrder(ctx, db.GetSalesOrderParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return db.SalesOrder{}, database.WrapError(err, "failed to reload sales order")
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return db.SalesOrder{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return so, nil
+}
+
+// CancelSalesOrder cancels a sales order that has not shipped anything, releasing its reservation
+func (s *SalesOrderService) CancelSalesOrder(ctx context.Context, id, tenantID uuid.UUID) (db.SalesOrder, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return db.SalesOrder{}, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	if _, err := qtx.GetSalesOrderForUpdate(ctx, db.GetSalesOrderForUpdateParams{ID: id, TenantID: tenantID}); err != nil {
+		return db.SalesOrder{}, database.WrapError(err, "failed to get sales order")
+	}
+
+	items, err := qtx.GetSalesOrderItems(ctx, db.GetSalesOrderItemsParams{SalesOrderID: id, TenantID: tenantID})
+	if err != nil {
+		return db.SalesOrder{}, database.WrapError(err, "failed to get sales order items")
+	}
+	for _, item := range items {
+		if utils.PgNumericToInt(item.QuantityShipped) > 0 {
+			return db.SalesOrder{}, ErrPartiallyShipped
+		}
+	}
+
+	so, err := s.transition(ctx, qtx, id, tenantID, StatusCancelled)
+	if err != nil {
+		return db.SalesOrder{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return db.SalesOrder{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return so, nil
+}
+
+// transition moves a sales order to a new status if the transition table allows it.
+// The update is guarded on the current status so concurrent transitions cannot both win.
+func (s *SalesOrderService) transition(ctx context.Context, q *db.Queries, id, tenantID uuid.UUID, to string) (db.SalesOrder, error) {
+	current, err := q.GetSalesOrder(ctx, db.GetSalesOrderParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return db.SalesOrder{}, database.WrapError(err, "failed to get sales order")
+	}
+	if !CanTransition(current.Status, to) {
+		return db.SalesOrder{}, fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, current.Status, to)
+	}
+
+	so, err := q.TransitionSalesOrderStatus(ctx, db.TransitionSalesOrderStatusParams{
+		NewStatus:     to,
+		ID:            id,
+		TenantID:      tenantID,
+		CurrentStatus: current.Status,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.SalesOrder{}, fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, current.Status, to)
+		}
+		return db.SalesOrder{}, database.WrapError(err, "failed to update sales order status")
+	}
+
+	return so, nil
+}
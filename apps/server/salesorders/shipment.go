@@ -0,0 +1,215 @@
+package salesorders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"agromart2/apps/server/inventory"
+	"agromart2/db"
+	"agromart2/internal/database"
+	"agromart2/internal/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrNotShippable        = errors.New("goods can only be shipped against an approved sales order")
+	ErrNoShipmentLines     = errors.New("shipment must have at least one line")
+	ErrInvalidShipmentLine = errors.New("shipment line requires an item and positive quantity")
+	ErrUnknownItem         = errors.New("shipment line does not belong to this sales order")
+	ErrOverShipment        = errors.New("shipped quantity exceeds quantity ordered")
+)
+
+// ShipmentLine is the quantity of one sales order item shipped from a batch.
+// A nil BatchID ships from the earliest expiring batches instead. Either way batches
+// expiring within the customer's minimum shelf life are refused.
+type ShipmentLine struct {
+	ItemID   uuid.UUID
+	BatchID  uuid.UUID
+	Quantity int
+}
+
+type ShipSalesOrderParams struct {
+	SalesOrderID uuid.UUID
+	TenantID     uuid.UUID
+	Lines        []ShipmentLine
+	Notes        string
+}
+
+// ShipSalesOrder books a (possibly partial) shipment against an approved sales order.
+// Stock is taken at the order's location from the given (or FEFO-allocated) batches and
+// a SALE log row is written per batch, all in one transaction. The order moves to SHIPPED
+// once every line is fully shipped.
+func (s *SalesOrderService) ShipSalesOrder(ctx context.Context, params ShipSalesOrderParams) (*SalesOrderWithItems, error) {
+	if len(params.Lines) == 0 {
+		return nil, ErrNoShipmentLines
+	}
+	for _, line := range params.Lines {
+		if line.ItemID == uuid.Nil || line.Quantity <= 0 {
+			return nil, ErrInvalidShipmentLine
+		}
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	// Lock the order so concurrent shipments against it are serialized
+	so, err := qtx.GetSalesOrderForUpdate(ctx, db.GetSalesOrderForUpdateParams{
+		ID:       params.SalesOrderID,
+		TenantID: params.TenantID,
+	})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get sales order")
+	}
+	if so.Status != StatusApproved {
+		return nil, ErrNotShippable
+	}
+	if !so.LocationID.Valid {
+		return nil, ErrLocationRequired
+	}
+	locationID := uuid.UUID(so.LocationID.Bytes)
+
+	items, err := qtx.GetSalesOrderItems(ctx, db.GetSalesOrderItemsParams{
+		SalesOrderID: so.ID,
+		TenantID:     params.TenantID,
+	})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get sales order items")
+	}
+
+	customer, err := qtx.GetCustomerByID(ctx, db.GetCustomerByIDParams{ID: so.CustomerID, TenantID: params.TenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get customer")
+	}
+
+	byID := make(map[uuid.UUID]*db.SalesOrderItem, len(items))
+	for i := range items {
+		byID[items[i].ID] = &items[i]
+	}
+
+	for _, line := range params.Lines {
+		item, ok := byID[line.ItemID]
+		if !ok {
+			return nil, ErrUnknownItem
+		}
+
+		shipped := utils.PgNumericToInt(item.QuantityShipped) + line.Quantity
+		if shipped > utils.PgNumericToInt(item.QuantityOrdered) {
+			return nil, fmt.Errorf("%w: item %s", ErrOverShipment, item.ID)
+		}
+
+		notes := fmt.Sprintf("Shipped against %s", so.SoNumber)
+		if params.Notes != "" {
+			notes = fmt.Sprintf("%s: %s", notes, params.Notes)
+		}
+
+		// Without a batch the line is allocated first-expiry-first-out; a chosen batch is held
+		// to the same expiry and customer minimum shelf life cutoff
+		plan, err := inventory.AllocateFEFO(ctx, qtx, params.TenantID, inventory.AllocationRequest{
+			LocationID:       locationID,
+			ProductID:        item.ProductID,
+			Quantity:         line.Quantity,
+			MinShelfLifeDays: int(customer.MinShelfLifeDays),
+			BatchID:          line.BatchID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		allocations := plan.Allocations
+
+		for _, a := range allocations {
+			if err := shipFromBatch(ctx, qtx, params.TenantID, locationID, item.ProductID, a.BatchID, a.Quantity, so.ID, notes); err != nil {
+				return nil, err
+			}
+		}
+
+		// The item names its batch only while everything shipped for it came from that one
+		// batch. Once it spans several the batch is cleared; the SALE ledger entries
+		// referencing the order record each of them.
+		batchID := pgtype.UUID{}
+		if len(allocations) == 1 && (utils.PgNumericToInt(item.QuantityShipped) == 0 || item.BatchID == utils.P.UUID(allocations[0].BatchID)) {
+			batchID = utils.P.UUID(allocations[0].BatchID)
+		}
+
+		updated, err := qtx.UpdateSalesOrderItemQuantityShipped(ctx, db.UpdateSalesOrderItemQuantityShippedParams{
+			ID:              item.ID,
+			QuantityShipped: utils.P.Numeric(shipped),
+			TenantID:        params.TenantID,
+		})
+		if err != nil {
+			return nil, database.WrapError(err, "failed to update quantity shipped")
+		}
+
+		if err := qtx.SetSalesOrderItemBatch(ctx, db.SetSalesOrderItemBatchParams{
+			ID:       item.ID,
+			BatchID:  batchID,
+			TenantID: params.TenantID,
+		}); err != nil {
+			return nil, database.WrapError(err, "failed to link batch to sales order item")
+		}
+
+		updated.BatchID = batchID
+		*item = updated
+	}
+
+	if fullyShipped(items) {
+		if _, err := s.transition(ctx, qtx, so.ID, params.TenantID, StatusShipped); err != nil {
+			return nil, err
+		}
+	}
+
+	so, err = qtx.GetSalesOrder(ctx, db.GetSalesOrderParams{ID: so.ID, TenantID: params.TenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to reload sales order")
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Info().
+		Str("sales_order_id", so.ID.String()).
+		Str("status", so.Status).
+		Int("lines", len(params.Lines)).
+		Msg("goods shipped")
+
+	return &SalesOrderWithItems{SalesOrder: so, Items: items}, nil
+}
+
+// shipFromBatch takes quantity out of a batch at a location and logs the sale
+func shipFromBatch(ctx context.Context, q *db.Queries, tenantID, locationID, productID, batchID uuid.UUID, quantity int, salesOrderID uuid.UUID, notes string) error {
+	if _, err := inventory.Decrement(ctx, q, tenantID, locationID, productID, batchID, quantity); err != nil {
+		return err
+	}
+
+	if err := q.CreateInventoryLog(ctx, db.CreateInventoryLogParams{
+		TenantID:        tenantID,
+		ProductID:       productID,
+		BatchID:         batchID,
+		LocationID:      locationID,
+		TransactionType: inventory.TxSale,
+		QuantityChange:  utils.P.Numeric(-quantity),
+		ReferenceID:     utils.P.UUID(salesOrderID),
+		Notes:           utils.P.Text(notes),
+	}); err != nil {
+		return database.WrapError(err, "failed to log shipment")
+	}
+
+	return nil
+}
+
+func fullyShipped(items []db.SalesOrderItem) bool {
+	for _, item := range items {
+		if utils.PgNumericToInt(item.QuantityShipped) < utils.PgNumericToInt(item.QuantityOrdered) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,27 @@
+package salesorders
+
+// Sales order statuses as stored in sales_orders.status
+const (
+	StatusPending   = "PENDING"
+	StatusApproved  = "APPROVED"
+	StatusShipped   = "SHIPPED"
+	StatusDelivered = "DELIVERED"
+	StatusCancelled = "CANCELLED"
+)
+
+// transitions lists the statuses each status may move to
+var transitions = map[string][]string{
+	StatusPending:  {StatusApproved, StatusCancelled},
+	StatusApproved: {StatusShipped, StatusCancelled},
+	StatusShipped:  {StatusDelivered},
+}
+
+// CanTransition reports whether a sales order may move from one status to another
+func CanTransition(from, to string) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,13 @@
+-- name: ListAuditLog :many
+SELECT * FROM audit_log
+WHERE tenant_id = sqlc.arg('tenant_id')
+    AND (sqlc.narg('entity_type')::text IS NULL OR entity_type = sqlc.narg('entity_type'))
+    AND (sqlc.narg('entity_id')::uuid IS NULL OR entity_id = sqlc.narg('entity_id'))
+    AND (sqlc.narg('action')::text IS NULL OR action = sqlc.narg('action'))
+    AND (sqlc.narg('actor_user_id')::uuid IS NULL OR actor_user_id = sqlc.narg('actor_user_id'))
+    AND (sqlc.narg('impersonator_id')::uuid IS NULL OR impersonator_id = sqlc.narg('impersonator_id'))
+    AND (sqlc.narg('request_id')::text IS NULL OR request_id = sqlc.narg('request_id'))
+    AND (sqlc.narg('since')::timestamptz IS NULL OR created_at >= sqlc.narg('since'))
+    AND (sqlc.narg('until')::timestamptz IS NULL OR created_at < sqlc.narg('until'))
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
@@ -1,6 +1,6 @@
 -- name: CreateCustomer :one
-INSERT INTO customers (tenant_id, name, contact_person, email, phone, address, payment_mode)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO customers (tenant_id, name, contact_person, email, phone, address, payment_mode, min_shelf_life_days)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 RETURNING *;
 
 -- name: GetCustomerByID :one
@@ -9,7 +9,7 @@ WHERE id = $1 AND tenant_id = $2;
 
 -- name: UpdateCustomer :one
 UPDATE customers
-SET name = $2, contact_person = $3, email = $4, phone = $5, address = $6, payment_mode = $7, is_active = $8, updated_at = NOW()
+SET name = $2, contact_person = $3, email = $4, phone = $5, address = $6, payment_mode = $7, is_active = $8, min_shelf_life_days = $10, updated_at = NOW()
 WHERE id = $1 AND tenant_id = $9
 RETURNING *;
 
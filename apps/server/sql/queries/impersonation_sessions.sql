@@ -0,0 +1,32 @@
+-- name: CreateImpersonationSession :one
+INSERT INTO impersonation_sessions (impersonator_id, tenant_id, user_id, reason, ip_address, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: EndImpersonationSession :one
+UPDATE impersonation_sessions
+SET ended_at = NOW()
+WHERE id = $1 AND ended_at IS NULL
+RETURNING *;
+
+-- name: EndTenantImpersonationSessions :exec
+UPDATE impersonation_sessions
+SET ended_at = NOW()
+WHERE tenant_id = $1 AND ended_at IS NULL;
+
+-- name: IsImpersonationActive :one
+SELECT EXISTS (
+    SELECT 1 FROM impersonation_sessions s
+    JOIN users u ON u.id = s.impersonator_id
+    JOIN tenant_memberships m ON m.user_id = u.id
+    WHERE s.id = $1 AND s.ended_at IS NULL AND s.expires_at > NOW()
+        AND m.role = 'super_admin' AND m.is_active AND u.is_active IS NOT FALSE
+);
+
+-- name: ListImpersonationSessions :many
+SELECT * FROM impersonation_sessions
+WHERE (sqlc.narg('tenant_id')::uuid IS NULL OR tenant_id = sqlc.narg('tenant_id'))
+    AND (sqlc.narg('impersonator_id')::uuid IS NULL OR impersonator_id = sqlc.narg('impersonator_id'))
+    AND (NOT sqlc.arg('active_only')::boolean OR (ended_at IS NULL AND expires_at > NOW()))
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
@@ -4,31 +4,36 @@ VALUES ($1, $2, $3, $4, $5)
 RETURNING *;
 
 -- name: AddInventoryQuantity :exec
-INSERT INTO inventory (tenant_id, product_id, batch_id, quantity)
-VALUES ($1, $2, $3, $4)
-ON CONFLICT (tenant_id, product_id, batch_id)
+INSERT INTO inventory (tenant_id, product_id, batch_id, quantity, location_id)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (tenant_id, location_id, product_id, batch_id)
 DO UPDATE SET quantity = inventory.quantity + $4;
 
--- name: ReduceInventoryQuantity :exec
+-- name: ReduceInventoryQuantity :one
 UPDATE inventory
-SET quantity = quantity - $1
-WHERE tenant_id = $2 AND product_id = $3 AND batch_id = $4;
+SET quantity = quantity - sqlc.arg('quantity')
+WHERE tenant_id = sqlc.arg('tenant_id') AND product_id = sqlc.arg('product_id') AND batch_id = sqlc.arg('batch_id')
+    AND location_id = sqlc.arg('location_id') AND quantity >= sqlc.arg('quantity')
+RETURNING quantity;
 
 -- name: GetProductQuantity :one
 SELECT COALESCE(SUM(quantity), 0) AS total_quantity
 FROM inventory
-WHERE tenant_id = $1 AND product_id = $2;
+WHERE tenant_id = sqlc.arg('tenant_id') AND product_id = sqlc.arg('product_id')
+    AND (sqlc.narg('location_id')::uuid IS NULL OR location_id = sqlc.narg('location_id'));
 
 -- name: GetProductInventoryDetails :many
-SELECT b.batch_number, b.expiry_date, i.quantity
+SELECT i.location_id, l.name AS location_name, b.batch_number, b.expiry_date, i.quantity
 FROM inventory i
 JOIN batches b ON i.batch_id = b.id
-WHERE i.tenant_id = $1 AND i.product_id = $2
-ORDER BY b.expiry_date ASC;
+JOIN locations l ON i.location_id = l.id
+WHERE i.tenant_id = sqlc.arg('tenant_id') AND i.product_id = sqlc.arg('product_id')
+    AND (sqlc.narg('location_id')::uuid IS NULL OR i.location_id = sqlc.narg('location_id'))
+ORDER BY b.expiry_date ASC, l.name;
 
 -- name: CreateInventoryLog :exec
-INSERT INTO inventory_log (tenant_id, product_id, batch_id, transaction_type, quantity_change, reference_id, notes)
-VALUES ($1, $2, $3, $4, $5, $6, $7);
+INSERT INTO inventory_log (tenant_id, product_id, batch_id, transaction_type, quantity_change, reference_id, notes, location_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
 
 -- name: GetBatchByID :one
 SELECT * FROM batches
@@ -42,16 +47,12 @@ RETURNING *;
 
 -- name: GetInventoryByProductBatch :one
 SELECT * FROM inventory
-WHERE tenant_id = $1 AND product_id = $2 AND batch_id = $3;
-
--- name: SetInventoryQuantity :exec
-UPDATE inventory
-SET quantity = $1
-WHERE tenant_id = $2 AND product_id = $3 AND batch_id = $4;
-
+WHERE tenant_id = $1 AND product_id = $2 AND batch_id = $3 AND location_id = $4;
 -- name: ListAllInventory :many
 SELECT
     i.id,
+    i.location_id,
+    l.name AS location_name,
     p.name AS product_name,
     p.sku,
     b.batch_number,
@@ -62,28 +63,31 @@ FROM inventory i
 JOIN products p ON i.product_id = p.id
 JOIN batches b ON i.batch_id = b.id
 JOIN units u ON p.unit_id = u.id
-WHERE i.tenant_id = $1
-ORDER BY p.name, b.expiry_date
-LIMIT $2 OFFSET $3;
+JOIN locations l ON i.location_id = l.id
+WHERE i.tenant_id = sqlc.arg('tenant_id')
+    AND (sqlc.narg('location_id')::uuid IS NULL OR i.location_id = sqlc.narg('location_id'))
+ORDER BY p.name, b.expiry_date, l.name
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 
 -- name: GetLowStockReport :many
 SELECT p.id, p.name, p.sku, SUM(i.quantity) as total_quantity
 FROM products p
 JOIN inventory i ON p.id = i.product_id
-WHERE p.tenant_id = $1
+WHERE p.tenant_id = sqlc.arg('tenant_id')
+    AND (sqlc.narg('location_id')::uuid IS NULL OR i.location_id = sqlc.narg('location_id'))
 GROUP BY p.id
-HAVING SUM(i.quantity) <= $2;
+HAVING SUM(i.quantity) <= sqlc.arg('threshold');
 
 -- name: GetInventoryLogByProduct :many
 SELECT * FROM inventory_log
 WHERE tenant_id = $1 AND product_id = $2
-ORDER BY transaction_date DESC
+ORDER BY entry_no DESC
 LIMIT $3 OFFSET $4;
 
 -- name: GetInventoryLogByBatch :many
 SELECT * FROM inventory_log
 WHERE tenant_id = $1 AND batch_id = $2
-ORDER BY transaction_date DESC
+ORDER BY entry_no DESC
 LIMIT $3 OFFSET $4;
 
 -- name: GetExpiringBatches :many
@@ -94,22 +98,67 @@ SELECT
     p.id as product_id,
     p.name as product_name,
     p.sku as product_sku,
+    i.location_id,
+    l.name as location_name,
     i.quantity,
     EXTRACT(DAY FROM (b.expiry_date - CURRENT_DATE)) as days_until_expiry
 FROM batches b
 JOIN products p ON b.product_id = p.id
 JOIN inventory i ON b.id = i.batch_id
-WHERE b.tenant_id = $1
-    AND b.expiry_date <= $2
+JOIN locations l ON i.location_id = l.id
+WHERE b.tenant_id = sqlc.arg('tenant_id')
+    AND b.expiry_date <= sqlc.arg('expiry_date')
     AND i.quantity > 0
+    AND (sqlc.narg('location_id')::uuid IS NULL OR i.location_id = sqlc.narg('location_id'))
 ORDER BY b.expiry_date ASC;
 
 -- name: GetInventoryValue :one
 SELECT COALESCE(SUM(i.quantity * b.cost), 0) as total_value
 FROM inventory i
 JOIN batches b ON i.batch_id = b.id
-WHERE i.tenant_id = $1;
+WHERE i.tenant_id = sqlc.arg('tenant_id')
+    AND (sqlc.narg('location_id')::uuid IS NULL OR i.location_id = sqlc.narg('location_id'));
 
 -- name: CountProductsByTenant :one
 SELECT COUNT(*) FROM products
 WHERE tenant_id = $1;
+
+-- name: GetBatchByNumber :one
+SELECT * FROM batches
+WHERE tenant_id = $1 AND product_id = $2 AND batch_number = $3;
+
+-- name: LockProductInventory :exec
+SELECT id FROM inventory
+WHERE tenant_id = $1 AND product_id = $2 AND location_id = $3
+ORDER BY id
+FOR UPDATE;
+
+-- name: GetInventoryForUpdate :one
+SELECT * FROM inventory
+WHERE tenant_id = $1 AND product_id = $2 AND batch_id = $3 AND location_id = $4
+FOR UPDATE;
+
+-- name: ListAllocatableBatches :many
+SELECT i.batch_id, b.batch_number, b.expiry_date, i.quantity
+FROM inventory i
+JOIN batches b ON i.batch_id = b.id
+WHERE i.tenant_id = $1 AND i.product_id = $2 AND i.location_id = $3
+    AND i.quantity > 0
+    AND b.expiry_date >= $4
+ORDER BY b.expiry_date ASC, b.created_at ASC, b.batch_number ASC;
+
+-- name: GetInventoryValuation :many
+SELECT
+    p.id AS product_id,
+    p.name AS product_name,
+    p.sku AS product_sku,
+    SUM(i.quantity)::numeric AS total_quantity,
+    SUM(i.quantity * b.cost)::numeric AS total_value
+FROM inventory i
+JOIN products p ON i.product_id = p.id
+JOIN batches b ON i.batch_id = b.id
+WHERE i.tenant_id = sqlc.arg('tenant_id')
+    AND i.quantity > 0
+    AND (sqlc.narg('location_id')::uuid IS NULL OR i.location_id = sqlc.narg('location_id'))
+GROUP BY p.id, p.name, p.sku
+ORDER BY total_value DESC;
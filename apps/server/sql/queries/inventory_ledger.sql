@@ -0,0 +1,64 @@
+-- name: ListStockAsOf :many
+WITH totals AS (
+    SELECT
+        l.location_id, l.product_id, l.batch_id,
+        SUM(l.quantity_change) AS quantity,
+        MAX(l.transaction_date) AS last_movement_at
+    FROM inventory_log l
+    WHERE l.tenant_id = sqlc.arg('tenant_id') AND l.transaction_date < sqlc.arg('as_of')
+        AND (sqlc.narg('product_id')::uuid IS NULL OR l.product_id = sqlc.narg('product_id'))
+        AND (sqlc.narg('batch_id')::uuid IS NULL OR l.batch_id = sqlc.narg('batch_id'))
+        AND (sqlc.narg('location_id')::uuid IS NULL OR l.location_id = sqlc.narg('location_id'))
+    GROUP BY l.location_id, l.product_id, l.batch_id
+)
+SELECT
+    totals.location_id,
+    loc.name AS location_name,
+    totals.product_id,
+    p.name AS product_name,
+    p.sku AS product_sku,
+    totals.batch_id,
+    b.batch_number,
+    totals.quantity::numeric AS quantity,
+    totals.last_movement_at::timestamptz AS last_movement_at
+FROM totals
+JOIN locations loc ON totals.location_id = loc.id
+JOIN products p ON totals.product_id = p.id
+JOIN batches b ON totals.batch_id = b.id
+WHERE totals.quantity <> 0
+ORDER BY p.name, b.batch_number, loc.name;
+
+-- name: GetLedgerBalanceAsOf :one
+SELECT COALESCE(SUM(l.quantity_change), 0)::numeric AS balance
+FROM inventory_log l
+WHERE l.tenant_id = sqlc.arg('tenant_id') AND l.transaction_date < sqlc.arg('as_of')
+    AND (sqlc.narg('product_id')::uuid IS NULL OR l.product_id = sqlc.narg('product_id'))
+    AND (sqlc.narg('batch_id')::uuid IS NULL OR l.batch_id = sqlc.narg('batch_id'))
+    AND (sqlc.narg('location_id')::uuid IS NULL OR l.location_id = sqlc.narg('location_id'));
+
+-- name: ListLedgerEntries :many
+SELECT
+    l.id,
+    l.entry_no,
+    l.transaction_date,
+    l.transaction_type,
+    l.location_id,
+    loc.name AS location_name,
+    l.product_id,
+    p.name AS product_name,
+    l.batch_id,
+    b.batch_number,
+    l.quantity_change,
+    l.balance_after,
+    l.reference_id,
+    l.notes
+FROM inventory_log l
+JOIN locations loc ON l.location_id = loc.id
+JOIN products p ON l.product_id = p.id
+JOIN batches b ON l.batch_id = b.id
+WHERE l.tenant_id = sqlc.arg('tenant_id')
+    AND l.transaction_date >= sqlc.arg('from_date') AND l.transaction_date < sqlc.arg('to_date')
+    AND (sqlc.narg('product_id')::uuid IS NULL OR l.product_id = sqlc.narg('product_id'))
+    AND (sqlc.narg('batch_id')::uuid IS NULL OR l.batch_id = sqlc.narg('batch_id'))
+    AND (sqlc.narg('location_id')::uuid IS NULL OR l.location_id = sqlc.narg('location_id'))
+ORDER BY l.entry_no;
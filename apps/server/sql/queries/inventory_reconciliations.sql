@@ -0,0 +1,116 @@
+-- name: ListInventoryDiscrepancies :many
+WITH ledger AS (
+    SELECT DISTINCT ON (l.location_id, l.batch_id)
+        l.location_id, l.product_id, l.batch_id, l.balance_after
+    FROM inventory_log l
+    WHERE l.tenant_id = sqlc.arg('tenant_id')
+        AND (sqlc.narg('location_id')::uuid IS NULL OR l.location_id = sqlc.narg('location_id'))
+    ORDER BY l.location_id, l.batch_id, l.entry_no DESC
+), stock AS (
+    SELECT i.location_id, i.product_id, i.batch_id, i.quantity
+    FROM inventory i
+    WHERE i.tenant_id = sqlc.arg('tenant_id')
+        AND (sqlc.narg('location_id')::uuid IS NULL OR i.location_id = sqlc.narg('location_id'))
+), compared AS (
+    SELECT
+        COALESCE(s.location_id, g.location_id) AS location_id,
+        COALESCE(s.product_id, g.product_id) AS product_id,
+        COALESCE(s.batch_id, g.batch_id) AS batch_id,
+        COALESCE(s.quantity, 0) AS inventory_quantity,
+        COALESCE(g.balance_after, 0) AS ledger_quantity
+    FROM stock s
+    FULL OUTER JOIN ledger g ON s.location_id = g.location_id AND s.batch_id = g.batch_id
+)
+SELECT
+    c.location_id::uuid AS location_id,
+    c.product_id::uuid AS product_id,
+    c.batch_id::uuid AS batch_id,
+    c.inventory_quantity::numeric AS inventory_quantity,
+    c.ledger_quantity::numeric AS ledger_quantity,
+    (c.inventory_quantity - c.ledger_quantity)::numeric AS difference,
+    b.cost AS unit_cost,
+    (ABS(c.inventory_quantity - c.ledger_quantity) * b.cost)::numeric AS value
+FROM compared c
+JOIN batches b ON c.batch_id = b.id
+WHERE c.inventory_quantity <> c.ledger_quantity
+ORDER BY c.location_id, c.product_id, c.batch_id;
+
+-- name: LockLocationInventory :exec
+SELECT id FROM inventory
+WHERE tenant_id = sqlc.arg('tenant_id')
+    AND (sqlc.narg('location_id')::uuid IS NULL OR location_id = sqlc.narg('location_id'))
+ORDER BY id
+FOR UPDATE;
+
+-- name: CreateInventoryReconciliation :one
+INSERT INTO inventory_reconciliations (tenant_id, source, location_id, created_by)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: CreateInventoryReconciliationLine :exec
+INSERT INTO inventory_reconciliation_lines (
+    tenant_id, reconciliation_id, location_id, product_id, batch_id,
+    inventory_quantity, ledger_quantity, difference, unit_cost, value
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+
+-- name: SetInventoryReconciliationTotals :one
+UPDATE inventory_reconciliations r
+SET discrepancy_count = t.discrepancy_count, total_value = t.total_value, updated_at = NOW()
+FROM (
+    SELECT COUNT(*)::int AS discrepancy_count, COALESCE(SUM(value), 0) AS total_value
+    FROM inventory_reconciliation_lines
+    WHERE reconciliation_id = $1 AND tenant_id = $2
+) t
+WHERE r.id = $1 AND r.tenant_id = $2
+RETURNING r.id, r.tenant_id, r.status, r.source, r.location_id, r.discrepancy_count, r.total_value, r.created_by, r.resolved_by, r.resolved_at, r.created_at, r.updated_at;
+
+-- name: GetInventoryReconciliation :one
+SELECT * FROM inventory_reconciliations
+WHERE id = $1 AND tenant_id = $2;
+
+-- name: GetInventoryReconciliationForUpdate :one
+SELECT * FROM inventory_reconciliations
+WHERE id = $1 AND tenant_id = $2
+FOR UPDATE;
+
+-- name: ListInventoryReconciliations :many
+SELECT * FROM inventory_reconciliations
+WHERE tenant_id = sqlc.arg('tenant_id')
+    AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListInventoryReconciliationLines :many
+SELECT
+    l.id,
+    l.location_id,
+    loc.name AS location_name,
+    l.product_id,
+    p.name AS product_name,
+    l.batch_id,
+    b.batch_number,
+    l.inventory_quantity,
+    l.ledger_quantity,
+    l.difference,
+    l.unit_cost,
+    l.value
+FROM inventory_reconciliation_lines l
+JOIN locations loc ON l.location_id = loc.id
+JOIN products p ON l.product_id = p.id
+JOIN batches b ON l.batch_id = b.id
+WHERE l.reconciliation_id = $1 AND l.tenant_id = $2
+ORDER BY loc.name, p.name, b.batch_number;
+
+-- name: ResolveInventoryReconciliation :one
+UPDATE inventory_reconciliations
+SET status = sqlc.arg('new_status'), resolved_by = sqlc.arg('resolved_by'), resolved_at = NOW(), updated_at = NOW()
+WHERE id = sqlc.arg('id') AND tenant_id = sqlc.arg('tenant_id') AND status = sqlc.arg('current_status')
+RETURNING *;
+
+-- name: HasOpenInventoryReconciliation :one
+SELECT EXISTS (
+    SELECT 1 FROM inventory_reconciliations
+    WHERE tenant_id = sqlc.arg('tenant_id')
+        AND location_id IS NOT DISTINCT FROM sqlc.narg('location_id')
+        AND status = 'OPEN'
+);
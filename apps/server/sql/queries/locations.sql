@@ -15,6 +15,37 @@ RETURNING *;
 
 -- name: ListLocations :many
 SELECT * FROM locations
-WHERE tenant_id = $1 AND location_type = $2 AND is_active = $3
+WHERE tenant_id = sqlc.arg('tenant_id')
+    AND (sqlc.narg('location_type')::text IS NULL OR location_type = sqlc.narg('location_type'))
+    AND (sqlc.narg('is_active')::boolean IS NULL OR is_active = sqlc.narg('is_active'))
 ORDER BY name
-LIMIT $4 OFFSET $5;
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: SetLocationActive :one
+UPDATE locations
+SET is_active = $3, updated_at = NOW()
+WHERE id = $1 AND tenant_id = $2
+RETURNING *;
+
+-- name: DeleteLocation :exec
+DELETE FROM locations
+WHERE id = $1 AND tenant_id = $2;
+
+-- name: ListLocationStockSummary :many
+SELECT
+    l.id AS location_id,
+    l.name AS location_name,
+    l.location_type,
+    l.is_active,
+    COUNT(DISTINCT i.product_id) AS product_count,
+    COUNT(i.id) AS batch_count,
+    COALESCE(SUM(i.quantity), 0)::numeric AS total_quantity,
+    COALESCE(SUM(i.quantity * b.cost), 0)::numeric AS total_value,
+    COUNT(i.id) FILTER (WHERE b.expiry_date <= CURRENT_DATE + 30) AS expiring_batches
+FROM locations l
+LEFT JOIN inventory i ON i.location_id = l.id AND i.quantity > 0
+LEFT JOIN batches b ON i.batch_id = b.id
+WHERE l.tenant_id = sqlc.arg('tenant_id')
+    AND (sqlc.narg('location_id')::uuid IS NULL OR l.id = sqlc.narg('location_id'))
+GROUP BY l.id, l.name, l.location_type, l.is_active
+ORDER BY l.name;
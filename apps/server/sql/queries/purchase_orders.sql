@@ -1,6 +1,6 @@
 -- name: CreatePurchaseOrder :one
-INSERT INTO purchase_orders (tenant_id, po_number, supplier_id, location_id, created_by)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO purchase_orders (tenant_id, po_number, supplier_id, location_id, created_by, expected_delivery_date, notes)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING *;
 
 -- name: CreatePurchaseOrderItem :one
@@ -45,23 +45,93 @@ WHERE id = $1 AND tenant_id = $2;
 
 -- name: GetProductMovementReport :many
 SELECT
+    p.id AS product_id,
     p.name AS product_name,
-    SUM(COALESCE(poi.quantity_ordered, 0)) AS total_purchased,
-    SUM(COALESCE(soi.quantity_ordered, 0)) AS total_sold
+    p.sku AS product_sku,
+    COALESCE(pur.quantity_ordered, 0)::numeric AS quantity_purchased,
+    COALESCE(pur.quantity_received, 0)::numeric AS quantity_received,
+    COALESCE(sal.quantity_ordered, 0)::numeric AS quantity_sold,
+    COALESCE(sal.quantity_shipped, 0)::numeric AS quantity_shipped
 FROM products p
-LEFT JOIN purchase_order_items poi ON p.id = poi.product_id AND p.tenant_id = poi.tenant_id
-LEFT JOIN sales_order_items soi ON p.id = soi.product_id AND p.tenant_id = soi.tenant_id
-WHERE p.tenant_id = $1
-GROUP BY p.id, p.name
+LEFT JOIN (
+    SELECT poi.product_id, SUM(poi.quantity_ordered) AS quantity_ordered, SUM(COALESCE(poi.quantity_received, 0)) AS quantity_received
+    FROM purchase_order_items poi
+    JOIN purchase_orders po ON poi.purchase_order_id = po.id
+    WHERE po.tenant_id = sqlc.arg('tenant_id')
+        AND po.status <> 'CANCELLED'
+        AND po.order_date BETWEEN sqlc.arg('start_date')::date AND sqlc.arg('end_date')::date
+    GROUP BY poi.product_id
+) pur ON pur.product_id = p.id
+LEFT JOIN (
+    SELECT soi.product_id, SUM(soi.quantity_ordered) AS quantity_ordered, SUM(COALESCE(soi.quantity_shipped, 0)) AS quantity_shipped
+    FROM sales_order_items soi
+    JOIN sales_orders so ON soi.sales_order_id = so.id
+    WHERE so.tenant_id = sqlc.arg('tenant_id')
+        AND so.status <> 'CANCELLED'
+        AND so.order_date BETWEEN sqlc.arg('start_date')::date AND sqlc.arg('end_date')::date
+    GROUP BY soi.product_id
+) sal ON sal.product_id = p.id
+WHERE p.tenant_id = sqlc.arg('tenant_id')
 ORDER BY p.name;
 
 -- name: GetSupplierPurchaseSummary :many
 SELECT
+    s.id AS supplier_id,
     s.name AS supplier_name,
-    SUM(po.final_amount) AS total_purchased_amount,
+    COALESCE(SUM(po.final_amount), 0)::numeric AS total_purchased_amount,
     COUNT(po.id) AS total_orders
 FROM suppliers s
 JOIN purchase_orders po ON s.id = po.supplier_id AND s.tenant_id = po.tenant_id
-WHERE s.tenant_id = $1
+WHERE s.tenant_id = sqlc.arg('tenant_id')
+    AND po.status <> 'CANCELLED'
+    AND po.order_date BETWEEN sqlc.arg('start_date')::date AND sqlc.arg('end_date')::date
 GROUP BY s.id, s.name
 ORDER BY total_purchased_amount DESC;
+
+-- name: ListPurchaseOrders :many
+SELECT * FROM purchase_orders
+WHERE tenant_id = $1
+ORDER BY order_date DESC, created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: UpdatePurchaseOrderDetails :one
+UPDATE purchase_orders
+SET supplier_id = $2, location_id = $3, expected_delivery_date = $4, notes = $5, updated_at = NOW()
+WHERE id = $1 AND tenant_id = $6 AND status = 'PENDING'
+RETURNING *;
+
+-- name: UpdatePurchaseOrderTotals :exec
+UPDATE purchase_orders
+SET total_amount = $2, final_amount = $3, updated_at = NOW()
+WHERE id = $1 AND tenant_id = $4;
+
+-- name: DeletePurchaseOrderItems :exec
+DELETE FROM purchase_order_items
+WHERE purchase_order_id = $1 AND tenant_id = $2;
+
+-- name: ApprovePurchaseOrder :one
+UPDATE purchase_orders
+SET status = 'APPROVED', approved_by = $1, approved_at = NOW(), updated_at = NOW()
+WHERE id = $2 AND tenant_id = $3 AND status = 'PENDING'
+RETURNING *;
+
+-- name: TransitionPurchaseOrderStatus :one
+UPDATE purchase_orders
+SET status = sqlc.arg('new_status'), updated_at = NOW()
+WHERE id = sqlc.arg('id') AND tenant_id = sqlc.arg('tenant_id') AND status = sqlc.arg('current_status')
+RETURNING *;
+
+-- name: GetPurchaseOrderForUpdate :one
+SELECT * FROM purchase_orders
+WHERE id = $1 AND tenant_id = $2
+FOR UPDATE;
+
+-- name: SetPurchaseOrderItemBatch :exec
+UPDATE purchase_order_items
+SET batch_id = $2, updated_at = NOW()
+WHERE id = $1 AND tenant_id = $3;
+
+-- name: SetPurchaseOrderDeliveryDate :exec
+UPDATE purchase_orders
+SET actual_delivery_date = CURRENT_DATE, updated_at = NOW()
+WHERE id = $1 AND tenant_id = $2;
@@ -0,0 +1,55 @@
+-- name: CreateRefreshToken :one
+INSERT INTO refresh_tokens (tenant_id, user_id, family_id, token_hash, user_agent, ip_address, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetRefreshTokenByHashForUpdate :one
+SELECT * FROM refresh_tokens
+WHERE token_hash = $1
+FOR UPDATE;
+
+-- name: MarkRefreshTokenUsed :exec
+UPDATE refresh_tokens
+SET used_at = NOW()
+WHERE id = $1;
+
+-- name: RevokeRefreshTokenFamily :exec
+UPDATE refresh_tokens
+SET revoked_at = NOW()
+WHERE family_id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeRefreshTokenFamilyByHash :exec
+UPDATE refresh_tokens
+SET revoked_at = NOW()
+WHERE family_id = (SELECT rt.family_id FROM refresh_tokens rt WHERE rt.token_hash = $1)
+    AND revoked_at IS NULL;
+
+-- name: RevokeUserSession :execrows
+UPDATE refresh_tokens
+SET revoked_at = NOW()
+WHERE family_id = $1 AND user_id = $2 AND revoked_at IS NULL;
+
+-- name: RevokeUserRefreshTokens :exec
+UPDATE refresh_tokens
+SET revoked_at = NOW()
+WHERE user_id = $1 AND revoked_at IS NULL;
+
+-- name: IsSessionRevoked :one
+SELECT EXISTS (
+    SELECT 1 FROM refresh_tokens WHERE family_id = $1 AND revoked_at IS NOT NULL
+);
+
+-- name: ListUserSessions :many
+SELECT * FROM refresh_tokens
+WHERE user_id = $1 AND used_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()
+ORDER BY created_at DESC;
+
+-- name: RevokeTenantRefreshTokens :exec
+UPDATE refresh_tokens
+SET revoked_at = NOW()
+WHERE tenant_id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeUserTenantRefreshTokens :exec
+UPDATE refresh_tokens
+SET revoked_at = NOW()
+WHERE user_id = $1 AND tenant_id = $2 AND revoked_at IS NULL;
@@ -1,6 +1,6 @@
 -- name: CreateSalesOrder :one
-INSERT INTO sales_orders (tenant_id, so_number, customer_id, location_id, created_by)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO sales_orders (tenant_id, so_number, customer_id, location_id, created_by, expected_delivery_date, notes)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING *;
 
 -- name: CreateSalesOrderItem :one
@@ -35,17 +35,23 @@ RETURNING *;
 
 -- name: GetSalesReportByDate :many
 SELECT
-    p.name as product_name,
-    SUM(soi.quantity_shipped) as total_units_sold,
-    SUM(soi.total_price) as total_revenue
-FROM sales_order_items soi
-JOIN products p ON soi.product_id = p.id
-JOIN sales_orders so ON soi.sales_order_id = so.id
-WHERE soi.tenant_id = $1
-  AND so.order_date BETWEEN $2 AND $3
-  AND so.status = 'DELIVERED' -- or 'SHIPPED'
-GROUP BY p.name
-ORDER BY total_revenue DESC;
+    so.order_date,
+    COUNT(so.id) AS total_orders,
+    COALESCE(SUM(so.final_amount), 0)::numeric AS total_sales_amount,
+    COALESCE(SUM(u.units_ordered), 0)::numeric AS units_ordered,
+    COALESCE(SUM(u.units_shipped), 0)::numeric AS units_shipped
+FROM sales_orders so
+LEFT JOIN (
+    SELECT sales_order_id, SUM(quantity_ordered) AS units_ordered, SUM(COALESCE(quantity_shipped, 0)) AS units_shipped
+    FROM sales_order_items
+    WHERE tenant_id = sqlc.arg('tenant_id')
+    GROUP BY sales_order_id
+) u ON u.sales_order_id = so.id
+WHERE so.tenant_id = sqlc.arg('tenant_id')
+    AND so.status <> 'CANCELLED'
+    AND so.order_date BETWEEN sqlc.arg('start_date')::date AND sqlc.arg('end_date')::date
+GROUP BY so.order_date
+ORDER BY so.order_date;
 
 -- name: GetSalesOrderItemByID :one
 SELECT * FROM sales_order_items
@@ -53,11 +59,72 @@ WHERE id = $1 AND tenant_id = $2;
 
 -- name: GetCustomerSalesSummary :many
 SELECT
+    c.id AS customer_id,
     c.name AS customer_name,
-    SUM(so.final_amount) AS total_sales_amount,
+    COALESCE(SUM(so.final_amount), 0)::numeric AS total_sales_amount,
     COUNT(so.id) AS total_orders
 FROM customers c
 JOIN sales_orders so ON c.id = so.customer_id AND c.tenant_id = so.tenant_id
-WHERE c.tenant_id = $1
+WHERE c.tenant_id = sqlc.arg('tenant_id')
+    AND so.status <> 'CANCELLED'
+    AND so.order_date BETWEEN sqlc.arg('start_date')::date AND sqlc.arg('end_date')::date
 GROUP BY c.id, c.name
 ORDER BY total_sales_amount DESC;
+
+-- name: ListSalesOrders :many
+SELECT * FROM sales_orders
+WHERE tenant_id = $1
+ORDER BY order_date DESC, created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: ListSalesOrdersByStatus :many
+SELECT * FROM sales_orders
+WHERE tenant_id = $1 AND status = $2
+ORDER BY order_date DESC
+LIMIT $3 OFFSET $4;
+
+-- name: GetSalesOrderForUpdate :one
+SELECT * FROM sales_orders
+WHERE id = $1 AND tenant_id = $2
+FOR UPDATE;
+
+-- name: UpdateSalesOrderTotals :exec
+UPDATE sales_orders
+SET total_amount = $2, final_amount = $3, updated_at = NOW()
+WHERE id = $1 AND tenant_id = $4;
+
+-- name: ApproveSalesOrder :one
+UPDATE sales_orders
+SET status = 'APPROVED', approved_by = $1, approved_at = NOW(), updated_at = NOW()
+WHERE id = $2 AND tenant_id = $3 AND status = 'PENDING'
+RETURNING *;
+
+-- name: TransitionSalesOrderStatus :one
+UPDATE sales_orders
+SET status = sqlc.arg('new_status'), updated_at = NOW()
+WHERE id = sqlc.arg('id') AND tenant_id = sqlc.arg('tenant_id') AND status = sqlc.arg('current_status')
+RETURNING *;
+
+-- name: SetSalesOrderItemBatch :exec
+UPDATE sales_order_items
+SET batch_id = $2, updated_at = NOW()
+WHERE id = $1 AND tenant_id = $3;
+
+-- name: SetSalesOrderDeliveryDate :exec
+UPDATE sales_orders
+SET actual_delivery_date = CURRENT_DATE, updated_at = NOW()
+WHERE id = $1 AND tenant_id = $2;
+
+-- name: GetProductStockPosition :one
+SELECT
+    COALESCE((
+        SELECT SUM(i.quantity)
+        FROM inventory i
+        WHERE i.tenant_id = $1 AND i.product_id = $2 AND i.location_id = $3
+    ), 0)::bigint AS on_hand,
+    COALESCE((
+        SELECT SUM(soi.quantity_ordered - COALESCE(soi.quantity_shipped, 0))
+        FROM sales_order_items soi
+        JOIN sales_orders so ON soi.sales_order_id = so.id
+        WHERE soi.tenant_id = $1 AND soi.product_id = $2 AND so.location_id = $3 AND so.status = 'APPROVED'
+    ), 0)::bigint AS reserved;
@@ -0,0 +1,54 @@
+-- name: CreateStockAdjustment :one
+INSERT INTO stock_adjustments (tenant_id, adjustment_number, location_id, notes, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: CreateStockAdjustmentItem :one
+INSERT INTO stock_adjustment_items (tenant_id, stock_adjustment_id, product_id, batch_id, quantity_change, reason_code, notes)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetStockAdjustment :one
+SELECT * FROM stock_adjustments
+WHERE id = $1 AND tenant_id = $2;
+
+-- name: GetStockAdjustmentForUpdate :one
+SELECT * FROM stock_adjustments
+WHERE id = $1 AND tenant_id = $2
+FOR UPDATE;
+
+-- name: GetStockAdjustmentItems :many
+SELECT * FROM stock_adjustment_items
+WHERE stock_adjustment_id = $1 AND tenant_id = $2
+ORDER BY created_at;
+
+-- name: ListStockAdjustments :many
+SELECT * FROM stock_adjustments
+WHERE tenant_id = sqlc.arg('tenant_id')
+    AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+    AND (sqlc.narg('location_id')::uuid IS NULL OR location_id = sqlc.narg('location_id'))
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: GetStockAdjustmentValue :one
+SELECT COALESCE(SUM(ABS(i.quantity_change) * b.cost), 0)::numeric AS total_value
+FROM stock_adjustment_items i
+JOIN batches b ON i.batch_id = b.id
+WHERE i.stock_adjustment_id = $1 AND i.tenant_id = $2;
+
+-- name: ApproveStockAdjustment :one
+UPDATE stock_adjustments
+SET status = 'APPROVED', approved_by = $3, approved_at = NOW(), total_value = $4, updated_at = NOW()
+WHERE id = $1 AND tenant_id = $2 AND status = 'DRAFT'
+RETURNING *;
+
+-- name: TransitionStockAdjustmentStatus :one
+UPDATE stock_adjustments
+SET status = sqlc.arg('new_status'), updated_at = NOW()
+WHERE id = sqlc.arg('id') AND tenant_id = sqlc.arg('tenant_id') AND status = sqlc.arg('current_status')
+RETURNING *;
+
+-- name: SetStockAdjustmentPosted :exec
+UPDATE stock_adjustments
+SET posted_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND tenant_id = $2;
@@ -0,0 +1,84 @@
+-- name: CreateStockCount :one
+INSERT INTO stock_counts (tenant_id, count_number, location_id, blind, notes, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: SnapshotStockCountLines :execrows
+INSERT INTO stock_count_lines (tenant_id, stock_count_id, product_id, batch_id, expected_quantity)
+SELECT i.tenant_id, sqlc.arg('stock_count_id')::uuid, i.product_id, i.batch_id, i.quantity
+FROM inventory i
+WHERE i.tenant_id = sqlc.arg('tenant_id') AND i.location_id = sqlc.arg('location_id')
+    AND (sqlc.narg('product_ids')::uuid[] IS NULL OR i.product_id = ANY(sqlc.narg('product_ids')::uuid[]));
+
+-- name: GetStockCount :one
+SELECT * FROM stock_counts
+WHERE id = $1 AND tenant_id = $2;
+
+-- name: GetStockCountForUpdate :one
+SELECT * FROM stock_counts
+WHERE id = $1 AND tenant_id = $2
+FOR UPDATE;
+
+-- name: ListStockCounts :many
+SELECT * FROM stock_counts
+WHERE tenant_id = sqlc.arg('tenant_id')
+    AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+    AND (sqlc.narg('location_id')::uuid IS NULL OR location_id = sqlc.narg('location_id'))
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: EnsureStockCountLine :one
+INSERT INTO stock_count_lines (tenant_id, stock_count_id, product_id, batch_id)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (stock_count_id, batch_id) DO UPDATE SET product_id = stock_count_lines.product_id
+RETURNING *;
+
+-- name: UpsertStockCountEntry :one
+INSERT INTO stock_count_entries (tenant_id, stock_count_line_id, counted_by, counted_quantity)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (stock_count_line_id, counted_by)
+DO UPDATE SET counted_quantity = EXCLUDED.counted_quantity, updated_at = NOW()
+RETURNING *;
+
+-- name: ListStockCountEntries :many
+SELECT e.id, e.tenant_id, e.stock_count_line_id, e.counted_by, e.counted_quantity, e.created_at, e.updated_at
+FROM stock_count_entries e
+JOIN stock_count_lines l ON e.stock_count_line_id = l.id
+WHERE l.stock_count_id = $1 AND e.tenant_id = $2
+ORDER BY e.created_at;
+
+-- name: ListStockCountLines :many
+SELECT
+    l.id,
+    l.product_id,
+    p.name AS product_name,
+    l.batch_id,
+    b.batch_number,
+    l.expected_quantity,
+    b.cost,
+    COUNT(e.id) AS counters,
+    MIN(e.counted_quantity)::numeric AS min_counted,
+    MAX(e.counted_quantity)::numeric AS max_counted
+FROM stock_count_lines l
+JOIN products p ON l.product_id = p.id
+JOIN batches b ON l.batch_id = b.id
+LEFT JOIN stock_count_entries e ON e.stock_count_line_id = l.id
+WHERE l.stock_count_id = $1 AND l.tenant_id = $2
+GROUP BY l.id, p.name, b.batch_number, b.cost
+ORDER BY p.name, b.batch_number;
+
+-- name: TransitionStockCountStatus :one
+UPDATE stock_counts
+SET status = sqlc.arg('new_status'), updated_at = NOW()
+WHERE id = sqlc.arg('id') AND tenant_id = sqlc.arg('tenant_id') AND status = sqlc.arg('current_status')
+RETURNING *;
+
+-- name: SetStockCountSubmitted :exec
+UPDATE stock_counts
+SET submitted_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND tenant_id = $2;
+
+-- name: SetStockCountApproved :exec
+UPDATE stock_counts
+SET approved_by = $3, approved_at = NOW(), stock_adjustment_id = $4, updated_at = NOW()
+WHERE id = $1 AND tenant_id = $2;
@@ -0,0 +1,26 @@
+-- name: CreateTenantMembership :one
+INSERT INTO tenant_memberships (tenant_id, user_id, role, invited_by)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetTenantMembership :one
+SELECT * FROM tenant_memberships
+WHERE tenant_id = $1 AND user_id = $2;
+
+-- name: ListUserMemberships :many
+SELECT sqlc.embed(m), sqlc.embed(t) FROM tenant_memberships m
+JOIN tenants t ON t.id = m.tenant_id
+WHERE m.user_id = $1
+ORDER BY m.created_at;
+
+-- name: UpdateTenantMembershipRole :one
+UPDATE tenant_memberships
+SET role = $3
+WHERE tenant_id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: SetTenantMembershipActive :one
+UPDATE tenant_memberships
+SET is_active = $3
+WHERE tenant_id = $1 AND user_id = $2
+RETURNING *;
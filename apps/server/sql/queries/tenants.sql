@@ -17,3 +17,47 @@ RETURNING *;
 SELECT * FROM tenants
 ORDER BY created_at DESC
 LIMIT $1 OFFSET $2;
+
+-- name: SetTenantRequireTwoFactor :one
+UPDATE tenants
+SET require_two_factor = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: IsTenantActive :one
+SELECT is_active FROM tenants
+WHERE id = $1;
+
+-- name: SetTenantActive :one
+UPDATE tenants
+SET is_active = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: GetTenantWithUsage :one
+SELECT t.*,
+    (SELECT COUNT(*) FROM tenant_memberships m WHERE m.tenant_id = t.id) AS user_count,
+    (SELECT COUNT(*) FROM products p WHERE p.tenant_id = t.id) AS product_count,
+    (SELECT COUNT(*) FROM purchase_orders po WHERE po.tenant_id = t.id) AS purchase_order_count,
+    (SELECT COUNT(*) FROM sales_orders so WHERE so.tenant_id = t.id) AS sales_order_count
+FROM tenants t
+WHERE t.id = $1;
+
+-- name: ListTenantsWithUsage :many
+SELECT t.*,
+    (SELECT COUNT(*) FROM tenant_memberships m WHERE m.tenant_id = t.id) AS user_count,
+    (SELECT COUNT(*) FROM products p WHERE p.tenant_id = t.id) AS product_count,
+    (SELECT COUNT(*) FROM purchase_orders po WHERE po.tenant_id = t.id) AS purchase_order_count,
+    (SELECT COUNT(*) FROM sales_orders so WHERE so.tenant_id = t.id) AS sales_order_count
+FROM tenants t
+WHERE (sqlc.narg('search')::text IS NULL
+        OR t.name ILIKE '%' || sqlc.narg('search') || '%'
+        OR t.email ILIKE '%' || sqlc.narg('search') || '%')
+    AND (sqlc.narg('is_active')::boolean IS NULL OR t.is_active = sqlc.narg('is_active'))
+ORDER BY t.created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListActiveTenantIDs :many
+SELECT id FROM tenants
+WHERE is_active
+ORDER BY created_at;
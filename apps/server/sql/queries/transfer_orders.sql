@@ -0,0 +1,88 @@
+
+
+-- name: CreateTransferOrder :one
+INSERT INTO transfer_orders (tenant_id, transfer_number, from_location_id, to_location_id, notes, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: CreateTransferOrderItem :one
+INSERT INTO transfer_order_items (tenant_id, transfer_order_id, product_id, batch_id, quantity)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetTransferOrder :one
+SELECT * FROM transfer_orders
+WHERE id = $1 AND tenant_id = $2;
+
+-- name: GetTransferOrderForUpdate :one
+SELECT * FROM transfer_orders
+WHERE id = $1 AND tenant_id = $2
+FOR UPDATE;
+
+-- name: GetTransferOrderItems :many
+SELECT * FROM transfer_order_items
+WHERE transfer_order_id = $1 AND tenant_id = $2
+ORDER BY created_at;
+
+-- name: ListTransferOrders :many
+SELECT * FROM transfer_orders
+WHERE tenant_id = sqlc.arg('tenant_id')
+    AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+    AND (sqlc.narg('location_id')::uuid IS NULL
+        OR from_location_id = sqlc.narg('location_id')
+        OR to_location_id = sqlc.narg('location_id'))
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: TransitionTransferOrderStatus :one
+UPDATE transfer_orders
+SET status = sqlc.arg('new_status'), updated_at = NOW()
+WHERE id = sqlc.arg('id') AND tenant_id = sqlc.arg('tenant_id') AND status = sqlc.arg('current_status')
+RETURNING *;
+
+-- name: SetTransferOrderDispatched :exec
+UPDATE transfer_orders
+SET dispatched_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND tenant_id = $2;
+
+-- name: SetTransferOrderReceived :exec
+UPDATE transfer_orders
+SET received_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND tenant_id = $2;
+
+-- name: UpdateTransferOrderItemReceipt :one
+UPDATE transfer_order_items
+SET quantity_received = $2, quantity_short = $3, updated_at = NOW()
+WHERE id = $1 AND tenant_id = $4
+RETURNING *;
+
+-- name: ListInTransitStock :many
+SELECT
+    t.id AS transfer_order_id,
+    t.transfer_number,
+    t.from_location_id,
+    t.to_location_id,
+    i.product_id,
+    p.name AS product_name,
+    i.batch_id,
+    b.batch_number,
+    i.quantity,
+    t.dispatched_at
+FROM transfer_order_items i
+JOIN transfer_orders t ON i.transfer_order_id = t.id
+JOIN products p ON i.product_id = p.id
+JOIN batches b ON i.batch_id = b.id
+WHERE t.tenant_id = sqlc.arg('tenant_id')
+    AND t.status = 'IN_TRANSIT'
+    AND (sqlc.narg('location_id')::uuid IS NULL
+        OR t.from_location_id = sqlc.narg('location_id')
+        OR t.to_location_id = sqlc.narg('location_id'))
+ORDER BY t.dispatched_at, p.name;
+
+-- name: GetInTransitQuantity :one
+SELECT COALESCE(SUM(i.quantity), 0)::bigint AS in_transit_quantity
+FROM transfer_order_items i
+JOIN transfer_orders t ON i.transfer_order_id = t.id
+WHERE t.tenant_id = sqlc.arg('tenant_id')
+    AND t.status = 'IN_TRANSIT'
+    AND (sqlc.narg('location_id')::uuid IS NULL OR t.to_location_id = sqlc.narg('location_id'));
@@ -0,0 +1,29 @@
+
+
+-- name: CreateUserInvite :one
+INSERT INTO user_invites (tenant_id, email, role, token_hash, invited_by, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetUserInviteByTokenHashForUpdate :one
+SELECT * FROM user_invites
+WHERE token_hash = $1
+FOR UPDATE;
+
+-- name: MarkUserInviteAccepted :exec
+UPDATE user_invites
+SET accepted_at = NOW()
+WHERE id = $1;
+
+-- name: DeletePendingUserInvites :exec
+DELETE FROM user_invites
+WHERE tenant_id = $1 AND lower(email) = lower($2) AND accepted_at IS NULL;
+
+-- name: DeleteUserInvite :execrows
+DELETE FROM user_invites
+WHERE id = $1 AND tenant_id = $2 AND accepted_at IS NULL;
+
+-- name: ListPendingUserInvites :many
+SELECT * FROM user_invites
+WHERE tenant_id = $1 AND accepted_at IS NULL
+ORDER BY created_at DESC;
@@ -1,6 +1,6 @@
 -- name: CreateUser :one
-INSERT INTO users (name, email, password, phone, tenant_id, role)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO users (name, email, password, phone, tenant_id)
+VALUES ($1, $2, $3, $4, $5)
 RETURNING *;
 
 -- name: GetUserByEmail :one
@@ -14,16 +14,44 @@ WHERE id = $1;
 -- name: UpdateUserPassword :exec
 UPDATE users
 SET password = $1
-WHERE id = $2 AND tenant_id = $3;
+WHERE id = $2;
 
--- name: ListUsersByRole :many
+-- name: UpdateUser :one
+UPDATE users
+SET name = $2, email = $3, phone = $4, email_verified = $5
+WHERE id = $1 AND tenant_id = $6
+RETURNING *;
+
+-- name: UserEmailExists :one
+SELECT EXISTS (
+    SELECT 1 FROM users WHERE lower(email) = lower($1)
+);
+
+-- name: ListUsers :many
+SELECT sqlc.embed(u), sqlc.embed(m) FROM users u
+JOIN tenant_memberships m ON m.user_id = u.id
+WHERE m.tenant_id = sqlc.arg('tenant_id')
+    AND (sqlc.narg('role')::text IS NULL OR m.role::text = sqlc.narg('role'))
+ORDER BY u.name
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: FindUserByEmail :one
 SELECT * FROM users
-WHERE tenant_id = $1 AND role = $2
-ORDER BY name
-LIMIT $3 OFFSET $4;
+WHERE lower(email) = lower($1);
 
--- name: UpdateUser :one
+-- name: SetUserEmailVerified :exec
 UPDATE users
-SET name = $2, email = $3, phone = $4, role = $5, email_verified = $6
-WHERE id = $1 AND tenant_id = $7
-RETURNING *;
\ No newline at end of file
+SET email_verified = TRUE
+WHERE id = $1;
+
+-- name: GetTenantAdmin :one
+SELECT sqlc.embed(u), sqlc.embed(m) FROM users u
+JOIN tenant_memberships m ON m.user_id = u.id
+WHERE m.tenant_id = $1 AND m.role = 'admin' AND m.is_active AND u.is_active IS NOT FALSE
+ORDER BY m.created_at
+LIMIT 1;
+
+-- name: GetTenantMember :one
+SELECT sqlc.embed(u), sqlc.embed(m) FROM users u
+JOIN tenant_memberships m ON m.user_id = u.id
+WHERE u.id = $1 AND m.tenant_id = $2;
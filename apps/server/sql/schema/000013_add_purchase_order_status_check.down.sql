@@ -0,0 +1 @@
+ALTER TABLE purchase_orders DROP CONSTRAINT IF EXISTS chk_purchase_orders_status;
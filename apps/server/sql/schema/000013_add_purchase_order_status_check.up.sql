@@ -0,0 +1,3 @@
+ALTER TABLE purchase_orders
+    ADD CONSTRAINT chk_purchase_orders_status
+    CHECK (status IN ('PENDING', 'APPROVED', 'ORDERED', 'RECEIVED', 'CANCELLED'));
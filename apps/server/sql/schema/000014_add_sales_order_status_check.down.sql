@@ -0,0 +1 @@
+ALTER TABLE sales_orders DROP CONSTRAINT IF EXISTS chk_sales_orders_status;
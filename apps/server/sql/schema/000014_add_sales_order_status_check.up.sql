@@ -0,0 +1,3 @@
+ALTER TABLE sales_orders
+    ADD CONSTRAINT chk_sales_orders_status
+    CHECK (status IN ('PENDING', 'APPROVED', 'SHIPPED', 'DELIVERED', 'CANCELLED'));
@@ -0,0 +1 @@
+ALTER TABLE customers DROP COLUMN IF EXISTS min_shelf_life_days;
@@ -0,0 +1,4 @@
+-- Minimum remaining shelf life (in days) a customer accepts on delivered batches
+ALTER TABLE customers
+    ADD COLUMN IF NOT EXISTS min_shelf_life_days INTEGER NOT NULL DEFAULT 0
+    CHECK (min_shelf_life_days >= 0);
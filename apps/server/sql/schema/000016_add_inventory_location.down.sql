@@ -0,0 +1,23 @@
+DROP INDEX IF EXISTS idx_inventory_log_tenant_location;
+ALTER TABLE inventory_log DROP COLUMN IF EXISTS location_id;
+
+-- Collapse per-location stock back into a single row per (tenant, product, batch)
+CREATE TEMP TABLE inventory_totals AS
+SELECT tenant_id, product_id, batch_id, SUM(quantity) AS quantity
+FROM inventory
+GROUP BY tenant_id, product_id, batch_id;
+
+DELETE FROM inventory;
+
+DROP INDEX IF EXISTS idx_inventory_tenant_location;
+ALTER TABLE inventory DROP CONSTRAINT IF EXISTS inventory_tenant_location_product_batch_key;
+ALTER TABLE inventory DROP COLUMN IF EXISTS location_id;
+
+INSERT INTO inventory (tenant_id, product_id, batch_id, quantity)
+SELECT tenant_id, product_id, batch_id, quantity FROM inventory_totals;
+
+DROP TABLE inventory_totals;
+
+ALTER TABLE inventory
+    ADD CONSTRAINT inventory_tenant_id_product_id_batch_id_key
+    UNIQUE (tenant_id, product_id, batch_id);
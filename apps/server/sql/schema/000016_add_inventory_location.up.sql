@@ -0,0 +1,47 @@
+-- Stock is held per location. Tenants with existing stock or orders get a default
+-- warehouse that existing inventory, log rows and location-less orders are assigned to.
+INSERT INTO locations (tenant_id, name, location_type)
+SELECT tenant_id, 'Main Warehouse', 'WAREHOUSE' FROM inventory
+UNION
+SELECT tenant_id, 'Main Warehouse', 'WAREHOUSE' FROM inventory_log
+UNION
+SELECT tenant_id, 'Main Warehouse', 'WAREHOUSE' FROM purchase_orders WHERE location_id IS NULL
+UNION
+SELECT tenant_id, 'Main Warehouse', 'WAREHOUSE' FROM sales_orders WHERE location_id IS NULL
+ON CONFLICT (tenant_id, name) DO NOTHING;
+
+UPDATE purchase_orders po
+SET location_id = l.id
+FROM locations l
+WHERE po.location_id IS NULL AND l.tenant_id = po.tenant_id AND l.name = 'Main Warehouse';
+
+UPDATE sales_orders so
+SET location_id = l.id
+FROM locations l
+WHERE so.location_id IS NULL AND l.tenant_id = so.tenant_id AND l.name = 'Main Warehouse';
+
+ALTER TABLE inventory ADD COLUMN IF NOT EXISTS location_id UUID REFERENCES locations(id);
+
+UPDATE inventory i
+SET location_id = l.id
+FROM locations l
+WHERE i.location_id IS NULL AND l.tenant_id = i.tenant_id AND l.name = 'Main Warehouse';
+
+ALTER TABLE inventory ALTER COLUMN location_id SET NOT NULL;
+ALTER TABLE inventory DROP CONSTRAINT IF EXISTS inventory_tenant_id_product_id_batch_id_key;
+ALTER TABLE inventory
+    ADD CONSTRAINT inventory_tenant_location_product_batch_key
+    UNIQUE (tenant_id, location_id, product_id, batch_id);
+
+CREATE INDEX IF NOT EXISTS idx_inventory_tenant_location ON inventory(tenant_id, location_id);
+
+ALTER TABLE inventory_log ADD COLUMN IF NOT EXISTS location_id UUID REFERENCES locations(id);
+
+UPDATE inventory_log il
+SET location_id = l.id
+FROM locations l
+WHERE il.location_id IS NULL AND l.tenant_id = il.tenant_id AND l.name = 'Main Warehouse';
+
+ALTER TABLE inventory_log ALTER COLUMN location_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_inventory_log_tenant_location ON inventory_log(tenant_id, location_id);
@@ -0,0 +1,42 @@
+CREATE TABLE IF NOT EXISTS transfer_orders(
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    transfer_number TEXT NOT NULL,
+    from_location_id UUID NOT NULL REFERENCES locations(id),
+    to_location_id UUID NOT NULL REFERENCES locations(id),
+    status TEXT NOT NULL DEFAULT 'PENDING',
+    notes TEXT,
+    created_by UUID REFERENCES users(id),
+    dispatched_at TIMESTAMPTZ,
+    received_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(tenant_id, transfer_number),
+    CONSTRAINT chk_transfer_orders_locations CHECK (from_location_id <> to_location_id),
+    CONSTRAINT chk_transfer_orders_status CHECK (status IN ('PENDING', 'IN_TRANSIT', 'RECEIVED', 'CANCELLED'))
+);
+
+-- Transfer Order Items
+CREATE TABLE IF NOT EXISTS transfer_order_items(
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    transfer_order_id UUID NOT NULL REFERENCES transfer_orders(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id),
+    batch_id UUID NOT NULL REFERENCES batches(id),
+    quantity NUMERIC(10,2) NOT NULL CHECK (quantity > 0),
+    quantity_received NUMERIC(10,2) NOT NULL DEFAULT 0,
+    quantity_short NUMERIC(10,2) NOT NULL DEFAULT 0, -- Dispatched but never arrived
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Performance indexes for transfer_orders
+CREATE INDEX IF NOT EXISTS idx_transfer_orders_tenant_id ON transfer_orders (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_transfer_orders_tenant_status ON transfer_orders (tenant_id, status);
+CREATE INDEX IF NOT EXISTS idx_transfer_orders_from_location ON transfer_orders (from_location_id);
+CREATE INDEX IF NOT EXISTS idx_transfer_orders_to_location ON transfer_orders (to_location_id);
+
+-- Performance indexes for transfer_order_items
+CREATE INDEX IF NOT EXISTS idx_transfer_items_tenant_id ON transfer_order_items (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_transfer_items_transfer_order_id ON transfer_order_items (transfer_order_id);
+CREATE INDEX IF NOT EXISTS idx_transfer_items_product_id ON transfer_order_items (product_id);
@@ -0,0 +1 @@
+ALTER TABLE locations DROP CONSTRAINT IF EXISTS chk_locations_location_type;
@@ -0,0 +1,6 @@
+UPDATE locations SET location_type = UPPER(location_type);
+
+ALTER TABLE locations DROP CONSTRAINT IF EXISTS chk_locations_location_type;
+ALTER TABLE locations
+    ADD CONSTRAINT chk_locations_location_type
+    CHECK (location_type IN ('WAREHOUSE', 'STORE', 'OFFICE', 'DISTRIBUTION_CENTER'));
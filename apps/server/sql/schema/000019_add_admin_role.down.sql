@@ -0,0 +1,11 @@
+-- Enum values cannot be dropped, so rebuild the type without 'admin'
+UPDATE users SET role = 'manager' WHERE role = 'admin';
+
+ALTER TYPE user_role RENAME TO user_role_old;
+CREATE TYPE user_role AS ENUM ('user', 'supervisor', 'manager', 'super_admin');
+
+ALTER TABLE users ALTER COLUMN role DROP DEFAULT;
+ALTER TABLE users ALTER COLUMN role TYPE user_role USING role::text::user_role;
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'user';
+
+DROP TYPE user_role_old;
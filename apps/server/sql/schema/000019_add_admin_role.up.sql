@@ -0,0 +1,3 @@
+-- Tenant administrators sit between manager and the platform-wide super_admin.
+-- Registration has always assigned 'admin', which the enum did not accept.
+ALTER TYPE user_role ADD VALUE IF NOT EXISTS 'admin' BEFORE 'super_admin';
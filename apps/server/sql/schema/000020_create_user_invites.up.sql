@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS user_invites(
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    role user_role NOT NULL DEFAULT 'user',
+    token_hash TEXT NOT NULL UNIQUE, -- SHA-256 of the emailed token; the token itself is never stored
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- At most one outstanding invite per email within a tenant
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_invites_pending
+    ON user_invites (tenant_id, lower(email)) WHERE accepted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_user_invites_tenant_id ON user_invites (tenant_id);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS refresh_tokens(
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    family_id UUID NOT NULL, -- one family per login session; every rotation stays in the family
+    token_hash TEXT NOT NULL UNIQUE, -- SHA-256 of the token handed to the client
+    user_agent TEXT,
+    ip_address TEXT,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ, -- set when the token is rotated; presenting it again is reuse
+    revoked_at TIMESTAMPTZ, -- set on every token of the family when the session ends
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens (family_id);
@@ -0,0 +1,23 @@
+-- Failed login counters, one row per account email and per client IP
+CREATE TABLE IF NOT EXISTS login_throttles(
+    key TEXT PRIMARY KEY, -- 'account:<email>' or 'ip:<address>'
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_until TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS security_events(
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID REFERENCES tenants(id) ON DELETE CASCADE, -- NULL when no known account was involved
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    event_type TEXT NOT NULL,
+    email TEXT,
+    ip_address TEXT,
+    user_agent TEXT,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_security_events_tenant_created ON security_events (tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_security_events_user_id ON security_events (user_id);
+CREATE INDEX IF NOT EXISTS idx_security_events_ip_address ON security_events (ip_address);
@@ -0,0 +1,9 @@
+DELETE FROM user_tokens WHERE purpose = 'two_factor';
+ALTER TABLE user_tokens DROP CONSTRAINT IF EXISTS user_tokens_purpose_check;
+ALTER TABLE user_tokens ADD CONSTRAINT user_tokens_purpose_check
+    CHECK (purpose IN ('verify_email', 'reset_password'));
+
+DROP TABLE IF EXISTS user_recovery_codes;
+DROP TABLE IF EXISTS user_totp;
+
+ALTER TABLE tenants DROP COLUMN IF EXISTS require_two_factor;
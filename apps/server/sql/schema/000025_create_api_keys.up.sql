@@ -0,0 +1,16 @@
+-- Tenant-scoped credentials for machine integrations
+CREATE TABLE IF NOT EXISTS api_keys(
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL UNIQUE, -- public part of the key, shown in listings and logs
+    key_hash TEXT NOT NULL UNIQUE, -- SHA-256 of the full key
+    scopes TEXT[] NOT NULL, -- permissions the key is restricted to
+    created_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE, -- requests made with the key are attributed to this user
+    expires_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_tenant_id ON api_keys (tenant_id);
@@ -0,0 +1,23 @@
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY[
+        'tenants', 'users', 'units', 'products', 'batches', 'inventory', 'inventory_log',
+        'suppliers', 'customers', 'locations',
+        'purchase_orders', 'purchase_order_items', 'sales_orders', 'sales_order_items',
+        'transfer_orders', 'transfer_order_items',
+        'user_invites', 'refresh_tokens', 'security_events', 'api_keys'
+    ] LOOP
+        EXECUTE format('DROP POLICY IF EXISTS tenant_isolation ON %I', t);
+        EXECUTE format('ALTER TABLE %I NO FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I DISABLE ROW LEVEL SECURITY', t);
+    END LOOP;
+END
+$$;
+
+DROP FUNCTION IF EXISTS app_rls_bypass();
+DROP FUNCTION IF EXISTS app_current_tenant();
+
+-- The agromart_app role is left in place: roles are cluster-wide and may own grants in
+-- other databases
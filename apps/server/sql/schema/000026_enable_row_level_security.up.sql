@@ -0,0 +1,61 @@
+-- Row-level security: every tenant-owned row is only visible to, and writable by, a
+-- connection whose app.tenant_id matches it, so a query that forgets its tenant_id filter
+-- returns nothing instead of another tenant's data. The application sets app.tenant_id per
+-- connection checkout; app.rls_bypass = 'on' is reserved for flows that run before the
+-- tenant is known, such as login and token redemption.
+
+CREATE OR REPLACE FUNCTION app_current_tenant() RETURNS UUID
+LANGUAGE sql STABLE AS $$
+    SELECT NULLIF(current_setting('app.tenant_id', true), '')::uuid
+$$;
+
+CREATE OR REPLACE FUNCTION app_rls_bypass() RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+    SELECT COALESCE(current_setting('app.rls_bypass', true), '') = 'on'
+$$;
+
+-- Superusers and BYPASSRLS roles ignore policies, so the application switches to this role
+-- with SET ROLE on every connection
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'agromart_app') THEN
+        CREATE ROLE agromart_app NOLOGIN NOBYPASSRLS;
+    END IF;
+END
+$$;
+
+GRANT agromart_app TO CURRENT_USER;
+GRANT USAGE ON SCHEMA public TO agromart_app;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO agromart_app;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO agromart_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO agromart_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE, SELECT ON SEQUENCES TO agromart_app;
+
+ALTER TABLE tenants ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tenants FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON tenants
+    USING (app_rls_bypass() OR id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR id = app_current_tenant());
+
+-- Tables carrying a tenant_id column. Tables keyed only by user (user_tokens, user_totp,
+-- user_recovery_codes) and login_throttles are reached through a user or a token hash.
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY[
+        'users', 'units', 'products', 'batches', 'inventory', 'inventory_log',
+        'suppliers', 'customers', 'locations',
+        'purchase_orders', 'purchase_order_items', 'sales_orders', 'sales_order_items',
+        'transfer_orders', 'transfer_order_items',
+        'user_invites', 'refresh_tokens', 'security_events', 'api_keys'
+    ] LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format(
+            'CREATE POLICY tenant_isolation ON %I
+                USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+                WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant())', t);
+    END LOOP;
+END
+$$;
@@ -0,0 +1,20 @@
+DROP TRIGGER IF EXISTS audit_products ON products;
+DROP TRIGGER IF EXISTS audit_units ON units;
+DROP TRIGGER IF EXISTS audit_batches ON batches;
+DROP TRIGGER IF EXISTS audit_inventory ON inventory;
+DROP TRIGGER IF EXISTS audit_suppliers ON suppliers;
+DROP TRIGGER IF EXISTS audit_customers ON customers;
+DROP TRIGGER IF EXISTS audit_locations ON locations;
+DROP TRIGGER IF EXISTS audit_purchase_orders ON purchase_orders;
+DROP TRIGGER IF EXISTS audit_purchase_order_items ON purchase_order_items;
+DROP TRIGGER IF EXISTS audit_sales_orders ON sales_orders;
+DROP TRIGGER IF EXISTS audit_sales_order_items ON sales_order_items;
+DROP TRIGGER IF EXISTS audit_transfer_orders ON transfer_orders;
+DROP TRIGGER IF EXISTS audit_transfer_order_items ON transfer_order_items;
+DROP TRIGGER IF EXISTS audit_users ON users;
+
+DROP TABLE IF EXISTS audit_log;
+
+DROP FUNCTION IF EXISTS audit_row_change();
+DROP FUNCTION IF EXISTS audit_redact(JSONB);
+DROP FUNCTION IF EXISTS audit_log_immutable();
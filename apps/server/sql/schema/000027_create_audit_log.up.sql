@@ -0,0 +1,131 @@
+-- Append-only record of every create, update and delete on business tables. Rows are written
+-- by triggers, so a change cannot skip the trail, and the request that made it is identified
+-- by the app.* settings the application puts on each connection it checks out.
+CREATE TABLE IF NOT EXISTS audit_log(
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL, -- no foreign keys: the trail outlives what it describes
+    actor_user_id UUID, -- NULL for changes made before login, such as signup
+    api_key_id UUID,
+    entity_type TEXT NOT NULL,
+    entity_id UUID NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+    before JSONB, -- whole row on delete, changed columns on update
+    after JSONB, -- whole row on create, changed columns on update
+    request_id TEXT,
+    ip_address TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_tenant_created ON audit_log (tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log (entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log (actor_user_id);
+
+CREATE OR REPLACE FUNCTION audit_log_immutable() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END
+$$;
+
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_immutable();
+CREATE TRIGGER audit_log_no_truncate BEFORE TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_immutable();
+
+-- Secrets never reach the trail; a changed password shows up as a redacted change
+CREATE OR REPLACE FUNCTION audit_redact(row_data JSONB) RETURNS JSONB
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT CASE WHEN row_data ? 'password' THEN row_data || '{"password": "[redacted]"}' ELSE row_data END
+$$;
+
+-- audit_row_change(entity_type, ignored_column...) records one row change. Updates that only
+-- touch updated_at or an ignored column are not recorded. It runs as its owner so the
+-- application role can read the trail but never write to it directly.
+CREATE OR REPLACE FUNCTION audit_row_change() RETURNS TRIGGER
+LANGUAGE plpgsql SECURITY DEFINER SET search_path = public AS $$
+DECLARE
+    old_row JSONB;
+    new_row JSONB;
+    before_data JSONB;
+    after_data JSONB;
+    ignored TEXT[] := ARRAY['updated_at'] || TG_ARGV[1:];
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        old_row := to_jsonb(OLD);
+    END IF;
+    IF TG_OP <> 'DELETE' THEN
+        new_row := to_jsonb(NEW);
+    END IF;
+
+    IF TG_OP = 'UPDATE' THEN
+        SELECT jsonb_object_agg(key, o.value), jsonb_object_agg(key, n.value)
+        INTO before_data, after_data
+        FROM jsonb_each(old_row) o
+        JOIN jsonb_each(new_row) n USING (key)
+        WHERE o.value IS DISTINCT FROM n.value AND key <> ALL (ignored);
+
+        IF before_data IS NULL THEN
+            RETURN NULL;
+        END IF;
+    ELSE
+        before_data := old_row;
+        after_data := new_row;
+    END IF;
+
+    INSERT INTO audit_log (
+        tenant_id, actor_user_id, api_key_id, entity_type, entity_id, action,
+        before, after, request_id, ip_address
+    ) VALUES (
+        (COALESCE(new_row, old_row) ->> 'tenant_id')::uuid,
+        NULLIF(current_setting('app.user_id', true), '')::uuid,
+        NULLIF(current_setting('app.api_key_id', true), '')::uuid,
+        TG_ARGV[0],
+        (COALESCE(new_row, old_row) ->> 'id')::uuid,
+        CASE TG_OP WHEN 'INSERT' THEN 'create' WHEN 'UPDATE' THEN 'update' ELSE 'delete' END,
+        audit_redact(before_data),
+        audit_redact(after_data),
+        NULLIF(current_setting('app.request_id', true), ''),
+        NULLIF(current_setting('app.ip_address', true), '')
+    );
+    RETURN NULL;
+END
+$$;
+
+CREATE TRIGGER audit_products AFTER INSERT OR UPDATE OR DELETE ON products
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('product');
+CREATE TRIGGER audit_units AFTER INSERT OR UPDATE OR DELETE ON units
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('unit');
+CREATE TRIGGER audit_batches AFTER INSERT OR UPDATE OR DELETE ON batches
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('batch');
+CREATE TRIGGER audit_inventory AFTER INSERT OR UPDATE OR DELETE ON inventory
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('inventory');
+CREATE TRIGGER audit_suppliers AFTER INSERT OR UPDATE OR DELETE ON suppliers
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('supplier');
+CREATE TRIGGER audit_customers AFTER INSERT OR UPDATE OR DELETE ON customers
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('customer');
+CREATE TRIGGER audit_locations AFTER INSERT OR UPDATE OR DELETE ON locations
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('location');
+CREATE TRIGGER audit_purchase_orders AFTER INSERT OR UPDATE OR DELETE ON purchase_orders
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('purchase_order');
+CREATE TRIGGER audit_purchase_order_items AFTER INSERT OR UPDATE OR DELETE ON purchase_order_items
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('purchase_order_item');
+CREATE TRIGGER audit_sales_orders AFTER INSERT OR UPDATE OR DELETE ON sales_orders
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('sales_order');
+CREATE TRIGGER audit_sales_order_items AFTER INSERT OR UPDATE OR DELETE ON sales_order_items
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('sales_order_item');
+CREATE TRIGGER audit_transfer_orders AFTER INSERT OR UPDATE OR DELETE ON transfer_orders
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('transfer_order');
+CREATE TRIGGER audit_transfer_order_items AFTER INSERT OR UPDATE OR DELETE ON transfer_order_items
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('transfer_order_item');
+CREATE TRIGGER audit_users AFTER INSERT OR UPDATE OR DELETE ON users
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('user');
+
+-- The application reads its tenant's trail and nothing more
+ALTER TABLE audit_log ENABLE ROW LEVEL SECURITY;
+ALTER TABLE audit_log FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON audit_log
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
+
+REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON audit_log FROM agromart_app;
+GRANT SELECT ON audit_log TO agromart_app;
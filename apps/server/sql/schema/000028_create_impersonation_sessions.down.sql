@@ -0,0 +1,53 @@
+CREATE OR REPLACE FUNCTION audit_row_change() RETURNS TRIGGER
+LANGUAGE plpgsql SECURITY DEFINER SET search_path = public AS $$
+DECLARE
+    old_row JSONB;
+    new_row JSONB;
+    before_data JSONB;
+    after_data JSONB;
+    ignored TEXT[] := ARRAY['updated_at'] || TG_ARGV[1:];
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        old_row := to_jsonb(OLD);
+    END IF;
+    IF TG_OP <> 'DELETE' THEN
+        new_row := to_jsonb(NEW);
+    END IF;
+
+    IF TG_OP = 'UPDATE' THEN
+        SELECT jsonb_object_agg(key, o.value), jsonb_object_agg(key, n.value)
+        INTO before_data, after_data
+        FROM jsonb_each(old_row) o
+        JOIN jsonb_each(new_row) n USING (key)
+        WHERE o.value IS DISTINCT FROM n.value AND key <> ALL (ignored);
+
+        IF before_data IS NULL THEN
+            RETURN NULL;
+        END IF;
+    ELSE
+        before_data := old_row;
+        after_data := new_row;
+    END IF;
+
+    INSERT INTO audit_log (
+        tenant_id, actor_user_id, api_key_id, entity_type, entity_id, action,
+        before, after, request_id, ip_address
+    ) VALUES (
+        (COALESCE(new_row, old_row) ->> 'tenant_id')::uuid,
+        NULLIF(current_setting('app.user_id', true), '')::uuid,
+        NULLIF(current_setting('app.api_key_id', true), '')::uuid,
+        TG_ARGV[0],
+        (COALESCE(new_row, old_row) ->> 'id')::uuid,
+        CASE TG_OP WHEN 'INSERT' THEN 'create' WHEN 'UPDATE' THEN 'update' ELSE 'delete' END,
+        audit_redact(before_data),
+        audit_redact(after_data),
+        NULLIF(current_setting('app.request_id', true), ''),
+        NULLIF(current_setting('app.ip_address', true), '')
+    );
+    RETURN NULL;
+END
+$$;
+
+ALTER TABLE audit_log DROP COLUMN IF EXISTS impersonator_id;
+
+DROP TABLE IF EXISTS impersonation_sessions;
@@ -0,0 +1,79 @@
+-- A platform super admin acting as a tenant's admin for support. The access token handed
+-- out is bound to the session and stops working when it expires or is ended early.
+CREATE TABLE IF NOT EXISTS impersonation_sessions(
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    impersonator_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE, -- the admin being impersonated
+    reason TEXT NOT NULL,
+    ip_address TEXT,
+    expires_at TIMESTAMPTZ NOT NULL,
+    ended_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_impersonation_sessions_tenant_id ON impersonation_sessions (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_impersonation_sessions_impersonator_id ON impersonation_sessions (impersonator_id);
+
+-- Only the platform console reads and writes sessions, through app.rls_bypass; a tenant
+-- connection sees no more than the sessions opened against it
+ALTER TABLE impersonation_sessions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE impersonation_sessions FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON impersonation_sessions
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
+
+-- Changes made while impersonating are attributed to the impersonated admin and the
+-- super admin behind them
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS impersonator_id UUID;
+
+CREATE OR REPLACE FUNCTION audit_row_change() RETURNS TRIGGER
+LANGUAGE plpgsql SECURITY DEFINER SET search_path = public AS $$
+DECLARE
+    old_row JSONB;
+    new_row JSONB;
+    before_data JSONB;
+    after_data JSONB;
+    ignored TEXT[] := ARRAY['updated_at'] || TG_ARGV[1:];
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        old_row := to_jsonb(OLD);
+    END IF;
+    IF TG_OP <> 'DELETE' THEN
+        new_row := to_jsonb(NEW);
+    END IF;
+
+    IF TG_OP = 'UPDATE' THEN
+        SELECT jsonb_object_agg(key, o.value), jsonb_object_agg(key, n.value)
+        INTO before_data, after_data
+        FROM jsonb_each(old_row) o
+        JOIN jsonb_each(new_row) n USING (key)
+        WHERE o.value IS DISTINCT FROM n.value AND key <> ALL (ignored);
+
+        IF before_data IS NULL THEN
+            RETURN NULL;
+        END IF;
+    ELSE
+        before_data := old_row;
+        after_data := new_row;
+    END IF;
+
+    INSERT INTO audit_log (
+        tenant_id, actor_user_id, api_key_id, impersonator_id, entity_type, entity_id, action,
+        before, after, request_id, ip_address
+    ) VALUES (
+        (COALESCE(new_row, old_row) ->> 'tenant_id')::uuid,
+        NULLIF(current_setting('app.user_id', true), '')::uuid,
+        NULLIF(current_setting('app.api_key_id', true), '')::uuid,
+        NULLIF(current_setting('app.impersonator_id', true), '')::uuid,
+        TG_ARGV[0],
+        (COALESCE(new_row, old_row) ->> 'id')::uuid,
+        CASE TG_OP WHEN 'INSERT' THEN 'create' WHEN 'UPDATE' THEN 'update' ELSE 'delete' END,
+        audit_redact(before_data),
+        audit_redact(after_data),
+        NULLIF(current_setting('app.request_id', true), ''),
+        NULLIF(current_setting('app.ip_address', true), '')
+    );
+    RETURN NULL;
+END
+$$;
@@ -0,0 +1,12 @@
+DROP TRIGGER IF EXISTS audit_tenant_memberships ON tenant_memberships;
+DROP POLICY IF EXISTS tenant_members ON users;
+
+-- Each user keeps the role and status they had in the tenant the account was created in;
+-- memberships of other tenants are lost
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role user_role NOT NULL DEFAULT 'user';
+UPDATE users u
+SET role = m.role, is_active = u.is_active AND m.is_active
+FROM tenant_memberships m
+WHERE m.user_id = u.id AND m.tenant_id = u.tenant_id;
+
+DROP TABLE IF EXISTS tenant_memberships;
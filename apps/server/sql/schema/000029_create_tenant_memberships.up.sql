@@ -0,0 +1,42 @@
+-- A user can belong to several tenants, with a role in each. users.tenant_id stays as the
+-- tenant the account was created in; what a user may do in a tenant, and whether they may
+-- use it at all, now lives on their membership.
+CREATE TABLE IF NOT EXISTS tenant_memberships(
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role user_role NOT NULL DEFAULT 'user',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tenant_memberships_user_id ON tenant_memberships (user_id);
+
+INSERT INTO tenant_memberships (tenant_id, user_id, role, is_active, created_at)
+SELECT tenant_id, id, role, COALESCE(is_active, TRUE), created_at
+FROM users
+ON CONFLICT (tenant_id, user_id) DO NOTHING;
+
+-- Deactivating a user in a tenant now deactivates their membership; users.is_active is left
+-- to disable the account everywhere
+UPDATE users SET is_active = TRUE WHERE is_active IS FALSE;
+ALTER TABLE users DROP COLUMN role;
+
+ALTER TABLE tenant_memberships ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tenant_memberships FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON tenant_memberships
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
+
+-- A tenant sees the accounts of its members, not only the ones created in it. It can only
+-- change the accounts created in it.
+CREATE POLICY tenant_members ON users FOR SELECT
+    USING (EXISTS (
+        SELECT 1 FROM tenant_memberships m
+        WHERE m.user_id = users.id AND m.tenant_id = app_current_tenant()
+    ));
+
+CREATE TRIGGER audit_tenant_memberships AFTER INSERT OR UPDATE OR DELETE ON tenant_memberships
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('membership');
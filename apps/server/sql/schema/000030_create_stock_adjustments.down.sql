@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS stock_adjustment_items;
+DROP TABLE IF EXISTS stock_adjustments;
@@ -0,0 +1,59 @@
+-- Stock adjustments write stock up or down for reasons other than buying, selling or
+-- moving it. A draft is approved (by a manager when its value is above the approval
+-- threshold) and then posted, which changes inventory and logs ADJUSTMENT rows.
+CREATE TABLE IF NOT EXISTS stock_adjustments(
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    adjustment_number TEXT NOT NULL,
+    location_id UUID NOT NULL REFERENCES locations(id),
+    status TEXT NOT NULL DEFAULT 'DRAFT',
+    notes TEXT,
+    total_value NUMERIC(12,2) NOT NULL DEFAULT 0, -- Sum of |quantity change| x batch cost
+    created_by UUID REFERENCES users(id),
+    approved_by UUID REFERENCES users(id),
+    approved_at TIMESTAMPTZ,
+    posted_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(tenant_id, adjustment_number),
+    CONSTRAINT chk_stock_adjustments_status CHECK (status IN ('DRAFT', 'APPROVED', 'POSTED', 'CANCELLED'))
+);
+
+CREATE TABLE IF NOT EXISTS stock_adjustment_items(
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    stock_adjustment_id UUID NOT NULL REFERENCES stock_adjustments(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id),
+    batch_id UUID NOT NULL REFERENCES batches(id),
+    quantity_change NUMERIC(10,2) NOT NULL CHECK (quantity_change <> 0),
+    reason_code TEXT NOT NULL,
+    notes TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_stock_adjustment_items_reason CHECK (
+        reason_code IN ('DAMAGE', 'THEFT', 'EXPIRED', 'COUNT_CORRECTION', 'SAMPLE', 'OTHER'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_adjustments_tenant_status ON stock_adjustments (tenant_id, status);
+CREATE INDEX IF NOT EXISTS idx_stock_adjustments_location ON stock_adjustments (location_id);
+CREATE INDEX IF NOT EXISTS idx_stock_adjustment_items_adjustment ON stock_adjustment_items (stock_adjustment_id);
+CREATE INDEX IF NOT EXISTS idx_stock_adjustment_items_product ON stock_adjustment_items (product_id);
+
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['stock_adjustments', 'stock_adjustment_items'] LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format(
+            'CREATE POLICY tenant_isolation ON %I
+                USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+                WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant())', t);
+    END LOOP;
+END
+$$;
+
+CREATE TRIGGER audit_stock_adjustments AFTER INSERT OR UPDATE OR DELETE ON stock_adjustments
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('stock_adjustment');
+CREATE TRIGGER audit_stock_adjustment_items AFTER INSERT OR UPDATE OR DELETE ON stock_adjustment_items
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('stock_adjustment_item');
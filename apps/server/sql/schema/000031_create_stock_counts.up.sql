@@ -0,0 +1,69 @@
+-- Stock counts record a physical count of a location. Opening one snapshots the expected
+-- quantity of every batch in scope; counters then record what they find, each counter
+-- keeping their own entry per line. Approving the count posts the variances as a
+-- COUNT_CORRECTION stock adjustment.
+CREATE TABLE IF NOT EXISTS stock_counts(
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    count_number TEXT NOT NULL,
+    location_id UUID NOT NULL REFERENCES locations(id),
+    status TEXT NOT NULL DEFAULT 'OPEN',
+    blind BOOLEAN NOT NULL DEFAULT FALSE, -- Expected quantities are hidden while counting
+    notes TEXT,
+    created_by UUID REFERENCES users(id),
+    submitted_at TIMESTAMPTZ,
+    approved_by UUID REFERENCES users(id),
+    approved_at TIMESTAMPTZ,
+    stock_adjustment_id UUID REFERENCES stock_adjustments(id), -- Posted variances, if any
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(tenant_id, count_number),
+    CONSTRAINT chk_stock_counts_status CHECK (status IN ('OPEN', 'SUBMITTED', 'APPROVED', 'CANCELLED'))
+);
+
+CREATE TABLE IF NOT EXISTS stock_count_lines(
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    stock_count_id UUID NOT NULL REFERENCES stock_counts(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id),
+    batch_id UUID NOT NULL REFERENCES batches(id),
+    expected_quantity NUMERIC(10,2) NOT NULL DEFAULT 0, -- Snapshot when the count opened
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(stock_count_id, batch_id)
+);
+
+CREATE TABLE IF NOT EXISTS stock_count_entries(
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    stock_count_line_id UUID NOT NULL REFERENCES stock_count_lines(id) ON DELETE CASCADE,
+    counted_by UUID NOT NULL REFERENCES users(id),
+    counted_quantity NUMERIC(10,2) NOT NULL CHECK (counted_quantity >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(stock_count_line_id, counted_by)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_counts_tenant_status ON stock_counts (tenant_id, status);
+CREATE INDEX IF NOT EXISTS idx_stock_counts_location ON stock_counts (location_id);
+CREATE INDEX IF NOT EXISTS idx_stock_count_lines_count ON stock_count_lines (stock_count_id);
+CREATE INDEX IF NOT EXISTS idx_stock_count_entries_line ON stock_count_entries (stock_count_line_id);
+
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['stock_counts', 'stock_count_lines', 'stock_count_entries'] LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format(
+            'CREATE POLICY tenant_isolation ON %I
+                USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+                WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant())', t);
+    END LOOP;
+END
+$$;
+
+CREATE TRIGGER audit_stock_counts AFTER INSERT OR UPDATE OR DELETE ON stock_counts
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('stock_count');
+CREATE TRIGGER audit_stock_count_entries AFTER INSERT OR UPDATE OR DELETE ON stock_count_entries
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('stock_count_entry');
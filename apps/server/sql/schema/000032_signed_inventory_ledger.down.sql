@@ -0,0 +1,14 @@
+DROP TRIGGER IF EXISTS inventory_log_no_update ON inventory_log;
+DROP FUNCTION IF EXISTS inventory_log_immutable();
+DROP TRIGGER IF EXISTS inventory_log_balance ON inventory_log;
+DROP FUNCTION IF EXISTS inventory_log_balance();
+
+DROP INDEX IF EXISTS idx_inventory_log_tenant_date;
+DROP INDEX IF EXISTS idx_inventory_log_balance;
+ALTER TABLE inventory_log DROP CONSTRAINT IF EXISTS chk_inventory_log_sign;
+ALTER TABLE inventory_log DROP CONSTRAINT IF EXISTS chk_inventory_log_type;
+ALTER TABLE inventory_log DROP COLUMN IF EXISTS balance_after;
+ALTER TABLE inventory_log DROP COLUMN IF EXISTS entry_no;
+
+UPDATE inventory_log SET quantity_change = -quantity_change
+WHERE transaction_type IN ('SALE', 'ISSUE', 'TRANSFER_OUT', 'REDUCE') AND quantity_change < 0;
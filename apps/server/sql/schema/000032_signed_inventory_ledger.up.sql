@@ -0,0 +1,83 @@
+-- inventory_log becomes a signed ledger: stock taken out is logged negative, transaction
+-- types are enumerated, and every entry carries the running balance of its batch at its
+-- location, so stock on any past date can be read straight from the log.
+UPDATE inventory_log SET transaction_type = UPPER(transaction_type);
+UPDATE inventory_log SET quantity_change = -quantity_change
+WHERE transaction_type IN ('SALE', 'ISSUE', 'TRANSFER_OUT', 'REDUCE') AND quantity_change > 0;
+
+-- entry_no orders entries; the balance trigger assigns it once it holds the batch's lock,
+-- so a later entry_no always means a later balance. Existing entries are numbered in the
+-- order they happened.
+ALTER TABLE inventory_log ADD COLUMN entry_no BIGINT;
+UPDATE inventory_log l
+SET entry_no = r.entry_no
+FROM (
+    SELECT id, ROW_NUMBER() OVER (ORDER BY transaction_date, id) AS entry_no
+    FROM inventory_log
+) r
+WHERE l.id = r.id;
+ALTER TABLE inventory_log ALTER COLUMN entry_no SET NOT NULL;
+ALTER TABLE inventory_log ADD CONSTRAINT uq_inventory_log_entry_no UNIQUE (entry_no);
+
+CREATE SEQUENCE inventory_log_entry_no_seq OWNED BY inventory_log.entry_no;
+SELECT setval('inventory_log_entry_no_seq', COALESCE(MAX(entry_no), 0) + 1, false) FROM inventory_log;
+
+ALTER TABLE inventory_log ADD COLUMN balance_after NUMERIC(12,2);
+UPDATE inventory_log l
+SET balance_after = r.balance
+FROM (
+    SELECT id, SUM(quantity_change) OVER (
+        PARTITION BY tenant_id, location_id, batch_id ORDER BY entry_no) AS balance
+    FROM inventory_log
+) r
+WHERE l.id = r.id;
+ALTER TABLE inventory_log ALTER COLUMN balance_after SET NOT NULL;
+
+ALTER TABLE inventory_log ADD CONSTRAINT chk_inventory_log_type CHECK (transaction_type IN (
+    'PURCHASE', 'SALE', 'ISSUE', 'TRANSFER_IN', 'TRANSFER_OUT', 'ADD', 'REDUCE', 'ADJUSTMENT'));
+ALTER TABLE inventory_log ADD CONSTRAINT chk_inventory_log_sign CHECK (CASE
+    WHEN transaction_type IN ('PURCHASE', 'TRANSFER_IN', 'ADD') THEN quantity_change > 0
+    WHEN transaction_type IN ('SALE', 'ISSUE', 'TRANSFER_OUT', 'REDUCE') THEN quantity_change < 0
+    ELSE quantity_change <> 0
+END);
+
+CREATE INDEX IF NOT EXISTS idx_inventory_log_balance
+    ON inventory_log (tenant_id, location_id, batch_id, entry_no DESC);
+CREATE INDEX IF NOT EXISTS idx_inventory_log_tenant_date ON inventory_log (tenant_id, transaction_date);
+
+CREATE OR REPLACE FUNCTION inventory_log_balance() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    -- Entries for one batch at one location are serialised so each sees the one before
+    PERFORM pg_advisory_xact_lock(hashtextextended(
+        NEW.tenant_id::text || NEW.location_id::text || NEW.batch_id::text, 0));
+
+    SELECT balance_after INTO NEW.balance_after
+    FROM inventory_log
+    WHERE tenant_id = NEW.tenant_id AND location_id = NEW.location_id AND batch_id = NEW.batch_id
+    ORDER BY entry_no DESC
+    LIMIT 1;
+
+    NEW.balance_after := COALESCE(NEW.balance_after, 0) + NEW.quantity_change;
+    NEW.entry_no := nextval('inventory_log_entry_no_seq');
+    RETURN NEW;
+END
+$$;
+
+CREATE TRIGGER inventory_log_balance BEFORE INSERT ON inventory_log
+    FOR EACH ROW EXECUTE FUNCTION inventory_log_balance();
+
+-- Ledger entries are corrected by posting new ones, never by editing. Deletes are only
+-- allowed when cascading from a deleted tenant.
+CREATE OR REPLACE FUNCTION inventory_log_immutable() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF TG_OP = 'DELETE' AND pg_trigger_depth() > 1 THEN
+        RETURN OLD;
+    END IF;
+    RAISE EXCEPTION 'inventory_log is append-only';
+END
+$$;
+
+CREATE TRIGGER inventory_log_no_update BEFORE UPDATE OR DELETE ON inventory_log
+    FOR EACH ROW EXECUTE FUNCTION inventory_log_immutable();
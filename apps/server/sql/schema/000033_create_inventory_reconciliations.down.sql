@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS inventory_reconciliation_lines;
+DROP TABLE IF EXISTS inventory_reconciliations;
+
+ALTER TABLE inventory_log DROP CONSTRAINT chk_inventory_log_type;
+ALTER TABLE inventory_log ADD CONSTRAINT chk_inventory_log_type CHECK (transaction_type IN (
+    'PURCHASE', 'SALE', 'ISSUE', 'TRANSFER_IN', 'TRANSFER_OUT', 'ADD', 'REDUCE', 'ADJUSTMENT'));
@@ -0,0 +1,61 @@
+-- A reconciliation compares the stock held in inventory with the balances the ledger says
+-- there should be, batch by batch and location by location, and keeps the discrepancies it
+-- found. Posting it writes a RECONCILIATION entry per discrepancy so the ledger agrees with
+-- inventory again.
+ALTER TABLE inventory_log DROP CONSTRAINT chk_inventory_log_type;
+ALTER TABLE inventory_log ADD CONSTRAINT chk_inventory_log_type CHECK (transaction_type IN (
+    'PURCHASE', 'SALE', 'ISSUE', 'TRANSFER_IN', 'TRANSFER_OUT', 'ADD', 'REDUCE', 'ADJUSTMENT',
+    'RECONCILIATION'));
+
+CREATE TABLE IF NOT EXISTS inventory_reconciliations(
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'OPEN',
+    source TEXT NOT NULL DEFAULT 'MANUAL', -- MANUAL or SCHEDULED
+    location_id UUID REFERENCES locations(id), -- NULL reconciles every location
+    discrepancy_count INTEGER NOT NULL DEFAULT 0,
+    total_value NUMERIC(12,2) NOT NULL DEFAULT 0, -- Sum of |difference| x batch cost
+    created_by UUID REFERENCES users(id),
+    resolved_by UUID REFERENCES users(id),
+    resolved_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_inventory_reconciliations_status CHECK (status IN ('OPEN', 'POSTED', 'DISMISSED')),
+    CONSTRAINT chk_inventory_reconciliations_source CHECK (source IN ('MANUAL', 'SCHEDULED'))
+);
+
+CREATE TABLE IF NOT EXISTS inventory_reconciliation_lines(
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    reconciliation_id UUID NOT NULL REFERENCES inventory_reconciliations(id) ON DELETE CASCADE,
+    location_id UUID NOT NULL REFERENCES locations(id),
+    product_id UUID NOT NULL REFERENCES products(id),
+    batch_id UUID NOT NULL REFERENCES batches(id),
+    inventory_quantity NUMERIC(12,2) NOT NULL,
+    ledger_quantity NUMERIC(12,2) NOT NULL,
+    difference NUMERIC(12,2) NOT NULL, -- inventory_quantity - ledger_quantity
+    unit_cost NUMERIC(12,2) NOT NULL DEFAULT 0,
+    value NUMERIC(12,2) NOT NULL DEFAULT 0,
+    UNIQUE(reconciliation_id, location_id, batch_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_inventory_reconciliations_tenant_status ON inventory_reconciliations (tenant_id, status);
+CREATE INDEX IF NOT EXISTS idx_inventory_reconciliation_lines_reconciliation ON inventory_reconciliation_lines (reconciliation_id);
+
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['inventory_reconciliations', 'inventory_reconciliation_lines'] LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format(
+            'CREATE POLICY tenant_isolation ON %I
+                USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+                WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant())', t);
+    END LOOP;
+END
+$$;
+
+CREATE TRIGGER audit_inventory_reconciliations AFTER INSERT OR UPDATE OR DELETE ON inventory_reconciliations
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('inventory_reconciliation');
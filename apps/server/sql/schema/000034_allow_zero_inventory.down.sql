@@ -0,0 +1,3 @@
+DELETE FROM inventory WHERE quantity = 0;
+ALTER TABLE inventory DROP CONSTRAINT IF EXISTS chk_inventory_quantity;
+ALTER TABLE inventory ADD CONSTRAINT inventory_quantity_check CHECK (quantity > 0);
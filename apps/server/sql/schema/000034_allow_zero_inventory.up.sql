@@ -0,0 +1,4 @@
+-- A batch sold or issued down to nothing keeps its inventory row at zero rather than
+-- failing the decrement; stock still never goes negative.
+ALTER TABLE inventory DROP CONSTRAINT IF EXISTS inventory_quantity_check;
+ALTER TABLE inventory ADD CONSTRAINT chk_inventory_quantity CHECK (quantity >= 0);
@@ -0,0 +1,357 @@
+package stockcounts
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"agromart2/internal/auth"
+	"agromart2/internal/database"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	service *StockCountService
+}
+
+func NewHandler(service *StockCountService) *Handler {
+	return &Handler{service: service}
+}
+
+// CreateCount opens a stock count at a location
+func (h *Handler) CreateCount(c echo.Context) error {
+	var req StockCountRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if req.LocationID == uuid.Nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "location_id is required")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	userID, err := uuid.Parse(c.Get("user_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid user ID")
+	}
+
+	count, err := h.service.CreateCount(c.Request().Context(), CreateCountParams{
+		TenantID:    tenantID,
+		CountNumber: req.CountNumber,
+		LocationID:  req.LocationID,
+		ProductIDs:  req.ProductIDs,
+		Blind:       req.Blind,
+		Notes:       req.Notes,
+		CreatedBy:   userID,
+	})
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    count,
+		"message": "Stock count created successfully",
+	})
+}
+
+// GetCount retrieves a stock count with its lines
+func (h *Handler) GetCount(c echo.Context) error {
+	countID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid stock count ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	userID, err := uuid.Parse(c.Get("user_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid user ID")
+	}
+
+	count, err := h.service.GetCount(c.Request().Context(), countID, tenantID, userID)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    count,
+	})
+}
+
+// ListCounts lists stock counts with optional status/location filters
+func (h *Handler) ListCounts(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	var locationID *uuid.UUID
+	if locationIDStr := c.QueryParam("location_id"); locationIDStr != "" {
+		id, err := uuid.Parse(locationIDStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid location ID")
+		}
+		locationID = &id
+	}
+
+	status := strings.ToUpper(c.QueryParam("status"))
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offset := int32((page - 1) * limit)
+
+	counts, err := h.service.ListCounts(c.Request().Context(), tenantID, status, locationID, int32(limit), offset)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    counts,
+		"pagination": map[string]interface{}{
+			"page":  page,
+			"limit": limit,
+		},
+	})
+}
+
+// RecordCounts records the caller's counted quantities
+func (h *Handler) RecordCounts(c echo.Context) error {
+	countID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid stock count ID")
+	}
+
+	var req RecordCountsRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	userID, err := uuid.Parse(c.Get("user_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid user ID")
+	}
+
+	lines := make([]CountLineParams, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		lines = append(lines, CountLineParams{
+			ProductID:       line.ProductID,
+			BatchID:         line.BatchID,
+			CountedQuantity: line.CountedQuantity,
+		})
+	}
+
+	entries, err := h.service.RecordCounts(c.Request().Context(), countID, tenantID, userID, lines)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    entries,
+		"message": "Counts recorded successfully",
+	})
+}
+
+// SubmitCount closes counting on a stock count
+func (h *Handler) SubmitCount(c echo.Context) error {
+	countID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid stock count ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	count, err := h.service.SubmitCount(c.Request().Context(), countID, tenantID)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    count,
+		"message": "Stock count submitted successfully",
+	})
+}
+
+// ReopenCount returns a submitted stock count to counting
+func (h *Handler) ReopenCount(c echo.Context) error {
+	countID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid stock count ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	count, err := h.service.ReopenCount(c.Request().Context(), countID, tenantID)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    count,
+		"message": "Stock count reopened successfully",
+	})
+}
+
+// GetVarianceReport reports counted against expected quantities, valued at batch cost
+func (h *Handler) GetVarianceReport(c echo.Context) error {
+	countID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid stock count ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	report, err := h.service.GetVarianceReport(c.Request().Context(), countID, tenantID)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// ApproveCount approves a submitted stock count and posts its variances
+func (h *Handler) ApproveCount(c echo.Context) error {
+	countID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid stock count ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	userID, err := uuid.Parse(c.Get("user_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid user ID")
+	}
+
+	report, err := h.service.ApproveCount(c.Request().Context(), countID, tenantID, userID,
+		auth.Granted(c, auth.PermInventoryApprove))
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    report,
+		"message": "Stock count approved successfully",
+	})
+}
+
+// CancelCount cancels a stock count that has not been approved
+func (h *Handler) CancelCount(c echo.Context) error {
+	countID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid stock count ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	count, err := h.service.CancelCount(c.Request().Context(), countID, tenantID)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    count,
+		"message": "Stock count cancelled successfully",
+	})
+}
+
+// RegisterRoutes registers all stock count routes
+func (h *Handler) RegisterRoutes(g *echo.Group) {
+	g.POST("/stock-counts", h.CreateCount, auth.RequirePermission(auth.PermInventoryAdjust))
+	g.GET("/stock-counts", h.ListCounts, auth.RequirePermission(auth.PermInventoryView))
+	g.GET("/stock-counts/:id", h.GetCount, auth.RequirePermission(auth.PermInventoryView))
+	g.POST("/stock-counts/:id/entries", h.RecordCounts, auth.RequirePermission(auth.PermInventoryCount))
+	g.GET("/stock-counts/:id/variance", h.GetVarianceReport, auth.RequirePermission(auth.PermInventoryView))
+	g.POST("/stock-counts/:id/submit", h.SubmitCount, auth.RequirePermission(auth.PermInventoryAdjust))
+	g.POST("/stock-counts/:id/reopen", h.ReopenCount, auth.RequirePermission(auth.PermInventoryAdjust))
+	g.POST("/stock-counts/:id/approve", h.ApproveCount, auth.RequirePermission(auth.PermInventoryAdjust))
+	g.POST("/stock-counts/:id/cancel", h.CancelCount, auth.RequirePermission(auth.PermInventoryAdjust))
+}
+
+// toHTTPError maps service errors to HTTP errors
+func toHTTPError(err error) error {
+	switch {
+	case database.IsNotFound(err):
+		return echo.NewHTTPError(http.StatusNotFound, "stock count not found")
+	case errors.Is(err, ErrApprovalRequired):
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	case errors.Is(err, ErrInvalidStatusTransition), errors.Is(err, ErrNotCounting),
+		errors.Is(err, ErrBlindCount), errors.Is(err, ErrIncompleteCount),
+		errors.Is(err, ErrDisputedCount), errors.Is(err, ErrInsufficientStock):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, ErrInvalidCountLine), errors.Is(err, ErrBatchMismatch):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case database.IsDuplicateKey(err):
+		return echo.NewHTTPError(http.StatusConflict, "count number already exists")
+	case database.IsForeignKeyViolation(err):
+		return echo.NewHTTPError(http.StatusBadRequest, "referenced location, product or batch does not exist")
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// Request types
+type StockCountRequest struct {
+	CountNumber string      `json:"count_number"`
+	LocationID  uuid.UUID   `json:"location_id" validate:"required"`
+	ProductIDs  []uuid.UUID `json:"product_ids"`
+	Blind       bool        `json:"blind"`
+	Notes       string      `json:"notes"`
+}
+
+type RecordCountsRequest struct {
+	Lines []CountLineRequest `json:"lines" validate:"required,min=1"`
+}
+
+type CountLineRequest struct {
+	ProductID       uuid.UUID `json:"product_id" validate:"required"`
+	BatchID         uuid.UUID `json:"batch_id" validate:"required"`
+	CountedQuantity int       `json:"counted_quantity" validate:"min=0"`
+}
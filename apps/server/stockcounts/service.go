@@ -0,0 +1,526 @@
+package stockcounts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"agromart2/apps/server/adjustments"
+	"agromart2/apps/server/inventory"
+	"agromart2/db"
+	"agromart2/internal/database"
+	"agromart2/internal/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrInvalidStatusTransition = errors.New("invalid stock count status transition")
+	ErrNotCounting             = errors.New("counts can only be recorded while the stock count is open")
+	ErrInvalidCountLine        = errors.New("count line requires a product, batch and a counted quantity of zero or more")
+	ErrBlindCount              = errors.New("variances of a blind count are hidden until counting is submitted")
+	ErrIncompleteCount         = errors.New("every line must be counted before the stock count can be approved")
+	ErrDisputedCount           = errors.New("counters disagree on some lines; reopen the count and recount them")
+	ErrBatchMismatch           = adjustments.ErrBatchMismatch
+	ErrApprovalRequired        = adjustments.ErrApprovalRequired
+	ErrInsufficientStock       = inventory.ErrInsufficientStock
+)
+
+type StockCountService struct {
+	db          *pgxpool.Pool
+	q           *db.Queries
+	adjustments *adjustments.AdjustmentService
+}
+
+func NewStockCountService(db *pgxpool.Pool, queries *db.Queries, adjustmentService *adjustments.AdjustmentService) *StockCountService {
+	return &StockCountService{
+		db:          db,
+		q:           queries,
+		adjustments: adjustmentService,
+	}
+}
+
+type CreateCountParams struct {
+	TenantID    uuid.UUID
+	CountNumber string
+	LocationID  uuid.UUID
+	// ProductIDs limits the count to these products; empty counts the whole location
+	ProductIDs []uuid.UUID
+	// Blind withholds expected quantities from the count until it is submitted. It does not
+	// hide stock elsewhere: counters holding inventory.view can still read it from inventory.
+	Blind     bool
+	Notes     string
+	CreatedBy uuid.UUID
+}
+
+// CountLineParams is what one counter found of a batch
+type CountLineParams struct {
+	ProductID       uuid.UUID
+	BatchID         uuid.UUID
+	CountedQuantity int
+}
+
+// Line is a batch in scope of a stock count
+type Line struct {
+	ID          uuid.UUID `json:"id"`
+	ProductID   uuid.UUID `json:"product_id"`
+	ProductName string    `json:"product_name"`
+	BatchID     uuid.UUID `json:"batch_id"`
+	BatchNumber string    `json:"batch_number"`
+	// ExpectedQuantity is nil while a blind count is open
+	ExpectedQuantity *int `json:"expected_quantity"`
+	Counters         int  `json:"counters"`
+}
+
+// StockCountWithLines is a stock count with its lines and the count entries the caller may see
+type StockCountWithLines struct {
+	db.StockCount
+	Lines   []Line               `json:"lines"`
+	Entries []db.StockCountEntry `json:"entries"`
+}
+
+// VarianceLine compares what was expected of a batch with what was counted
+type VarianceLine struct {
+	LineID           uuid.UUID `json:"line_id"`
+	ProductID        uuid.UUID `json:"product_id"`
+	ProductName      string    `json:"product_name"`
+	BatchID          uuid.UUID `json:"batch_id"`
+	BatchNumber      string    `json:"batch_number"`
+	ExpectedQuantity int       `json:"expected_quantity"`
+	// CountedQuantity is nil until someone has counted the line
+	CountedQuantity *int    `json:"counted_quantity"`
+	Variance        int     `json:"variance"`
+	UnitCost        float64 `json:"unit_cost"`
+	ValueImpact     float64 `json:"value_impact"`
+	Counters        int     `json:"counters"`
+	// Disputed is set when counters recorded different quantities
+	Disputed bool `json:"disputed"`
+}
+
+// VarianceReport is the outcome of a stock count, valued at batch cost
+type VarianceReport struct {
+	StockCountID   uuid.UUID      `json:"stock_count_id"`
+	CountNumber    string         `json:"count_number"`
+	Status         string         `json:"status"`
+	Lines          []VarianceLine `json:"lines"`
+	LinesCounted   int            `json:"lines_counted"`
+	LinesUncounted int            `json:"lines_uncounted"`
+	LinesDisputed  int            `json:"lines_disputed"`
+	LinesVariant   int            `json:"lines_with_variance"`
+	// NetValueImpact is gains less losses; GrossValueImpact adds both
+	NetValueImpact   float64 `json:"net_value_impact"`
+	GrossValueImpact float64 `json:"gross_value_impact"`
+}
+
+// CreateCount opens a stock count and snapshots the expected quantity of every batch in scope
+func (s *StockCountService) CreateCount(ctx context.Context, params CreateCountParams) (*StockCountWithLines, error) {
+	countNumber := params.CountNumber
+	if countNumber == "" {
+		countNumber = generateCountNumber()
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	count, err := qtx.CreateStockCount(ctx, db.CreateStockCountParams{
+		TenantID:    params.TenantID,
+		CountNumber: countNumber,
+		LocationID:  params.LocationID,
+		Blind:       params.Blind,
+		Notes:       utils.P.Text(params.Notes),
+		CreatedBy:   utils.P.UUID(params.CreatedBy),
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("failed to create stock count")
+		return nil, database.WrapError(err, "failed to create stock count")
+	}
+
+	// The query takes a NULL product list, not an empty one, to mean the whole location
+	var productIDs []uuid.UUID
+	if len(params.ProductIDs) > 0 {
+		productIDs = params.ProductIDs
+	}
+	if _, err := qtx.SnapshotStockCountLines(ctx, db.SnapshotStockCountLinesParams{
+		StockCountID: count.ID,
+		TenantID:     params.TenantID,
+		LocationID:   params.LocationID,
+		ProductIds:   productIDs,
+	}); err != nil {
+		return nil, database.WrapError(err, "failed to snapshot stock count lines")
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return s.GetCount(ctx, count.ID, params.TenantID, params.CreatedBy)
+}
+
+// GetCount retrieves a stock count with its lines. While a blind count is open, expected
+// quantities are withheld and the viewer only sees their own count entries.
+func (s *StockCountService) GetCount(ctx context.Context, id, tenantID, viewerID uuid.UUID) (*StockCountWithLines, error) {
+	count, err := s.q.GetStockCount(ctx, db.GetStockCountParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get stock count")
+	}
+
+	rows, err := s.q.ListStockCountLines(ctx, db.ListStockCountLinesParams{StockCountID: id, TenantID: tenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get stock count lines")
+	}
+
+	entries, err := s.q.ListStockCountEntries(ctx, db.ListStockCountEntriesParams{StockCountID: id, TenantID: tenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get stock count entries")
+	}
+
+	hidden := count.Blind && count.Status == StatusOpen
+
+	lines := make([]Line, len(rows))
+	for i, row := range rows {
+		lines[i] = Line{
+			ID:          row.ID,
+			ProductID:   row.ProductID,
+			ProductName: row.ProductName,
+			BatchID:     row.BatchID,
+			BatchNumber: row.BatchNumber,
+			Counters:    int(row.Counters),
+		}
+		if !hidden {
+			expected := utils.PgNumericToInt(row.ExpectedQuantity)
+			lines[i].ExpectedQuantity = &expected
+		}
+	}
+
+	if hidden {
+		own := make([]db.StockCountEntry, 0, len(entries))
+		for _, entry := range entries {
+			if entry.CountedBy == viewerID {
+				own = append(own, entry)
+			}
+		}
+		entries = own
+	}
+
+	return &StockCountWithLines{StockCount: count, Lines: lines, Entries: entries}, nil
+}
+
+// ListCounts lists stock counts, optionally filtered by status or location
+func (s *StockCountService) ListCounts(ctx context.Context, tenantID uuid.UUID, status string, locationID *uuid.UUID, limit, offset int32) ([]db.StockCount, error) {
+	counts, err := s.q.ListStockCounts(ctx, db.ListStockCountsParams{
+		TenantID:   tenantID,
+		Status:     utils.P.Text(status),
+		LocationID: utils.P.UUIDPtr(locationID),
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("failed to list stock counts")
+		return []db.StockCount{}, fmt.Errorf("failed to list stock counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// RecordCounts stores what a counter found. Each counter keeps one entry per batch, so
+// recounting replaces their earlier figure. Batches that were not in the snapshot are added
+// with an expected quantity of zero.
+func (s *StockCountService) RecordCounts(ctx context.Context, id, tenantID, counterID uuid.UUID, lines []CountLineParams) ([]db.StockCountEntry, error) {
+	for _, line := range lines {
+		if line.ProductID == uuid.Nil || line.BatchID == uuid.Nil || line.CountedQuantity < 0 {
+			return nil, ErrInvalidCountLine
+		}
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	count, err := qtx.GetStockCountForUpdate(ctx, db.GetStockCountForUpdateParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get stock count")
+	}
+	if count.Status != StatusOpen {
+		return nil, ErrNotCounting
+	}
+
+	entries := make([]db.StockCountEntry, 0, len(lines))
+	for _, line := range lines {
+		batch, err := qtx.GetBatchByID(ctx, db.GetBatchByIDParams{ID: line.BatchID, TenantID: tenantID})
+		if err != nil && !database.IsNotFound(err) {
+			return nil, database.WrapError(err, "failed to get batch")
+		}
+		if err != nil || batch.ProductID != line.ProductID {
+			return nil, fmt.Errorf("%w: batch %s", ErrBatchMismatch, line.BatchID)
+		}
+
+		countLine, err := qtx.EnsureStockCountLine(ctx, db.EnsureStockCountLineParams{
+			TenantID:     tenantID,
+			StockCountID: id,
+			ProductID:    line.ProductID,
+			BatchID:      line.BatchID,
+		})
+		if err != nil {
+			return nil, database.WrapError(err, "failed to get stock count line")
+		}
+
+		entry, err := qtx.UpsertStockCountEntry(ctx, db.UpsertStockCountEntryParams{
+			TenantID:         tenantID,
+			StockCountLineID: countLine.ID,
+			CountedBy:        counterID,
+			CountedQuantity:  utils.P.Numeric(line.CountedQuantity),
+		})
+		if err != nil {
+			return nil, database.WrapError(err, "failed to record count")
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return entries, nil
+}
+
+// SubmitCount closes counting so the variances can be reviewed
+func (s *StockCountService) SubmitCount(ctx context.Context, id, tenantID uuid.UUID) (db.StockCount, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return db.StockCount{}, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	count, err := qtx.GetStockCountForUpdate(ctx, db.GetStockCountForUpdateParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return db.StockCount{}, database.WrapError(err, "failed to get stock count")
+	}
+
+	if _, err := s.transition(ctx, qtx, count, StatusSubmitted); err != nil {
+		return db.StockCount{}, err
+	}
+	if err := qtx.SetStockCountSubmitted(ctx, db.SetStockCountSubmittedParams{ID: id, TenantID: tenantID}); err != nil {
+		return db.StockCount{}, database.WrapError(err, "failed to set submission time")
+	}
+
+	count, err = qtx.GetStockCount(ctx, db.GetStockCountParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return db.StockCount{}, database.WrapError(err, "failed to reload stock count")
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return db.StockCount{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return count, nil
+}
+
+// ReopenCount returns a submitted count to counting, e.g. to recount disputed lines
+func (s *StockCountService) ReopenCount(ctx context.Context, id, tenantID uuid.UUID) (db.StockCount, error) {
+	count, err := s.q.GetStockCount(ctx, db.GetStockCountParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return db.StockCount{}, database.WrapError(err, "failed to get stock count")
+	}
+
+	return s.transition(ctx, s.q, count, StatusOpen)
+}
+
+// CancelCount abandons a count that has not been approved; nothing is posted
+func (s *StockCountService) CancelCount(ctx context.Context, id, tenantID uuid.UUID) (db.StockCount, error) {
+	count, err := s.q.GetStockCount(ctx, db.GetStockCountParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return db.StockCount{}, database.WrapError(err, "failed to get stock count")
+	}
+
+	return s.transition(ctx, s.q, count, StatusCancelled)
+}
+
+// GetVarianceReport compares counted with expected quantities for every line of a count
+func (s *StockCountService) GetVarianceReport(ctx context.Context, id, tenantID uuid.UUID) (*VarianceReport, error) {
+	count, err := s.q.GetStockCount(ctx, db.GetStockCountParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get stock count")
+	}
+	if count.Blind && count.Status == StatusOpen {
+		return nil, ErrBlindCount
+	}
+
+	return varianceReport(ctx, s.q, count)
+}
+
+// ApproveCount accepts a submitted count and posts its variances as a COUNT_CORRECTION
+// stock adjustment at the count's location. Variances are measured against the snapshot
+// taken when the count was opened. Every line must be counted and undisputed, and a count
+// whose variances are worth more than the adjustment approval threshold needs a caller
+// holding inventory.approve, which canApproveAboveThreshold reports.
+func (s *StockCountService) ApproveCount(ctx context.Context, id, tenantID, approverID uuid.UUID, canApproveAboveThreshold bool) (*VarianceReport, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	count, err := qtx.GetStockCountForUpdate(ctx, db.GetStockCountForUpdateParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get stock count")
+	}
+	if !CanTransition(count.Status, StatusApproved) {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, count.Status, StatusApproved)
+	}
+
+	report, err := varianceReport(ctx, qtx, count)
+	if err != nil {
+		return nil, err
+	}
+	if report.LinesUncounted > 0 {
+		return nil, fmt.Errorf("%w: %d uncounted", ErrIncompleteCount, report.LinesUncounted)
+	}
+	if report.LinesDisputed > 0 {
+		return nil, fmt.Errorf("%w: %d disputed", ErrDisputedCount, report.LinesDisputed)
+	}
+
+	items := make([]adjustments.LineItemParams, 0, report.LinesVariant)
+	for _, line := range report.Lines {
+		if line.Variance == 0 {
+			continue
+		}
+		items = append(items, adjustments.LineItemParams{
+			ProductID:      line.ProductID,
+			BatchID:        line.BatchID,
+			QuantityChange: line.Variance,
+			ReasonCode:     adjustments.ReasonCountCorrection,
+		})
+	}
+
+	var adjustmentID *uuid.UUID
+	if len(items) > 0 {
+		adjustment, err := s.adjustments.CreatePosted(ctx, qtx, adjustments.CreateAdjustmentParams{
+			TenantID:   tenantID,
+			LocationID: count.LocationID,
+			Notes:      fmt.Sprintf("Stock count %s", count.CountNumber),
+			CreatedBy:  approverID,
+			Items:      items,
+		}, approverID, canApproveAboveThreshold)
+		if err != nil {
+			return nil, err
+		}
+		adjustmentID = &adjustment.ID
+	}
+
+	approved, err := s.transition(ctx, qtx, count, StatusApproved)
+	if err != nil {
+		return nil, err
+	}
+	if err := qtx.SetStockCountApproved(ctx, db.SetStockCountApprovedParams{
+		ID:                id,
+		TenantID:          tenantID,
+		ApprovedBy:        utils.P.UUID(approverID),
+		StockAdjustmentID: utils.P.UUIDPtr(adjustmentID),
+	}); err != nil {
+		return nil, database.WrapError(err, "failed to record stock count approval")
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	report.Status = approved.Status
+	return report, nil
+}
+
+// varianceReport builds the variance report of a count from its lines and entries
+func varianceReport(ctx context.Context, q *db.Queries, count db.StockCount) (*VarianceReport, error) {
+	rows, err := q.ListStockCountLines(ctx, db.ListStockCountLinesParams{StockCountID: count.ID, TenantID: count.TenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get stock count lines")
+	}
+
+	report := &VarianceReport{
+		StockCountID: count.ID,
+		CountNumber:  count.CountNumber,
+		Status:       count.Status,
+		Lines:        make([]VarianceLine, len(rows)),
+	}
+	for i, row := range rows {
+		line := VarianceLine{
+			LineID:           row.ID,
+			ProductID:        row.ProductID,
+			ProductName:      row.ProductName,
+			BatchID:          row.BatchID,
+			BatchNumber:      row.BatchNumber,
+			ExpectedQuantity: utils.PgNumericToInt(row.ExpectedQuantity),
+			UnitCost:         utils.PgNumericToFloat64(row.Cost),
+			Counters:         int(row.Counters),
+		}
+
+		switch {
+		case row.Counters == 0:
+			report.LinesUncounted++
+		case utils.PgNumericToInt(row.MinCounted) != utils.PgNumericToInt(row.MaxCounted):
+			line.Disputed = true
+			report.LinesDisputed++
+		default:
+			counted := utils.PgNumericToInt(row.MinCounted)
+			line.CountedQuantity = &counted
+			line.Variance = counted - line.ExpectedQuantity
+			line.ValueImpact = float64(line.Variance) * line.UnitCost
+			report.LinesCounted++
+			if line.Variance != 0 {
+				report.LinesVariant++
+			}
+			report.NetValueImpact += line.ValueImpact
+			if line.ValueImpact < 0 {
+				report.GrossValueImpact -= line.ValueImpact
+			} else {
+				report.GrossValueImpact += line.ValueImpact
+			}
+		}
+		report.Lines[i] = line
+	}
+
+	return report, nil
+}
+
+// transition moves a stock count to a new status if the transition table allows it.
+// The update is guarded on the current status so concurrent transitions cannot both win.
+func (s *StockCountService) transition(ctx context.Context, q *db.Queries, current db.StockCount, to string) (db.StockCount, error) {
+	if !CanTransition(current.Status, to) {
+		return db.StockCount{}, fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, current.Status, to)
+	}
+
+	count, err := q.TransitionStockCountStatus(ctx, db.TransitionStockCountStatusParams{
+		NewStatus:     to,
+		ID:            current.ID,
+		TenantID:      current.TenantID,
+		CurrentStatus: current.Status,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.StockCount{}, fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, current.Status, to)
+		}
+		return db.StockCount{}, database.WrapError(err, "failed to update stock count status")
+	}
+
+	return count, nil
+}
+
+func generateCountNumber() string {
+	return fmt.Sprintf("CNT-%s-%s", time.Now().Format("20060102"), strings.ToUpper(uuid.NewString()[:8]))
+}
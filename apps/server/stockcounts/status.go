@@ -0,0 +1,26 @@
+package stockcounts
+
+// Stock count statuses as stored in stock_counts.status
+const (
+	StatusOpen      = "OPEN"
+	StatusSubmitted = "SUBMITTED"
+	StatusApproved  = "APPROVED"
+	StatusCancelled = "CANCELLED"
+)
+
+// transitions lists the statuses each status may move to. A submitted count can be
+// reopened so disputed lines can be recounted.
+var transitions = map[string][]string{
+	StatusOpen:      {StatusSubmitted, StatusCancelled},
+	StatusSubmitted: {StatusOpen, StatusApproved, StatusCancelled},
+}
+
+// CanTransition reports whether a stock count may move from one status to another
+func CanTransition(from, to string) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
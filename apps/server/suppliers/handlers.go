@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"agromart2/internal/auth"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
@@ -234,12 +235,12 @@ func (h *Handler) DeleteSupplier(c echo.Context) error {
 
 // RegisterRoutes registers all supplier routes
 func (h *Handler) RegisterRoutes(g *echo.Group) {
-	g.POST("/suppliers", h.CreateSupplier)
-	g.GET("/suppliers", h.ListSuppliers)
-	g.GET("/suppliers/search", h.SearchSuppliers)
-	g.GET("/suppliers/:id", h.GetSupplier)
-	g.PUT("/suppliers/:id", h.UpdateSupplier)
-	g.DELETE("/suppliers/:id", h.DeleteSupplier)
+	g.POST("/suppliers", h.CreateSupplier, auth.RequirePermission(auth.PermSuppliersManage))
+	g.GET("/suppliers", h.ListSuppliers, auth.RequirePermission(auth.PermSuppliersView))
+	g.GET("/suppliers/search", h.SearchSuppliers, auth.RequirePermission(auth.PermSuppliersView))
+	g.GET("/suppliers/:id", h.GetSupplier, auth.RequirePermission(auth.PermSuppliersView))
+	g.PUT("/suppliers/:id", h.UpdateSupplier, auth.RequirePermission(auth.PermSuppliersManage))
+	g.DELETE("/suppliers/:id", h.DeleteSupplier, auth.RequirePermission(auth.PermSuppliersManage))
 }
 
 // Request/Response types
@@ -0,0 +1,278 @@
+package transfers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"agromart2/internal/auth"
+	"agromart2/internal/database"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	service *TransferService
+}
+
+func NewHandler(service *TransferService) *Handler {
+	return &Handler{service: service}
+}
+
+// CreateTransfer creates a new transfer order with line items
+func (h *Handler) CreateTransfer(c echo.Context) error {
+	var req TransferRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if req.FromLocationID == uuid.Nil || req.ToLocationID == uuid.Nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "from_location_id and to_location_id are required")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	userID, err := uuid.Parse(c.Get("user_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid user ID")
+	}
+
+	items := make([]LineItemParams, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, LineItemParams{
+			ProductID: item.ProductID,
+			BatchID:   item.BatchID,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	transfer, err := h.service.CreateTransfer(c.Request().Context(), CreateTransferParams{
+		TenantID:       tenantID,
+		TransferNumber: req.TransferNumber,
+		FromLocationID: req.FromLocationID,
+		ToLocationID:   req.ToLocationID,
+		Notes:          req.Notes,
+		CreatedBy:      userID,
+		Items:          items,
+	})
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    transfer,
+		"message": "Transfer order created successfully",
+	})
+}
+
+// GetTransfer retrieves a transfer order with its line items
+func (h *Handler) GetTransfer(c echo.Context) error {
+	transferID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transfer order ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	transfer, err := h.service.GetTransfer(c.Request().Context(), transferID, tenantID)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    transfer,
+	})
+}
+
+// ListTransfers lists transfer orders with optional status/location filters
+func (h *Handler) ListTransfers(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	var locationID *uuid.UUID
+	if locationIDStr := c.QueryParam("location_id"); locationIDStr != "" {
+		id, err := uuid.Parse(locationIDStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid location ID")
+		}
+		locationID = &id
+	}
+
+	status := strings.ToUpper(c.QueryParam("status"))
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offset := int32((page - 1) * limit)
+
+	transfers, err := h.service.ListTransfers(c.Request().Context(), tenantID, status, locationID, int32(limit), offset)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    transfers,
+		"pagination": map[string]interface{}{
+			"page":  page,
+			"limit": limit,
+		},
+	})
+}
+
+// DispatchTransfer takes a pending transfer's stock out of the source location
+func (h *Handler) DispatchTransfer(c echo.Context) error {
+	transferID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transfer order ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	transfer, err := h.service.DispatchTransfer(c.Request().Context(), transferID, tenantID)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    transfer,
+		"message": "Transfer order dispatched successfully",
+	})
+}
+
+// ReceiveTransfer books an in-transit transfer's stock into the destination location
+func (h *Handler) ReceiveTransfer(c echo.Context) error {
+	transferID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transfer order ID")
+	}
+
+	var req ReceiveTransferRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	lines := make([]ReceiptLine, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		lines = append(lines, ReceiptLine{
+			ItemID:           line.ItemID,
+			QuantityReceived: line.QuantityReceived,
+		})
+	}
+
+	transfer, err := h.service.ReceiveTransfer(c.Request().Context(), transferID, tenantID, lines)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    transfer,
+		"message": "Transfer order received successfully",
+	})
+}
+
+// CancelTransfer cancels a transfer order that has not been dispatched
+func (h *Handler) CancelTransfer(c echo.Context) error {
+	transferID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transfer order ID")
+	}
+
+	tenantID, err := uuid.Parse(c.Get("tenant_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid tenant")
+	}
+
+	transfer, err := h.service.CancelTransfer(c.Request().Context(), transferID, tenantID)
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    transfer,
+		"message": "Transfer order cancelled successfully",
+	})
+}
+
+// RegisterRoutes registers all transfer order routes
+func (h *Handler) RegisterRoutes(g *echo.Group) {
+	g.POST("/transfers", h.CreateTransfer, auth.RequirePermission(auth.PermInventoryMove))
+	g.GET("/transfers", h.ListTransfers, auth.RequirePermission(auth.PermInventoryView))
+	g.GET("/transfers/:id", h.GetTransfer, auth.RequirePermission(auth.PermInventoryView))
+	g.POST("/transfers/:id/dispatch", h.DispatchTransfer, auth.RequirePermission(auth.PermInventoryMove))
+	g.POST("/transfers/:id/receive", h.ReceiveTransfer, auth.RequirePermission(auth.PermInventoryMove))
+	g.POST("/transfers/:id/cancel", h.CancelTransfer, auth.RequirePermission(auth.PermInventoryMove))
+}
+
+// toHTTPError maps service errors to HTTP errors
+func toHTTPError(err error) error {
+	switch {
+	case database.IsNotFound(err):
+		return echo.NewHTTPError(http.StatusNotFound, "transfer order not found")
+	case errors.Is(err, ErrInvalidStatusTransition), errors.Is(err, ErrInsufficientStock),
+		errors.Is(err, ErrNotReceivable), errors.Is(err, ErrOverReceipt):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, ErrSameLocation), errors.Is(err, ErrNoLineItems),
+		errors.Is(err, ErrInvalidLineItem), errors.Is(err, ErrUnknownItem):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case database.IsDuplicateKey(err):
+		return echo.NewHTTPError(http.StatusConflict, "transfer number already exists")
+	case database.IsForeignKeyViolation(err):
+		return echo.NewHTTPError(http.StatusBadRequest, "referenced location, product or batch does not exist")
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// Request types
+type TransferRequest struct {
+	TransferNumber string            `json:"transfer_number"`
+	FromLocationID uuid.UUID         `json:"from_location_id" validate:"required"`
+	ToLocationID   uuid.UUID         `json:"to_location_id" validate:"required"`
+	Notes          string            `json:"notes"`
+	Items          []LineItemRequest `json:"items" validate:"required,min=1"`
+}
+
+type LineItemRequest struct {
+	ProductID uuid.UUID `json:"product_id" validate:"required"`
+	BatchID   uuid.UUID `json:"batch_id" validate:"required"`
+	Quantity  int       `json:"quantity" validate:"required,min=1"`
+}
+
+type ReceiveTransferRequest struct {
+	Lines []ReceiptLineRequest `json:"lines"`
+}
+
+type ReceiptLineRequest struct {
+	ItemID           uuid.UUID `json:"item_id" validate:"required"`
+	QuantityReceived int       `json:"quantity_received" validate:"min=0"`
+}
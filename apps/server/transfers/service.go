@@ -0,0 +1,411 @@
+package transfers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"agromart2/apps/server/inventory"
+	"agromart2/db"
+	"agromart2/internal/database"
+	"agromart2/internal/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrInvalidStatusTransition = errors.New("invalid transfer order status transition")
+	ErrSameLocation            = errors.New("transfer source and destination must differ")
+	ErrNoLineItems             = errors.New("transfer order must have at least one line item")
+	ErrInvalidLineItem         = errors.New("line item requires a product, batch and positive quantity")
+	ErrNotReceivable           = errors.New("only in-transit transfer orders can be received")
+	ErrUnknownItem             = errors.New("receipt line does not belong to this transfer order")
+	ErrOverReceipt             = errors.New("received quantity exceeds quantity dispatched")
+	ErrInsufficientStock       = inventory.ErrInsufficientStock
+)
+
+type TransferService struct {
+	db *pgxpool.Pool
+	q  *db.Queries
+}
+
+func NewTransferService(db *pgxpool.Pool, queries *db.Queries) *TransferService {
+	return &TransferService{
+		db: db,
+		q:  queries,
+	}
+}
+
+type LineItemParams struct {
+	ProductID uuid.UUID
+	BatchID   uuid.UUID
+	Quantity  int
+}
+
+type CreateTransferParams struct {
+	TenantID       uuid.UUID
+	TransferNumber string
+	FromLocationID uuid.UUID
+	ToLocationID   uuid.UUID
+	Notes          string
+	CreatedBy      uuid.UUID
+	Items          []LineItemParams
+}
+
+// ReceiptLine is the quantity of one transfer item that arrived at the destination
+type ReceiptLine struct {
+	ItemID           uuid.UUID
+	QuantityReceived int
+}
+
+// TransferWithItems is a transfer order together with its line items
+type TransferWithItems struct {
+	db.TransferOrder
+	Items []db.TransferOrderItem `json:"items"`
+}
+
+// CreateTransfer creates a pending transfer order with its line items
+func (s *TransferService) CreateTransfer(ctx context.Context, params CreateTransferParams) (*TransferWithItems, error) {
+	if params.FromLocationID == params.ToLocationID {
+		return nil, ErrSameLocation
+	}
+	if len(params.Items) == 0 {
+		return nil, ErrNoLineItems
+	}
+	for _, item := range params.Items {
+		if item.ProductID == uuid.Nil || item.BatchID == uuid.Nil || item.Quantity <= 0 {
+			return nil, ErrInvalidLineItem
+		}
+	}
+
+	transferNumber := params.TransferNumber
+	if transferNumber == "" {
+		transferNumber = generateTransferNumber()
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	transfer, err := qtx.CreateTransferOrder(ctx, db.CreateTransferOrderParams{
+		TenantID:       params.TenantID,
+		TransferNumber: transferNumber,
+		FromLocationID: params.FromLocationID,
+		ToLocationID:   params.ToLocationID,
+		Notes:          utils.P.Text(params.Notes),
+		CreatedBy:      utils.P.UUID(params.CreatedBy),
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("failed to create transfer order")
+		return nil, database.WrapError(err, "failed to create transfer order")
+	}
+
+	items := make([]db.TransferOrderItem, 0, len(params.Items))
+	for _, item := range params.Items {
+		created, err := qtx.CreateTransferOrderItem(ctx, db.CreateTransferOrderItemParams{
+			TenantID:        params.TenantID,
+			TransferOrderID: transfer.ID,
+			ProductID:       item.ProductID,
+			BatchID:         item.BatchID,
+			Quantity:        utils.P.Numeric(item.Quantity),
+		})
+		if err != nil {
+			return nil, database.WrapError(err, "failed to create transfer order item")
+		}
+		items = append(items, created)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &TransferWithItems{TransferOrder: transfer, Items: items}, nil
+}
+
+// GetTransfer retrieves a transfer order and its line items
+func (s *TransferService) GetTransfer(ctx context.Context, id, tenantID uuid.UUID) (*TransferWithItems, error) {
+	transfer, err := s.q.GetTransferOrder(ctx, db.GetTransferOrderParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get transfer order")
+	}
+
+	items, err := s.q.GetTransferOrderItems(ctx, db.GetTransferOrderItemsParams{
+		TransferOrderID: id,
+		TenantID:        tenantID,
+	})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get transfer order items")
+	}
+
+	return &TransferWithItems{TransferOrder: transfer, Items: items}, nil
+}
+
+// ListTransfers lists transfer orders, optionally filtered by status or a location at either end
+func (s *TransferService) ListTransfers(ctx context.Context, tenantID uuid.UUID, status string, locationID *uuid.UUID, limit, offset int32) ([]db.TransferOrder, error) {
+	transfers, err := s.q.ListTransferOrders(ctx, db.ListTransferOrdersParams{
+		TenantID:   tenantID,
+		Status:     utils.P.Text(status),
+		LocationID: utils.P.UUIDPtr(locationID),
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("failed to list transfer orders")
+		return []db.TransferOrder{}, fmt.Errorf("failed to list transfer orders: %w", err)
+	}
+
+	return transfers, nil
+}
+
+// DispatchTransfer takes the transfer's stock out of the source location, leaving it in transit.
+// It fails with ErrInsufficientStock if that would dip into stock reserved by approved sales
+// orders or take a batch below zero. Each line is logged as TRANSFER_OUT.
+func (s *TransferService) DispatchTransfer(ctx context.Context, id, tenantID uuid.UUID) (*TransferWithItems, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	transfer, err := qtx.GetTransferOrderForUpdate(ctx, db.GetTransferOrderForUpdateParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get transfer order")
+	}
+	if !CanTransition(transfer.Status, StatusInTransit) {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, transfer.Status, StatusInTransit)
+	}
+
+	items, err := qtx.GetTransferOrderItems(ctx, db.GetTransferOrderItemsParams{TransferOrderID: id, TenantID: tenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get transfer order items")
+	}
+
+	// Stock promised to approved sales orders at the source stays there. Products are locked
+	// in a stable order, as sales order approval does, so the two cannot deadlock.
+	demand := make(map[uuid.UUID]int)
+	for _, item := range items {
+		demand[item.ProductID] += utils.PgNumericToInt(item.Quantity)
+	}
+	productIDs := make([]uuid.UUID, 0, len(demand))
+	for productID := range demand {
+		productIDs = append(productIDs, productID)
+	}
+	sort.Slice(productIDs, func(i, j int) bool { return productIDs[i].String() < productIDs[j].String() })
+
+	for _, productID := range productIDs {
+		if err := qtx.LockProductInventory(ctx, db.LockProductInventoryParams{
+			TenantID:   tenantID,
+			ProductID:  productID,
+			LocationID: transfer.FromLocationID,
+		}); err != nil {
+			return nil, database.WrapError(err, "failed to lock inventory")
+		}
+		if err := inventory.EnsureUnreserved(ctx, qtx, tenantID, transfer.FromLocationID, productID, demand[productID]); err != nil {
+			return nil, err
+		}
+	}
+
+	// Take stock out batch by batch in a stable order
+	ordered := make([]db.TransferOrderItem, len(items))
+	copy(ordered, items)
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].BatchID.String() < ordered[j].BatchID.String() })
+
+	notes := fmt.Sprintf("Dispatched on %s", transfer.TransferNumber)
+	for _, item := range ordered {
+		quantity := utils.PgNumericToInt(item.Quantity)
+
+		if _, err := inventory.Decrement(ctx, qtx, tenantID, transfer.FromLocationID, item.ProductID, item.BatchID, quantity); err != nil {
+			return nil, err
+		}
+
+		if err := qtx.CreateInventoryLog(ctx, db.CreateInventoryLogParams{
+			TenantID:        tenantID,
+			ProductID:       item.ProductID,
+			BatchID:         item.BatchID,
+			LocationID:      transfer.FromLocationID,
+			TransactionType: inventory.TxTransferOut,
+			QuantityChange:  utils.P.Numeric(-quantity),
+			ReferenceID:     utils.P.UUID(transfer.ID),
+			Notes:           utils.P.Text(notes),
+		}); err != nil {
+			return nil, database.WrapError(err, "failed to log transfer out")
+		}
+	}
+
+	if _, err := s.transition(ctx, qtx, transfer, StatusInTransit); err != nil {
+		return nil, err
+	}
+	if err := qtx.SetTransferOrderDispatched(ctx, db.SetTransferOrderDispatchedParams{ID: id, TenantID: tenantID}); err != nil {
+		return nil, database.WrapError(err, "failed to set dispatch time")
+	}
+
+	transfer, err = qtx.GetTransferOrder(ctx, db.GetTransferOrderParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to reload transfer order")
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &TransferWithItems{TransferOrder: transfer, Items: items}, nil
+}
+
+// ReceiveTransfer books the stock that arrived at the destination and completes the transfer.
+// Lines not listed are treated as not received; anything dispatched but not received is
+// recorded as a shortage on the item.
+func (s *TransferService) ReceiveTransfer(ctx context.Context, id, tenantID uuid.UUID, lines []ReceiptLine) (*TransferWithItems, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	transfer, err := qtx.GetTransferOrderForUpdate(ctx, db.GetTransferOrderForUpdateParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get transfer order")
+	}
+	if transfer.Status != StatusInTransit {
+		return nil, ErrNotReceivable
+	}
+
+	items, err := qtx.GetTransferOrderItems(ctx, db.GetTransferOrderItemsParams{TransferOrderID: id, TenantID: tenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get transfer order items")
+	}
+
+	received := make(map[uuid.UUID]int, len(items))
+	for _, item := range items {
+		received[item.ID] = 0
+	}
+	for _, line := range lines {
+		if _, ok := received[line.ItemID]; !ok {
+			return nil, ErrUnknownItem
+		}
+		if line.QuantityReceived < 0 {
+			return nil, ErrInvalidLineItem
+		}
+		received[line.ItemID] += line.QuantityReceived
+	}
+
+	notes := fmt.Sprintf("Received on %s", transfer.TransferNumber)
+	for i, item := range items {
+		dispatched := utils.PgNumericToInt(item.Quantity)
+		qty := received[item.ID]
+		if qty > dispatched {
+			return nil, fmt.Errorf("%w: item %s", ErrOverReceipt, item.ID)
+		}
+
+		if qty > 0 {
+			if err := qtx.AddInventoryQuantity(ctx, db.AddInventoryQuantityParams{
+				TenantID:   tenantID,
+				ProductID:  item.ProductID,
+				BatchID:    item.BatchID,
+				Quantity:   utils.P.Numeric(qty),
+				LocationID: transfer.ToLocationID,
+			}); err != nil {
+				return nil, database.WrapError(err, "failed to add inventory")
+			}
+
+			if err := qtx.CreateInventoryLog(ctx, db.CreateInventoryLogParams{
+				TenantID:        tenantID,
+				ProductID:       item.ProductID,
+				BatchID:         item.BatchID,
+				LocationID:      transfer.ToLocationID,
+				TransactionType: inventory.TxTransferIn,
+				QuantityChange:  utils.P.Numeric(qty),
+				ReferenceID:     utils.P.UUID(transfer.ID),
+				Notes:           utils.P.Text(notes),
+			}); err != nil {
+				return nil, database.WrapError(err, "failed to log transfer in")
+			}
+		}
+
+		updated, err := qtx.UpdateTransferOrderItemReceipt(ctx, db.UpdateTransferOrderItemReceiptParams{
+			ID:               item.ID,
+			QuantityReceived: utils.P.Numeric(qty),
+			QuantityShort:    utils.P.Numeric(dispatched - qty),
+			TenantID:         tenantID,
+		})
+		if err != nil {
+			return nil, database.WrapError(err, "failed to record transfer receipt")
+		}
+		items[i] = updated
+
+		if dispatched > qty {
+			log.Warn().
+				Str("transfer_order_id", transfer.ID.String()).
+				Str("item_id", item.ID.String()).
+				Int("short", dispatched-qty).
+				Msg("transfer received short")
+		}
+	}
+
+	if _, err := s.transition(ctx, qtx, transfer, StatusReceived); err != nil {
+		return nil, err
+	}
+	if err := qtx.SetTransferOrderReceived(ctx, db.SetTransferOrderReceivedParams{ID: id, TenantID: tenantID}); err != nil {
+		return nil, database.WrapError(err, "failed to set receipt time")
+	}
+
+	transfer, err = qtx.GetTransferOrder(ctx, db.GetTransferOrderParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to reload transfer order")
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &TransferWithItems{TransferOrder: transfer, Items: items}, nil
+}
+
+// CancelTransfer cancels a transfer that has not been dispatched
+func (s *TransferService) CancelTransfer(ctx context.Context, id, tenantID uuid.UUID) (db.TransferOrder, error) {
+	transfer, err := s.q.GetTransferOrder(ctx, db.GetTransferOrderParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return db.TransferOrder{}, database.WrapError(err, "failed to get transfer order")
+	}
+
+	return s.transition(ctx, s.q, transfer, StatusCancelled)
+}
+
+// transition moves a transfer order to a new status if the transition table allows it.
+// The update is guarded on the current status so concurrent transitions cannot both win.
+func (s *TransferService) transition(ctx context.Context, q *db.Queries, current db.TransferOrder, to string) (db.TransferOrder, error) {
+	if !CanTransition(current.Status, to) {
+		return db.TransferOrder{}, fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, current.Status, to)
+	}
+
+	transfer, err := q.TransitionTransferOrderStatus(ctx, db.TransitionTransferOrderStatusParams{
+		NewStatus:     to,
+		ID:            current.ID,
+		TenantID:      current.TenantID,
+		CurrentStatus: current.Status,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.TransferOrder{}, fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, current.Status, to)
+		}
+		return db.TransferOrder{}, database.WrapError(err, "failed to update transfer order status")
+	}
+
+	return transfer, nil
+}
+
+func generateTransferNumber() string {
+	return fmt.Sprintf("TR-%s-%s", time.Now().Format("20060102"), strings.ToUpper(uuid.NewString()[:8]))
+}
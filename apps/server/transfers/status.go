@@ -0,0 +1,25 @@
+package transfers
+
+// Transfer order statuses as stored in transfer_orders.status
+const (
+	StatusPending   = "PENDING"
+	StatusInTransit = "IN_TRANSIT"
+	StatusReceived  = "RECEIVED"
+	StatusCancelled = "CANCELLED"
+)
+
+// transitions lists the statuses each status may move to
+var transitions = map[string][]string{
+	StatusPending:   {StatusInTransit, StatusCancelled},
+	StatusInTransit: {StatusReceived},
+}
+
+// CanTransition reports whether a transfer order may move from one status to another
+func CanTransition(from, to string) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
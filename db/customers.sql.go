@@ -44,19 +44,20 @@ func (q *Queries) CountCustomers(ctx context.Context, tenantID uuid.UUID) (int64
 }
 
 const createCustomer = `-- name: CreateCustomer :one
-INSERT INTO customers (tenant_id, name, contact_person, email, phone, address, payment_mode)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, tenant_id, name, contact_person, email, phone, address, payment_mode, is_active, created_at, updated_at
+INSERT INTO customers (tenant_id, name, contact_person, email, phone, address, payment_mode, min_shelf_life_days)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, tenant_id, name, contact_person, email, phone, address, payment_mode, is_active, created_at, updated_at, min_shelf_life_days
 `
 
 type CreateCustomerParams struct {
-	TenantID      uuid.UUID   `json:"tenant_id"`
-	Name          string      `json:"name"`
-	ContactPerson pgtype.Text `json:"contact_person"`
-	Email         pgtype.Text `json:"email"`
-	Phone         pgtype.Text `json:"phone"`
-	Address       pgtype.Text `json:"address"`
-	PaymentMode   pgtype.Text `json:"payment_mode"`
+	TenantID         uuid.UUID   `json:"tenant_id"`
+	Name             string      `json:"name"`
+	ContactPerson    pgtype.Text `json:"contact_person"`
+	Email            pgtype.Text `json:"email"`
+	Phone            pgtype.Text `json:"phone"`
+	Address          pgtype.Text `json:"address"`
+	PaymentMode      pgtype.Text `json:"payment_mode"`
+	MinShelfLifeDays int32       `json:"min_shelf_life_days"`
 }
 
 func (q *Queries) CreateCustomer(ctx context.Context, arg CreateCustomerParams) (Customer, error) {
@@ -68,6 +69,7 @@ func (q *Queries) CreateCustomer(ctx context.Context, arg CreateCustomerParams)
 		arg.Phone,
 		arg.Address,
 		arg.PaymentMode,
+		arg.MinShelfLifeDays,
 	)
 	var i Customer
 	err := row.Scan(
@@ -82,6 +84,7 @@ func (q *Queries) CreateCustomer(ctx context.Context, arg CreateCustomerParams)
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.MinShelfLifeDays,
 	)
 	return i, err
 }
@@ -103,7 +106,7 @@ func (q *Queries) DeactivateCustomer(ctx context.Context, arg DeactivateCustomer
 }
 
 const getCustomerByID = `-- name: GetCustomerByID :one
-SELECT id, tenant_id, name, contact_person, email, phone, address, payment_mode, is_active, created_at, updated_at FROM customers
+SELECT id, tenant_id, name, contact_person, email, phone, address, payment_mode, is_active, created_at, updated_at, min_shelf_life_days FROM customers
 WHERE id = $1 AND tenant_id = $2
 `
 
@@ -127,12 +130,13 @@ func (q *Queries) GetCustomerByID(ctx context.Context, arg GetCustomerByIDParams
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.MinShelfLifeDays,
 	)
 	return i, err
 }
 
 const getCustomerByName = `-- name: GetCustomerByName :one
-SELECT id, tenant_id, name, contact_person, email, phone, address, payment_mode, is_active, created_at, updated_at FROM customers
+SELECT id, tenant_id, name, contact_person, email, phone, address, payment_mode, is_active, created_at, updated_at, min_shelf_life_days FROM customers
 WHERE tenant_id = $1 AND name = $2
 `
 
@@ -156,12 +160,13 @@ func (q *Queries) GetCustomerByName(ctx context.Context, arg GetCustomerByNamePa
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.MinShelfLifeDays,
 	)
 	return i, err
 }
 
 const listActiveCustomers = `-- name: ListActiveCustomers :many
-SELECT id, tenant_id, name, contact_person, email, phone, address, payment_mode, is_active, created_at, updated_at FROM customers
+SELECT id, tenant_id, name, contact_person, email, phone, address, payment_mode, is_active, created_at, updated_at, min_shelf_life_days FROM customers
 WHERE tenant_id = $1 AND (is_active IS NULL OR is_active = true)
 ORDER BY name
 LIMIT $2 OFFSET $3
@@ -194,6 +199,7 @@ func (q *Queries) ListActiveCustomers(ctx context.Context, arg ListActiveCustome
 			&i.IsActive,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.MinShelfLifeDays,
 		); err != nil {
 			return nil, err
 		}
@@ -206,7 +212,7 @@ func (q *Queries) ListActiveCustomers(ctx context.Context, arg ListActiveCustome
 }
 
 const listCustomers = `-- name: ListCustomers :many
-SELECT id, tenant_id, name, contact_person, email, phone, address, payment_mode, is_active, created_at, updated_at FROM customers
+SELECT id, tenant_id, name, contact_person, email, phone, address, payment_mode, is_active, created_at, updated_at, min_shelf_life_days FROM customers
 WHERE tenant_id = $1
 ORDER BY name
 LIMIT $2 OFFSET $3
@@ -239,6 +245,7 @@ func (q *Queries) ListCustomers(ctx context.Context, arg ListCustomersParams) ([
 			&i.IsActive,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.MinShelfLifeDays,
 		); err != nil {
 			return nil, err
 		}
@@ -251,7 +258,7 @@ func (q *Queries) ListCustomers(ctx context.Context, arg ListCustomersParams) ([
 }
 
 const searchCustomers = `-- name: SearchCustomers :many
-SELECT id, tenant_id, name, contact_person, email, phone, address, payment_mode, is_active, created_at, updated_at FROM customers
+SELECT id, tenant_id, name, contact_person, email, phone, address, payment_mode, is_active, created_at, updated_at, min_shelf_life_days FROM customers
 WHERE tenant_id = $1 AND name ILIKE $2
 ORDER BY name
 LIMIT $3 OFFSET $4
@@ -290,6 +297,7 @@ func (q *Queries) SearchCustomers(ctx context.Context, arg SearchCustomersParams
 			&i.IsActive,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.MinShelfLifeDays,
 		); err != nil {
 			return nil, err
 		}
@@ -303,21 +311,22 @@ func (q *Queries) SearchCustomers(ctx context.Context, arg SearchCustomersParams
 
 const updateCustomer = `-- name: UpdateCustomer :one
 UPDATE customers
-SET name = $2, contact_person = $3, email = $4, phone = $5, address = $6, payment_mode = $7, is_active = $8, updated_at = NOW()
+SET name = $2, contact_person = $3, email = $4, phone = $5, address = $6, payment_mode = $7, is_active = $8, min_shelf_life_days = $10, updated_at = NOW()
 WHERE id = $1 AND tenant_id = $9
-RETURNING id, tenant_id, name, contact_person, email, phone, address, payment_mode, is_active, created_at, updated_at
+RETURNING id, tenant_id, name, contact_person, email, phone, address, payment_mode, is_active, created_at, updated_at, min_shelf_life_days
 `
 
 type UpdateCustomerParams struct {
-	ID            uuid.UUID   `json:"id"`
-	Name          string      `json:"name"`
-	ContactPerson pgtype.Text `json:"contact_person"`
-	Email         pgtype.Text `json:"email"`
-	Phone         pgtype.Text `json:"phone"`
-	Address       pgtype.Text `json:"address"`
-	PaymentMode   pgtype.Text `json:"payment_mode"`
-	IsActive      pgtype.Bool `json:"is_active"`
-	TenantID      uuid.UUID   `json:"tenant_id"`
+	ID               uuid.UUID   `json:"id"`
+	Name             string      `json:"name"`
+	ContactPerson    pgtype.Text `json:"contact_person"`
+	Email            pgtype.Text `json:"email"`
+	Phone            pgtype.Text `json:"phone"`
+	Address          pgtype.Text `json:"address"`
+	PaymentMode      pgtype.Text `json:"payment_mode"`
+	IsActive         pgtype.Bool `json:"is_active"`
+	TenantID         uuid.UUID   `json:"tenant_id"`
+	MinShelfLifeDays int32       `json:"min_shelf_life_days"`
 }
 
 func (q *Queries) UpdateCustomer(ctx context.Context, arg UpdateCustomerParams) (Customer, error) {
@@ -331,6 +340,7 @@ func (q *Queries) UpdateCustomer(ctx context.Context, arg UpdateCustomerParams)
 		arg.PaymentMode,
 		arg.IsActive,
 		arg.TenantID,
+		arg.MinShelfLifeDays,
 	)
 	var i Customer
 	err := row.Scan(
@@ -345,6 +355,7 @@ func (q *Queries) UpdateCustomer(ctx context.Context, arg UpdateCustomerParams)
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.MinShelfLifeDays,
 	)
 	return i, err
 }
@@ -14,17 +14,18 @@ import (
 )
 
 const addInventoryQuantity = `-- name: AddInventoryQuantity :exec
-INSERT INTO inventory (tenant_id, product_id, batch_id, quantity)
-VALUES ($1, $2, $3, $4)
-ON CONFLICT (tenant_id, product_id, batch_id)
+INSERT INTO inventory (tenant_id, product_id, batch_id, quantity, location_id)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (tenant_id, location_id, product_id, batch_id)
 DO UPDATE SET quantity = inventory.quantity + $4
 `
 
 type AddInventoryQuantityParams struct {
-	TenantID  uuid.UUID      `json:"tenant_id"`
-	ProductID uuid.UUID      `json:"product_id"`
-	BatchID   uuid.UUID      `json:"batch_id"`
-	Quantity  pgtype.Numeric `json:"quantity"`
+	TenantID   uuid.UUID      `json:"tenant_id"`
+	ProductID  uuid.UUID      `json:"product_id"`
+	BatchID    uuid.UUID      `json:"batch_id"`
+	Quantity   pgtype.Numeric `json:"quantity"`
+	LocationID uuid.UUID      `json:"location_id"`
 }
 
 func (q *Queries) AddInventoryQuantity(ctx context.Context, arg AddInventoryQuantityParams) error {
@@ -33,6 +34,7 @@ func (q *Queries) AddInventoryQuantity(ctx context.Context, arg AddInventoryQuan
 		arg.ProductID,
 		arg.BatchID,
 		arg.Quantity,
+		arg.LocationID,
 	)
 	return err
 }
@@ -85,8 +87,8 @@ func (q *Queries) CreateBatch(ctx context.Context, arg CreateBatchParams) (Batch
 }
 
 const createInventoryLog = `-- name: CreateInventoryLog :exec
-INSERT INTO inventory_log (tenant_id, product_id, batch_id, transaction_type, quantity_change, reference_id, notes)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO inventory_log (tenant_id, product_id, batch_id, transaction_type, quantity_change, reference_id, notes, location_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 `
 
 type CreateInventoryLogParams struct {
@@ -97,6 +99,7 @@ type CreateInventoryLogParams struct {
 	QuantityChange  pgtype.Numeric `json:"quantity_change"`
 	ReferenceID     pgtype.UUID    `json:"reference_id"`
 	Notes           pgtype.Text    `json:"notes"`
+	LocationID      uuid.UUID      `json:"location_id"`
 }
 
 func (q *Queries) CreateInventoryLog(ctx context.Context, arg CreateInventoryLogParams) error {
@@ -108,6 +111,7 @@ func (q *Queries) CreateInventoryLog(ctx context.Context, arg CreateInventoryLog
 		arg.QuantityChange,
 		arg.ReferenceID,
 		arg.Notes,
+		arg.LocationID,
 	)
 	return err
 }
@@ -137,6 +141,32 @@ func (q *Queries) GetBatchByID(ctx context.Context, arg GetBatchByIDParams) (Bat
 	return i, err
 }
 
+const getBatchByNumber = `-- name: GetBatchByNumber :one
+SELECT id, tenant_id, product_id, batch_number, expiry_date, cost, created_at FROM batches
+WHERE tenant_id = $1 AND product_id = $2 AND batch_number = $3
+`
+
+type GetBatchByNumberParams struct {
+	TenantID    uuid.UUID `json:"tenant_id"`
+	ProductID   uuid.UUID `json:"product_id"`
+	BatchNumber string    `json:"batch_number"`
+}
+
+func (q *Queries) GetBatchByNumber(ctx context.Context, arg GetBatchByNumberParams) (Batch, error) {
+	row := q.db.QueryRow(ctx, getBatchByNumber, arg.TenantID, arg.ProductID, arg.BatchNumber)
+	var i Batch
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.ProductID,
+		&i.BatchNumber,
+		&i.ExpiryDate,
+		&i.Cost,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const getExpiringBatches = `-- name: GetExpiringBatches :many
 SELECT
     b.id as batch_id,
@@ -145,20 +175,25 @@ SELECT
     p.id as product_id,
     p.name as product_name,
     p.sku as product_sku,
+    i.location_id,
+    l.name as location_name,
     i.quantity,
     EXTRACT(DAY FROM (b.expiry_date - CURRENT_DATE)) as days_until_expiry
 FROM batches b
 JOIN products p ON b.product_id = p.id
 JOIN inventory i ON b.id = i.batch_id
+JOIN locations l ON i.location_id = l.id
 WHERE b.tenant_id = $1
     AND b.expiry_date <= $2
     AND i.quantity > 0
+    AND ($3::uuid IS NULL OR i.location_id = $3)
 ORDER BY b.expiry_date ASC
 `
 
 type GetExpiringBatchesParams struct {
-	TenantID   uuid.UUID `json:"tenant_id"`
-	ExpiryDate time.Time `json:"expiry_date"`
+	TenantID   uuid.UUID   `json:"tenant_id"`
+	ExpiryDate time.Time   `json:"expiry_date"`
+	LocationID pgtype.UUID `json:"location_id"`
 }
 
 type GetExpiringBatchesRow struct {
@@ -168,12 +203,14 @@ type GetExpiringBatchesRow struct {
 	ProductID       uuid.UUID      `json:"product_id"`
 	ProductName     string         `json:"product_name"`
 	ProductSku      string         `json:"product_sku"`
+	LocationID      uuid.UUID      `json:"location_id"`
+	LocationName    string         `json:"location_name"`
 	Quantity        pgtype.Numeric `json:"quantity"`
 	DaysUntilExpiry float64        `json:"days_until_expiry"`
 }
 
 func (q *Queries) GetExpiringBatches(ctx context.Context, arg GetExpiringBatchesParams) ([]GetExpiringBatchesRow, error) {
-	rows, err := q.db.Query(ctx, getExpiringBatches, arg.TenantID, arg.ExpiryDate)
+	rows, err := q.db.Query(ctx, getExpiringBatches, arg.TenantID, arg.ExpiryDate, arg.LocationID)
 	if err != nil {
 		return nil, err
 	}
@@ -188,6 +225,8 @@ func (q *Queries) GetExpiringBatches(ctx context.Context, arg GetExpiringBatches
 			&i.ProductID,
 			&i.ProductName,
 			&i.ProductSku,
+			&i.LocationID,
+			&i.LocationName,
 			&i.Quantity,
 			&i.DaysUntilExpiry,
 		); err != nil {
@@ -202,18 +241,56 @@ func (q *Queries) GetExpiringBatches(ctx context.Context, arg GetExpiringBatches
 }
 
 const getInventoryByProductBatch = `-- name: GetInventoryByProductBatch :one
-SELECT id, tenant_id, product_id, batch_id, quantity FROM inventory
-WHERE tenant_id = $1 AND product_id = $2 AND batch_id = $3
+SELECT id, tenant_id, product_id, batch_id, quantity, location_id FROM inventory
+WHERE tenant_id = $1 AND product_id = $2 AND batch_id = $3 AND location_id = $4
 `
 
 type GetInventoryByProductBatchParams struct {
-	TenantID  uuid.UUID `json:"tenant_id"`
-	ProductID uuid.UUID `json:"product_id"`
-	BatchID   uuid.UUID `json:"batch_id"`
+	TenantID   uuid.UUID `json:"tenant_id"`
+	ProductID  uuid.UUID `json:"product_id"`
+	BatchID    uuid.UUID `json:"batch_id"`
+	LocationID uuid.UUID `json:"location_id"`
 }
 
 func (q *Queries) GetInventoryByProductBatch(ctx context.Context, arg GetInventoryByProductBatchParams) (Inventory, error) {
-	row := q.db.QueryRow(ctx, getInventoryByProductBatch, arg.TenantID, arg.ProductID, arg.BatchID)
+	row := q.db.QueryRow(ctx, getInventoryByProductBatch,
+		arg.TenantID,
+		arg.ProductID,
+		arg.BatchID,
+		arg.LocationID,
+	)
+	var i Inventory
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.ProductID,
+		&i.BatchID,
+		&i.Quantity,
+		&i.LocationID,
+	)
+	return i, err
+}
+
+const getInventoryForUpdate = `-- name: GetInventoryForUpdate :one
+SELECT id, tenant_id, product_id, batch_id, quantity, location_id FROM inventory
+WHERE tenant_id = $1 AND product_id = $2 AND batch_id = $3 AND location_id = $4
+FOR UPDATE
+`
+
+type GetInventoryForUpdateParams struct {
+	TenantID   uuid.UUID `json:"tenant_id"`
+	ProductID  uuid.UUID `json:"product_id"`
+	BatchID    uuid.UUID `json:"batch_id"`
+	LocationID uuid.UUID `json:"location_id"`
+}
+
+func (q *Queries) GetInventoryForUpdate(ctx context.Context, arg GetInventoryForUpdateParams) (Inventory, error) {
+	row := q.db.QueryRow(ctx, getInventoryForUpdate,
+		arg.TenantID,
+		arg.ProductID,
+		arg.BatchID,
+		arg.LocationID,
+	)
 	var i Inventory
 	err := row.Scan(
 		&i.ID,
@@ -221,14 +298,15 @@ func (q *Queries) GetInventoryByProductBatch(ctx context.Context, arg GetInvento
 		&i.ProductID,
 		&i.BatchID,
 		&i.Quantity,
+		&i.LocationID,
 	)
 	return i, err
 }
 
 const getInventoryLogByBatch = `-- name: GetInventoryLogByBatch :many
-SELECT id, tenant_id, product_id, batch_id, transaction_type, quantity_change, transaction_date, notes, reference_id FROM inventory_log
+SELECT id, tenant_id, product_id, batch_id, transaction_type, quantity_change, transaction_date, notes, reference_id, location_id, entry_no, balance_after FROM inventory_log
 WHERE tenant_id = $1 AND batch_id = $2
-ORDER BY transaction_date DESC
+ORDER BY entry_no DESC
 LIMIT $3 OFFSET $4
 `
 
@@ -263,6 +341,9 @@ func (q *Queries) GetInventoryLogByBatch(ctx context.Context, arg GetInventoryLo
 			&i.TransactionDate,
 			&i.Notes,
 			&i.ReferenceID,
+			&i.LocationID,
+			&i.EntryNo,
+			&i.BalanceAfter,
 		); err != nil {
 			return nil, err
 		}
@@ -275,9 +356,9 @@ func (q *Queries) GetInventoryLogByBatch(ctx context.Context, arg GetInventoryLo
 }
 
 const getInventoryLogByProduct = `-- name: GetInventoryLogByProduct :many
-SELECT id, tenant_id, product_id, batch_id, transaction_type, quantity_change, transaction_date, notes, reference_id FROM inventory_log
+SELECT id, tenant_id, product_id, batch_id, transaction_type, quantity_change, transaction_date, notes, reference_id, location_id, entry_no, balance_after FROM inventory_log
 WHERE tenant_id = $1 AND product_id = $2
-ORDER BY transaction_date DESC
+ORDER BY entry_no DESC
 LIMIT $3 OFFSET $4
 `
 
@@ -312,6 +393,65 @@ func (q *Queries) GetInventoryLogByProduct(ctx context.Context, arg GetInventory
 			&i.TransactionDate,
 			&i.Notes,
 			&i.ReferenceID,
+			&i.LocationID,
+			&i.EntryNo,
+			&i.BalanceAfter,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getInventoryValuation = `-- name: GetInventoryValuation :many
+SELECT
+    p.id AS product_id,
+    p.name AS product_name,
+    p.sku AS product_sku,
+    SUM(i.quantity)::numeric AS total_quantity,
+    SUM(i.quantity * b.cost)::numeric AS total_value
+FROM inventory i
+JOIN products p ON i.product_id = p.id
+JOIN batches b ON i.batch_id = b.id
+WHERE i.tenant_id = $1
+    AND i.quantity > 0
+    AND ($2::uuid IS NULL OR i.location_id = $2)
+GROUP BY p.id, p.name, p.sku
+ORDER BY total_value DESC
+`
+
+type GetInventoryValuationParams struct {
+	TenantID   uuid.UUID   `json:"tenant_id"`
+	LocationID pgtype.UUID `json:"location_id"`
+}
+
+type GetInventoryValuationRow struct {
+	ProductID     uuid.UUID      `json:"product_id"`
+	ProductName   string         `json:"product_name"`
+	ProductSku    string         `json:"product_sku"`
+	TotalQuantity pgtype.Numeric `json:"total_quantity"`
+	TotalValue    pgtype.Numeric `json:"total_value"`
+}
+
+func (q *Queries) GetInventoryValuation(ctx context.Context, arg GetInventoryValuationParams) ([]GetInventoryValuationRow, error) {
+	rows, err := q.db.Query(ctx, getInventoryValuation, arg.TenantID, arg.LocationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetInventoryValuationRow{}
+	for rows.Next() {
+		var i GetInventoryValuationRow
+		if err := rows.Scan(
+			&i.ProductID,
+			&i.ProductName,
+			&i.ProductSku,
+			&i.TotalQuantity,
+			&i.TotalValue,
 		); err != nil {
 			return nil, err
 		}
@@ -328,10 +468,16 @@ SELECT COALESCE(SUM(i.quantity * b.cost), 0) as total_value
 FROM inventory i
 JOIN batches b ON i.batch_id = b.id
 WHERE i.tenant_id = $1
+    AND ($2::uuid IS NULL OR i.location_id = $2)
 `
 
-func (q *Queries) GetInventoryValue(ctx context.Context, tenantID uuid.UUID) (interface{}, error) {
-	row := q.db.QueryRow(ctx, getInventoryValue, tenantID)
+type GetInventoryValueParams struct {
+	TenantID   uuid.UUID   `json:"tenant_id"`
+	LocationID pgtype.UUID `json:"location_id"`
+}
+
+func (q *Queries) GetInventoryValue(ctx context.Context, arg GetInventoryValueParams) (interface{}, error) {
+	row := q.db.QueryRow(ctx, getInventoryValue, arg.TenantID, arg.LocationID)
 	var total_value interface{}
 	err := row.Scan(&total_value)
 	return total_value, err
@@ -342,13 +488,15 @@ SELECT p.id, p.name, p.sku, SUM(i.quantity) as total_quantity
 FROM products p
 JOIN inventory i ON p.id = i.product_id
 WHERE p.tenant_id = $1
+    AND ($2::uuid IS NULL OR i.location_id = $2)
 GROUP BY p.id
-HAVING SUM(i.quantity) <= $2
+HAVING SUM(i.quantity) <= $3
 `
 
 type GetLowStockReportParams struct {
-	TenantID uuid.UUID      `json:"tenant_id"`
-	Quantity pgtype.Numeric `json:"quantity"`
+	TenantID   uuid.UUID      `json:"tenant_id"`
+	LocationID pgtype.UUID    `json:"location_id"`
+	Threshold  pgtype.Numeric `json:"threshold"`
 }
 
 type GetLowStockReportRow struct {
@@ -359,7 +507,7 @@ type GetLowStockReportRow struct {
 }
 
 func (q *Queries) GetLowStockReport(ctx context.Context, arg GetLowStockReportParams) ([]GetLowStockReportRow, error) {
-	rows, err := q.db.Query(ctx, getLowStockReport, arg.TenantID, arg.Quantity)
+	rows, err := q.db.Query(ctx, getLowStockReport, arg.TenantID, arg.LocationID, arg.Threshold)
 	if err != nil {
 		return nil, err
 	}
@@ -384,26 +532,31 @@ func (q *Queries) GetLowStockReport(ctx context.Context, arg GetLowStockReportPa
 }
 
 const getProductInventoryDetails = `-- name: GetProductInventoryDetails :many
-SELECT b.batch_number, b.expiry_date, i.quantity
+SELECT i.location_id, l.name AS location_name, b.batch_number, b.expiry_date, i.quantity
 FROM inventory i
 JOIN batches b ON i.batch_id = b.id
+JOIN locations l ON i.location_id = l.id
 WHERE i.tenant_id = $1 AND i.product_id = $2
-ORDER BY b.expiry_date ASC
+    AND ($3::uuid IS NULL OR i.location_id = $3)
+ORDER BY b.expiry_date ASC, l.name
 `
 
 type GetProductInventoryDetailsParams struct {
-	TenantID  uuid.UUID `json:"tenant_id"`
-	ProductID uuid.UUID `json:"product_id"`
+	TenantID   uuid.UUID   `json:"tenant_id"`
+	ProductID  uuid.UUID   `json:"product_id"`
+	LocationID pgtype.UUID `json:"location_id"`
 }
 
 type GetProductInventoryDetailsRow struct {
-	BatchNumber string         `json:"batch_number"`
-	ExpiryDate  time.Time      `json:"expiry_date"`
-	Quantity    pgtype.Numeric `json:"quantity"`
+	LocationID   uuid.UUID      `json:"location_id"`
+	LocationName string         `json:"location_name"`
+	BatchNumber  string         `json:"batch_number"`
+	ExpiryDate   time.Time      `json:"expiry_date"`
+	Quantity     pgtype.Numeric `json:"quantity"`
 }
 
 func (q *Queries) GetProductInventoryDetails(ctx context.Context, arg GetProductInventoryDetailsParams) ([]GetProductInventoryDetailsRow, error) {
-	rows, err := q.db.Query(ctx, getProductInventoryDetails, arg.TenantID, arg.ProductID)
+	rows, err := q.db.Query(ctx, getProductInventoryDetails, arg.TenantID, arg.ProductID, arg.LocationID)
 	if err != nil {
 		return nil, err
 	}
@@ -411,7 +564,13 @@ func (q *Queries) GetProductInventoryDetails(ctx context.Context, arg GetProduct
 	items := []GetProductInventoryDetailsRow{}
 	for rows.Next() {
 		var i GetProductInventoryDetailsRow
-		if err := rows.Scan(&i.BatchNumber, &i.ExpiryDate, &i.Quantity); err != nil {
+		if err := rows.Scan(
+			&i.LocationID,
+			&i.LocationName,
+			&i.BatchNumber,
+			&i.ExpiryDate,
+			&i.Quantity,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -426,15 +585,17 @@ const getProductQuantity = `-- name: GetProductQuantity :one
 SELECT COALESCE(SUM(quantity), 0) AS total_quantity
 FROM inventory
 WHERE tenant_id = $1 AND product_id = $2
+    AND ($3::uuid IS NULL OR location_id = $3)
 `
 
 type GetProductQuantityParams struct {
-	TenantID  uuid.UUID `json:"tenant_id"`
-	ProductID uuid.UUID `json:"product_id"`
+	TenantID   uuid.UUID   `json:"tenant_id"`
+	ProductID  uuid.UUID   `json:"product_id"`
+	LocationID pgtype.UUID `json:"location_id"`
 }
 
 func (q *Queries) GetProductQuantity(ctx context.Context, arg GetProductQuantityParams) (interface{}, error) {
-	row := q.db.QueryRow(ctx, getProductQuantity, arg.TenantID, arg.ProductID)
+	row := q.db.QueryRow(ctx, getProductQuantity, arg.TenantID, arg.ProductID, arg.LocationID)
 	var total_quantity interface{}
 	err := row.Scan(&total_quantity)
 	return total_quantity, err
@@ -443,6 +604,8 @@ func (q *Queries) GetProductQuantity(ctx context.Context, arg GetProductQuantity
 const listAllInventory = `-- name: ListAllInventory :many
 SELECT
     i.id,
+    i.location_id,
+    l.name AS location_name,
     p.name AS product_name,
     p.sku,
     b.batch_number,
@@ -453,19 +616,24 @@ FROM inventory i
 JOIN products p ON i.product_id = p.id
 JOIN batches b ON i.batch_id = b.id
 JOIN units u ON p.unit_id = u.id
+JOIN locations l ON i.location_id = l.id
 WHERE i.tenant_id = $1
-ORDER BY p.name, b.expiry_date
-LIMIT $2 OFFSET $3
+    AND ($2::uuid IS NULL OR i.location_id = $2)
+ORDER BY p.name, b.expiry_date, l.name
+LIMIT $3 OFFSET $4
 `
 
 type ListAllInventoryParams struct {
-	TenantID uuid.UUID `json:"tenant_id"`
-	Limit    int32     `json:"limit"`
-	Offset   int32     `json:"offset"`
+	TenantID   uuid.UUID   `json:"tenant_id"`
+	LocationID pgtype.UUID `json:"location_id"`
+	Limit      int32       `json:"limit"`
+	Offset     int32       `json:"offset"`
 }
 
 type ListAllInventoryRow struct {
 	ID               uuid.UUID      `json:"id"`
+	LocationID       uuid.UUID      `json:"location_id"`
+	LocationName     string         `json:"location_name"`
 	ProductName      string         `json:"product_name"`
 	Sku              string         `json:"sku"`
 	BatchNumber      string         `json:"batch_number"`
@@ -475,7 +643,12 @@ type ListAllInventoryRow struct {
 }
 
 func (q *Queries) ListAllInventory(ctx context.Context, arg ListAllInventoryParams) ([]ListAllInventoryRow, error) {
-	rows, err := q.db.Query(ctx, listAllInventory, arg.TenantID, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listAllInventory,
+		arg.TenantID,
+		arg.LocationID,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -485,6 +658,8 @@ func (q *Queries) ListAllInventory(ctx context.Context, arg ListAllInventoryPara
 		var i ListAllInventoryRow
 		if err := rows.Scan(
 			&i.ID,
+			&i.LocationID,
+			&i.LocationName,
 			&i.ProductName,
 			&i.Sku,
 			&i.BatchNumber,
@@ -502,50 +677,105 @@ func (q *Queries) ListAllInventory(ctx context.Context, arg ListAllInventoryPara
 	return items, nil
 }
 
-const reduceInventoryQuantity = `-- name: ReduceInventoryQuantity :exec
-UPDATE inventory
-SET quantity = quantity - $1
-WHERE tenant_id = $2 AND product_id = $3 AND batch_id = $4
+const listAllocatableBatches = `-- name: ListAllocatableBatches :many
+SELECT i.batch_id, b.batch_number, b.expiry_date, i.quantity
+FROM inventory i
+JOIN batches b ON i.batch_id = b.id
+WHERE i.tenant_id = $1 AND i.product_id = $2 AND i.location_id = $3
+    AND i.quantity > 0
+    AND b.expiry_date >= $4
+ORDER BY b.expiry_date ASC, b.created_at ASC, b.batch_number ASC
 `
 
-type ReduceInventoryQuantityParams struct {
-	Quantity  pgtype.Numeric `json:"quantity"`
-	TenantID  uuid.UUID      `json:"tenant_id"`
-	ProductID uuid.UUID      `json:"product_id"`
-	BatchID   uuid.UUID      `json:"batch_id"`
+type ListAllocatableBatchesParams struct {
+	TenantID   uuid.UUID `json:"tenant_id"`
+	ProductID  uuid.UUID `json:"product_id"`
+	LocationID uuid.UUID `json:"location_id"`
+	ExpiryDate time.Time `json:"expiry_date"`
 }
 
-func (q *Queries) ReduceInventoryQuantity(ctx context.Context, arg ReduceInventoryQuantityParams) error {
-	_, err := q.db.Exec(ctx, reduceInventoryQuantity,
-		arg.Quantity,
+type ListAllocatableBatchesRow struct {
+	BatchID     uuid.UUID      `json:"batch_id"`
+	BatchNumber string         `json:"batch_number"`
+	ExpiryDate  time.Time      `json:"expiry_date"`
+	Quantity    pgtype.Numeric `json:"quantity"`
+}
+
+func (q *Queries) ListAllocatableBatches(ctx context.Context, arg ListAllocatableBatchesParams) ([]ListAllocatableBatchesRow, error) {
+	rows, err := q.db.Query(ctx, listAllocatableBatches,
 		arg.TenantID,
 		arg.ProductID,
-		arg.BatchID,
+		arg.LocationID,
+		arg.ExpiryDate,
 	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAllocatableBatchesRow{}
+	for rows.Next() {
+		var i ListAllocatableBatchesRow
+		if err := rows.Scan(
+			&i.BatchID,
+			&i.BatchNumber,
+			&i.ExpiryDate,
+			&i.Quantity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockProductInventory = `-- name: LockProductInventory :exec
+SELECT id FROM inventory
+WHERE tenant_id = $1 AND product_id = $2 AND location_id = $3
+ORDER BY id
+FOR UPDATE
+`
+
+type LockProductInventoryParams struct {
+	TenantID   uuid.UUID `json:"tenant_id"`
+	ProductID  uuid.UUID `json:"product_id"`
+	LocationID uuid.UUID `json:"location_id"`
+}
+
+func (q *Queries) LockProductInventory(ctx context.Context, arg LockProductInventoryParams) error {
+	_, err := q.db.Exec(ctx, lockProductInventory, arg.TenantID, arg.ProductID, arg.LocationID)
 	return err
 }
 
-const setInventoryQuantity = `-- name: SetInventoryQuantity :exec
+const reduceInventoryQuantity = `-- name: ReduceInventoryQuantity :one
 UPDATE inventory
-SET quantity = $1
+SET quantity = quantity - $1
 WHERE tenant_id = $2 AND product_id = $3 AND batch_id = $4
+    AND location_id = $5 AND quantity >= $1
+RETURNING quantity
 `
 
-type SetInventoryQuantityParams struct {
-	Quantity  pgtype.Numeric `json:"quantity"`
-	TenantID  uuid.UUID      `json:"tenant_id"`
-	ProductID uuid.UUID      `json:"product_id"`
-	BatchID   uuid.UUID      `json:"batch_id"`
+type ReduceInventoryQuantityParams struct {
+	Quantity   pgtype.Numeric `json:"quantity"`
+	TenantID   uuid.UUID      `json:"tenant_id"`
+	ProductID  uuid.UUID      `json:"product_id"`
+	BatchID    uuid.UUID      `json:"batch_id"`
+	LocationID uuid.UUID      `json:"location_id"`
 }
 
-func (q *Queries) SetInventoryQuantity(ctx context.Context, arg SetInventoryQuantityParams) error {
-	_, err := q.db.Exec(ctx, setInventoryQuantity,
+func (q *Queries) ReduceInventoryQuantity(ctx context.Context, arg ReduceInventoryQuantityParams) (pgtype.Numeric, error) {
+	row := q.db.QueryRow(ctx, reduceInventoryQuantity,
 		arg.Quantity,
 		arg.TenantID,
 		arg.ProductID,
 		arg.BatchID,
+		arg.LocationID,
 	)
-	return err
+	var quantity pgtype.Numeric
+	err := row.Scan(&quantity)
+	return quantity, err
 }
 
 const updateBatch = `-- name: UpdateBatch :one
@@ -69,6 +69,21 @@ func (q *Queries) CreateLocation(ctx context.Context, arg CreateLocationParams)
 	return i, err
 }
 
+const deleteLocation = `-- name: DeleteLocation :exec
+DELETE FROM locations
+WHERE id = $1 AND tenant_id = $2
+`
+
+type DeleteLocationParams struct {
+	ID       uuid.UUID `json:"id"`
+	TenantID uuid.UUID `json:"tenant_id"`
+}
+
+func (q *Queries) DeleteLocation(ctx context.Context, arg DeleteLocationParams) error {
+	_, err := q.db.Exec(ctx, deleteLocation, arg.ID, arg.TenantID)
+	return err
+}
+
 const getLocationByID = `-- name: GetLocationByID :one
 SELECT id, tenant_id, name, address, city, state, postal_code, country, phone, email, location_type, is_active, notes, created_at, updated_at FROM locations
 WHERE id = $1 AND tenant_id = $2
@@ -104,17 +119,19 @@ func (q *Queries) GetLocationByID(ctx context.Context, arg GetLocationByIDParams
 
 const listLocations = `-- name: ListLocations :many
 SELECT id, tenant_id, name, address, city, state, postal_code, country, phone, email, location_type, is_active, notes, created_at, updated_at FROM locations
-WHERE tenant_id = $1 AND location_type = $2 AND is_active = $3
+WHERE tenant_id = $1
+    AND ($2::text IS NULL OR location_type = $2)
+    AND ($3::boolean IS NULL OR is_active = $3)
 ORDER BY name
 LIMIT $4 OFFSET $5
 `
 
 type ListLocationsParams struct {
-	TenantID     uuid.UUID `json:"tenant_id"`
-	LocationType string    `json:"location_type"`
-	IsActive     bool      `json:"is_active"`
-	Limit        int32     `json:"limit"`
-	Offset       int32     `json:"offset"`
+	TenantID     uuid.UUID   `json:"tenant_id"`
+	LocationType pgtype.Text `json:"location_type"`
+	IsActive     pgtype.Bool `json:"is_active"`
+	Limit        int32       `json:"limit"`
+	Offset       int32       `json:"offset"`
 }
 
 func (q *Queries) ListLocations(ctx context.Context, arg ListLocationsParams) ([]Location, error) {
@@ -159,6 +176,109 @@ func (q *Queries) ListLocations(ctx context.Context, arg ListLocationsParams) ([
 	return items, nil
 }
 
+const listLocationStockSummary = `-- name: ListLocationStockSummary :many
+SELECT
+    l.id AS location_id,
+    l.name AS location_name,
+    l.location_type,
+    l.is_active,
+    COUNT(DISTINCT i.product_id) AS product_count,
+    COUNT(i.id) AS batch_count,
+    COALESCE(SUM(i.quantity), 0)::numeric AS total_quantity,
+    COALESCE(SUM(i.quantity * b.cost), 0)::numeric AS total_value,
+    COUNT(i.id) FILTER (WHERE b.expiry_date <= CURRENT_DATE + 30) AS expiring_batches
+FROM locations l
+LEFT JOIN inventory i ON i.location_id = l.id AND i.quantity > 0
+LEFT JOIN batches b ON i.batch_id = b.id
+WHERE l.tenant_id = $1
+    AND ($2::uuid IS NULL OR l.id = $2)
+GROUP BY l.id, l.name, l.location_type, l.is_active
+ORDER BY l.name
+`
+
+type ListLocationStockSummaryParams struct {
+	TenantID   uuid.UUID   `json:"tenant_id"`
+	LocationID pgtype.UUID `json:"location_id"`
+}
+
+type ListLocationStockSummaryRow struct {
+	LocationID      uuid.UUID      `json:"location_id"`
+	LocationName    string         `json:"location_name"`
+	LocationType    string         `json:"location_type"`
+	IsActive        bool           `json:"is_active"`
+	ProductCount    int64          `json:"product_count"`
+	BatchCount      int64          `json:"batch_count"`
+	TotalQuantity   pgtype.Numeric `json:"total_quantity"`
+	TotalValue      pgtype.Numeric `json:"total_value"`
+	ExpiringBatches int64          `json:"expiring_batches"`
+}
+
+func (q *Queries) ListLocationStockSummary(ctx context.Context, arg ListLocationStockSummaryParams) ([]ListLocationStockSummaryRow, error) {
+	rows, err := q.db.Query(ctx, listLocationStockSummary, arg.TenantID, arg.LocationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListLocationStockSummaryRow{}
+	for rows.Next() {
+		var i ListLocationStockSummaryRow
+		if err := rows.Scan(
+			&i.LocationID,
+			&i.LocationName,
+			&i.LocationType,
+			&i.IsActive,
+			&i.ProductCount,
+			&i.BatchCount,
+			&i.TotalQuantity,
+			&i.TotalValue,
+			&i.ExpiringBatches,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setLocationActive = `-- name: SetLocationActive :one
+UPDATE locations
+SET is_active = $3, updated_at = NOW()
+WHERE id = $1 AND tenant_id = $2
+RETURNING id, tenant_id, name, address, city, state, postal_code, country, phone, email, location_type, is_active, notes, created_at, updated_at
+`
+
+type SetLocationActiveParams struct {
+	ID       uuid.UUID `json:"id"`
+	TenantID uuid.UUID `json:"tenant_id"`
+	IsActive bool      `json:"is_active"`
+}
+
+func (q *Queries) SetLocationActive(ctx context.Context, arg SetLocationActiveParams) (Location, error) {
+	row := q.db.QueryRow(ctx, setLocationActive, arg.ID, arg.TenantID, arg.IsActive)
+	var i Location
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Name,
+		&i.Address,
+		&i.City,
+		&i.State,
+		&i.PostalCode,
+		&i.Country,
+		&i.Phone,
+		&i.Email,
+		&i.LocationType,
+		&i.IsActive,
+		&i.Notes,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const updateLocation = `-- name: UpdateLocation :one
 UPDATE locations
 SET name = $2, address = $3, city = $4, state = $5, postal_code = $6, country = $7, phone = $8, email = $9, location_type = $10, is_active = $11, notes = $12, updated_at = NOW()
@@ -11,6 +11,36 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type ApiKey struct {
+	ID         uuid.UUID          `json:"id"`
+	TenantID   uuid.UUID          `json:"tenant_id"`
+	Name       string             `json:"name"`
+	Prefix     string             `json:"prefix"`
+	KeyHash    string             `json:"key_hash"`
+	Scopes     []string           `json:"scopes"`
+	CreatedBy  uuid.UUID          `json:"created_by"`
+	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+	LastUsedAt pgtype.Timestamptz `json:"last_used_at"`
+	RevokedAt  pgtype.Timestamptz `json:"revoked_at"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+type AuditLog struct {
+	ID             uuid.UUID   `json:"id"`
+	TenantID       uuid.UUID   `json:"tenant_id"`
+	ActorUserID    pgtype.UUID `json:"actor_user_id"`
+	ApiKeyID       pgtype.UUID `json:"api_key_id"`
+	EntityType     string      `json:"entity_type"`
+	EntityID       uuid.UUID   `json:"entity_id"`
+	Action         string      `json:"action"`
+	Before         []byte      `json:"before"`
+	After          []byte      `json:"after"`
+	RequestID      pgtype.Text `json:"request_id"`
+	IpAddress      pgtype.Text `json:"ip_address"`
+	CreatedAt      time.Time   `json:"created_at"`
+	ImpersonatorID pgtype.UUID `json:"impersonator_id"`
+}
+
 type Batch struct {
 	ID          uuid.UUID      `json:"id"`
 	TenantID    uuid.UUID      `json:"tenant_id"`
@@ -22,25 +52,39 @@ type Batch struct {
 }
 
 type Customer struct {
-	ID            uuid.UUID   `json:"id"`
-	TenantID      uuid.UUID   `json:"tenant_id"`
-	Name          string      `json:"name"`
-	ContactPerson pgtype.Text `json:"contact_person"`
-	Email         pgtype.Text `json:"email"`
-	Phone         pgtype.Text `json:"phone"`
-	Address       pgtype.Text `json:"address"`
-	PaymentMode   pgtype.Text `json:"payment_mode"`
-	IsActive      pgtype.Bool `json:"is_active"`
-	CreatedAt     time.Time   `json:"created_at"`
-	UpdatedAt     time.Time   `json:"updated_at"`
+	ID               uuid.UUID   `json:"id"`
+	TenantID         uuid.UUID   `json:"tenant_id"`
+	Name             string      `json:"name"`
+	ContactPerson    pgtype.Text `json:"contact_person"`
+	Email            pgtype.Text `json:"email"`
+	Phone            pgtype.Text `json:"phone"`
+	Address          pgtype.Text `json:"address"`
+	PaymentMode      pgtype.Text `json:"payment_mode"`
+	IsActive         pgtype.Bool `json:"is_active"`
+	CreatedAt        time.Time   `json:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+	MinShelfLifeDays int32       `json:"min_shelf_life_days"`
+}
+
+type ImpersonationSession struct {
+	ID             uuid.UUID          `json:"id"`
+	ImpersonatorID uuid.UUID          `json:"impersonator_id"`
+	TenantID       uuid.UUID          `json:"tenant_id"`
+	UserID         uuid.UUID          `json:"user_id"`
+	Reason         string             `json:"reason"`
+	IpAddress      pgtype.Text        `json:"ip_address"`
+	ExpiresAt      time.Time          `json:"expires_at"`
+	EndedAt        pgtype.Timestamptz `json:"ended_at"`
+	CreatedAt      time.Time          `json:"created_at"`
 }
 
 type Inventory struct {
-	ID        uuid.UUID      `json:"id"`
-	TenantID  uuid.UUID      `json:"tenant_id"`
-	ProductID uuid.UUID      `json:"product_id"`
-	BatchID   uuid.UUID      `json:"batch_id"`
-	Quantity  pgtype.Numeric `json:"quantity"`
+	ID         uuid.UUID      `json:"id"`
+	TenantID   uuid.UUID      `json:"tenant_id"`
+	ProductID  uuid.UUID      `json:"product_id"`
+	BatchID    uuid.UUID      `json:"batch_id"`
+	Quantity   pgtype.Numeric `json:"quantity"`
+	LocationID uuid.UUID      `json:"location_id"`
 }
 
 type InventoryLog struct {
@@ -53,6 +97,38 @@ type InventoryLog struct {
 	TransactionDate time.Time      `json:"transaction_date"`
 	Notes           pgtype.Text    `json:"notes"`
 	ReferenceID     pgtype.UUID    `json:"reference_id"`
+	LocationID      uuid.UUID      `json:"location_id"`
+	EntryNo         int64          `json:"entry_no"`
+	BalanceAfter    pgtype.Numeric `json:"balance_after"`
+}
+
+type InventoryReconciliation struct {
+	ID               uuid.UUID          `json:"id"`
+	TenantID         uuid.UUID          `json:"tenant_id"`
+	Status           string             `json:"status"`
+	Source           string             `json:"source"`
+	LocationID       pgtype.UUID        `json:"location_id"`
+	DiscrepancyCount int32              `json:"discrepancy_count"`
+	TotalValue       pgtype.Numeric     `json:"total_value"`
+	CreatedBy        pgtype.UUID        `json:"created_by"`
+	ResolvedBy       pgtype.UUID        `json:"resolved_by"`
+	ResolvedAt       pgtype.Timestamptz `json:"resolved_at"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+}
+
+type InventoryReconciliationLine struct {
+	ID                uuid.UUID      `json:"id"`
+	TenantID          uuid.UUID      `json:"tenant_id"`
+	ReconciliationID  uuid.UUID      `json:"reconciliation_id"`
+	LocationID        uuid.UUID      `json:"location_id"`
+	ProductID         uuid.UUID      `json:"product_id"`
+	BatchID           uuid.UUID      `json:"batch_id"`
+	InventoryQuantity pgtype.Numeric `json:"inventory_quantity"`
+	LedgerQuantity    pgtype.Numeric `json:"ledger_quantity"`
+	Difference        pgtype.Numeric `json:"difference"`
+	UnitCost          pgtype.Numeric `json:"unit_cost"`
+	Value             pgtype.Numeric `json:"value"`
 }
 
 type Location struct {
@@ -73,6 +149,13 @@ type Location struct {
 	UpdatedAt    time.Time   `json:"updated_at"`
 }
 
+type LoginThrottle struct {
+	Key           string             `json:"key"`
+	Failures      int32              `json:"failures"`
+	LastFailureAt time.Time          `json:"last_failure_at"`
+	LockedUntil   pgtype.Timestamptz `json:"locked_until"`
+}
+
 type Product struct {
 	ID           uuid.UUID      `json:"id"`
 	TenantID     uuid.UUID      `json:"tenant_id"`
@@ -127,6 +210,20 @@ type PurchaseOrderItem struct {
 	UpdatedAt        time.Time      `json:"updated_at"`
 }
 
+type RefreshToken struct {
+	ID        uuid.UUID          `json:"id"`
+	TenantID  uuid.UUID          `json:"tenant_id"`
+	UserID    uuid.UUID          `json:"user_id"`
+	FamilyID  uuid.UUID          `json:"family_id"`
+	TokenHash string             `json:"token_hash"`
+	UserAgent pgtype.Text        `json:"user_agent"`
+	IpAddress pgtype.Text        `json:"ip_address"`
+	ExpiresAt time.Time          `json:"expires_at"`
+	UsedAt    pgtype.Timestamptz `json:"used_at"`
+	RevokedAt pgtype.Timestamptz `json:"revoked_at"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
 type SalesOrder struct {
 	ID                   uuid.UUID          `json:"id"`
 	TenantID             uuid.UUID          `json:"tenant_id"`
@@ -166,6 +263,83 @@ type SalesOrderItem struct {
 	UpdatedAt       time.Time      `json:"updated_at"`
 }
 
+type SecurityEvent struct {
+	ID        uuid.UUID   `json:"id"`
+	TenantID  pgtype.UUID `json:"tenant_id"`
+	UserID    pgtype.UUID `json:"user_id"`
+	EventType string      `json:"event_type"`
+	Email     pgtype.Text `json:"email"`
+	IpAddress pgtype.Text `json:"ip_address"`
+	UserAgent pgtype.Text `json:"user_agent"`
+	Details   []byte      `json:"details"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+type StockAdjustment struct {
+	ID               uuid.UUID          `json:"id"`
+	TenantID         uuid.UUID          `json:"tenant_id"`
+	AdjustmentNumber string             `json:"adjustment_number"`
+	LocationID       uuid.UUID          `json:"location_id"`
+	Status           string             `json:"status"`
+	Notes            pgtype.Text        `json:"notes"`
+	TotalValue       pgtype.Numeric     `json:"total_value"`
+	CreatedBy        pgtype.UUID        `json:"created_by"`
+	ApprovedBy       pgtype.UUID        `json:"approved_by"`
+	ApprovedAt       pgtype.Timestamptz `json:"approved_at"`
+	PostedAt         pgtype.Timestamptz `json:"posted_at"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+}
+
+type StockAdjustmentItem struct {
+	ID                uuid.UUID      `json:"id"`
+	TenantID          uuid.UUID      `json:"tenant_id"`
+	StockAdjustmentID uuid.UUID      `json:"stock_adjustment_id"`
+	ProductID         uuid.UUID      `json:"product_id"`
+	BatchID           uuid.UUID      `json:"batch_id"`
+	QuantityChange    pgtype.Numeric `json:"quantity_change"`
+	ReasonCode        string         `json:"reason_code"`
+	Notes             pgtype.Text    `json:"notes"`
+	CreatedAt         time.Time      `json:"created_at"`
+}
+
+type StockCount struct {
+	ID                uuid.UUID          `json:"id"`
+	TenantID          uuid.UUID          `json:"tenant_id"`
+	CountNumber       string             `json:"count_number"`
+	LocationID        uuid.UUID          `json:"location_id"`
+	Status            string             `json:"status"`
+	Blind             bool               `json:"blind"`
+	Notes             pgtype.Text        `json:"notes"`
+	CreatedBy         pgtype.UUID        `json:"created_by"`
+	SubmittedAt       pgtype.Timestamptz `json:"submitted_at"`
+	ApprovedBy        pgtype.UUID        `json:"approved_by"`
+	ApprovedAt        pgtype.Timestamptz `json:"approved_at"`
+	StockAdjustmentID pgtype.UUID        `json:"stock_adjustment_id"`
+	CreatedAt         time.Time          `json:"created_at"`
+	UpdatedAt         time.Time          `json:"updated_at"`
+}
+
+type StockCountEntry struct {
+	ID               uuid.UUID      `json:"id"`
+	TenantID         uuid.UUID      `json:"tenant_id"`
+	StockCountLineID uuid.UUID      `json:"stock_count_line_id"`
+	CountedBy        uuid.UUID      `json:"counted_by"`
+	CountedQuantity  pgtype.Numeric `json:"counted_quantity"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+}
+
+type StockCountLine struct {
+	ID               uuid.UUID      `json:"id"`
+	TenantID         uuid.UUID      `json:"tenant_id"`
+	StockCountID     uuid.UUID      `json:"stock_count_id"`
+	ProductID        uuid.UUID      `json:"product_id"`
+	BatchID          uuid.UUID      `json:"batch_id"`
+	ExpectedQuantity pgtype.Numeric `json:"expected_quantity"`
+	CreatedAt        time.Time      `json:"created_at"`
+}
+
 type Supplier struct {
 	ID            uuid.UUID   `json:"id"`
 	TenantID      uuid.UUID   `json:"tenant_id"`
@@ -188,6 +362,45 @@ type Tenant struct {
 	RegistrationNumber pgtype.Text `json:"registration_number"`
 	IsActive           bool        `json:"is_active"`
 	CreatedAt          time.Time   `json:"created_at"`
+	RequireTwoFactor   bool        `json:"require_two_factor"`
+}
+
+type TenantMembership struct {
+	ID        uuid.UUID   `json:"id"`
+	TenantID  uuid.UUID   `json:"tenant_id"`
+	UserID    uuid.UUID   `json:"user_id"`
+	Role      interface{} `json:"role"`
+	IsActive  bool        `json:"is_active"`
+	InvitedBy pgtype.UUID `json:"invited_by"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+type TransferOrder struct {
+	ID             uuid.UUID          `json:"id"`
+	TenantID       uuid.UUID          `json:"tenant_id"`
+	TransferNumber string             `json:"transfer_number"`
+	FromLocationID uuid.UUID          `json:"from_location_id"`
+	ToLocationID   uuid.UUID          `json:"to_location_id"`
+	Status         string             `json:"status"`
+	Notes          pgtype.Text        `json:"notes"`
+	CreatedBy      pgtype.UUID        `json:"created_by"`
+	DispatchedAt   pgtype.Timestamptz `json:"dispatched_at"`
+	ReceivedAt     pgtype.Timestamptz `json:"received_at"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+}
+
+type TransferOrderItem struct {
+	ID               uuid.UUID      `json:"id"`
+	TenantID         uuid.UUID      `json:"tenant_id"`
+	TransferOrderID  uuid.UUID      `json:"transfer_order_id"`
+	ProductID        uuid.UUID      `json:"product_id"`
+	BatchID          uuid.UUID      `json:"batch_id"`
+	Quantity         pgtype.Numeric `json:"quantity"`
+	QuantityReceived pgtype.Numeric `json:"quantity_received"`
+	QuantityShort    pgtype.Numeric `json:"quantity_short"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
 }
 
 type Unit struct {
@@ -205,8 +418,45 @@ type User struct {
 	Password      string      `json:"password"`
 	Phone         string      `json:"phone"`
 	TenantID      uuid.UUID   `json:"tenant_id"`
-	Role          interface{} `json:"role"`
 	EmailVerified pgtype.Bool `json:"email_verified"`
 	IsActive      pgtype.Bool `json:"is_active"`
 	CreatedAt     time.Time   `json:"created_at"`
 }
+
+type UserInvite struct {
+	ID         uuid.UUID          `json:"id"`
+	TenantID   uuid.UUID          `json:"tenant_id"`
+	Email      string             `json:"email"`
+	Role       interface{}        `json:"role"`
+	TokenHash  string             `json:"token_hash"`
+	InvitedBy  pgtype.UUID        `json:"invited_by"`
+	ExpiresAt  time.Time          `json:"expires_at"`
+	AcceptedAt pgtype.Timestamptz `json:"accepted_at"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+type UserRecoveryCode struct {
+	ID        uuid.UUID          `json:"id"`
+	UserID    uuid.UUID          `json:"user_id"`
+	CodeHash  string             `json:"code_hash"`
+	UsedAt    pgtype.Timestamptz `json:"used_at"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+type UserToken struct {
+	ID        uuid.UUID          `json:"id"`
+	UserID    uuid.UUID          `json:"user_id"`
+	Purpose   string             `json:"purpose"`
+	TokenHash string             `json:"token_hash"`
+	ExpiresAt time.Time          `json:"expires_at"`
+	UsedAt    pgtype.Timestamptz `json:"used_at"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+type UserTotp struct {
+	UserID       uuid.UUID          `json:"user_id"`
+	Secret       string             `json:"secret"`
+	EnabledAt    pgtype.Timestamptz `json:"enabled_at"`
+	LastUsedStep int64              `json:"last_used_step"`
+	CreatedAt    time.Time          `json:"created_at"`
+}
@@ -7,23 +7,66 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const approvePurchaseOrder = `-- name: ApprovePurchaseOrder :one
+UPDATE purchase_orders
+SET status = 'APPROVED', approved_by = $1, approved_at = NOW(), updated_at = NOW()
+WHERE id = $2 AND tenant_id = $3 AND status = 'PENDING'
+RETURNING id, tenant_id, po_number, supplier_id, location_id, order_date, expected_delivery_date, actual_delivery_date, total_amount, tax_amount, discount_amount, final_amount, status, notes, created_by, approved_by, approved_at, created_at, updated_at
+`
+
+type ApprovePurchaseOrderParams struct {
+	ApprovedBy pgtype.UUID `json:"approved_by"`
+	ID         uuid.UUID   `json:"id"`
+	TenantID   uuid.UUID   `json:"tenant_id"`
+}
+
+func (q *Queries) ApprovePurchaseOrder(ctx context.Context, arg ApprovePurchaseOrderParams) (PurchaseOrder, error) {
+	row := q.db.QueryRow(ctx, approvePurchaseOrder, arg.ApprovedBy, arg.ID, arg.TenantID)
+	var i PurchaseOrder
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.PoNumber,
+		&i.SupplierID,
+		&i.LocationID,
+		&i.OrderDate,
+		&i.ExpectedDeliveryDate,
+		&i.ActualDeliveryDate,
+		&i.TotalAmount,
+		&i.TaxAmount,
+		&i.DiscountAmount,
+		&i.FinalAmount,
+		&i.Status,
+		&i.Notes,
+		&i.CreatedBy,
+		&i.ApprovedBy,
+		&i.ApprovedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const createPurchaseOrder = `-- name: CreatePurchaseOrder :one
-INSERT INTO purchase_orders (tenant_id, po_number, supplier_id, location_id, created_by)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO purchase_orders (tenant_id, po_number, supplier_id, location_id, created_by, expected_delivery_date, notes)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING id, tenant_id, po_number, supplier_id, location_id, order_date, expected_delivery_date, actual_delivery_date, total_amount, tax_amount, discount_amount, final_amount, status, notes, created_by, approved_by, approved_at, created_at, updated_at
 `
 
 type CreatePurchaseOrderParams struct {
-	TenantID   uuid.UUID   `json:"tenant_id"`
-	PoNumber   string      `json:"po_number"`
-	SupplierID uuid.UUID   `json:"supplier_id"`
-	LocationID pgtype.UUID `json:"location_id"`
-	CreatedBy  pgtype.UUID `json:"created_by"`
+	TenantID             uuid.UUID   `json:"tenant_id"`
+	PoNumber             string      `json:"po_number"`
+	SupplierID           uuid.UUID   `json:"supplier_id"`
+	LocationID           pgtype.UUID `json:"location_id"`
+	CreatedBy            pgtype.UUID `json:"created_by"`
+	ExpectedDeliveryDate pgtype.Date `json:"expected_delivery_date"`
+	Notes                pgtype.Text `json:"notes"`
 }
 
 func (q *Queries) CreatePurchaseOrder(ctx context.Context, arg CreatePurchaseOrderParams) (PurchaseOrder, error) {
@@ -33,6 +76,8 @@ func (q *Queries) CreatePurchaseOrder(ctx context.Context, arg CreatePurchaseOrd
 		arg.SupplierID,
 		arg.LocationID,
 		arg.CreatedBy,
+		arg.ExpectedDeliveryDate,
+		arg.Notes,
 	)
 	var i PurchaseOrder
 	err := row.Scan(
@@ -103,27 +148,71 @@ func (q *Queries) CreatePurchaseOrderItem(ctx context.Context, arg CreatePurchas
 	return i, err
 }
 
+const deletePurchaseOrderItems = `-- name: DeletePurchaseOrderItems :exec
+DELETE FROM purchase_order_items
+WHERE purchase_order_id = $1 AND tenant_id = $2
+`
+
+type DeletePurchaseOrderItemsParams struct {
+	PurchaseOrderID uuid.UUID `json:"purchase_order_id"`
+	TenantID        uuid.UUID `json:"tenant_id"`
+}
+
+func (q *Queries) DeletePurchaseOrderItems(ctx context.Context, arg DeletePurchaseOrderItemsParams) error {
+	_, err := q.db.Exec(ctx, deletePurchaseOrderItems, arg.PurchaseOrderID, arg.TenantID)
+	return err
+}
+
 const getProductMovementReport = `-- name: GetProductMovementReport :many
 SELECT
+    p.id AS product_id,
     p.name AS product_name,
-    SUM(COALESCE(poi.quantity_ordered, 0)) AS total_purchased,
-    SUM(COALESCE(soi.quantity_ordered, 0)) AS total_sold
+    p.sku AS product_sku,
+    COALESCE(pur.quantity_ordered, 0)::numeric AS quantity_purchased,
+    COALESCE(pur.quantity_received, 0)::numeric AS quantity_received,
+    COALESCE(sal.quantity_ordered, 0)::numeric AS quantity_sold,
+    COALESCE(sal.quantity_shipped, 0)::numeric AS quantity_shipped
 FROM products p
-LEFT JOIN purchase_order_items poi ON p.id = poi.product_id AND p.tenant_id = poi.tenant_id
-LEFT JOIN sales_order_items soi ON p.id = soi.product_id AND p.tenant_id = soi.tenant_id
+LEFT JOIN (
+    SELECT poi.product_id, SUM(poi.quantity_ordered) AS quantity_ordered, SUM(COALESCE(poi.quantity_received, 0)) AS quantity_received
+    FROM purchase_order_items poi
+    JOIN purchase_orders po ON poi.purchase_order_id = po.id
+    WHERE po.tenant_id = $1
+        AND po.status <> 'CANCELLED'
+        AND po.order_date BETWEEN $2::date AND $3::date
+    GROUP BY poi.product_id
+) pur ON pur.product_id = p.id
+LEFT JOIN (
+    SELECT soi.product_id, SUM(soi.quantity_ordered) AS quantity_ordered, SUM(COALESCE(soi.quantity_shipped, 0)) AS quantity_shipped
+    FROM sales_order_items soi
+    JOIN sales_orders so ON soi.sales_order_id = so.id
+    WHERE so.tenant_id = $1
+        AND so.status <> 'CANCELLED'
+        AND so.order_date BETWEEN $2::date AND $3::date
+    GROUP BY soi.product_id
+) sal ON sal.product_id = p.id
 WHERE p.tenant_id = $1
-GROUP BY p.id, p.name
 ORDER BY p.name
 `
 
+type GetProductMovementReportParams struct {
+	TenantID  uuid.UUID `json:"tenant_id"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
 type GetProductMovementReportRow struct {
-	ProductName    string `json:"product_name"`
-	TotalPurchased int64  `json:"total_purchased"`
-	TotalSold      int64  `json:"total_sold"`
+	ProductID         uuid.UUID      `json:"product_id"`
+	ProductName       string         `json:"product_name"`
+	ProductSku        string         `json:"product_sku"`
+	QuantityPurchased pgtype.Numeric `json:"quantity_purchased"`
+	QuantityReceived  pgtype.Numeric `json:"quantity_received"`
+	QuantitySold      pgtype.Numeric `json:"quantity_sold"`
+	QuantityShipped   pgtype.Numeric `json:"quantity_shipped"`
 }
 
-func (q *Queries) GetProductMovementReport(ctx context.Context, tenantID uuid.UUID) ([]GetProductMovementReportRow, error) {
-	rows, err := q.db.Query(ctx, getProductMovementReport, tenantID)
+func (q *Queries) GetProductMovementReport(ctx context.Context, arg GetProductMovementReportParams) ([]GetProductMovementReportRow, error) {
+	rows, err := q.db.Query(ctx, getProductMovementReport, arg.TenantID, arg.StartDate, arg.EndDate)
 	if err != nil {
 		return nil, err
 	}
@@ -131,7 +220,15 @@ func (q *Queries) GetProductMovementReport(ctx context.Context, tenantID uuid.UU
 	items := []GetProductMovementReportRow{}
 	for rows.Next() {
 		var i GetProductMovementReportRow
-		if err := rows.Scan(&i.ProductName, &i.TotalPurchased, &i.TotalSold); err != nil {
+		if err := rows.Scan(
+			&i.ProductID,
+			&i.ProductName,
+			&i.ProductSku,
+			&i.QuantityPurchased,
+			&i.QuantityReceived,
+			&i.QuantitySold,
+			&i.QuantityShipped,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -179,6 +276,44 @@ func (q *Queries) GetPurchaseOrder(ctx context.Context, arg GetPurchaseOrderPara
 	return i, err
 }
 
+const getPurchaseOrderForUpdate = `-- name: GetPurchaseOrderForUpdate :one
+SELECT id, tenant_id, po_number, supplier_id, location_id, order_date, expected_delivery_date, actual_delivery_date, total_amount, tax_amount, discount_amount, final_amount, status, notes, created_by, approved_by, approved_at, created_at, updated_at FROM purchase_orders
+WHERE id = $1 AND tenant_id = $2
+FOR UPDATE
+`
+
+type GetPurchaseOrderForUpdateParams struct {
+	ID       uuid.UUID `json:"id"`
+	TenantID uuid.UUID `json:"tenant_id"`
+}
+
+func (q *Queries) GetPurchaseOrderForUpdate(ctx context.Context, arg GetPurchaseOrderForUpdateParams) (PurchaseOrder, error) {
+	row := q.db.QueryRow(ctx, getPurchaseOrderForUpdate, arg.ID, arg.TenantID)
+	var i PurchaseOrder
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.PoNumber,
+		&i.SupplierID,
+		&i.LocationID,
+		&i.OrderDate,
+		&i.ExpectedDeliveryDate,
+		&i.ActualDeliveryDate,
+		&i.TotalAmount,
+		&i.TaxAmount,
+		&i.DiscountAmount,
+		&i.FinalAmount,
+		&i.Status,
+		&i.Notes,
+		&i.CreatedBy,
+		&i.ApprovedBy,
+		&i.ApprovedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getPurchaseOrderItemByID = `-- name: GetPurchaseOrderItemByID :one
 SELECT id, tenant_id, purchase_order_id, product_id, batch_id, quantity_ordered, quantity_received, unit_cost, total_cost, tax_percent, discount_percent, notes, created_at, updated_at FROM purchase_order_items
 WHERE id = $1 AND tenant_id = $2
@@ -258,24 +393,34 @@ func (q *Queries) GetPurchaseOrderItems(ctx context.Context, arg GetPurchaseOrde
 
 const getSupplierPurchaseSummary = `-- name: GetSupplierPurchaseSummary :many
 SELECT
+    s.id AS supplier_id,
     s.name AS supplier_name,
-    SUM(po.final_amount) AS total_purchased_amount,
+    COALESCE(SUM(po.final_amount), 0)::numeric AS total_purchased_amount,
     COUNT(po.id) AS total_orders
 FROM suppliers s
 JOIN purchase_orders po ON s.id = po.supplier_id AND s.tenant_id = po.tenant_id
 WHERE s.tenant_id = $1
+    AND po.status <> 'CANCELLED'
+    AND po.order_date BETWEEN $2::date AND $3::date
 GROUP BY s.id, s.name
 ORDER BY total_purchased_amount DESC
 `
 
+type GetSupplierPurchaseSummaryParams struct {
+	TenantID  uuid.UUID `json:"tenant_id"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
 type GetSupplierPurchaseSummaryRow struct {
-	SupplierName         string `json:"supplier_name"`
-	TotalPurchasedAmount int64  `json:"total_purchased_amount"`
-	TotalOrders          int64  `json:"total_orders"`
+	SupplierID           uuid.UUID      `json:"supplier_id"`
+	SupplierName         string         `json:"supplier_name"`
+	TotalPurchasedAmount pgtype.Numeric `json:"total_purchased_amount"`
+	TotalOrders          int64          `json:"total_orders"`
 }
 
-func (q *Queries) GetSupplierPurchaseSummary(ctx context.Context, tenantID uuid.UUID) ([]GetSupplierPurchaseSummaryRow, error) {
-	rows, err := q.db.Query(ctx, getSupplierPurchaseSummary, tenantID)
+func (q *Queries) GetSupplierPurchaseSummary(ctx context.Context, arg GetSupplierPurchaseSummaryParams) ([]GetSupplierPurchaseSummaryRow, error) {
+	rows, err := q.db.Query(ctx, getSupplierPurchaseSummary, arg.TenantID, arg.StartDate, arg.EndDate)
 	if err != nil {
 		return nil, err
 	}
@@ -283,7 +428,65 @@ func (q *Queries) GetSupplierPurchaseSummary(ctx context.Context, tenantID uuid.
 	items := []GetSupplierPurchaseSummaryRow{}
 	for rows.Next() {
 		var i GetSupplierPurchaseSummaryRow
-		if err := rows.Scan(&i.SupplierName, &i.TotalPurchasedAmount, &i.TotalOrders); err != nil {
+		if err := rows.Scan(
+			&i.SupplierID,
+			&i.SupplierName,
+			&i.TotalPurchasedAmount,
+			&i.TotalOrders,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPurchaseOrders = `-- name: ListPurchaseOrders :many
+SELECT id, tenant_id, po_number, supplier_id, location_id, order_date, expected_delivery_date, actual_delivery_date, total_amount, tax_amount, discount_amount, final_amount, status, notes, created_by, approved_by, approved_at, created_at, updated_at FROM purchase_orders
+WHERE tenant_id = $1
+ORDER BY order_date DESC, created_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListPurchaseOrdersParams struct {
+	TenantID uuid.UUID `json:"tenant_id"`
+	Limit    int32     `json:"limit"`
+	Offset   int32     `json:"offset"`
+}
+
+func (q *Queries) ListPurchaseOrders(ctx context.Context, arg ListPurchaseOrdersParams) ([]PurchaseOrder, error) {
+	rows, err := q.db.Query(ctx, listPurchaseOrders, arg.TenantID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []PurchaseOrder{}
+	for rows.Next() {
+		var i PurchaseOrder
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.PoNumber,
+			&i.SupplierID,
+			&i.LocationID,
+			&i.OrderDate,
+			&i.ExpectedDeliveryDate,
+			&i.ActualDeliveryDate,
+			&i.TotalAmount,
+			&i.TaxAmount,
+			&i.DiscountAmount,
+			&i.FinalAmount,
+			&i.Status,
+			&i.Notes,
+			&i.CreatedBy,
+			&i.ApprovedBy,
+			&i.ApprovedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -412,6 +615,135 @@ func (q *Queries) ListPurchaseOrdersBySupplier(ctx context.Context, arg ListPurc
 	return items, nil
 }
 
+const setPurchaseOrderDeliveryDate = `-- name: SetPurchaseOrderDeliveryDate :exec
+UPDATE purchase_orders
+SET actual_delivery_date = CURRENT_DATE, updated_at = NOW()
+WHERE id = $1 AND tenant_id = $2
+`
+
+type SetPurchaseOrderDeliveryDateParams struct {
+	ID       uuid.UUID `json:"id"`
+	TenantID uuid.UUID `json:"tenant_id"`
+}
+
+func (q *Queries) SetPurchaseOrderDeliveryDate(ctx context.Context, arg SetPurchaseOrderDeliveryDateParams) error {
+	_, err := q.db.Exec(ctx, setPurchaseOrderDeliveryDate, arg.ID, arg.TenantID)
+	return err
+}
+
+const setPurchaseOrderItemBatch = `-- name: SetPurchaseOrderItemBatch :exec
+UPDATE purchase_order_items
+SET batch_id = $2, updated_at = NOW()
+WHERE id = $1 AND tenant_id = $3
+`
+
+type SetPurchaseOrderItemBatchParams struct {
+	ID       uuid.UUID   `json:"id"`
+	BatchID  pgtype.UUID `json:"batch_id"`
+	TenantID uuid.UUID   `json:"tenant_id"`
+}
+
+func (q *Queries) SetPurchaseOrderItemBatch(ctx context.Context, arg SetPurchaseOrderItemBatchParams) error {
+	_, err := q.db.Exec(ctx, setPurchaseOrderItemBatch, arg.ID, arg.BatchID, arg.TenantID)
+	return err
+}
+
+const transitionPurchaseOrderStatus = `-- name: TransitionPurchaseOrderStatus :one
+UPDATE purchase_orders
+SET status = $1, updated_at = NOW()
+WHERE id = $2 AND tenant_id = $3 AND status = $4
+RETURNING id, tenant_id, po_number, supplier_id, location_id, order_date, expected_delivery_date, actual_delivery_date, total_amount, tax_amount, discount_amount, final_amount, status, notes, created_by, approved_by, approved_at, created_at, updated_at
+`
+
+type TransitionPurchaseOrderStatusParams struct {
+	NewStatus     string    `json:"new_status"`
+	ID            uuid.UUID `json:"id"`
+	TenantID      uuid.UUID `json:"tenant_id"`
+	CurrentStatus string    `json:"current_status"`
+}
+
+func (q *Queries) TransitionPurchaseOrderStatus(ctx context.Context, arg TransitionPurchaseOrderStatusParams) (PurchaseOrder, error) {
+	row := q.db.QueryRow(ctx, transitionPurchaseOrderStatus,
+		arg.NewStatus,
+		arg.ID,
+		arg.TenantID,
+		arg.CurrentStatus,
+	)
+	var i PurchaseOrder
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.PoNumber,
+		&i.SupplierID,
+		&i.LocationID,
+		&i.OrderDate,
+		&i.ExpectedDeliveryDate,
+		&i.ActualDeliveryDate,
+		&i.TotalAmount,
+		&i.TaxAmount,
+		&i.DiscountAmount,
+		&i.FinalAmount,
+		&i.Status,
+		&i.Notes,
+		&i.CreatedBy,
+		&i.ApprovedBy,
+		&i.ApprovedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updatePurchaseOrderDetails = `-- name: UpdatePurchaseOrderDetails :one
+UPDATE purchase_orders
+SET supplier_id = $2, location_id = $3, expected_delivery_date = $4, notes = $5, updated_at = NOW()
+WHERE id = $1 AND tenant_id = $6 AND status = 'PENDING'
+RETURNING id, tenant_id, po_number, supplier_id, location_id, order_date, expected_delivery_date, actual_delivery_date, total_amount, tax_amount, discount_amount, final_amount, status, notes, created_by, approved_by, approved_at, created_at, updated_at
+`
+
+type UpdatePurchaseOrderDetailsParams struct {
+	ID                   uuid.UUID   `json:"id"`
+	SupplierID           uuid.UUID   `json:"supplier_id"`
+	LocationID           pgtype.UUID `json:"location_id"`
+	ExpectedDeliveryDate pgtype.Date `json:"expected_delivery_date"`
+	Notes                pgtype.Text `json:"notes"`
+	TenantID             uuid.UUID   `json:"tenant_id"`
+}
+
+func (q *Queries) UpdatePurchaseOrderDetails(ctx context.Context, arg UpdatePurchaseOrderDetailsParams) (PurchaseOrder, error) {
+	row := q.db.QueryRow(ctx, updatePurchaseOrderDetails,
+		arg.ID,
+		arg.SupplierID,
+		arg.LocationID,
+		arg.ExpectedDeliveryDate,
+		arg.Notes,
+		arg.TenantID,
+	)
+	var i PurchaseOrder
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.PoNumber,
+		&i.SupplierID,
+		&i.LocationID,
+		&i.OrderDate,
+		&i.ExpectedDeliveryDate,
+		&i.ActualDeliveryDate,
+		&i.TotalAmount,
+		&i.TaxAmount,
+		&i.DiscountAmount,
+		&i.FinalAmount,
+		&i.Status,
+		&i.Notes,
+		&i.CreatedBy,
+		&i.ApprovedBy,
+		&i.ApprovedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const updatePurchaseOrderItemQuantityReceived = `-- name: UpdatePurchaseOrderItemQuantityReceived :one
 UPDATE purchase_order_items
 SET quantity_received = $2, updated_at = NOW()
@@ -469,3 +801,26 @@ func (q *Queries) UpdatePurchaseOrderStatus(ctx context.Context, arg UpdatePurch
 	)
 	return err
 }
+
+const updatePurchaseOrderTotals = `-- name: UpdatePurchaseOrderTotals :exec
+UPDATE purchase_orders
+SET total_amount = $2, final_amount = $3, updated_at = NOW()
+WHERE id = $1 AND tenant_id = $4
+`
+
+type UpdatePurchaseOrderTotalsParams struct {
+	ID          uuid.UUID      `json:"id"`
+	TotalAmount pgtype.Numeric `json:"total_amount"`
+	FinalAmount pgtype.Numeric `json:"final_amount"`
+	TenantID    uuid.UUID      `json:"tenant_id"`
+}
+
+func (q *Queries) UpdatePurchaseOrderTotals(ctx context.Context, arg UpdatePurchaseOrderTotalsParams) error {
+	_, err := q.db.Exec(ctx, updatePurchaseOrderTotals,
+		arg.ID,
+		arg.TotalAmount,
+		arg.FinalAmount,
+		arg.TenantID,
+	)
+	return err
+}
@@ -8,94 +8,231 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
 	AddInventoryQuantity(ctx context.Context, arg AddInventoryQuantityParams) error
+	AdvanceUserTOTPStep(ctx context.Context, arg AdvanceUserTOTPStepParams) (int64, error)
+	ApprovePurchaseOrder(ctx context.Context, arg ApprovePurchaseOrderParams) (PurchaseOrder, error)
+	ApproveSalesOrder(ctx context.Context, arg ApproveSalesOrderParams) (SalesOrder, error)
+	ApproveStockAdjustment(ctx context.Context, arg ApproveStockAdjustmentParams) (StockAdjustment, error)
 	CheckCustomerExists(ctx context.Context, arg CheckCustomerExistsParams) (bool, error)
 	CheckProductExists(ctx context.Context, arg CheckProductExistsParams) (bool, error)
 	CheckSupplierExists(ctx context.Context, arg CheckSupplierExistsParams) (bool, error)
+	ClearLoginThrottle(ctx context.Context, key string) error
 	CountCustomers(ctx context.Context, tenantID uuid.UUID) (int64, error)
 	CountProducts(ctx context.Context, tenantID uuid.UUID) (int64, error)
 	CountProductsByTenant(ctx context.Context, tenantID uuid.UUID) (int64, error)
 	CountSuppliers(ctx context.Context, tenantID uuid.UUID) (int64, error)
+	CountUnusedRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error)
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error)
 	CreateBatch(ctx context.Context, arg CreateBatchParams) (Batch, error)
 	CreateCustomer(ctx context.Context, arg CreateCustomerParams) (Customer, error)
+	CreateImpersonationSession(ctx context.Context, arg CreateImpersonationSessionParams) (ImpersonationSession, error)
 	CreateInventoryLog(ctx context.Context, arg CreateInventoryLogParams) error
+	CreateInventoryReconciliation(ctx context.Context, arg CreateInventoryReconciliationParams) (InventoryReconciliation, error)
+	CreateInventoryReconciliationLine(ctx context.Context, arg CreateInventoryReconciliationLineParams) error
 	CreateLocation(ctx context.Context, arg CreateLocationParams) (Location, error)
 	CreateProduct(ctx context.Context, arg CreateProductParams) (Product, error)
 	CreatePurchaseOrder(ctx context.Context, arg CreatePurchaseOrderParams) (PurchaseOrder, error)
 	CreatePurchaseOrderItem(ctx context.Context, arg CreatePurchaseOrderItemParams) (PurchaseOrderItem, error)
+	CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) (RefreshToken, error)
 	CreateSalesOrder(ctx context.Context, arg CreateSalesOrderParams) (SalesOrder, error)
 	CreateSalesOrderItem(ctx context.Context, arg CreateSalesOrderItemParams) (SalesOrderItem, error)
+	CreateSecurityEvent(ctx context.Context, arg CreateSecurityEventParams) error
+	CreateStockAdjustment(ctx context.Context, arg CreateStockAdjustmentParams) (StockAdjustment, error)
+	CreateStockAdjustmentItem(ctx context.Context, arg CreateStockAdjustmentItemParams) (StockAdjustmentItem, error)
+	CreateStockCount(ctx context.Context, arg CreateStockCountParams) (StockCount, error)
 	CreateSupplier(ctx context.Context, arg CreateSupplierParams) (Supplier, error)
 	CreateTenant(ctx context.Context, arg CreateTenantParams) (Tenant, error)
+	CreateTenantMembership(ctx context.Context, arg CreateTenantMembershipParams) (TenantMembership, error)
+	CreateTransferOrder(ctx context.Context, arg CreateTransferOrderParams) (TransferOrder, error)
+	CreateTransferOrderItem(ctx context.Context, arg CreateTransferOrderItemParams) (TransferOrderItem, error)
 	CreateUnit(ctx context.Context, arg CreateUnitParams) (Unit, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateUserInvite(ctx context.Context, arg CreateUserInviteParams) (UserInvite, error)
+	CreateUserRecoveryCode(ctx context.Context, arg CreateUserRecoveryCodeParams) error
+	CreateUserToken(ctx context.Context, arg CreateUserTokenParams) (UserToken, error)
 	DeactivateCustomer(ctx context.Context, arg DeactivateCustomerParams) error
 	DeactivateSupplier(ctx context.Context, arg DeactivateSupplierParams) error
+	DeleteLocation(ctx context.Context, arg DeleteLocationParams) error
+	DeletePendingUserInvites(ctx context.Context, arg DeletePendingUserInvitesParams) error
+	DeletePurchaseOrderItems(ctx context.Context, arg DeletePurchaseOrderItemsParams) error
+	DeleteUnusedUserTokens(ctx context.Context, arg DeleteUnusedUserTokensParams) error
+	DeleteUserInvite(ctx context.Context, arg DeleteUserInviteParams) (int64, error)
+	DeleteUserRecoveryCodes(ctx context.Context, userID uuid.UUID) error
+	DeleteUserTOTP(ctx context.Context, userID uuid.UUID) error
+	EnableUserTOTP(ctx context.Context, userID uuid.UUID) error
+	EndImpersonationSession(ctx context.Context, id uuid.UUID) (ImpersonationSession, error)
+	EndTenantImpersonationSessions(ctx context.Context, tenantID uuid.UUID) error
+	EnsureStockCountLine(ctx context.Context, arg EnsureStockCountLineParams) (StockCountLine, error)
+	FindUserByEmail(ctx context.Context, lower string) (User, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (ApiKey, error)
 	GetBatchByID(ctx context.Context, arg GetBatchByIDParams) (Batch, error)
+	GetBatchByNumber(ctx context.Context, arg GetBatchByNumberParams) (Batch, error)
 	GetCustomerByID(ctx context.Context, arg GetCustomerByIDParams) (Customer, error)
 	GetCustomerByName(ctx context.Context, arg GetCustomerByNameParams) (Customer, error)
-	GetCustomerSalesSummary(ctx context.Context, tenantID uuid.UUID) ([]GetCustomerSalesSummaryRow, error)
+	GetCustomerSalesSummary(ctx context.Context, arg GetCustomerSalesSummaryParams) ([]GetCustomerSalesSummaryRow, error)
 	GetExpiringBatches(ctx context.Context, arg GetExpiringBatchesParams) ([]GetExpiringBatchesRow, error)
+	GetInTransitQuantity(ctx context.Context, arg GetInTransitQuantityParams) (int64, error)
 	GetInventoryByProductBatch(ctx context.Context, arg GetInventoryByProductBatchParams) (Inventory, error)
+	GetInventoryForUpdate(ctx context.Context, arg GetInventoryForUpdateParams) (Inventory, error)
 	GetInventoryLogByBatch(ctx context.Context, arg GetInventoryLogByBatchParams) ([]InventoryLog, error)
 	GetInventoryLogByProduct(ctx context.Context, arg GetInventoryLogByProductParams) ([]InventoryLog, error)
-	GetInventoryValue(ctx context.Context, tenantID uuid.UUID) (interface{}, error)
+	GetInventoryReconciliation(ctx context.Context, arg GetInventoryReconciliationParams) (InventoryReconciliation, error)
+	GetInventoryReconciliationForUpdate(ctx context.Context, arg GetInventoryReconciliationForUpdateParams) (InventoryReconciliation, error)
+	GetInventoryValuation(ctx context.Context, arg GetInventoryValuationParams) ([]GetInventoryValuationRow, error)
+	GetInventoryValue(ctx context.Context, arg GetInventoryValueParams) (interface{}, error)
+	GetLedgerBalanceAsOf(ctx context.Context, arg GetLedgerBalanceAsOfParams) (pgtype.Numeric, error)
 	GetLocationByID(ctx context.Context, arg GetLocationByIDParams) (Location, error)
+	GetLoginThrottle(ctx context.Context, key string) (LoginThrottle, error)
 	GetLowStockReport(ctx context.Context, arg GetLowStockReportParams) ([]GetLowStockReportRow, error)
 	GetProductByID(ctx context.Context, arg GetProductByIDParams) (Product, error)
 	GetProductBySKU(ctx context.Context, arg GetProductBySKUParams) (Product, error)
 	GetProductInventoryDetails(ctx context.Context, arg GetProductInventoryDetailsParams) ([]GetProductInventoryDetailsRow, error)
-	GetProductMovementReport(ctx context.Context, tenantID uuid.UUID) ([]GetProductMovementReportRow, error)
+	GetProductMovementReport(ctx context.Context, arg GetProductMovementReportParams) ([]GetProductMovementReportRow, error)
 	GetProductQuantity(ctx context.Context, arg GetProductQuantityParams) (interface{}, error)
+	GetProductStockPosition(ctx context.Context, arg GetProductStockPositionParams) (GetProductStockPositionRow, error)
 	GetPurchaseOrder(ctx context.Context, arg GetPurchaseOrderParams) (PurchaseOrder, error)
+	GetPurchaseOrderForUpdate(ctx context.Context, arg GetPurchaseOrderForUpdateParams) (PurchaseOrder, error)
 	GetPurchaseOrderItemByID(ctx context.Context, arg GetPurchaseOrderItemByIDParams) (PurchaseOrderItem, error)
 	GetPurchaseOrderItems(ctx context.Context, arg GetPurchaseOrderItemsParams) ([]PurchaseOrderItem, error)
+	GetRefreshTokenByHashForUpdate(ctx context.Context, tokenHash string) (RefreshToken, error)
 	GetSalesOrder(ctx context.Context, arg GetSalesOrderParams) (SalesOrder, error)
+	GetSalesOrderForUpdate(ctx context.Context, arg GetSalesOrderForUpdateParams) (SalesOrder, error)
 	GetSalesOrderItemByID(ctx context.Context, arg GetSalesOrderItemByIDParams) (SalesOrderItem, error)
 	GetSalesOrderItems(ctx context.Context, arg GetSalesOrderItemsParams) ([]SalesOrderItem, error)
 	GetSalesReportByDate(ctx context.Context, arg GetSalesReportByDateParams) ([]GetSalesReportByDateRow, error)
+	GetStockAdjustment(ctx context.Context, arg GetStockAdjustmentParams) (StockAdjustment, error)
+	GetStockAdjustmentForUpdate(ctx context.Context, arg GetStockAdjustmentForUpdateParams) (StockAdjustment, error)
+	GetStockAdjustmentItems(ctx context.Context, arg GetStockAdjustmentItemsParams) ([]StockAdjustmentItem, error)
+	GetStockAdjustmentValue(ctx context.Context, arg GetStockAdjustmentValueParams) (pgtype.Numeric, error)
+	GetStockCount(ctx context.Context, arg GetStockCountParams) (StockCount, error)
+	GetStockCountForUpdate(ctx context.Context, arg GetStockCountForUpdateParams) (StockCount, error)
 	GetSupplierByID(ctx context.Context, arg GetSupplierByIDParams) (Supplier, error)
 	GetSupplierByName(ctx context.Context, arg GetSupplierByNameParams) (Supplier, error)
-	GetSupplierPurchaseSummary(ctx context.Context, tenantID uuid.UUID) ([]GetSupplierPurchaseSummaryRow, error)
+	GetSupplierPurchaseSummary(ctx context.Context, arg GetSupplierPurchaseSummaryParams) ([]GetSupplierPurchaseSummaryRow, error)
+	GetTenantAdmin(ctx context.Context, tenantID uuid.UUID) (GetTenantAdminRow, error)
 	GetTenantByID(ctx context.Context, id uuid.UUID) (Tenant, error)
+	GetTenantMember(ctx context.Context, arg GetTenantMemberParams) (GetTenantMemberRow, error)
+	GetTenantMembership(ctx context.Context, arg GetTenantMembershipParams) (TenantMembership, error)
+	GetTenantWithUsage(ctx context.Context, id uuid.UUID) (GetTenantWithUsageRow, error)
+	GetTransferOrder(ctx context.Context, arg GetTransferOrderParams) (TransferOrder, error)
+	GetTransferOrderForUpdate(ctx context.Context, arg GetTransferOrderForUpdateParams) (TransferOrder, error)
+	GetTransferOrderItems(ctx context.Context, arg GetTransferOrderItemsParams) ([]TransferOrderItem, error)
 	GetUnitByID(ctx context.Context, arg GetUnitByIDParams) (Unit, error)
 	GetUserByEmail(ctx context.Context, arg GetUserByEmailParams) (User, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (User, error)
+	GetUserInviteByTokenHashForUpdate(ctx context.Context, tokenHash string) (UserInvite, error)
+	GetUserTokenForUpdate(ctx context.Context, arg GetUserTokenForUpdateParams) (UserToken, error)
+	GetUserTOTP(ctx context.Context, userID uuid.UUID) (UserTotp, error)
+	HasOpenInventoryReconciliation(ctx context.Context, arg HasOpenInventoryReconciliationParams) (bool, error)
+	IsImpersonationActive(ctx context.Context, id uuid.UUID) (bool, error)
+	IsSessionRevoked(ctx context.Context, familyID uuid.UUID) (bool, error)
+	IsTenantActive(ctx context.Context, id uuid.UUID) (bool, error)
 	ListActiveCustomers(ctx context.Context, arg ListActiveCustomersParams) ([]Customer, error)
 	ListActiveSuppliers(ctx context.Context, arg ListActiveSuppliersParams) ([]Supplier, error)
+	ListActiveTenantIDs(ctx context.Context) ([]uuid.UUID, error)
 	ListAllInventory(ctx context.Context, arg ListAllInventoryParams) ([]ListAllInventoryRow, error)
+	ListAllocatableBatches(ctx context.Context, arg ListAllocatableBatchesParams) ([]ListAllocatableBatchesRow, error)
+	ListAPIKeys(ctx context.Context, tenantID uuid.UUID) ([]ApiKey, error)
+	ListAuditLog(ctx context.Context, arg ListAuditLogParams) ([]AuditLog, error)
 	ListCustomers(ctx context.Context, arg ListCustomersParams) ([]Customer, error)
+	ListImpersonationSessions(ctx context.Context, arg ListImpersonationSessionsParams) ([]ImpersonationSession, error)
+	ListInTransitStock(ctx context.Context, arg ListInTransitStockParams) ([]ListInTransitStockRow, error)
+	ListInventoryDiscrepancies(ctx context.Context, arg ListInventoryDiscrepanciesParams) ([]ListInventoryDiscrepanciesRow, error)
+	ListInventoryReconciliationLines(ctx context.Context, arg ListInventoryReconciliationLinesParams) ([]ListInventoryReconciliationLinesRow, error)
+	ListInventoryReconciliations(ctx context.Context, arg ListInventoryReconciliationsParams) ([]InventoryReconciliation, error)
+	ListLedgerEntries(ctx context.Context, arg ListLedgerEntriesParams) ([]ListLedgerEntriesRow, error)
 	ListLocations(ctx context.Context, arg ListLocationsParams) ([]Location, error)
+	ListLocationStockSummary(ctx context.Context, arg ListLocationStockSummaryParams) ([]ListLocationStockSummaryRow, error)
+	ListPendingUserInvites(ctx context.Context, tenantID uuid.UUID) ([]UserInvite, error)
 	ListProducts(ctx context.Context, arg ListProductsParams) ([]Product, error)
+	ListPurchaseOrders(ctx context.Context, arg ListPurchaseOrdersParams) ([]PurchaseOrder, error)
 	ListPurchaseOrdersByStatus(ctx context.Context, arg ListPurchaseOrdersByStatusParams) ([]PurchaseOrder, error)
 	ListPurchaseOrdersBySupplier(ctx context.Context, arg ListPurchaseOrdersBySupplierParams) ([]PurchaseOrder, error)
+	ListSalesOrders(ctx context.Context, arg ListSalesOrdersParams) ([]SalesOrder, error)
 	ListSalesOrdersByCustomer(ctx context.Context, arg ListSalesOrdersByCustomerParams) ([]SalesOrder, error)
+	ListSalesOrdersByStatus(ctx context.Context, arg ListSalesOrdersByStatusParams) ([]SalesOrder, error)
+	ListSecurityEvents(ctx context.Context, arg ListSecurityEventsParams) ([]SecurityEvent, error)
+	ListStockAdjustments(ctx context.Context, arg ListStockAdjustmentsParams) ([]StockAdjustment, error)
+	ListStockAsOf(ctx context.Context, arg ListStockAsOfParams) ([]ListStockAsOfRow, error)
+	ListStockCountEntries(ctx context.Context, arg ListStockCountEntriesParams) ([]StockCountEntry, error)
+	ListStockCountLines(ctx context.Context, arg ListStockCountLinesParams) ([]ListStockCountLinesRow, error)
+	ListStockCounts(ctx context.Context, arg ListStockCountsParams) ([]StockCount, error)
 	ListSuppliers(ctx context.Context, arg ListSuppliersParams) ([]Supplier, error)
 	ListTenants(ctx context.Context, arg ListTenantsParams) ([]Tenant, error)
+	ListTenantsWithUsage(ctx context.Context, arg ListTenantsWithUsageParams) ([]ListTenantsWithUsageRow, error)
+	ListTransferOrders(ctx context.Context, arg ListTransferOrdersParams) ([]TransferOrder, error)
 	ListUnits(ctx context.Context, arg ListUnitsParams) ([]Unit, error)
-	ListUsersByRole(ctx context.Context, arg ListUsersByRoleParams) ([]User, error)
-	ReduceInventoryQuantity(ctx context.Context, arg ReduceInventoryQuantityParams) error
+	ListUserMemberships(ctx context.Context, userID uuid.UUID) ([]ListUserMembershipsRow, error)
+	ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUsersRow, error)
+	ListUserSessions(ctx context.Context, userID uuid.UUID) ([]RefreshToken, error)
+	LockLocationInventory(ctx context.Context, arg LockLocationInventoryParams) error
+	LockLoginThrottle(ctx context.Context, arg LockLoginThrottleParams) error
+	LockProductInventory(ctx context.Context, arg LockProductInventoryParams) error
+	MarkRefreshTokenUsed(ctx context.Context, id uuid.UUID) error
+	MarkUserInviteAccepted(ctx context.Context, id uuid.UUID) error
+	MarkUserTokenUsed(ctx context.Context, id uuid.UUID) error
+	RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (LoginThrottle, error)
+	ReduceInventoryQuantity(ctx context.Context, arg ReduceInventoryQuantityParams) (pgtype.Numeric, error)
+	ResolveInventoryReconciliation(ctx context.Context, arg ResolveInventoryReconciliationParams) (InventoryReconciliation, error)
+	RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (int64, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error
+	RevokeRefreshTokenFamilyByHash(ctx context.Context, tokenHash string) error
+	RevokeTenantRefreshTokens(ctx context.Context, tenantID uuid.UUID) error
+	RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) error
+	RevokeUserSession(ctx context.Context, arg RevokeUserSessionParams) (int64, error)
+	RevokeUserTenantRefreshTokens(ctx context.Context, arg RevokeUserTenantRefreshTokensParams) error
 	SearchCustomers(ctx context.Context, arg SearchCustomersParams) ([]Customer, error)
 	SearchProducts(ctx context.Context, arg SearchProductsParams) ([]Product, error)
 	SearchSuppliers(ctx context.Context, arg SearchSuppliersParams) ([]Supplier, error)
-	SetInventoryQuantity(ctx context.Context, arg SetInventoryQuantityParams) error
+	SetInventoryReconciliationTotals(ctx context.Context, arg SetInventoryReconciliationTotalsParams) (InventoryReconciliation, error)
+	SetLocationActive(ctx context.Context, arg SetLocationActiveParams) (Location, error)
+	SetPurchaseOrderDeliveryDate(ctx context.Context, arg SetPurchaseOrderDeliveryDateParams) error
+	SetPurchaseOrderItemBatch(ctx context.Context, arg SetPurchaseOrderItemBatchParams) error
+	SetSalesOrderDeliveryDate(ctx context.Context, arg SetSalesOrderDeliveryDateParams) error
+	SetSalesOrderItemBatch(ctx context.Context, arg SetSalesOrderItemBatchParams) error
+	SetStockAdjustmentPosted(ctx context.Context, arg SetStockAdjustmentPostedParams) error
+	SetStockCountApproved(ctx context.Context, arg SetStockCountApprovedParams) error
+	SetStockCountSubmitted(ctx context.Context, arg SetStockCountSubmittedParams) error
+	SetTenantActive(ctx context.Context, arg SetTenantActiveParams) (Tenant, error)
+	SetTenantMembershipActive(ctx context.Context, arg SetTenantMembershipActiveParams) (TenantMembership, error)
+	SetTenantRequireTwoFactor(ctx context.Context, arg SetTenantRequireTwoFactorParams) (Tenant, error)
+	SetTransferOrderDispatched(ctx context.Context, arg SetTransferOrderDispatchedParams) error
+	SetTransferOrderReceived(ctx context.Context, arg SetTransferOrderReceivedParams) error
+	SetUserEmailVerified(ctx context.Context, id uuid.UUID) error
+	SnapshotStockCountLines(ctx context.Context, arg SnapshotStockCountLinesParams) (int64, error)
+	TouchAPIKey(ctx context.Context, id uuid.UUID) error
+	TransitionPurchaseOrderStatus(ctx context.Context, arg TransitionPurchaseOrderStatusParams) (PurchaseOrder, error)
+	TransitionSalesOrderStatus(ctx context.Context, arg TransitionSalesOrderStatusParams) (SalesOrder, error)
+	TransitionStockAdjustmentStatus(ctx context.Context, arg TransitionStockAdjustmentStatusParams) (StockAdjustment, error)
+	TransitionStockCountStatus(ctx context.Context, arg TransitionStockCountStatusParams) (StockCount, error)
+	TransitionTransferOrderStatus(ctx context.Context, arg TransitionTransferOrderStatusParams) (TransferOrder, error)
 	UpdateBatch(ctx context.Context, arg UpdateBatchParams) (Batch, error)
 	UpdateCustomer(ctx context.Context, arg UpdateCustomerParams) (Customer, error)
 	UpdateLocation(ctx context.Context, arg UpdateLocationParams) (Location, error)
 	UpdateProductDetails(ctx context.Context, arg UpdateProductDetailsParams) (Product, error)
 	UpdateProductPatch(ctx context.Context, arg UpdateProductPatchParams) error
+	UpdatePurchaseOrderDetails(ctx context.Context, arg UpdatePurchaseOrderDetailsParams) (PurchaseOrder, error)
 	UpdatePurchaseOrderItemQuantityReceived(ctx context.Context, arg UpdatePurchaseOrderItemQuantityReceivedParams) (PurchaseOrderItem, error)
 	UpdatePurchaseOrderStatus(ctx context.Context, arg UpdatePurchaseOrderStatusParams) error
+	UpdatePurchaseOrderTotals(ctx context.Context, arg UpdatePurchaseOrderTotalsParams) error
 	UpdateSalesOrderItemQuantityShipped(ctx context.Context, arg UpdateSalesOrderItemQuantityShippedParams) (SalesOrderItem, error)
 	UpdateSalesOrderStatus(ctx context.Context, arg UpdateSalesOrderStatusParams) error
+	UpdateSalesOrderTotals(ctx context.Context, arg UpdateSalesOrderTotalsParams) error
 	UpdateSupplier(ctx context.Context, arg UpdateSupplierParams) (Supplier, error)
 	UpdateTenant(ctx context.Context, arg UpdateTenantParams) (Tenant, error)
+	UpdateTenantMembershipRole(ctx context.Context, arg UpdateTenantMembershipRoleParams) (TenantMembership, error)
+	UpdateTransferOrderItemReceipt(ctx context.Context, arg UpdateTransferOrderItemReceiptParams) (TransferOrderItem, error)
 	UpdateUnit(ctx context.Context, arg UpdateUnitParams) (Unit, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
 	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error
+	UpsertPendingUserTOTP(ctx context.Context, arg UpsertPendingUserTOTPParams) (UserTotp, error)
+	UpsertStockCountEntry(ctx context.Context, arg UpsertStockCountEntryParams) (StockCountEntry, error)
+	UserEmailExists(ctx context.Context, lower string) (bool, error)
+	UseUserRecoveryCode(ctx context.Context, arg UseUserRecoveryCodeParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
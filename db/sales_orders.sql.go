@@ -13,18 +13,60 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const approveSalesOrder = `-- name: ApproveSalesOrder :one
+UPDATE sales_orders
+SET status = 'APPROVED', approved_by = $1, approved_at = NOW(), updated_at = NOW()
+WHERE id = $2 AND tenant_id = $3 AND status = 'PENDING'
+RETURNING id, tenant_id, so_number, customer_id, location_id, order_date, expected_delivery_date, actual_delivery_date, total_amount, tax_amount, discount_amount, final_amount, status, notes, created_by, approved_by, approved_at, created_at, updated_at
+`
+
+type ApproveSalesOrderParams struct {
+	ApprovedBy pgtype.UUID `json:"approved_by"`
+	ID         uuid.UUID   `json:"id"`
+	TenantID   uuid.UUID   `json:"tenant_id"`
+}
+
+func (q *Queries) ApproveSalesOrder(ctx context.Context, arg ApproveSalesOrderParams) (SalesOrder, error) {
+	row := q.db.QueryRow(ctx, approveSalesOrder, arg.ApprovedBy, arg.ID, arg.TenantID)
+	var i SalesOrder
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.SoNumber,
+		&i.CustomerID,
+		&i.LocationID,
+		&i.OrderDate,
+		&i.ExpectedDeliveryDate,
+		&i.ActualDeliveryDate,
+		&i.TotalAmount,
+		&i.TaxAmount,
+		&i.DiscountAmount,
+		&i.FinalAmount,
+		&i.Status,
+		&i.Notes,
+		&i.CreatedBy,
+		&i.ApprovedBy,
+		&i.ApprovedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const createSalesOrder = `-- name: CreateSalesOrder :one
-INSERT INTO sales_orders (tenant_id, so_number, customer_id, location_id, created_by)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO sales_orders (tenant_id, so_number, customer_id, location_id, created_by, expected_delivery_date, notes)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING id, tenant_id, so_number, customer_id, location_id, order_date, expected_delivery_date, actual_delivery_date, total_amount, tax_amount, discount_amount, final_amount, status, notes, created_by, approved_by, approved_at, created_at, updated_at
 `
 
 type CreateSalesOrderParams struct {
-	TenantID   uuid.UUID   `json:"tenant_id"`
-	SoNumber   string      `json:"so_number"`
-	CustomerID uuid.UUID   `json:"customer_id"`
-	LocationID pgtype.UUID `json:"location_id"`
-	CreatedBy  pgtype.UUID `json:"created_by"`
+	TenantID             uuid.UUID   `json:"tenant_id"`
+	SoNumber             string      `json:"so_number"`
+	CustomerID           uuid.UUID   `json:"customer_id"`
+	LocationID           pgtype.UUID `json:"location_id"`
+	CreatedBy            pgtype.UUID `json:"created_by"`
+	ExpectedDeliveryDate pgtype.Date `json:"expected_delivery_date"`
+	Notes                pgtype.Text `json:"notes"`
 }
 
 func (q *Queries) CreateSalesOrder(ctx context.Context, arg CreateSalesOrderParams) (SalesOrder, error) {
@@ -34,6 +76,8 @@ func (q *Queries) CreateSalesOrder(ctx context.Context, arg CreateSalesOrderPara
 		arg.CustomerID,
 		arg.LocationID,
 		arg.CreatedBy,
+		arg.ExpectedDeliveryDate,
+		arg.Notes,
 	)
 	var i SalesOrder
 	err := row.Scan(
@@ -106,24 +150,34 @@ func (q *Queries) CreateSalesOrderItem(ctx context.Context, arg CreateSalesOrder
 
 const getCustomerSalesSummary = `-- name: GetCustomerSalesSummary :many
 SELECT
+    c.id AS customer_id,
     c.name AS customer_name,
-    SUM(so.final_amount) AS total_sales_amount,
+    COALESCE(SUM(so.final_amount), 0)::numeric AS total_sales_amount,
     COUNT(so.id) AS total_orders
 FROM customers c
 JOIN sales_orders so ON c.id = so.customer_id AND c.tenant_id = so.tenant_id
 WHERE c.tenant_id = $1
+    AND so.status <> 'CANCELLED'
+    AND so.order_date BETWEEN $2::date AND $3::date
 GROUP BY c.id, c.name
 ORDER BY total_sales_amount DESC
 `
 
+type GetCustomerSalesSummaryParams struct {
+	TenantID  uuid.UUID `json:"tenant_id"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
 type GetCustomerSalesSummaryRow struct {
-	CustomerName     string `json:"customer_name"`
-	TotalSalesAmount int64  `json:"total_sales_amount"`
-	TotalOrders      int64  `json:"total_orders"`
+	CustomerID       uuid.UUID      `json:"customer_id"`
+	CustomerName     string         `json:"customer_name"`
+	TotalSalesAmount pgtype.Numeric `json:"total_sales_amount"`
+	TotalOrders      int64          `json:"total_orders"`
 }
 
-func (q *Queries) GetCustomerSalesSummary(ctx context.Context, tenantID uuid.UUID) ([]GetCustomerSalesSummaryRow, error) {
-	rows, err := q.db.Query(ctx, getCustomerSalesSummary, tenantID)
+func (q *Queries) GetCustomerSalesSummary(ctx context.Context, arg GetCustomerSalesSummaryParams) ([]GetCustomerSalesSummaryRow, error) {
+	rows, err := q.db.Query(ctx, getCustomerSalesSummary, arg.TenantID, arg.StartDate, arg.EndDate)
 	if err != nil {
 		return nil, err
 	}
@@ -131,7 +185,12 @@ func (q *Queries) GetCustomerSalesSummary(ctx context.Context, tenantID uuid.UUI
 	items := []GetCustomerSalesSummaryRow{}
 	for rows.Next() {
 		var i GetCustomerSalesSummaryRow
-		if err := rows.Scan(&i.CustomerName, &i.TotalSalesAmount, &i.TotalOrders); err != nil {
+		if err := rows.Scan(
+			&i.CustomerID,
+			&i.CustomerName,
+			&i.TotalSalesAmount,
+			&i.TotalOrders,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -142,6 +201,39 @@ func (q *Queries) GetCustomerSalesSummary(ctx context.Context, tenantID uuid.UUI
 	return items, nil
 }
 
+const getProductStockPosition = `-- name: GetProductStockPosition :one
+SELECT
+    COALESCE((
+        SELECT SUM(i.quantity)
+        FROM inventory i
+        WHERE i.tenant_id = $1 AND i.product_id = $2 AND i.location_id = $3
+    ), 0)::bigint AS on_hand,
+    COALESCE((
+        SELECT SUM(soi.quantity_ordered - COALESCE(soi.quantity_shipped, 0))
+        FROM sales_order_items soi
+        JOIN sales_orders so ON soi.sales_order_id = so.id
+        WHERE soi.tenant_id = $1 AND soi.product_id = $2 AND so.location_id = $3 AND so.status = 'APPROVED'
+    ), 0)::bigint AS reserved
+`
+
+type GetProductStockPositionParams struct {
+	TenantID   uuid.UUID `json:"tenant_id"`
+	ProductID  uuid.UUID `json:"product_id"`
+	LocationID uuid.UUID `json:"location_id"`
+}
+
+type GetProductStockPositionRow struct {
+	OnHand   int64 `json:"on_hand"`
+	Reserved int64 `json:"reserved"`
+}
+
+func (q *Queries) GetProductStockPosition(ctx context.Context, arg GetProductStockPositionParams) (GetProductStockPositionRow, error) {
+	row := q.db.QueryRow(ctx, getProductStockPosition, arg.TenantID, arg.ProductID, arg.LocationID)
+	var i GetProductStockPositionRow
+	err := row.Scan(&i.OnHand, &i.Reserved)
+	return i, err
+}
+
 const getSalesOrder = `-- name: GetSalesOrder :one
 SELECT id, tenant_id, so_number, customer_id, location_id, order_date, expected_delivery_date, actual_delivery_date, total_amount, tax_amount, discount_amount, final_amount, status, notes, created_by, approved_by, approved_at, created_at, updated_at FROM sales_orders
 WHERE id = $1 AND tenant_id = $2
@@ -179,6 +271,44 @@ func (q *Queries) GetSalesOrder(ctx context.Context, arg GetSalesOrderParams) (S
 	return i, err
 }
 
+const getSalesOrderForUpdate = `-- name: GetSalesOrderForUpdate :one
+SELECT id, tenant_id, so_number, customer_id, location_id, order_date, expected_delivery_date, actual_delivery_date, total_amount, tax_amount, discount_amount, final_amount, status, notes, created_by, approved_by, approved_at, created_at, updated_at FROM sales_orders
+WHERE id = $1 AND tenant_id = $2
+FOR UPDATE
+`
+
+type GetSalesOrderForUpdateParams struct {
+	ID       uuid.UUID `json:"id"`
+	TenantID uuid.UUID `json:"tenant_id"`
+}
+
+func (q *Queries) GetSalesOrderForUpdate(ctx context.Context, arg GetSalesOrderForUpdateParams) (SalesOrder, error) {
+	row := q.db.QueryRow(ctx, getSalesOrderForUpdate, arg.ID, arg.TenantID)
+	var i SalesOrder
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.SoNumber,
+		&i.CustomerID,
+		&i.LocationID,
+		&i.OrderDate,
+		&i.ExpectedDeliveryDate,
+		&i.ActualDeliveryDate,
+		&i.TotalAmount,
+		&i.TaxAmount,
+		&i.DiscountAmount,
+		&i.FinalAmount,
+		&i.Status,
+		&i.Notes,
+		&i.CreatedBy,
+		&i.ApprovedBy,
+		&i.ApprovedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getSalesOrderItemByID = `-- name: GetSalesOrderItemByID :one
 SELECT id, tenant_id, sales_order_id, product_id, batch_id, quantity_ordered, quantity_shipped, unit_price, total_price, tax_percent, discount_percent, notes, created_at, updated_at FROM sales_order_items
 WHERE id = $1 AND tenant_id = $2
@@ -258,33 +388,41 @@ func (q *Queries) GetSalesOrderItems(ctx context.Context, arg GetSalesOrderItems
 
 const getSalesReportByDate = `-- name: GetSalesReportByDate :many
 SELECT
-    p.name as product_name,
-    SUM(soi.quantity_shipped) as total_units_sold,
-    SUM(soi.total_price) as total_revenue
-FROM sales_order_items soi
-JOIN products p ON soi.product_id = p.id
-JOIN sales_orders so ON soi.sales_order_id = so.id
-WHERE soi.tenant_id = $1
-  AND so.order_date BETWEEN $2 AND $3
-  AND so.status = 'DELIVERED' -- or 'SHIPPED'
-GROUP BY p.name
-ORDER BY total_revenue DESC
+    so.order_date,
+    COUNT(so.id) AS total_orders,
+    COALESCE(SUM(so.final_amount), 0)::numeric AS total_sales_amount,
+    COALESCE(SUM(u.units_ordered), 0)::numeric AS units_ordered,
+    COALESCE(SUM(u.units_shipped), 0)::numeric AS units_shipped
+FROM sales_orders so
+LEFT JOIN (
+    SELECT sales_order_id, SUM(quantity_ordered) AS units_ordered, SUM(COALESCE(quantity_shipped, 0)) AS units_shipped
+    FROM sales_order_items
+    WHERE tenant_id = $1
+    GROUP BY sales_order_id
+) u ON u.sales_order_id = so.id
+WHERE so.tenant_id = $1
+    AND so.status <> 'CANCELLED'
+    AND so.order_date BETWEEN $2::date AND $3::date
+GROUP BY so.order_date
+ORDER BY so.order_date
 `
 
 type GetSalesReportByDateParams struct {
-	TenantID    uuid.UUID `json:"tenant_id"`
-	OrderDate   time.Time `json:"order_date"`
-	OrderDate_2 time.Time `json:"order_date_2"`
+	TenantID  uuid.UUID `json:"tenant_id"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
 }
 
 type GetSalesReportByDateRow struct {
-	ProductName    string `json:"product_name"`
-	TotalUnitsSold int64  `json:"total_units_sold"`
-	TotalRevenue   int64  `json:"total_revenue"`
+	OrderDate        time.Time      `json:"order_date"`
+	TotalOrders      int64          `json:"total_orders"`
+	TotalSalesAmount pgtype.Numeric `json:"total_sales_amount"`
+	UnitsOrdered     pgtype.Numeric `json:"units_ordered"`
+	UnitsShipped     pgtype.Numeric `json:"units_shipped"`
 }
 
 func (q *Queries) GetSalesReportByDate(ctx context.Context, arg GetSalesReportByDateParams) ([]GetSalesReportByDateRow, error) {
-	rows, err := q.db.Query(ctx, getSalesReportByDate, arg.TenantID, arg.OrderDate, arg.OrderDate_2)
+	rows, err := q.db.Query(ctx, getSalesReportByDate, arg.TenantID, arg.StartDate, arg.EndDate)
 	if err != nil {
 		return nil, err
 	}
@@ -292,7 +430,66 @@ func (q *Queries) GetSalesReportByDate(ctx context.Context, arg GetSalesReportBy
 	items := []GetSalesReportByDateRow{}
 	for rows.Next() {
 		var i GetSalesReportByDateRow
-		if err := rows.Scan(&i.ProductName, &i.TotalUnitsSold, &i.TotalRevenue); err != nil {
+		if err := rows.Scan(
+			&i.OrderDate,
+			&i.TotalOrders,
+			&i.TotalSalesAmount,
+			&i.UnitsOrdered,
+			&i.UnitsShipped,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSalesOrders = `-- name: ListSalesOrders :many
+SELECT id, tenant_id, so_number, customer_id, location_id, order_date, expected_delivery_date, actual_delivery_date, total_amount, tax_amount, discount_amount, final_amount, status, notes, created_by, approved_by, approved_at, created_at, updated_at FROM sales_orders
+WHERE tenant_id = $1
+ORDER BY order_date DESC, created_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListSalesOrdersParams struct {
+	TenantID uuid.UUID `json:"tenant_id"`
+	Limit    int32     `json:"limit"`
+	Offset   int32     `json:"offset"`
+}
+
+func (q *Queries) ListSalesOrders(ctx context.Context, arg ListSalesOrdersParams) ([]SalesOrder, error) {
+	rows, err := q.db.Query(ctx, listSalesOrders, arg.TenantID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SalesOrder{}
+	for rows.Next() {
+		var i SalesOrder
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.SoNumber,
+			&i.CustomerID,
+			&i.LocationID,
+			&i.OrderDate,
+			&i.ExpectedDeliveryDate,
+			&i.ActualDeliveryDate,
+			&i.TotalAmount,
+			&i.TaxAmount,
+			&i.DiscountAmount,
+			&i.FinalAmount,
+			&i.Status,
+			&i.Notes,
+			&i.CreatedBy,
+			&i.ApprovedBy,
+			&i.ApprovedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -362,6 +559,144 @@ func (q *Queries) ListSalesOrdersByCustomer(ctx context.Context, arg ListSalesOr
 	return items, nil
 }
 
+const listSalesOrdersByStatus = `-- name: ListSalesOrdersByStatus :many
+SELECT id, tenant_id, so_number, customer_id, location_id, order_date, expected_delivery_date, actual_delivery_date, total_amount, tax_amount, discount_amount, final_amount, status, notes, created_by, approved_by, approved_at, created_at, updated_at FROM sales_orders
+WHERE tenant_id = $1 AND status = $2
+ORDER BY order_date DESC
+LIMIT $3 OFFSET $4
+`
+
+type ListSalesOrdersByStatusParams struct {
+	TenantID uuid.UUID `json:"tenant_id"`
+	Status   string    `json:"status"`
+	Limit    int32     `json:"limit"`
+	Offset   int32     `json:"offset"`
+}
+
+func (q *Queries) ListSalesOrdersByStatus(ctx context.Context, arg ListSalesOrdersByStatusParams) ([]SalesOrder, error) {
+	rows, err := q.db.Query(ctx, listSalesOrdersByStatus,
+		arg.TenantID,
+		arg.Status,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SalesOrder{}
+	for rows.Next() {
+		var i SalesOrder
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.SoNumber,
+			&i.CustomerID,
+			&i.LocationID,
+			&i.OrderDate,
+			&i.ExpectedDeliveryDate,
+			&i.ActualDeliveryDate,
+			&i.TotalAmount,
+			&i.TaxAmount,
+			&i.DiscountAmount,
+			&i.FinalAmount,
+			&i.Status,
+			&i.Notes,
+			&i.CreatedBy,
+			&i.ApprovedBy,
+			&i.ApprovedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setSalesOrderDeliveryDate = `-- name: SetSalesOrderDeliveryDate :exec
+UPDATE sales_orders
+SET actual_delivery_date = CURRENT_DATE, updated_at = NOW()
+WHERE id = $1 AND tenant_id = $2
+`
+
+type SetSalesOrderDeliveryDateParams struct {
+	ID       uuid.UUID `json:"id"`
+	TenantID uuid.UUID `json:"tenant_id"`
+}
+
+func (q *Queries) SetSalesOrderDeliveryDate(ctx context.Context, arg SetSalesOrderDeliveryDateParams) error {
+	_, err := q.db.Exec(ctx, setSalesOrderDeliveryDate, arg.ID, arg.TenantID)
+	return err
+}
+
+const setSalesOrderItemBatch = `-- name: SetSalesOrderItemBatch :exec
+UPDATE sales_order_items
+SET batch_id = $2, updated_at = NOW()
+WHERE id = $1 AND tenant_id = $3
+`
+
+type SetSalesOrderItemBatchParams struct {
+	ID       uuid.UUID   `json:"id"`
+	BatchID  pgtype.UUID `json:"batch_id"`
+	TenantID uuid.UUID   `json:"tenant_id"`
+}
+
+func (q *Queries) SetSalesOrderItemBatch(ctx context.Context, arg SetSalesOrderItemBatchParams) error {
+	_, err := q.db.Exec(ctx, setSalesOrderItemBatch, arg.ID, arg.BatchID, arg.TenantID)
+	return err
+}
+
+const transitionSalesOrderStatus = `-- name: TransitionSalesOrderStatus :one
+UPDATE sales_orders
+SET status = $1, updated_at = NOW()
+WHERE id = $2 AND tenant_id = $3 AND status = $4
+RETURNING id, tenant_id, so_number, customer_id, location_id, order_date, expected_delivery_date, actual_delivery_date, total_amount, tax_amount, discount_amount, final_amount, status, notes, created_by, approved_by, approved_at, created_at, updated_at
+`
+
+type TransitionSalesOrderStatusParams struct {
+	NewStatus     string    `json:"new_status"`
+	ID            uuid.UUID `json:"id"`
+	TenantID      uuid.UUID `json:"tenant_id"`
+	CurrentStatus string    `json:"current_status"`
+}
+
+func (q *Queries) TransitionSalesOrderStatus(ctx context.Context, arg TransitionSalesOrderStatusParams) (SalesOrder, error) {
+	row := q.db.QueryRow(ctx, transitionSalesOrderStatus,
+		arg.NewStatus,
+		arg.ID,
+		arg.TenantID,
+		arg.CurrentStatus,
+	)
+	var i SalesOrder
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.SoNumber,
+		&i.CustomerID,
+		&i.LocationID,
+		&i.OrderDate,
+		&i.ExpectedDeliveryDate,
+		&i.ActualDeliveryDate,
+		&i.TotalAmount,
+		&i.TaxAmount,
+		&i.DiscountAmount,
+		&i.FinalAmount,
+		&i.Status,
+		&i.Notes,
+		&i.CreatedBy,
+		&i.ApprovedBy,
+		&i.ApprovedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const updateSalesOrderItemQuantityShipped = `-- name: UpdateSalesOrderItemQuantityShipped :one
 UPDATE sales_order_items
 SET quantity_shipped = $2, updated_at = NOW()
@@ -413,3 +748,26 @@ func (q *Queries) UpdateSalesOrderStatus(ctx context.Context, arg UpdateSalesOrd
 	_, err := q.db.Exec(ctx, updateSalesOrderStatus, arg.Status, arg.ID, arg.TenantID)
 	return err
 }
+
+const updateSalesOrderTotals = `-- name: UpdateSalesOrderTotals :exec
+UPDATE sales_orders
+SET total_amount = $2, final_amount = $3, updated_at = NOW()
+WHERE id = $1 AND tenant_id = $4
+`
+
+type UpdateSalesOrderTotalsParams struct {
+	ID          uuid.UUID      `json:"id"`
+	TotalAmount pgtype.Numeric `json:"total_amount"`
+	FinalAmount pgtype.Numeric `json:"final_amount"`
+	TenantID    uuid.UUID      `json:"tenant_id"`
+}
+
+func (q *Queries) UpdateSalesOrderTotals(ctx context.Context, arg UpdateSalesOrderTotalsParams) error {
+	_, err := q.db.Exec(ctx, updateSalesOrderTotals,
+		arg.ID,
+		arg.TotalAmount,
+		arg.FinalAmount,
+		arg.TenantID,
+	)
+	return err
+}
@@ -7,6 +7,7 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -15,7 +16,7 @@ import (
 const createTenant = `-- name: CreateTenant :one
 INSERT INTO tenants (name, email, phone, address, registration_number)
 VALUES ($1, $2, $3, $4, $5)
-RETURNING id, name, email, phone, address, registration_number, is_active, created_at
+RETURNING id, name, email, phone, address, registration_number, is_active, created_at, require_two_factor
 `
 
 type CreateTenantParams struct {
@@ -44,12 +45,13 @@ func (q *Queries) CreateTenant(ctx context.Context, arg CreateTenantParams) (Ten
 		&i.RegistrationNumber,
 		&i.IsActive,
 		&i.CreatedAt,
+		&i.RequireTwoFactor,
 	)
 	return i, err
 }
 
 const getTenantByID = `-- name: GetTenantByID :one
-SELECT id, name, email, phone, address, registration_number, is_active, created_at FROM tenants
+SELECT id, name, email, phone, address, registration_number, is_active, created_at, require_two_factor FROM tenants
 WHERE id = $1
 `
 
@@ -65,12 +67,98 @@ func (q *Queries) GetTenantByID(ctx context.Context, id uuid.UUID) (Tenant, erro
 		&i.RegistrationNumber,
 		&i.IsActive,
 		&i.CreatedAt,
+		&i.RequireTwoFactor,
 	)
 	return i, err
 }
 
+const getTenantWithUsage = `-- name: GetTenantWithUsage :one
+SELECT t.id, t.name, t.email, t.phone, t.address, t.registration_number, t.is_active, t.created_at, t.require_two_factor,
+    (SELECT COUNT(*) FROM tenant_memberships m WHERE m.tenant_id = t.id) AS user_count,
+    (SELECT COUNT(*) FROM products p WHERE p.tenant_id = t.id) AS product_count,
+    (SELECT COUNT(*) FROM purchase_orders po WHERE po.tenant_id = t.id) AS purchase_order_count,
+    (SELECT COUNT(*) FROM sales_orders so WHERE so.tenant_id = t.id) AS sales_order_count
+FROM tenants t
+WHERE t.id = $1
+`
+
+type GetTenantWithUsageRow struct {
+	ID                 uuid.UUID   `json:"id"`
+	Name               string      `json:"name"`
+	Email              string      `json:"email"`
+	Phone              string      `json:"phone"`
+	Address            pgtype.Text `json:"address"`
+	RegistrationNumber pgtype.Text `json:"registration_number"`
+	IsActive           bool        `json:"is_active"`
+	CreatedAt          time.Time   `json:"created_at"`
+	RequireTwoFactor   bool        `json:"require_two_factor"`
+	UserCount          int64       `json:"user_count"`
+	ProductCount       int64       `json:"product_count"`
+	PurchaseOrderCount int64       `json:"purchase_order_count"`
+	SalesOrderCount    int64       `json:"sales_order_count"`
+}
+
+func (q *Queries) GetTenantWithUsage(ctx context.Context, id uuid.UUID) (GetTenantWithUsageRow, error) {
+	row := q.db.QueryRow(ctx, getTenantWithUsage, id)
+	var i GetTenantWithUsageRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Phone,
+		&i.Address,
+		&i.RegistrationNumber,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.RequireTwoFactor,
+		&i.UserCount,
+		&i.ProductCount,
+		&i.PurchaseOrderCount,
+		&i.SalesOrderCount,
+	)
+	return i, err
+}
+
+const isTenantActive = `-- name: IsTenantActive :one
+SELECT is_active FROM tenants
+WHERE id = $1
+`
+
+func (q *Queries) IsTenantActive(ctx context.Context, id uuid.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, isTenantActive, id)
+	var is_active bool
+	err := row.Scan(&is_active)
+	return is_active, err
+}
+
+const listActiveTenantIDs = `-- name: ListActiveTenantIDs :many
+SELECT id FROM tenants
+WHERE is_active
+ORDER BY created_at
+`
+
+func (q *Queries) ListActiveTenantIDs(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, listActiveTenantIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTenants = `-- name: ListTenants :many
-SELECT id, name, email, phone, address, registration_number, is_active, created_at FROM tenants
+SELECT id, name, email, phone, address, registration_number, is_active, created_at, require_two_factor FROM tenants
 ORDER BY created_at DESC
 LIMIT $1 OFFSET $2
 `
@@ -98,6 +186,7 @@ func (q *Queries) ListTenants(ctx context.Context, arg ListTenantsParams) ([]Ten
 			&i.RegistrationNumber,
 			&i.IsActive,
 			&i.CreatedAt,
+			&i.RequireTwoFactor,
 		); err != nil {
 			return nil, err
 		}
@@ -109,11 +198,146 @@ func (q *Queries) ListTenants(ctx context.Context, arg ListTenantsParams) ([]Ten
 	return items, nil
 }
 
+const listTenantsWithUsage = `-- name: ListTenantsWithUsage :many
+SELECT t.id, t.name, t.email, t.phone, t.address, t.registration_number, t.is_active, t.created_at, t.require_two_factor,
+    (SELECT COUNT(*) FROM tenant_memberships m WHERE m.tenant_id = t.id) AS user_count,
+    (SELECT COUNT(*) FROM products p WHERE p.tenant_id = t.id) AS product_count,
+    (SELECT COUNT(*) FROM purchase_orders po WHERE po.tenant_id = t.id) AS purchase_order_count,
+    (SELECT COUNT(*) FROM sales_orders so WHERE so.tenant_id = t.id) AS sales_order_count
+FROM tenants t
+WHERE ($1::text IS NULL
+        OR t.name ILIKE '%' || $1 || '%'
+        OR t.email ILIKE '%' || $1 || '%')
+    AND ($2::boolean IS NULL OR t.is_active = $2)
+ORDER BY t.created_at DESC
+LIMIT $3 OFFSET $4
+`
+
+type ListTenantsWithUsageParams struct {
+	Search   pgtype.Text `json:"search"`
+	IsActive pgtype.Bool `json:"is_active"`
+	Limit    int32       `json:"limit"`
+	Offset   int32       `json:"offset"`
+}
+
+type ListTenantsWithUsageRow struct {
+	ID                 uuid.UUID   `json:"id"`
+	Name               string      `json:"name"`
+	Email              string      `json:"email"`
+	Phone              string      `json:"phone"`
+	Address            pgtype.Text `json:"address"`
+	RegistrationNumber pgtype.Text `json:"registration_number"`
+	IsActive           bool        `json:"is_active"`
+	CreatedAt          time.Time   `json:"created_at"`
+	RequireTwoFactor   bool        `json:"require_two_factor"`
+	UserCount          int64       `json:"user_count"`
+	ProductCount       int64       `json:"product_count"`
+	PurchaseOrderCount int64       `json:"purchase_order_count"`
+	SalesOrderCount    int64       `json:"sales_order_count"`
+}
+
+func (q *Queries) ListTenantsWithUsage(ctx context.Context, arg ListTenantsWithUsageParams) ([]ListTenantsWithUsageRow, error) {
+	rows, err := q.db.Query(ctx, listTenantsWithUsage,
+		arg.Search,
+		arg.IsActive,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTenantsWithUsageRow{}
+	for rows.Next() {
+		var i ListTenantsWithUsageRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Email,
+			&i.Phone,
+			&i.Address,
+			&i.RegistrationNumber,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.RequireTwoFactor,
+			&i.UserCount,
+			&i.ProductCount,
+			&i.PurchaseOrderCount,
+			&i.SalesOrderCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setTenantActive = `-- name: SetTenantActive :one
+UPDATE tenants
+SET is_active = $2
+WHERE id = $1
+RETURNING id, name, email, phone, address, registration_number, is_active, created_at, require_two_factor
+`
+
+type SetTenantActiveParams struct {
+	ID       uuid.UUID `json:"id"`
+	IsActive bool      `json:"is_active"`
+}
+
+func (q *Queries) SetTenantActive(ctx context.Context, arg SetTenantActiveParams) (Tenant, error) {
+	row := q.db.QueryRow(ctx, setTenantActive, arg.ID, arg.IsActive)
+	var i Tenant
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Phone,
+		&i.Address,
+		&i.RegistrationNumber,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.RequireTwoFactor,
+	)
+	return i, err
+}
+
+const setTenantRequireTwoFactor = `-- name: SetTenantRequireTwoFactor :one
+UPDATE tenants
+SET require_two_factor = $2
+WHERE id = $1
+RETURNING id, name, email, phone, address, registration_number, is_active, created_at, require_two_factor
+`
+
+type SetTenantRequireTwoFactorParams struct {
+	ID               uuid.UUID `json:"id"`
+	RequireTwoFactor bool      `json:"require_two_factor"`
+}
+
+func (q *Queries) SetTenantRequireTwoFactor(ctx context.Context, arg SetTenantRequireTwoFactorParams) (Tenant, error) {
+	row := q.db.QueryRow(ctx, setTenantRequireTwoFactor, arg.ID, arg.RequireTwoFactor)
+	var i Tenant
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Phone,
+		&i.Address,
+		&i.RegistrationNumber,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.RequireTwoFactor,
+	)
+	return i, err
+}
+
 const updateTenant = `-- name: UpdateTenant :one
 UPDATE tenants
 SET name = $2, email = $3, phone = $4, address = $5, registration_number = $6, is_active = $7
 WHERE id = $1
-RETURNING id, name, email, phone, address, registration_number, is_active, created_at
+RETURNING id, name, email, phone, address, registration_number, is_active, created_at, require_two_factor
 `
 
 type UpdateTenantParams struct {
@@ -146,6 +370,7 @@ func (q *Queries) UpdateTenant(ctx context.Context, arg UpdateTenantParams) (Ten
 		&i.RegistrationNumber,
 		&i.IsActive,
 		&i.CreatedAt,
+		&i.RequireTwoFactor,
 	)
 	return i, err
 }
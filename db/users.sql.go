@@ -13,18 +13,17 @@ import (
 )
 
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (name, email, password, phone, tenant_id, role)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, name, email, password, phone, tenant_id, role, email_verified, is_active, created_at
+INSERT INTO users (name, email, password, phone, tenant_id)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, name, email, password, phone, tenant_id, email_verified, is_active, created_at
 `
 
 type CreateUserParams struct {
-	Name     string      `json:"name"`
-	Email    string      `json:"email"`
-	Password string      `json:"password"`
-	Phone    string      `json:"phone"`
-	TenantID uuid.UUID   `json:"tenant_id"`
-	Role     interface{} `json:"role"`
+	Name     string    `json:"name"`
+	Email    string    `json:"email"`
+	Password string    `json:"password"`
+	Phone    string    `json:"phone"`
+	TenantID uuid.UUID `json:"tenant_id"`
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
@@ -34,7 +33,6 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.Password,
 		arg.Phone,
 		arg.TenantID,
-		arg.Role,
 	)
 	var i User
 	err := row.Scan(
@@ -44,7 +42,6 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Password,
 		&i.Phone,
 		&i.TenantID,
-		&i.Role,
 		&i.EmailVerified,
 		&i.IsActive,
 		&i.CreatedAt,
@@ -52,8 +49,107 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 	return i, err
 }
 
+const findUserByEmail = `-- name: FindUserByEmail :one
+SELECT id, name, email, password, phone, tenant_id, email_verified, is_active, created_at FROM users
+WHERE lower(email) = lower($1)
+`
+
+func (q *Queries) FindUserByEmail(ctx context.Context, lower string) (User, error) {
+	row := q.db.QueryRow(ctx, findUserByEmail, lower)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.Phone,
+		&i.TenantID,
+		&i.EmailVerified,
+		&i.IsActive,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getTenantAdmin = `-- name: GetTenantAdmin :one
+SELECT u.id, u.name, u.email, u.password, u.phone, u.tenant_id, u.email_verified, u.is_active, u.created_at, m.id, m.tenant_id, m.user_id, m.role, m.is_active, m.invited_by, m.created_at FROM users u
+JOIN tenant_memberships m ON m.user_id = u.id
+WHERE m.tenant_id = $1 AND m.role = 'admin' AND m.is_active AND u.is_active IS NOT FALSE
+ORDER BY m.created_at
+LIMIT 1
+`
+
+type GetTenantAdminRow struct {
+	User             User             `json:"user"`
+	TenantMembership TenantMembership `json:"tenant_membership"`
+}
+
+func (q *Queries) GetTenantAdmin(ctx context.Context, tenantID uuid.UUID) (GetTenantAdminRow, error) {
+	row := q.db.QueryRow(ctx, getTenantAdmin, tenantID)
+	var i GetTenantAdminRow
+	err := row.Scan(
+		&i.User.ID,
+		&i.User.Name,
+		&i.User.Email,
+		&i.User.Password,
+		&i.User.Phone,
+		&i.User.TenantID,
+		&i.User.EmailVerified,
+		&i.User.IsActive,
+		&i.User.CreatedAt,
+		&i.TenantMembership.ID,
+		&i.TenantMembership.TenantID,
+		&i.TenantMembership.UserID,
+		&i.TenantMembership.Role,
+		&i.TenantMembership.IsActive,
+		&i.TenantMembership.InvitedBy,
+		&i.TenantMembership.CreatedAt,
+	)
+	return i, err
+}
+
+const getTenantMember = `-- name: GetTenantMember :one
+SELECT u.id, u.name, u.email, u.password, u.phone, u.tenant_id, u.email_verified, u.is_active, u.created_at, m.id, m.tenant_id, m.user_id, m.role, m.is_active, m.invited_by, m.created_at FROM users u
+JOIN tenant_memberships m ON m.user_id = u.id
+WHERE u.id = $1 AND m.tenant_id = $2
+`
+
+type GetTenantMemberParams struct {
+	ID       uuid.UUID `json:"id"`
+	TenantID uuid.UUID `json:"tenant_id"`
+}
+
+type GetTenantMemberRow struct {
+	User             User             `json:"user"`
+	TenantMembership TenantMembership `json:"tenant_membership"`
+}
+
+func (q *Queries) GetTenantMember(ctx context.Context, arg GetTenantMemberParams) (GetTenantMemberRow, error) {
+	row := q.db.QueryRow(ctx, getTenantMember, arg.ID, arg.TenantID)
+	var i GetTenantMemberRow
+	err := row.Scan(
+		&i.User.ID,
+		&i.User.Name,
+		&i.User.Email,
+		&i.User.Password,
+		&i.User.Phone,
+		&i.User.TenantID,
+		&i.User.EmailVerified,
+		&i.User.IsActive,
+		&i.User.CreatedAt,
+		&i.TenantMembership.ID,
+		&i.TenantMembership.TenantID,
+		&i.TenantMembership.UserID,
+		&i.TenantMembership.Role,
+		&i.TenantMembership.IsActive,
+		&i.TenantMembership.InvitedBy,
+		&i.TenantMembership.CreatedAt,
+	)
+	return i, err
+}
+
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, name, email, password, phone, tenant_id, role, email_verified, is_active, created_at FROM users
+SELECT id, name, email, password, phone, tenant_id, email_verified, is_active, created_at FROM users
 WHERE email = $1 AND tenant_id = $2
 `
 
@@ -72,7 +168,6 @@ func (q *Queries) GetUserByEmail(ctx context.Context, arg GetUserByEmailParams)
 		&i.Password,
 		&i.Phone,
 		&i.TenantID,
-		&i.Role,
 		&i.EmailVerified,
 		&i.IsActive,
 		&i.CreatedAt,
@@ -81,7 +176,7 @@ func (q *Queries) GetUserByEmail(ctx context.Context, arg GetUserByEmailParams)
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, name, email, password, phone, tenant_id, role, email_verified, is_active, created_at FROM users
+SELECT id, name, email, password, phone, tenant_id, email_verified, is_active, created_at FROM users
 WHERE id = $1
 `
 
@@ -95,7 +190,6 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.Password,
 		&i.Phone,
 		&i.TenantID,
-		&i.Role,
 		&i.EmailVerified,
 		&i.IsActive,
 		&i.CreatedAt,
@@ -103,22 +197,29 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
 	return i, err
 }
 
-const listUsersByRole = `-- name: ListUsersByRole :many
-SELECT id, name, email, password, phone, tenant_id, role, email_verified, is_active, created_at FROM users
-WHERE tenant_id = $1 AND role = $2
-ORDER BY name
+const listUsers = `-- name: ListUsers :many
+SELECT u.id, u.name, u.email, u.password, u.phone, u.tenant_id, u.email_verified, u.is_active, u.created_at, m.id, m.tenant_id, m.user_id, m.role, m.is_active, m.invited_by, m.created_at FROM users u
+JOIN tenant_memberships m ON m.user_id = u.id
+WHERE m.tenant_id = $1
+    AND ($2::text IS NULL OR m.role::text = $2)
+ORDER BY u.name
 LIMIT $3 OFFSET $4
 `
 
-type ListUsersByRoleParams struct {
+type ListUsersParams struct {
 	TenantID uuid.UUID   `json:"tenant_id"`
-	Role     interface{} `json:"role"`
+	Role     pgtype.Text `json:"role"`
 	Limit    int32       `json:"limit"`
 	Offset   int32       `json:"offset"`
 }
 
-func (q *Queries) ListUsersByRole(ctx context.Context, arg ListUsersByRoleParams) ([]User, error) {
-	rows, err := q.db.Query(ctx, listUsersByRole,
+type ListUsersRow struct {
+	User             User             `json:"user"`
+	TenantMembership TenantMembership `json:"tenant_membership"`
+}
+
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUsersRow, error) {
+	rows, err := q.db.Query(ctx, listUsers,
 		arg.TenantID,
 		arg.Role,
 		arg.Limit,
@@ -128,20 +229,26 @@ func (q *Queries) ListUsersByRole(ctx context.Context, arg ListUsersByRoleParams
 		return nil, err
 	}
 	defer rows.Close()
-	items := []User{}
+	items := []ListUsersRow{}
 	for rows.Next() {
-		var i User
+		var i ListUsersRow
 		if err := rows.Scan(
-			&i.ID,
-			&i.Name,
-			&i.Email,
-			&i.Password,
-			&i.Phone,
-			&i.TenantID,
-			&i.Role,
-			&i.EmailVerified,
-			&i.IsActive,
-			&i.CreatedAt,
+			&i.User.ID,
+			&i.User.Name,
+			&i.User.Email,
+			&i.User.Password,
+			&i.User.Phone,
+			&i.User.TenantID,
+			&i.User.EmailVerified,
+			&i.User.IsActive,
+			&i.User.CreatedAt,
+			&i.TenantMembership.ID,
+			&i.TenantMembership.TenantID,
+			&i.TenantMembership.UserID,
+			&i.TenantMembership.Role,
+			&i.TenantMembership.IsActive,
+			&i.TenantMembership.InvitedBy,
+			&i.TenantMembership.CreatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -153,11 +260,22 @@ func (q *Queries) ListUsersByRole(ctx context.Context, arg ListUsersByRoleParams
 	return items, nil
 }
 
+const setUserEmailVerified = `-- name: SetUserEmailVerified :exec
+UPDATE users
+SET email_verified = TRUE
+WHERE id = $1
+`
+
+func (q *Queries) SetUserEmailVerified(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, setUserEmailVerified, id)
+	return err
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
-SET name = $2, email = $3, phone = $4, role = $5, email_verified = $6
-WHERE id = $1 AND tenant_id = $7
-RETURNING id, name, email, password, phone, tenant_id, role, email_verified, is_active, created_at
+SET name = $2, email = $3, phone = $4, email_verified = $5
+WHERE id = $1 AND tenant_id = $6
+RETURNING id, name, email, password, phone, tenant_id, email_verified, is_active, created_at
 `
 
 type UpdateUserParams struct {
@@ -165,7 +283,6 @@ type UpdateUserParams struct {
 	Name          string      `json:"name"`
 	Email         string      `json:"email"`
 	Phone         string      `json:"phone"`
-	Role          interface{} `json:"role"`
 	EmailVerified pgtype.Bool `json:"email_verified"`
 	TenantID      uuid.UUID   `json:"tenant_id"`
 }
@@ -176,7 +293,6 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		arg.Name,
 		arg.Email,
 		arg.Phone,
-		arg.Role,
 		arg.EmailVerified,
 		arg.TenantID,
 	)
@@ -188,7 +304,6 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.Password,
 		&i.Phone,
 		&i.TenantID,
-		&i.Role,
 		&i.EmailVerified,
 		&i.IsActive,
 		&i.CreatedAt,
@@ -199,16 +314,28 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 const updateUserPassword = `-- name: UpdateUserPassword :exec
 UPDATE users
 SET password = $1
-WHERE id = $2 AND tenant_id = $3
+WHERE id = $2
 `
 
 type UpdateUserPasswordParams struct {
 	Password string    `json:"password"`
 	ID       uuid.UUID `json:"id"`
-	TenantID uuid.UUID `json:"tenant_id"`
 }
 
 func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error {
-	_, err := q.db.Exec(ctx, updateUserPassword, arg.Password, arg.ID, arg.TenantID)
+	_, err := q.db.Exec(ctx, updateUserPassword, arg.Password, arg.ID)
 	return err
 }
+
+const userEmailExists = `-- name: UserEmailExists :one
+SELECT EXISTS (
+    SELECT 1 FROM users WHERE lower(email) = lower($1)
+)
+`
+
+func (q *Queries) UserEmailExists(ctx context.Context, lower string) (bool, error) {
+	row := q.db.QueryRow(ctx, userEmailExists, lower)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
@@ -0,0 +1,256 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"agromart2/db"
+	"agromart2/internal/database"
+	"agromart2/internal/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+// APIKeyPrefix starts every API key so keys can be told from JWTs and spotted in leaks
+const APIKeyPrefix = "agm_"
+
+// APIKeyHeader can carry an API key instead of the Authorization header
+const APIKeyHeader = "X-API-Key"
+
+var (
+	ErrInvalidAPIKey      = errors.New("invalid, expired or revoked API key")
+	ErrAPIKeyNameRequired = errors.New("API key name is required")
+	ErrInvalidScope       = errors.New("invalid API key scope")
+	ErrInvalidExpiry      = errors.New("API key expiry must be in the future")
+)
+
+// APIKey is an API key as exposed by the API; the secret part is never stored
+type APIKey struct {
+	ID         uuid.UUID    `json:"id"`
+	Name       string       `json:"name"`
+	Prefix     string       `json:"prefix"`
+	Scopes     []Permission `json:"scopes"`
+	CreatedBy  uuid.UUID    `json:"created_by"`
+	ExpiresAt  *time.Time   `json:"expires_at"`
+	LastUsedAt *time.Time   `json:"last_used_at"`
+	RevokedAt  *time.Time   `json:"revoked_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+func newAPIKey(k db.ApiKey) APIKey {
+	scopes := make([]Permission, len(k.Scopes))
+	for i, s := range k.Scopes {
+		scopes[i] = Permission(s)
+	}
+	return APIKey{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     scopes,
+		CreatedBy:  k.CreatedBy,
+		ExpiresAt:  timePtr(k.ExpiresAt),
+		LastUsedAt: timePtr(k.LastUsedAt),
+		RevokedAt:  timePtr(k.RevokedAt),
+		CreatedAt:  k.CreatedAt,
+	}
+}
+
+// CreatedAPIKey is returned once, when the key is created; Key cannot be retrieved again
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// CreateAPIKeyParams describes a new API key. ExpiresAt is optional.
+type CreateAPIKeyParams struct {
+	Name      string       `json:"name"`
+	Scopes    []Permission `json:"scopes"`
+	ExpiresAt *time.Time   `json:"expires_at"`
+}
+
+// APIKeyScopes lists the permissions an API key can be granted: everything except
+// managing users and the platform, so a leaked key can never mint more keys, promote
+// anyone or reach other tenants
+func APIKeyScopes() []Permission {
+	scopes := make([]Permission, 0, len(Permissions))
+	for _, p := range Permissions {
+		if p != PermUsersManage && p != PermPlatformManage {
+			scopes = append(scopes, p)
+		}
+	}
+	return scopes
+}
+
+// newAPIKeySecret returns a key of the form agm_<prefix>_<secret> and its public prefix
+func newAPIKeySecret() (key, prefix string, err error) {
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	prefix = APIKeyPrefix + hex.EncodeToString(id)
+	return prefix + "_" + base64.RawURLEncoding.EncodeToString(secret), prefix, nil
+}
+
+// APIKeyFromRequest returns the API key a request authenticates with, if any: either the
+// X-API-Key header or a Bearer token that is an API key rather than a JWT
+func APIKeyFromRequest(r *http.Request) string {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return key
+	}
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && strings.HasPrefix(token, APIKeyPrefix) {
+		return token
+	}
+	return ""
+}
+
+// AuthenticateAPIKey returns the key behind a request if it is neither expired nor revoked,
+// and notes that it was used
+func (s *AuthService) AuthenticateAPIKey(ctx context.Context, key string) (*db.ApiKey, error) {
+	ctx = database.WithSystem(ctx)
+
+	if !strings.HasPrefix(key, APIKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	apiKey, err := s.queries.GetAPIKeyByHash(ctx, hashToken(key))
+	if err != nil {
+		if database.IsNotFound(err) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	if apiKey.RevokedAt.Valid || (apiKey.ExpiresAt.Valid && time.Now().After(apiKey.ExpiresAt.Time)) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err := checkTenantActive(ctx, s.queries, apiKey.TenantID); err != nil {
+		return nil, err
+	}
+
+	if err := s.queries.TouchAPIKey(ctx, apiKey.ID); err != nil {
+		log.Error().Err(err).Str("api_key", apiKey.Prefix).Msg("failed to record API key use")
+	}
+
+	return &apiKey, nil
+}
+
+// CreateAPIKey issues a key for the actor's tenant. Its scopes must be permissions the actor
+// holds, so nobody can hand a machine more access than they have themselves.
+func (s *UserService) CreateAPIKey(ctx context.Context, actor Actor, params CreateAPIKeyParams, client ClientInfo) (*CreatedAPIKey, error) {
+	params.Name = strings.TrimSpace(params.Name)
+	if params.Name == "" {
+		return nil, ErrAPIKeyNameRequired
+	}
+	if len(params.Scopes) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidScope)
+	}
+
+	grantable := map[Permission]bool{}
+	for _, p := range APIKeyScopes() {
+		grantable[p] = true
+	}
+	seen := map[Permission]bool{}
+	scopes := make([]string, 0, len(params.Scopes))
+	for _, p := range params.Scopes {
+		if !grantable[p] || !HasPermission(actor.Role, p) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidScope, p)
+		}
+		if !seen[p] {
+			seen[p] = true
+			scopes = append(scopes, string(p))
+		}
+	}
+
+	if params.ExpiresAt != nil && !params.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidExpiry
+	}
+
+	key, prefix, err := newAPIKeySecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	apiKey, err := s.queries.CreateAPIKey(ctx, db.CreateAPIKeyParams{
+		TenantID:  actor.TenantID,
+		Name:      params.Name,
+		Prefix:    prefix,
+		KeyHash:   hashToken(key),
+		Scopes:    scopes,
+		CreatedBy: actor.UserID,
+		ExpiresAt: utils.P.TimestamptzPtr(params.ExpiresAt),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	s.recordAPIKeyEvent(ctx, actor, EventAPIKeyCreated, client, map[string]interface{}{
+		"api_key_id": apiKey.ID,
+		"prefix":     apiKey.Prefix,
+		"scopes":     apiKey.Scopes,
+	})
+
+	return &CreatedAPIKey{APIKey: newAPIKey(apiKey), Key: key}, nil
+}
+
+// ListAPIKeys lists the tenant's API keys, including revoked and expired ones
+func (s *UserService) ListAPIKeys(ctx context.Context, tenantID uuid.UUID) ([]APIKey, error) {
+	keys, err := s.queries.ListAPIKeys(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+
+	views := make([]APIKey, 0, len(keys))
+	for _, k := range keys {
+		views = append(views, newAPIKey(k))
+	}
+	return views, nil
+}
+
+// RevokeAPIKey stops a key of the actor's tenant from working; the next request made with
+// it is rejected
+func (s *UserService) RevokeAPIKey(ctx context.Context, actor Actor, keyID uuid.UUID, client ClientInfo) error {
+	n, err := s.queries.RevokeAPIKey(ctx, db.RevokeAPIKeyParams{
+		ID:       keyID,
+		TenantID: actor.TenantID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	if n == 0 {
+		return database.ErrNotFound
+	}
+
+	s.recordAPIKeyEvent(ctx, actor, EventAPIKeyRevoked, client, map[string]interface{}{
+		"api_key_id": keyID,
+	})
+	return nil
+}
+
+// recordAPIKeyEvent records a key being created or revoked against the admin who did it
+func (s *UserService) recordAPIKeyEvent(ctx context.Context, actor Actor, eventType string, client ClientInfo, details map[string]interface{}) {
+	user, err := s.queries.GetUserByID(ctx, actor.UserID)
+	if err != nil {
+		return
+	}
+	recordSecurityEvent(ctx, s.queries, eventType, &user, user.Email, client, details)
+}
+
+// timePtr returns nil for a NULL timestamp
+func timePtr(t pgtype.Timestamptz) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
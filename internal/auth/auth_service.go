@@ -4,23 +4,39 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"agromart2/db"
+	"agromart2/internal/database"
+	"agromart2/internal/mailer"
 	"agromart2/internal/utils"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrAccountDeactivated = errors.New("account is deactivated")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrTenantSuspended    = errors.New("organization is suspended")
+)
+
 type AuthService struct {
 	db      *pgxpool.Pool
 	queries *db.Queries
 	jwt     *JWTService
+	mailer  mailer.Mailer
+	appURL  string
+	// requireVerifiedEmail refuses logins until the user has verified their email
+	requireVerifiedEmail bool
 }
 
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=6"`
+	// TenantID picks which of the user's tenants to log into; by default it is their home
+	// tenant
+	TenantID *uuid.UUID `json:"tenant_id,omitempty"`
 }
 
 type RegisterRequest struct {
@@ -29,30 +45,55 @@ type RegisterRequest struct {
 	Password    string `json:"password" validate:"required,min=6"`
 	Phone       string `json:"phone" validate:"required"`
 	CompanyName string `json:"company_name" validate:"required"`
-	Role        string `json:"role,omitempty"`
 }
 
+// AuthResponse carries the new session. Token and RefreshToken are empty after Register
+// when the email must be verified before logging in, and after a Login that has to be
+// completed with a second factor: ChallengeToken is then passed to VerifyTwoFactor.
 type AuthResponse struct {
-	User         *db.User `json:"user"`
-	Token        string   `json:"token"`
-	RefreshToken string   `json:"refresh_token"`
+	User                   *db.User `json:"user,omitempty"`
+	Token                  string   `json:"token,omitempty"`
+	RefreshToken           string   `json:"refresh_token,omitempty"`
+	TwoFactorRequired      bool     `json:"two_factor_required,omitempty"`
+	TwoFactorSetupRequired bool     `json:"two_factor_setup_required,omitempty"`
+	ChallengeToken         string   `json:"challenge_token,omitempty"`
+	// RecoveryCodes is only set when two-factor was enabled as part of logging in
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+	// TenantID and Role are those of the tenant the session is logged into; Memberships
+	// lists every tenant the user can switch to
+	TenantID    *uuid.UUID   `json:"tenant_id,omitempty"`
+	Role        string       `json:"role,omitempty"`
+	Memberships []Membership `json:"memberships,omitempty"`
 }
 
 type UserWithTenant struct {
-	User   db.User   `json:"user"`
-	Tenant db.Tenant `json:"tenant"`
+	User        db.User      `json:"user"`
+	Tenant      db.Tenant    `json:"tenant"`
+	Role        string       `json:"role"`
+	Permissions []Permission `json:"permissions"`
+	Memberships []Membership `json:"memberships"`
 }
 
-func NewAuthService(dbPool *pgxpool.Pool, queries *db.Queries, jwtService *JWTService) *AuthService {
+func NewAuthService(dbPool *pgxpool.Pool, queries *db.Queries, jwtService *JWTService, m mailer.Mailer, appURL string, requireVerifiedEmail bool) *AuthService {
 	return &AuthService{
-		db:      dbPool,
-		queries: queries,
-		jwt:     jwtService,
+		db:                   dbPool,
+		queries:              queries,
+		jwt:                  jwtService,
+		mailer:               m,
+		appURL:               appURL,
+		requireVerifiedEmail: requireVerifiedEmail,
 	}
 }
 
+// RequiresVerifiedEmail reports whether users must verify their email before logging in
+func (s *AuthService) RequiresVerifiedEmail() bool {
+	return s.requireVerifiedEmail
+}
+
 // Register creates a new user and tenant
-func (s *AuthService) Register(ctx context.Context, req RegisterRequest) (*AuthResponse, error) {
+func (s *AuthService) Register(ctx context.Context, req RegisterRequest, client ClientInfo) (*AuthResponse, error) {
+	ctx = database.WithSystem(ctx)
+
 	// Start transaction
 	tx, err := s.db.Begin(ctx)
 	if err != nil {
@@ -80,12 +121,6 @@ func (s *AuthService) Register(ctx context.Context, req RegisterRequest) (*AuthR
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	// Set default role if not provided
-	role := req.Role
-	if role == "" {
-		role = "admin" // First user in tenant is admin
-	}
-
 	// Create user
 	user, err := qtx.CreateUser(ctx, db.CreateUserParams{
 		Name:     req.Name,
@@ -93,70 +128,100 @@ func (s *AuthService) Register(ctx context.Context, req RegisterRequest) (*AuthR
 		Password: string(hashedPassword),
 		Phone:    req.Phone,
 		TenantID: tenant.ID,
-		Role:     role,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
-	// Commit transaction
-	if err = tx.Commit(ctx); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	// The user registering a tenant is its admin; other roles are only assigned by an admin
+	membership, err := qtx.CreateTenantMembership(ctx, db.CreateTenantMembershipParams{
+		TenantID: tenant.ID,
+		UserID:   user.ID,
+		Role:     RoleAdmin,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create membership: %w", err)
 	}
 
-	// Generate tokens
-	token, err := s.jwt.GenerateToken(user.ID.String(), user.TenantID.String(), user.Email, role)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+	// Without a verified email there is no session to start yet
+	response := &AuthResponse{User: &user}
+	if !s.requireVerifiedEmail {
+		response, err = s.issueTokens(ctx, qtx, user, membership, uuid.New(), client)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	refreshToken, err := s.jwt.GenerateRefreshToken(user.ID.String())
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return &AuthResponse{
-		User:         &user,
-		Token:        token,
-		RefreshToken: refreshToken,
-	}, nil
+	s.sendWelcomeVerification(ctx, user)
+
+	return response, nil
 }
 
 // Login authenticates a user
-func (s *AuthService) Login(ctx context.Context, req LoginRequest) (*AuthResponse, error) {
-	// First, we need to find the user by email across all tenants
-	// This is a simplified approach - in production, you might want tenant-specific login
-	user, err := s.getUserByEmailAcrossTenants(ctx, req.Email)
+func (s *AuthService) Login(ctx context.Context, req LoginRequest, client ClientInfo) (*AuthResponse, error) {
+	ctx = database.WithSystem(ctx)
+
+	keys := throttleKeys(req.Email, client)
+	if err := s.checkLoginThrottle(ctx, keys); err != nil {
+		var throttled *LoginThrottledError
+		if errors.As(err, &throttled) {
+			recordSecurityEvent(ctx, s.queries, EventLoginBlocked, nil, req.Email, client, nil)
+		}
+		return nil, err
+	}
+
+	// Accounts are global; the tenant is picked from the user's memberships below
+	found, err := s.queries.FindUserByEmail(ctx, req.Email)
 	if err != nil {
-		return nil, errors.New("invalid credentials")
+		if !database.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to find user: %w", err)
+		}
+		s.recordLoginFailure(ctx, req.Email, nil, client)
+		return nil, ErrInvalidCredentials
 	}
+	user := &found
 
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		return nil, errors.New("invalid credentials")
+		s.recordLoginFailure(ctx, req.Email, user, client)
+		return nil, ErrInvalidCredentials
 	}
 
 	// Check if user is active
-	if user.IsActive.Valid && !user.IsActive.Bool {
-		return nil, errors.New("account is deactivated")
+	if !isActive(*user) {
+		return nil, ErrAccountDeactivated
 	}
 
-	// Generate tokens
-	token, err := s.jwt.GenerateToken(user.ID.String(), user.TenantID.String(), user.Email, user.Role.(string))
+	membership, err := chooseMembership(ctx, s.queries, *user, req.TenantID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+		return nil, err
+	}
+
+	if s.requireVerifiedEmail && !isEmailVerified(*user) {
+		return nil, ErrEmailNotVerified
 	}
 
-	refreshToken, err := s.jwt.GenerateRefreshToken(user.ID.String())
+	// The account stays throttled until the second factor checks out too, so a stolen
+	// password cannot be used to reset the counter while guessing codes
+	challenge, err := s.twoFactorChallenge(ctx, *user)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+		return nil, err
+	}
+	if challenge != nil {
+		return challenge, nil
 	}
 
-	return &AuthResponse{
-		User:         user,
-		Token:        token,
-		RefreshToken: refreshToken,
-	}, nil
+	if err := clearAccountThrottle(ctx, s.queries, req.Email); err != nil {
+		return nil, err
+	}
+
+	// Each login starts a new session
+	return s.issueTokens(ctx, s.queries, *user, *membership, uuid.New(), client)
 }
 
 // GetUserByID retrieves a user by ID
@@ -168,21 +233,35 @@ func (s *AuthService) GetUserByID(ctx context.Context, userID uuid.UUID) (*db.Us
 	return &user, nil
 }
 
-// GetUserWithTenant retrieves user with tenant information
-func (s *AuthService) GetUserWithTenant(ctx context.Context, userID uuid.UUID) (*UserWithTenant, error) {
-	user, err := s.queries.GetUserByID(ctx, userID)
+// GetUserWithTenant retrieves user with the tenant they are logged into and their role there
+func (s *AuthService) GetUserWithTenant(ctx context.Context, userID, tenantID uuid.UUID) (*UserWithTenant, error) {
+	member, err := s.queries.GetTenantMember(ctx, db.GetTenantMemberParams{
+		ID:       userID,
+		TenantID: tenantID,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("user not found: %w", err)
 	}
 
-	tenant, err := s.queries.GetTenantByID(ctx, user.TenantID)
+	tenant, err := s.queries.GetTenantByID(ctx, tenantID)
 	if err != nil {
 		return nil, fmt.Errorf("tenant not found: %w", err)
 	}
 
+	// The user's other tenants are outside the request's tenant scope
+	memberships, err := listMemberships(database.WithSystem(ctx), s.queries, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	role, _ := member.TenantMembership.Role.(string)
+
 	return &UserWithTenant{
-		User:   user,
-		Tenant: tenant,
+		User:        member.User,
+		Tenant:      tenant,
+		Role:        role,
+		Permissions: PermissionsForRole(role),
+		Memberships: memberships,
 	}, nil
 }
 
@@ -191,117 +270,104 @@ func (s *AuthService) ValidateToken(tokenStr string) (*Claims, error) {
 	return s.jwt.ValidateToken(tokenStr)
 }
 
-// RefreshToken generates a new access token from refresh token
-func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*AuthResponse, error) {
-	claims, err := s.jwt.ValidateRefreshToken(refreshToken)
+// RefreshToken rotates a refresh token: the presented token is spent and a new refresh and
+// access token pair is issued in the same session. Presenting an already spent token means
+// it was copied, so the whole session is revoked.
+func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string, client ClientInfo) (*AuthResponse, error) {
+	ctx = database.WithSystem(ctx)
+
+	tx, err := s.db.Begin(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("invalid refresh token: %w", err)
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
 	}
+	defer tx.Rollback(ctx)
 
-	userID, err := uuid.Parse(claims.UserID)
+	qtx := s.queries.WithTx(tx)
+
+	stored, err := qtx.GetRefreshTokenByHashForUpdate(ctx, hashToken(refreshToken))
 	if err != nil {
-		return nil, fmt.Errorf("invalid user ID in token: %w", err)
+		if database.IsNotFound(err) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
 	}
 
-	user, err := s.GetUserByID(ctx, userID)
+	if stored.RevokedAt.Valid || time.Now().After(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if stored.UsedAt.Valid {
+		if err := qtx.RevokeRefreshTokenFamily(ctx, stored.FamilyID); err != nil {
+			return nil, fmt.Errorf("failed to revoke session: %w", err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	user, err := qtx.GetUserByID(ctx, stored.UserID)
 	if err != nil {
 		return nil, fmt.Errorf("user not found: %w", err)
 	}
 
-	// Generate new tokens
-	token, err := s.jwt.GenerateToken(user.ID.String(), user.TenantID.String(), user.Email, user.Role.(string))
+	if !isActive(user) {
+		return nil, ErrAccountDeactivated
+	}
+
+	// The session stays in the tenant it was started in
+	membership, err := activeMembership(ctx, qtx, user.ID, stored.TenantID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+		return nil, err
+	}
+
+	if err := qtx.MarkRefreshTokenUsed(ctx, stored.ID); err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
 	}
 
-	newRefreshToken, err := s.jwt.GenerateRefreshToken(user.ID.String())
+	response, err := s.issueTokens(ctx, qtx, user, *membership, stored.FamilyID, client)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+		return nil, err
 	}
 
-	return &AuthResponse{
-		User:         user,
-		Token:        token,
-		RefreshToken: newRefreshToken,
-	}, nil
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return response, nil
+}
+
+// CheckPassword verifies a user's current password without starting a session
+func (s *AuthService) CheckPassword(ctx context.Context, userID uuid.UUID, password string) error {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return ErrInvalidCredentials
+	}
+	return nil
 }
 
 // UpdatePassword updates user password
-func (s *AuthService) UpdatePassword(ctx context.Context, userID, tenantID uuid.UUID, newPassword string) error {
+func (s *AuthService) UpdatePassword(ctx context.Context, userID uuid.UUID, newPassword string, client ClientInfo) error {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
 	if err != nil {
 		return fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	err = s.queries.UpdateUserPassword(ctx, db.UpdateUserPasswordParams{
+	// The account may have been created in another of the user's tenants
+	err = s.queries.UpdateUserPassword(database.WithSystem(ctx), db.UpdateUserPasswordParams{
 		Password: string(hashedPassword),
 		ID:       userID,
-		TenantID: tenantID,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to update password: %w", err)
 	}
 
-	return nil
-}
-
-// ListUsers lists users for a tenant
-func (s *AuthService) ListUsers(ctx context.Context, tenantID uuid.UUID, role string, limit, offset int32) ([]db.User, error) {
-	users, err := s.queries.ListUsersByRole(ctx, db.ListUsersByRoleParams{
-		TenantID: tenantID,
-		Role:     role,
-		Limit:    limit,
-		Offset:   offset,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to list users: %w", err)
-	}
-	return users, nil
-}
-
-// UpdateUser updates user information
-func (s *AuthService) UpdateUser(ctx context.Context, userID, tenantID uuid.UUID, name, email, phone, role string, emailVerified bool) (*db.User, error) {
-	user, err := s.queries.UpdateUser(ctx, db.UpdateUserParams{
-		ID:            userID,
-		Name:          name,
-		Email:         email,
-		Phone:         phone,
-		Role:          role,
-		EmailVerified: utils.P.Bool(emailVerified),
-		TenantID:      tenantID,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to update user: %w", err)
+	if user, err := s.queries.GetUserByID(ctx, userID); err == nil {
+		recordSecurityEvent(ctx, s.queries, EventPasswordChanged, &user, user.Email, client, nil)
 	}
-	return &user, nil
-}
 
-// Helper function to find user by email across tenants
-func (s *AuthService) getUserByEmailAcrossTenants(ctx context.Context, email string) (*db.User, error) {
-	// This is a simplified approach. In production, you might want to:
-	// 1. Have a separate query that doesn't require tenant_id
-	// 2. Or implement tenant-specific login domains
-	
-	// For now, we'll query the database directly
-	query := `SELECT id, name, email, password, phone, tenant_id, role, email_verified, is_active, created_at 
-			  FROM users WHERE email = $1 AND (is_active IS NULL OR is_active = true) LIMIT 1`
-	
-	var user db.User
-	err := s.db.QueryRow(ctx, query, email).Scan(
-		&user.ID,
-		&user.Name,
-		&user.Email,
-		&user.Password,
-		&user.Phone,
-		&user.TenantID,
-		&user.Role,
-		&user.EmailVerified,
-		&user.IsActive,
-		&user.CreatedAt,
-	)
-	if err != nil {
-		return nil, err
-	}
-	
-	return &user, nil
+	return nil
 }
@@ -0,0 +1,283 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"agromart2/db"
+	"agromart2/internal/database"
+	"agromart2/internal/mailer"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Purposes of single-use user tokens
+const (
+	TokenPurposeVerifyEmail   = "verify_email"
+	TokenPurposeResetPassword = "reset_password"
+	TokenPurposeTwoFactor     = "two_factor"
+)
+
+// How long an emailed link stays valid. Reset links are short-lived because they grant
+// access to the account.
+const (
+	VerifyEmailTTL   = 48 * time.Hour
+	PasswordResetTTL = time.Hour
+)
+
+var (
+	ErrEmailNotVerified = errors.New("email address has not been verified")
+	ErrInvalidToken     = errors.New("link is invalid or has expired")
+)
+
+// isEmailVerified treats a NULL email_verified as not verified
+func isEmailVerified(u db.User) bool {
+	return u.EmailVerified.Valid && u.EmailVerified.Bool
+}
+
+// createUserToken replaces any unused token the user has for purpose with a new one and
+// returns the token to email; only its hash is stored
+func createUserToken(ctx context.Context, q *db.Queries, user db.User, purpose string, ttl time.Duration) (string, time.Time, error) {
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	if err := q.DeleteUnusedUserTokens(ctx, db.DeleteUnusedUserTokensParams{
+		UserID:  user.ID,
+		Purpose: purpose,
+	}); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to replace token: %w", err)
+	}
+
+	stored, err := q.CreateUserToken(ctx, db.CreateUserTokenParams{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to create token: %w", err)
+	}
+	return token, stored.ExpiresAt, nil
+}
+
+// lookupUserToken locks a valid token and returns it with its user. The row lock makes a
+// second redemption of the same token wait, then fail.
+func lookupUserToken(ctx context.Context, q *db.Queries, token, purpose string) (db.UserToken, db.User, error) {
+	if token == "" {
+		return db.UserToken{}, db.User{}, ErrInvalidToken
+	}
+
+	stored, err := q.GetUserTokenForUpdate(ctx, db.GetUserTokenForUpdateParams{
+		TokenHash: hashToken(token),
+		Purpose:   purpose,
+	})
+	if err != nil {
+		if database.IsNotFound(err) {
+			return db.UserToken{}, db.User{}, ErrInvalidToken
+		}
+		return db.UserToken{}, db.User{}, fmt.Errorf("failed to get token: %w", err)
+	}
+	if stored.UsedAt.Valid || time.Now().After(stored.ExpiresAt) {
+		return db.UserToken{}, db.User{}, ErrInvalidToken
+	}
+
+	user, err := q.GetUserByID(ctx, stored.UserID)
+	if err != nil {
+		return db.UserToken{}, db.User{}, fmt.Errorf("user not found: %w", err)
+	}
+	if !isActive(user) {
+		return db.UserToken{}, db.User{}, ErrInvalidToken
+	}
+	return stored, user, nil
+}
+
+// consumeUserToken spends a token inside the caller's transaction and returns its user
+func consumeUserToken(ctx context.Context, q *db.Queries, token, purpose string) (db.User, error) {
+	stored, user, err := lookupUserToken(ctx, q, token, purpose)
+	if err != nil {
+		return db.User{}, err
+	}
+
+	if err := q.MarkUserTokenUsed(ctx, stored.ID); err != nil {
+		return db.User{}, fmt.Errorf("failed to mark token used: %w", err)
+	}
+	return user, nil
+}
+
+// sendVerificationEmail emails user a fresh verification link
+func (s *AuthService) sendVerificationEmail(ctx context.Context, user db.User) error {
+	token, expiresAt, err := createUserToken(ctx, s.queries, user, TokenPurposeVerifyEmail, VerifyEmailTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.appURL + "/verify-email?token=" + url.QueryEscape(token)
+	err = s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your AgroMart email address",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"Confirm that this is your email address by opening the link below:\n%s\n\n"+
+			"This link expires on %s.\n",
+			user.Name, link, expiresAt.UTC().Format(time.RFC1123)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+	return nil
+}
+
+// RequestEmailVerification resends the verification link. It succeeds without sending
+// anything when the address is unknown or already verified, so callers cannot probe
+// which emails have accounts.
+func (s *AuthService) RequestEmailVerification(ctx context.Context, email string) error {
+	ctx = database.WithSystem(ctx)
+
+	user, err := s.queries.FindUserByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		if database.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+	if !isActive(user) || isEmailVerified(user) {
+		return nil
+	}
+	return s.sendVerificationEmail(ctx, user)
+}
+
+// VerifyEmail redeems a verification token and marks the user's email verified
+func (s *AuthService) VerifyEmail(ctx context.Context, token string, client ClientInfo) error {
+	ctx = database.WithSystem(ctx)
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	user, err := consumeUserToken(ctx, qtx, token, TokenPurposeVerifyEmail)
+	if err != nil {
+		return err
+	}
+
+	if err := qtx.SetUserEmailVerified(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	recordSecurityEvent(ctx, s.queries, EventEmailVerified, &user, user.Email, client, nil)
+	return nil
+}
+
+// RequestPasswordReset emails a password reset link. Like RequestEmailVerification it
+// reports success for unknown addresses.
+func (s *AuthService) RequestPasswordReset(ctx context.Context, email string, client ClientInfo) error {
+	ctx = database.WithSystem(ctx)
+
+	user, err := s.queries.FindUserByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		if database.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+	if !isActive(user) {
+		return nil
+	}
+
+	token, expiresAt, err := createUserToken(ctx, s.queries, user, TokenPurposeResetPassword, PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	recordSecurityEvent(ctx, s.queries, EventPasswordResetRequested, &user, user.Email, client, nil)
+
+	link := s.appURL + "/reset-password?token=" + url.QueryEscape(token)
+	err = s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your AgroMart password",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"Someone asked to reset the password of your AgroMart account. Choose a new password here:\n%s\n\n"+
+			"This link expires on %s. If you did not ask for it, you can ignore this email.\n",
+			user.Name, link, expiresAt.UTC().Format(time.RFC1123)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send password reset email: %w", err)
+	}
+	return nil
+}
+
+// ResetPassword redeems a reset token and sets a new password. Every session is ended, and
+// since the user proved they read the mailbox the email counts as verified and any login
+// lockout on the account is lifted.
+func (s *AuthService) ResetPassword(ctx context.Context, token, newPassword string, client ClientInfo) error {
+	ctx = database.WithSystem(ctx)
+
+	if len(newPassword) < MinPasswordLength {
+		return ErrPasswordTooShort
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	user, err := consumeUserToken(ctx, qtx, token, TokenPurposeResetPassword)
+	if err != nil {
+		return err
+	}
+
+	err = qtx.UpdateUserPassword(ctx, db.UpdateUserPasswordParams{
+		Password: string(hashedPassword),
+		ID:       user.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if err := qtx.SetUserEmailVerified(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	if err := qtx.RevokeUserRefreshTokens(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	if err := clearAccountThrottle(ctx, qtx, user.Email); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	recordSecurityEvent(ctx, s.queries, EventPasswordReset, &user, user.Email, client, nil)
+	return nil
+}
+
+// sendWelcomeVerification sends the first verification email after registration. The
+// account already exists, so a mail failure is logged and the user can ask for a resend.
+func (s *AuthService) sendWelcomeVerification(ctx context.Context, user db.User) {
+	if err := s.sendVerificationEmail(ctx, user); err != nil {
+		log.Error().Err(err).Str("user_id", user.ID.String()).Msg("failed to send verification email")
+	}
+}
@@ -7,71 +7,82 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtKey = []byte("shared-secret-key") // In production, this should come from environment
+// AccessTokenTTL is how long an access token is valid
+const AccessTokenTTL = 24 * time.Hour
 
 type Claims struct {
 	UserID   string `json:"user_id"`
 	TenantID string `json:"tenant_id"`
 	Email    string `json:"email"`
 	Role     string `json:"role"`
-	jwt.RegisteredClaims
-}
-
-type RefreshClaims struct {
-	UserID string `json:"user_id"`
+	// SessionID is the refresh token family the access token was issued for; revoking the
+	// session rejects the access token too
+	SessionID string `json:"sid"`
+	// ImpersonatorID is the platform super admin acting as the user; SessionID is then the
+	// impersonation session rather than a refresh token family
+	ImpersonatorID string `json:"imp,omitempty"`
 	jwt.RegisteredClaims
 }
 
 type JWTService struct {
-	secretKey []byte
+	keys *KeySet
 }
 
-func NewJWTService(secretKey string) *JWTService {
-	if secretKey == "" {
-		secretKey = "shared-secret-key" // Default for development
-	}
+func NewJWTService(keys *KeySet) *JWTService {
 	return &JWTService{
-		secretKey: []byte(secretKey),
+		keys: keys,
 	}
 }
 
-func (j *JWTService) GenerateToken(userID, tenantID, email, role string) (string, error) {
-	claims := &Claims{
-		UserID:   userID,
-		TenantID: tenantID,
-		Email:    email,
-		Role:     role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // 24 hours
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-		},
-	}
+func (j *JWTService) GenerateToken(userID, tenantID, email, role, sessionID string) (string, error) {
+	return j.sign(&Claims{
+		UserID:    userID,
+		TenantID:  tenantID,
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
+	}, time.Now().Add(AccessTokenTTL))
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(j.secretKey)
+// GenerateImpersonationToken signs a token that lets impersonatorID act as the user until
+// expiresAt. There is no refresh token: when the token expires the impersonation is over.
+func (j *JWTService) GenerateImpersonationToken(userID, tenantID, email, role, sessionID, impersonatorID string, expiresAt time.Time) (string, error) {
+	return j.sign(&Claims{
+		UserID:         userID,
+		TenantID:       tenantID,
+		Email:          email,
+		Role:           role,
+		SessionID:      sessionID,
+		ImpersonatorID: impersonatorID,
+	}, expiresAt)
 }
 
-func (j *JWTService) GenerateRefreshToken(userID string) (string, error) {
-	claims := &RefreshClaims{
-		UserID: userID,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)), // 7 days
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-		},
+func (j *JWTService) sign(claims *Claims, expiresAt time.Time) (string, error) {
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(j.secretKey)
+	key := j.keys.signingKey(now)
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.private)
 }
 
 func (j *JWTService) ValidateToken(tokenStr string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := token.Header["kid"].(string)
+		key, err := j.keys.verificationKey(kid, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		// The key decides the algorithm, never the token header
+		if token.Method.Alg() != key.Algorithm {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return j.secretKey, nil
+		return key.public, nil
 	})
 
 	if err != nil {
@@ -86,33 +97,7 @@ func (j *JWTService) ValidateToken(tokenStr string) (*Claims, error) {
 	return claims, nil
 }
 
-func (j *JWTService) ValidateRefreshToken(tokenStr string) (*RefreshClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenStr, &RefreshClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return j.secretKey, nil
-	})
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse refresh token: %w", err)
-	}
-
-	claims, ok := token.Claims.(*RefreshClaims)
-	if !ok || !token.Valid {
-		return nil, fmt.Errorf("invalid refresh token")
-	}
-
-	return claims, nil
-}
-
-// Legacy functions for backward compatibility
-func GenerateToken(userID string, tenantID string, expiration time.Duration) (string, error) {
-	service := NewJWTService("")
-	return service.GenerateToken(userID, tenantID, "", "")
-}
-
-func ParseToken(tokenStr string) (*Claims, error) {
-	service := NewJWTService("")
-	return service.ValidateToken(tokenStr)
+// JWKS returns the public keys tokens can currently be verified with
+func (j *JWTService) JWKS() JWKS {
+	return j.keys.JWKS(time.Now())
 }
@@ -0,0 +1,257 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Signing algorithms accepted for JWT_ALGORITHM
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// keyManifestFile optionally sits next to the PEM files and schedules when each key starts
+// signing. Without it the last kid in sort order signs, every other key keeps verifying,
+// and a key is retired by deleting its file.
+const keyManifestFile = "keys.json"
+
+var ErrUnknownKey = errors.New("token signed with an unknown or retired key")
+
+// SigningKey is one key of a KeySet
+type SigningKey struct {
+	ID         string
+	Algorithm  string
+	ActiveFrom time.Time
+	private    interface{}
+	public     interface{}
+}
+
+// KeySet holds every key tokens may be signed or verified with. The newest key whose
+// ActiveFrom has passed signs new tokens; a key that has been superseded keeps verifying
+// until tokens it signed have expired, so rotating never logs anyone out.
+type KeySet struct {
+	keys []*SigningKey
+}
+
+// NewKeySet builds the key set for algorithm: HS256 uses secret, RS256 and EdDSA load PEM
+// private keys from dir
+func NewKeySet(algorithm, secret, dir string) (*KeySet, error) {
+	switch algorithm {
+	case "", AlgHS256:
+		if secret == "" {
+			return nil, errors.New("JWT secret is required for HS256")
+		}
+		return &KeySet{keys: []*SigningKey{{
+			ID:        "hs256",
+			Algorithm: AlgHS256,
+			private:   []byte(secret),
+			public:    []byte(secret),
+		}}}, nil
+	case AlgRS256, AlgEdDSA:
+		return loadKeyDir(algorithm, dir)
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", algorithm)
+	}
+}
+
+type keyManifestEntry struct {
+	KID        string    `json:"kid"`
+	File       string    `json:"file"`
+	ActiveFrom time.Time `json:"active_from"`
+}
+
+func loadKeyDir(algorithm, dir string) (*KeySet, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("a key directory is required for %s", algorithm)
+	}
+
+	var entries []keyManifestEntry
+	manifest, err := os.ReadFile(filepath.Join(dir, keyManifestFile))
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(manifest, &entries); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", keyManifestFile, err)
+		}
+	case errors.Is(err, os.ErrNotExist):
+		files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			name := filepath.Base(f)
+			entries = append(entries, keyManifestEntry{KID: strings.TrimSuffix(name, ".pem"), File: name})
+		}
+	default:
+		return nil, fmt.Errorf("failed to read %s: %w", keyManifestFile, err)
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no signing keys found in %s", dir)
+	}
+
+	ks := &KeySet{}
+	seen := map[string]bool{}
+	for _, e := range entries {
+		if e.KID == "" || seen[e.KID] {
+			return nil, fmt.Errorf("key IDs must be present and unique, got %q", e.KID)
+		}
+		seen[e.KID] = true
+
+		key, err := loadPrivateKey(algorithm, filepath.Join(dir, e.File))
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", e.KID, err)
+		}
+		key.ID = e.KID
+		key.ActiveFrom = e.ActiveFrom
+		ks.keys = append(ks.keys, key)
+	}
+
+	sort.SliceStable(ks.keys, func(i, j int) bool {
+		if !ks.keys[i].ActiveFrom.Equal(ks.keys[j].ActiveFrom) {
+			return ks.keys[i].ActiveFrom.Before(ks.keys[j].ActiveFrom)
+		}
+		return ks.keys[i].ID < ks.keys[j].ID
+	})
+
+	if ks.keys[0].ActiveFrom.After(time.Now()) {
+		return nil, errors.New("no signing key is active yet")
+	}
+	return ks, nil
+}
+
+func loadPrivateKey(algorithm, path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("not a PEM file")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		// openssl genrsa still writes PKCS#1 by default
+		rsaKey, rsaErr := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if rsaErr != nil {
+			return nil, fmt.Errorf("unsupported private key: %w", err)
+		}
+		parsed = rsaKey
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		if algorithm != AlgRS256 {
+			return nil, fmt.Errorf("RSA key cannot be used for %s", algorithm)
+		}
+		if k.N.BitLen() < 2048 {
+			return nil, errors.New("RSA keys must be at least 2048 bits")
+		}
+		return &SigningKey{Algorithm: AlgRS256, private: k, public: &k.PublicKey}, nil
+	case ed25519.PrivateKey:
+		if algorithm != AlgEdDSA {
+			return nil, fmt.Errorf("Ed25519 key cannot be used for %s", algorithm)
+		}
+		return &SigningKey{Algorithm: AlgEdDSA, private: k, public: k.Public()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", parsed)
+	}
+}
+
+// signingKey returns the key new tokens are signed with at now
+func (ks *KeySet) signingKey(now time.Time) *SigningKey {
+	current := ks.keys[0]
+	for _, k := range ks.keys {
+		if !k.ActiveFrom.After(now) {
+			current = k
+		}
+	}
+	return current
+}
+
+// verificationKey returns the key with the given ID if tokens it signed can still be valid
+func (ks *KeySet) verificationKey(kid string, now time.Time) (*SigningKey, error) {
+	for i, k := range ks.keys {
+		if k.ID != kid {
+			continue
+		}
+		if ks.retired(i, now) {
+			return nil, ErrUnknownKey
+		}
+		return k, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+// retired reports whether every token the i-th key could have signed has expired: its
+// successor has been signing for longer than an access token lives
+func (ks *KeySet) retired(i int, now time.Time) bool {
+	if i+1 >= len(ks.keys) || ks.keys[i+1].ActiveFrom.IsZero() {
+		return false
+	}
+	return now.After(ks.keys[i+1].ActiveFrom.Add(AccessTokenTTL))
+}
+
+// JWK is a public key in JSON Web Key format
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+// JWKS is the document served at /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS lists the public keys other services need to verify tokens: every key that is not
+// retired, including scheduled ones so verifiers have them before they start signing.
+// HS256 secrets are never published.
+func (ks *KeySet) JWKS(now time.Time) JWKS {
+	set := JWKS{Keys: []JWK{}}
+	for i, k := range ks.keys {
+		if ks.retired(i, now) {
+			continue
+		}
+		switch pub := k.public.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				KeyType:   "RSA",
+				KeyID:     k.ID,
+				Use:       "sig",
+				Algorithm: k.Algorithm,
+				N:         base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				KeyType:   "OKP",
+				KeyID:     k.ID,
+				Use:       "sig",
+				Algorithm: k.Algorithm,
+				Curve:     "Ed25519",
+				X:         base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	return set
+}
@@ -0,0 +1,251 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"agromart2/db"
+	"agromart2/internal/database"
+	"agromart2/internal/utils"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// Security event types
+const (
+	EventLoginFailed            = "login_failed"
+	EventLoginBlocked           = "login_blocked"
+	EventAccountLocked          = "account_locked"
+	EventIPBlocked              = "ip_blocked"
+	EventAccountUnlocked        = "account_unlocked"
+	EventPasswordChanged        = "password_changed"
+	EventPasswordResetRequested = "password_reset_requested"
+	EventPasswordReset          = "password_reset"
+	EventEmailVerified          = "email_verified"
+	EventTwoFactorEnabled       = "two_factor_enabled"
+	EventTwoFactorDisabled      = "two_factor_disabled"
+	EventTwoFactorReset         = "two_factor_reset"
+	EventRecoveryCodeUsed       = "recovery_code_used"
+	EventTwoFactorPolicyChanged = "two_factor_policy_changed"
+	EventAPIKeyCreated          = "api_key_created"
+	EventAPIKeyRevoked          = "api_key_revoked"
+	EventTenantSuspended        = "tenant_suspended"
+	EventTenantReactivated      = "tenant_reactivated"
+	EventImpersonationStarted   = "impersonation_started"
+	EventImpersonationEnded     = "impersonation_ended"
+	EventTenantSwitched         = "tenant_switched"
+)
+
+// Login throttling. Failures are counted per account email and per client IP; a counter
+// starts over once LoginFailureWindow passes without a failure.
+const (
+	MaxAccountLoginFailures = 5
+	MaxIPLoginFailures      = 20
+	LoginFailureWindow      = 15 * time.Minute
+	LockoutDuration         = 15 * time.Minute
+	maxLoginDelay           = 30 * time.Second
+)
+
+var ErrTooManyLoginAttempts = errors.New("too many failed login attempts")
+
+// LoginThrottledError is returned while an account or IP must wait before trying again
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("%s, try again in %s", ErrTooManyLoginAttempts, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+	return ErrTooManyLoginAttempts
+}
+
+func accountThrottleKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
+
+// loginDelay is how long a key must wait after its n-th consecutive failure: nothing for the
+// first two, then doubling from one second, and a full lockout once maxFailures is reached
+func loginDelay(failures, maxFailures int) time.Duration {
+	switch {
+	case failures >= maxFailures:
+		return LockoutDuration
+	case failures < 3:
+		return 0
+	default:
+		d := time.Second << (failures - 3)
+		if d > maxLoginDelay {
+			d = maxLoginDelay
+		}
+		return d
+	}
+}
+
+// throttleKeys lists the counters a login attempt from client for email is checked against
+func throttleKeys(email string, client ClientInfo) []string {
+	keys := []string{accountThrottleKey(email)}
+	if client.IPAddress != "" {
+		keys = append(keys, ipThrottleKey(client.IPAddress))
+	}
+	return keys
+}
+
+// checkLoginThrottle fails with a LoginThrottledError if any of the keys is still waiting
+func (s *AuthService) checkLoginThrottle(ctx context.Context, keys []string) error {
+	now := time.Now()
+	var wait time.Duration
+	for _, key := range keys {
+		t, err := s.queries.GetLoginThrottle(ctx, key)
+		if err != nil {
+			if database.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to check login throttle: %w", err)
+		}
+		if t.LockedUntil.Valid && t.LockedUntil.Time.After(now) {
+			if d := t.LockedUntil.Time.Sub(now); d > wait {
+				wait = d
+			}
+		}
+	}
+	if wait > 0 {
+		return &LoginThrottledError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failed attempt against the account and IP and records the
+// security events. user is nil when the email matched no account.
+func (s *AuthService) recordLoginFailure(ctx context.Context, email string, user *db.User, client ClientInfo) {
+	recordSecurityEvent(ctx, s.queries, EventLoginFailed, user, email, client, nil)
+
+	limits := map[string]int{accountThrottleKey(email): MaxAccountLoginFailures}
+	if client.IPAddress != "" {
+		limits[ipThrottleKey(client.IPAddress)] = MaxIPLoginFailures
+	}
+
+	now := time.Now()
+	for key, max := range limits {
+		t, err := s.queries.RecordLoginFailure(ctx, db.RecordLoginFailureParams{
+			Key:         key,
+			WindowStart: now.Add(-LoginFailureWindow),
+		})
+		if err != nil {
+			log.Error().Err(err).Str("key", key).Msg("failed to record login failure")
+			continue
+		}
+
+		delay := loginDelay(int(t.Failures), max)
+		if delay == 0 {
+			continue
+		}
+		err = s.queries.LockLoginThrottle(ctx, db.LockLoginThrottleParams{
+			Key:         key,
+			LockedUntil: utils.P.Timestamptz(now.Add(delay)),
+		})
+		if err != nil {
+			log.Error().Err(err).Str("key", key).Msg("failed to lock login throttle")
+			continue
+		}
+
+		if int(t.Failures) >= max {
+			eventType := EventAccountLocked
+			if strings.HasPrefix(key, "ip:") {
+				eventType = EventIPBlocked
+			}
+			recordSecurityEvent(ctx, s.queries, eventType, user, email, client, map[string]interface{}{
+				"failures":     t.Failures,
+				"locked_until": now.Add(delay),
+			})
+		}
+	}
+}
+
+// clearAccountThrottle forgets an account's failures after it logs in or is unlocked. IP
+// counters are left alone so one valid account cannot reset an attacker's address.
+func clearAccountThrottle(ctx context.Context, q *db.Queries, email string) error {
+	if err := q.ClearLoginThrottle(ctx, accountThrottleKey(email)); err != nil {
+		return fmt.Errorf("failed to clear login throttle: %w", err)
+	}
+	return nil
+}
+
+// recordSecurityEvent stores a security event. Failing to store one is logged rather than
+// failing the request that caused it.
+func recordSecurityEvent(ctx context.Context, q *db.Queries, eventType string, user *db.User, email string, client ClientInfo, details map[string]interface{}) {
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		detailsJSON = []byte("{}")
+	}
+
+	params := db.CreateSecurityEventParams{
+		EventType: eventType,
+		Email:     utils.P.Text(strings.ToLower(strings.TrimSpace(email))),
+		IpAddress: utils.P.Text(client.IPAddress),
+		UserAgent: utils.P.Text(client.UserAgent),
+		Details:   detailsJSON,
+	}
+	if user != nil {
+		// Users belong to several tenants; the event goes to the one the request acts in,
+		// falling back to the user's home tenant for flows that run before one is chosen
+		tenantID, ok := database.TenantFromContext(ctx)
+		if !ok {
+			tenantID = user.TenantID
+		}
+		params.TenantID = utils.P.UUID(tenantID)
+		params.UserID = utils.P.UUID(user.ID)
+	}
+
+	if err := q.CreateSecurityEvent(ctx, params); err != nil {
+		log.Error().Err(err).Str("event_type", eventType).Msg("failed to record security event")
+	}
+}
+
+// SecurityEvent is a recorded security event as exposed by the API
+type SecurityEvent struct {
+	ID        uuid.UUID       `json:"id"`
+	UserID    *uuid.UUID      `json:"user_id"`
+	EventType string          `json:"event_type"`
+	Email     string          `json:"email"`
+	IPAddress string          `json:"ip_address"`
+	UserAgent string          `json:"user_agent"`
+	Details   json.RawMessage `json:"details"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+func newSecurityEvent(e db.SecurityEvent) SecurityEvent {
+	event := SecurityEvent{
+		ID:        e.ID,
+		EventType: e.EventType,
+		Email:     e.Email.String,
+		IPAddress: e.IpAddress.String,
+		UserAgent: e.UserAgent.String,
+		Details:   json.RawMessage(e.Details),
+		CreatedAt: e.CreatedAt,
+	}
+	if e.UserID.Valid {
+		id := uuid.UUID(e.UserID.Bytes)
+		event.UserID = &id
+	}
+	return event
+}
+
+// SecurityEventFilter narrows ListSecurityEvents; zero values match everything
+type SecurityEventFilter struct {
+	EventType string
+	UserID    *uuid.UUID
+	IPAddress string
+	Since     *time.Time
+}
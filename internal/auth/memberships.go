@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"agromart2/db"
+	"agromart2/internal/database"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotMember     = errors.New("you are not a member of this organization")
+	ErrAlreadyMember = errors.New("user is already a member of this organization")
+)
+
+// Membership is one of a user's tenants as exposed by the API
+type Membership struct {
+	TenantID   uuid.UUID `json:"tenant_id"`
+	TenantName string    `json:"tenant_name"`
+	Role       string    `json:"role"`
+	// Active is false when the membership was deactivated or the tenant is suspended, so
+	// the user cannot switch to it
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newMembership(row db.ListUserMembershipsRow) Membership {
+	role, _ := row.TenantMembership.Role.(string)
+	return Membership{
+		TenantID:   row.Tenant.ID,
+		TenantName: row.Tenant.Name,
+		Role:       role,
+		Active:     row.TenantMembership.IsActive && row.Tenant.IsActive,
+		CreatedAt:  row.TenantMembership.CreatedAt,
+	}
+}
+
+// listMemberships returns every tenant the user belongs to, oldest membership first
+func listMemberships(ctx context.Context, q *db.Queries, userID uuid.UUID) ([]Membership, error) {
+	rows, err := q.ListUserMemberships(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memberships: %w", err)
+	}
+
+	memberships := make([]Membership, len(rows))
+	for i, row := range rows {
+		memberships[i] = newMembership(row)
+	}
+	return memberships, nil
+}
+
+// activeMembership returns the user's membership of a tenant, refusing deactivated
+// memberships and suspended tenants
+func activeMembership(ctx context.Context, q *db.Queries, userID, tenantID uuid.UUID) (*db.TenantMembership, error) {
+	membership, err := q.GetTenantMembership(ctx, db.GetTenantMembershipParams{
+		TenantID: tenantID,
+		UserID:   userID,
+	})
+	if err != nil {
+		if database.IsNotFound(err) {
+			return nil, ErrNotMember
+		}
+		return nil, fmt.Errorf("failed to get membership: %w", err)
+	}
+	if !membership.IsActive {
+		return nil, ErrAccountDeactivated
+	}
+	if err := checkTenantActive(ctx, q, tenantID); err != nil {
+		return nil, err
+	}
+	return &membership, nil
+}
+
+// chooseMembership picks the tenant a login starts in: the requested one if any, otherwise
+// the user's home tenant, otherwise their oldest usable membership
+func chooseMembership(ctx context.Context, q *db.Queries, user db.User, requested *uuid.UUID) (*db.TenantMembership, error) {
+	if requested != nil {
+		return activeMembership(ctx, q, user.ID, *requested)
+	}
+
+	rows, err := q.ListUserMemberships(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memberships: %w", err)
+	}
+
+	var chosen *db.TenantMembership
+	for _, row := range rows {
+		if !row.TenantMembership.IsActive || !row.Tenant.IsActive {
+			continue
+		}
+		if chosen == nil || row.Tenant.ID == user.TenantID {
+			membership := row.TenantMembership
+			chosen = &membership
+		}
+	}
+	if chosen != nil {
+		return chosen, nil
+	}
+
+	// Nothing usable: report why for the home tenant, as a single-tenant user expects
+	return activeMembership(ctx, q, user.ID, user.TenantID)
+}
+
+// ListMemberships lists the tenants the user belongs to
+func (s *AuthService) ListMemberships(ctx context.Context, userID uuid.UUID) ([]Membership, error) {
+	return listMemberships(database.WithSystem(ctx), s.queries, userID)
+}
+
+// SwitchTenant starts a new session in another of the user's tenants. The current session
+// stays valid, so other tabs keep working in the tenant they were in.
+func (s *AuthService) SwitchTenant(ctx context.Context, userID, tenantID uuid.UUID, client ClientInfo) (*AuthResponse, error) {
+	// The target tenant is not the one the request was authenticated for
+	ctx = database.WithSystem(ctx)
+
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isActive(*user) {
+		return nil, ErrAccountDeactivated
+	}
+
+	membership, err := activeMembership(ctx, s.queries, userID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.issueTokens(ctx, s.queries, *user, *membership, uuid.New(), client)
+	if err != nil {
+		return nil, err
+	}
+
+	recordSecurityEvent(database.WithTenant(ctx, tenantID), s.queries, EventTenantSwitched, user, user.Email, client, nil)
+
+	return response, nil
+}
@@ -1,8 +1,11 @@
 package auth
 
 import (
+	"errors"
 	"strings"
 
+	"agromart2/internal/database"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
@@ -18,6 +21,10 @@ func NewMiddleware(authService *AuthService) *Middleware {
 
 func (m *Middleware) RequireAuth(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
+		if key := APIKeyFromRequest(c.Request()); key != "" {
+			return m.authenticateAPIKey(c, key, next)
+		}
+
 		authHeader := c.Request().Header.Get("Authorization")
 		if authHeader == "" {
 			return echo.NewHTTPError(401, "missing authorization header")
@@ -33,16 +40,114 @@ func (m *Middleware) RequireAuth(next echo.HandlerFunc) echo.HandlerFunc {
 			return echo.NewHTTPError(401, "invalid token")
 		}
 
+		// Every query made for this request only sees the token's tenant
+		tenantID, err := uuid.Parse(claims.TenantID)
+		if err != nil {
+			return echo.NewHTTPError(401, "invalid token")
+		}
+		c.SetRequest(c.Request().WithContext(database.WithTenant(c.Request().Context(), tenantID)))
+
+		// Deactivation, logout and role changes take effect on the next request, not at token expiry
+		user, membership, err := m.authService.Authenticate(c.Request().Context(), claims)
+		if err != nil {
+			if errors.Is(err, ErrAccountDeactivated) || errors.Is(err, ErrSessionRevoked) || errors.Is(err, ErrNotMember) {
+				return echo.NewHTTPError(401, err.Error())
+			}
+			if errors.Is(err, ErrTenantSuspended) {
+				return echo.NewHTTPError(403, err.Error())
+			}
+			return echo.NewHTTPError(401, "invalid token")
+		}
+		// The role is the one the user holds in the token's tenant
+		role, _ := membership.Role.(string)
+
+		var impersonatorID uuid.UUID
+		if claims.ImpersonatorID != "" {
+			if impersonatorID, err = uuid.Parse(claims.ImpersonatorID); err != nil {
+				return echo.NewHTTPError(401, "invalid token")
+			}
+			c.Set("impersonator_id", claims.ImpersonatorID)
+		}
+		setRequestActor(c, user.ID, uuid.Nil, impersonatorID)
+
 		// Set user context
 		c.Set("user_id", claims.UserID)
 		c.Set("tenant_id", claims.TenantID)
-		c.Set("user_role", claims.Role)
+		c.Set("user_role", role)
 		c.Set("user_email", claims.Email)
+		c.Set("session_id", claims.SessionID)
 
 		return next(c)
 	}
 }
 
+// authenticateAPIKey lets a machine integration through. The request is attributed to the
+// admin who created the key, but it carries no role: RequirePermission checks the key's
+// scopes instead.
+func (m *Middleware) authenticateAPIKey(c echo.Context, key string, next echo.HandlerFunc) error {
+	apiKey, err := m.authService.AuthenticateAPIKey(c.Request().Context(), key)
+	if err != nil {
+		if errors.Is(err, ErrInvalidAPIKey) {
+			return echo.NewHTTPError(401, err.Error())
+		}
+		if errors.Is(err, ErrTenantSuspended) {
+			return echo.NewHTTPError(403, err.Error())
+		}
+		return echo.NewHTTPError(401, "invalid API key")
+	}
+
+	scopes := make([]Permission, len(apiKey.Scopes))
+	for i, s := range apiKey.Scopes {
+		scopes[i] = Permission(s)
+	}
+
+	c.SetRequest(c.Request().WithContext(database.WithTenant(c.Request().Context(), apiKey.TenantID)))
+	setRequestActor(c, apiKey.CreatedBy, apiKey.ID, uuid.Nil)
+
+	c.Set("user_id", apiKey.CreatedBy.String())
+	c.Set("tenant_id", apiKey.TenantID.String())
+	c.Set("user_role", "")
+	c.Set("user_email", "")
+	c.Set("api_key_id", apiKey.ID.String())
+	c.Set("api_key_scopes", scopes)
+
+	return next(c)
+}
+
+// setRequestActor attributes the changes a request makes to the authenticated user, and to
+// the API key it used or the super admin impersonating the user if any, in the audit trail
+func setRequestActor(c echo.Context, userID, apiKeyID, impersonatorID uuid.UUID) {
+	ctx := c.Request().Context()
+	info := database.RequestInfoFromContext(ctx)
+	info.UserID = userID
+	info.APIKeyID = apiKeyID
+	info.ImpersonatorID = impersonatorID
+	c.SetRequest(c.Request().WithContext(database.WithRequestInfo(ctx, info)))
+}
+
+// RequireUser rejects API keys on routes that act on the caller's own account, such as
+// sessions, passwords and two-factor settings. It must run after RequireAuth.
+func RequireUser(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if _, ok := c.Get("api_key_id").(string); ok {
+			return forbidden("not available to API keys")
+		}
+		return next(c)
+	}
+}
+
+// DenyImpersonation rejects impersonation tokens on routes that would let a super admin
+// take over the account they are acting as, such as its password, sessions, second factor
+// and API keys. It must run after RequireAuth.
+func DenyImpersonation(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if _, ok := c.Get("impersonator_id").(string); ok {
+			return forbidden("not available while impersonating")
+		}
+		return next(c)
+	}
+}
+
 func (m *Middleware) RequireRole(roles ...string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -58,7 +163,7 @@ func (m *Middleware) RequireRole(roles ...string) echo.MiddlewareFunc {
 				}
 			}
 
-			return echo.NewHTTPError(403, "insufficient permissions")
+			return forbidden("requires role " + strings.Join(roles, " or "))
 		}
 	}
 }
@@ -0,0 +1,369 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"agromart2/db"
+	"agromart2/internal/database"
+	"agromart2/internal/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Impersonation lifetimes. An impersonation token cannot be refreshed, so support work that
+// takes longer starts a new session with a new reason.
+const (
+	DefaultImpersonationTTL = 30 * time.Minute
+	MaxImpersonationTTL     = time.Hour
+)
+
+var (
+	ErrCannotSuspendOwnTenant  = errors.New("cannot suspend your own organization")
+	ErrReasonRequired          = errors.New("a reason is required")
+	ErrInvalidImpersonationTTL = fmt.Errorf("impersonation must last between 1 minute and %s", MaxImpersonationTTL)
+	ErrImpersonationTarget     = errors.New("only an active admin of the organization can be impersonated")
+)
+
+// PlatformService lets platform super admins oversee every tenant: usage, suspension and
+// impersonation for support. Everything it does crosses tenants, so it runs with
+// database.WithSystem; the routes in front of it must be limited to PermPlatformManage.
+type PlatformService struct {
+	db      *pgxpool.Pool
+	queries *db.Queries
+	jwt     *JWTService
+}
+
+func NewPlatformService(dbPool *pgxpool.Pool, queries *db.Queries, jwtService *JWTService) *PlatformService {
+	return &PlatformService{
+		db:      dbPool,
+		queries: queries,
+		jwt:     jwtService,
+	}
+}
+
+// TenantUsage counts what a tenant has stored
+type TenantUsage struct {
+	Users          int64 `json:"users"`
+	Products       int64 `json:"products"`
+	PurchaseOrders int64 `json:"purchase_orders"`
+	SalesOrders    int64 `json:"sales_orders"`
+}
+
+// TenantSummary is a tenant with its usage, as listed to super admins
+type TenantSummary struct {
+	db.Tenant
+	Usage TenantUsage `json:"usage"`
+}
+
+func newTenantSummary(r db.ListTenantsWithUsageRow) TenantSummary {
+	return TenantSummary{
+		Tenant: db.Tenant{
+			ID:                 r.ID,
+			Name:               r.Name,
+			Email:              r.Email,
+			Phone:              r.Phone,
+			Address:            r.Address,
+			RegistrationNumber: r.RegistrationNumber,
+			IsActive:           r.IsActive,
+			CreatedAt:          r.CreatedAt,
+			RequireTwoFactor:   r.RequireTwoFactor,
+		},
+		Usage: TenantUsage{
+			Users:          r.UserCount,
+			Products:       r.ProductCount,
+			PurchaseOrders: r.PurchaseOrderCount,
+			SalesOrders:    r.SalesOrderCount,
+		},
+	}
+}
+
+// TenantFilter narrows ListTenants; zero values match everything
+type TenantFilter struct {
+	// Search matches part of the tenant's name or email
+	Search   string
+	IsActive *bool
+}
+
+// ListTenants lists every tenant with its usage, newest first
+func (s *PlatformService) ListTenants(ctx context.Context, filter TenantFilter, limit, offset int32) ([]TenantSummary, error) {
+	ctx = database.WithSystem(ctx)
+
+	rows, err := s.queries.ListTenantsWithUsage(ctx, db.ListTenantsWithUsageParams{
+		Search:   utils.P.Text(strings.TrimSpace(filter.Search)),
+		IsActive: utils.P.BoolPtr(filter.IsActive),
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+
+	tenants := make([]TenantSummary, 0, len(rows))
+	for _, r := range rows {
+		tenants = append(tenants, newTenantSummary(r))
+	}
+	return tenants, nil
+}
+
+// GetTenant returns one tenant with its usage
+func (s *PlatformService) GetTenant(ctx context.Context, tenantID uuid.UUID) (*TenantSummary, error) {
+	ctx = database.WithSystem(ctx)
+
+	r, err := s.queries.GetTenantWithUsage(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	// Both usage queries return the same columns
+	tenant := newTenantSummary(db.ListTenantsWithUsageRow(r))
+	return &tenant, nil
+}
+
+// SetTenantActive suspends or reactivates a tenant. Suspending logs every user of the
+// tenant out and ends any impersonation of it; its users and API keys are then refused
+// until it is reactivated. Its data is kept.
+func (s *PlatformService) SetTenantActive(ctx context.Context, actor Actor, tenantID uuid.UUID, active bool, client ClientInfo) (*TenantSummary, error) {
+	ctx = database.WithSystem(ctx)
+
+	if !active && tenantID == actor.TenantID {
+		return nil, ErrCannotSuspendOwnTenant
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	tenant, err := qtx.SetTenantActive(ctx, db.SetTenantActiveParams{
+		ID:       tenantID,
+		IsActive: active,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update tenant status: %w", err)
+	}
+
+	if !active {
+		if err := qtx.RevokeTenantRefreshTokens(ctx, tenantID); err != nil {
+			return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+		if err := qtx.EndTenantImpersonationSessions(ctx, tenantID); err != nil {
+			return nil, fmt.Errorf("failed to end impersonation sessions: %w", err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	eventType := EventTenantReactivated
+	if !active {
+		eventType = EventTenantSuspended
+	}
+	s.recordActorEvent(ctx, actor, eventType, client, map[string]interface{}{
+		"tenant_id":   tenant.ID,
+		"tenant_name": tenant.Name,
+	})
+
+	return s.GetTenant(ctx, tenantID)
+}
+
+// ImpersonationSession is a super admin's time-boxed access to a tenant, as exposed by the API
+type ImpersonationSession struct {
+	ID             uuid.UUID  `json:"id"`
+	ImpersonatorID uuid.UUID  `json:"impersonator_id"`
+	TenantID       uuid.UUID  `json:"tenant_id"`
+	UserID         uuid.UUID  `json:"user_id"`
+	Reason         string     `json:"reason"`
+	IPAddress      string     `json:"ip_address"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	EndedAt        *time.Time `json:"ended_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func newImpersonationSession(s db.ImpersonationSession) ImpersonationSession {
+	return ImpersonationSession{
+		ID:             s.ID,
+		ImpersonatorID: s.ImpersonatorID,
+		TenantID:       s.TenantID,
+		UserID:         s.UserID,
+		Reason:         s.Reason,
+		IPAddress:      s.IpAddress.String,
+		ExpiresAt:      s.ExpiresAt,
+		EndedAt:        timePtr(s.EndedAt),
+		CreatedAt:      s.CreatedAt,
+	}
+}
+
+// StartImpersonationParams describes who to impersonate and why
+type StartImpersonationParams struct {
+	// UserID is the admin to act as; the tenant's oldest active admin if nil
+	UserID *uuid.UUID `json:"user_id"`
+	Reason string     `json:"reason"`
+	// Minutes is how long the session lasts, DefaultImpersonationTTL if zero
+	Minutes int `json:"minutes"`
+}
+
+// Impersonation is a started impersonation session and the access token that acts as the
+// impersonated admin. The token is only in this response.
+type Impersonation struct {
+	Session ImpersonationSession `json:"session"`
+	User    UserView             `json:"user"`
+	Token   string               `json:"token"`
+}
+
+// StartImpersonation lets the actor act as an admin of another tenant until the session
+// expires or is ended. Every change made with the token is audited under the admin with the
+// actor recorded as impersonator, and the tenant sees the session in its security events.
+func (s *PlatformService) StartImpersonation(ctx context.Context, actor Actor, tenantID uuid.UUID, params StartImpersonationParams, client ClientInfo) (*Impersonation, error) {
+	ctx = database.WithSystem(ctx)
+
+	params.Reason = strings.TrimSpace(params.Reason)
+	if params.Reason == "" {
+		return nil, ErrReasonRequired
+	}
+
+	ttl := DefaultImpersonationTTL
+	if params.Minutes != 0 {
+		ttl = time.Duration(params.Minutes) * time.Minute
+		if ttl < time.Minute || ttl > MaxImpersonationTTL {
+			return nil, ErrInvalidImpersonationTTL
+		}
+	}
+
+	if err := checkTenantActive(ctx, s.queries, tenantID); err != nil {
+		return nil, err
+	}
+
+	var user db.User
+	var membership db.TenantMembership
+	if params.UserID != nil {
+		row, err := s.queries.GetTenantMember(ctx, db.GetTenantMemberParams{ID: *params.UserID, TenantID: tenantID})
+		if err != nil {
+			if database.IsNotFound(err) {
+				return nil, ErrImpersonationTarget
+			}
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		user, membership = row.User, row.TenantMembership
+	} else {
+		row, err := s.queries.GetTenantAdmin(ctx, tenantID)
+		if err != nil {
+			if database.IsNotFound(err) {
+				return nil, ErrImpersonationTarget
+			}
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		user, membership = row.User, row.TenantMembership
+	}
+
+	role, _ := membership.Role.(string)
+	if role != RoleAdmin || !membership.IsActive || !isActive(user) || user.ID == actor.UserID {
+		return nil, ErrImpersonationTarget
+	}
+
+	session, err := s.queries.CreateImpersonationSession(ctx, db.CreateImpersonationSessionParams{
+		ImpersonatorID: actor.UserID,
+		TenantID:       tenantID,
+		UserID:         user.ID,
+		Reason:         params.Reason,
+		IpAddress:      utils.P.Text(client.IPAddress),
+		ExpiresAt:      time.Now().Add(ttl),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create impersonation session: %w", err)
+	}
+
+	token, err := s.jwt.GenerateImpersonationToken(user.ID.String(), tenantID.String(), user.Email, role,
+		session.ID.String(), actor.UserID.String(), session.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	details := map[string]interface{}{
+		"session_id":      session.ID,
+		"impersonator_id": actor.UserID,
+		"tenant_id":       tenantID,
+		"user_id":         user.ID,
+		"reason":          session.Reason,
+		"expires_at":      session.ExpiresAt,
+	}
+	s.recordActorEvent(ctx, actor, EventImpersonationStarted, client, details)
+	recordSecurityEvent(database.WithTenant(ctx, tenantID), s.queries, EventImpersonationStarted, &user, user.Email, client, details)
+
+	return &Impersonation{
+		Session: newImpersonationSession(session),
+		User:    NewUserView(user, membership),
+		Token:   token,
+	}, nil
+}
+
+// EndImpersonation ends an impersonation session early; its token stops working at once
+func (s *PlatformService) EndImpersonation(ctx context.Context, actor Actor, sessionID uuid.UUID, client ClientInfo) (*ImpersonationSession, error) {
+	ctx = database.WithSystem(ctx)
+
+	session, err := s.queries.EndImpersonationSession(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to end impersonation session: %w", err)
+	}
+
+	details := map[string]interface{}{
+		"session_id":      session.ID,
+		"impersonator_id": session.ImpersonatorID,
+		"tenant_id":       session.TenantID,
+		"user_id":         session.UserID,
+		"ended_by":        actor.UserID,
+	}
+	s.recordActorEvent(ctx, actor, EventImpersonationEnded, client, details)
+	if user, err := s.queries.GetUserByID(ctx, session.UserID); err == nil {
+		recordSecurityEvent(database.WithTenant(ctx, session.TenantID), s.queries, EventImpersonationEnded, &user, user.Email, client, details)
+	}
+
+	view := newImpersonationSession(session)
+	return &view, nil
+}
+
+// ImpersonationFilter narrows ListImpersonations; zero values match everything
+type ImpersonationFilter struct {
+	TenantID       *uuid.UUID
+	ImpersonatorID *uuid.UUID
+	ActiveOnly     bool
+}
+
+// ListImpersonations lists impersonation sessions across tenants, newest first
+func (s *PlatformService) ListImpersonations(ctx context.Context, filter ImpersonationFilter, limit, offset int32) ([]ImpersonationSession, error) {
+	ctx = database.WithSystem(ctx)
+
+	sessions, err := s.queries.ListImpersonationSessions(ctx, db.ListImpersonationSessionsParams{
+		TenantID:       utils.P.UUIDPtr(filter.TenantID),
+		ImpersonatorID: utils.P.UUIDPtr(filter.ImpersonatorID),
+		ActiveOnly:     filter.ActiveOnly,
+		Limit:          limit,
+		Offset:         offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list impersonation sessions: %w", err)
+	}
+
+	views := make([]ImpersonationSession, 0, len(sessions))
+	for _, s := range sessions {
+		views = append(views, newImpersonationSession(s))
+	}
+	return views, nil
+}
+
+// recordActorEvent records a platform action against the super admin who took it, in the
+// security events of the tenant they are logged into
+func (s *PlatformService) recordActorEvent(ctx context.Context, actor Actor, eventType string, client ClientInfo, details map[string]interface{}) {
+	user, err := s.queries.GetUserByID(ctx, actor.UserID)
+	if err != nil {
+		return
+	}
+	recordSecurityEvent(database.WithTenant(ctx, actor.TenantID), s.queries, eventType, &user, user.Email, client, details)
+}
@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Roles as stored in the user_role enum, from least to most privileged
+const (
+	RoleUser       = "user"
+	RoleSupervisor = "supervisor"
+	RoleManager    = "manager"
+	RoleAdmin      = "admin"
+	RoleSuperAdmin = "super_admin"
+)
+
+// Roles lists every role from least to most privileged
+var Roles = []string{RoleUser, RoleSupervisor, RoleManager, RoleAdmin, RoleSuperAdmin}
+
+// Permission names a single action a role may be allowed to perform
+type Permission string
+
+const (
+	PermProductsView   Permission = "products.view"
+	PermProductsManage Permission = "products.manage"
+	PermInventoryView  Permission = "inventory.view"
+	// PermInventoryCount lets a user record what they counted during a stock count
+	PermInventoryCount  Permission = "inventory.count"
+	PermInventoryAdjust Permission = "inventory.adjust"
+	PermInventoryMove   Permission = "inventory.transfer"
+	// PermInventoryApprove is needed to approve stock adjustments above the value threshold
+	PermInventoryApprove Permission = "inventory.approve"
+	PermSuppliersView    Permission = "suppliers.view"
+	PermSuppliersManage  Permission = "suppliers.manage"
+	PermCustomersView    Permission = "customers.view"
+	PermCustomersManage  Permission = "customers.manage"
+	PermPOView           Permission = "po.view"
+	PermPOCreate         Permission = "po.create"
+	PermPOApprove        Permission = "po.approve"
+	PermPOReceive        Permission = "po.receive"
+	PermSOView           Permission = "so.view"
+	PermSOCreate         Permission = "so.create"
+	PermSOApprove        Permission = "so.approve"
+	PermSOShip           Permission = "so.ship"
+	PermLocationsView    Permission = "locations.view"
+	PermLocationsManage  Permission = "locations.manage"
+	PermReportsView      Permission = "reports.view"
+	PermAuditView        Permission = "audit.view"
+	PermUsersManage      Permission = "users.manage"
+	// PermPlatformManage covers every tenant, not just the caller's own
+	PermPlatformManage Permission = "platform.manage"
+)
+
+// Permissions lists every permission in display order
+var Permissions = []Permission{
+	PermProductsView, PermProductsManage,
+	PermInventoryView, PermInventoryCount, PermInventoryAdjust, PermInventoryMove, PermInventoryApprove,
+	PermSuppliersView, PermSuppliersManage,
+	PermCustomersView, PermCustomersManage,
+	PermPOView, PermPOCreate, PermPOApprove, PermPOReceive,
+	PermSOView, PermSOCreate, PermSOApprove, PermSOShip,
+	PermLocationsView, PermLocationsManage,
+	PermReportsView, PermAuditView,
+	PermUsersManage,
+	PermPlatformManage,
+}
+
+// rolePermissions maps each role to what it is allowed to do. Each role builds on the one
+// below it; super_admin is granted everything.
+var rolePermissions = func() map[string]map[Permission]bool {
+	user := []Permission{
+		PermProductsView, PermInventoryView, PermInventoryCount, PermSuppliersView, PermCustomersView,
+		PermPOView, PermPOCreate, PermSOView, PermSOCreate, PermLocationsView,
+	}
+	supervisor := append(append([]Permission{}, user...),
+		PermInventoryAdjust, PermInventoryMove, PermPOReceive, PermSOShip, PermReportsView,
+	)
+	manager := append(append([]Permission{}, supervisor...),
+		PermProductsManage, PermSuppliersManage, PermCustomersManage, PermLocationsManage,
+		PermInventoryApprove, PermPOApprove, PermSOApprove, PermAuditView,
+	)
+	admin := append(append([]Permission{}, manager...), PermUsersManage)
+
+	set := func(perms []Permission) map[Permission]bool {
+		m := make(map[Permission]bool, len(perms))
+		for _, p := range perms {
+			m[p] = true
+		}
+		return m
+	}
+	return map[string]map[Permission]bool{
+		RoleUser:       set(user),
+		RoleSupervisor: set(supervisor),
+		RoleManager:    set(manager),
+		RoleAdmin:      set(admin),
+		RoleSuperAdmin: set(Permissions),
+	}
+}()
+
+// ValidRole reports whether role is a known role
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// HasPermission reports whether role grants perm
+func HasPermission(role string, perm Permission) bool {
+	return rolePermissions[role][perm]
+}
+
+// PermissionsForRole lists the permissions granted to role, in display order
+func PermissionsForRole(role string) []Permission {
+	perms := []Permission{}
+	for _, p := range Permissions {
+		if rolePermissions[role][p] {
+			perms = append(perms, p)
+		}
+	}
+	return perms
+}
+
+// RoleRank orders roles by privilege; unknown roles rank below every known role
+func RoleRank(role string) int {
+	for i, r := range Roles {
+		if r == role {
+			return i
+		}
+	}
+	return -1
+}
+
+// CanAssignRole reports whether a user with actorRole may give role to someone in their
+// tenant. Nobody can grant more than they hold, and super_admin is never granted in-tenant.
+func CanAssignRole(actorRole, role string) bool {
+	return ValidRole(role) && role != RoleSuperAdmin && RoleRank(role) <= RoleRank(actorRole)
+}
+
+// RolePermissionMatrix returns the permissions granted to every role
+func RolePermissionMatrix() map[string][]Permission {
+	matrix := make(map[string][]Permission, len(Roles))
+	for _, role := range Roles {
+		matrix[role] = PermissionsForRole(role)
+	}
+	return matrix
+}
+
+// Granted reports whether the authenticated caller holds perm: through their role, or for
+// API keys, through the key's scopes. It must run after RequireAuth.
+func Granted(c echo.Context, perm Permission) bool {
+	if scopes, ok := c.Get("api_key_scopes").([]Permission); ok {
+		return slices.Contains(scopes, perm)
+	}
+	role, _ := c.Get("user_role").(string)
+	return HasPermission(role, perm)
+}
+
+// RequirePermission allows the request through only if the authenticated user's role grants
+// every listed permission, or for API keys, if the key's scopes include them. It must run
+// after RequireAuth.
+func RequirePermission(perms ...Permission) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if _, ok := c.Get("user_role").(string); !ok {
+				return echo.NewHTTPError(http.StatusUnauthorized, "unauthorized")
+			}
+
+			var missing []string
+			for _, p := range perms {
+				if !Granted(c, p) {
+					missing = append(missing, string(p))
+				}
+			}
+			if len(missing) > 0 {
+				return forbidden("requires " + strings.Join(missing, ", "))
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// forbidden is the 403 returned whenever the caller lacks a required role or permission
+func forbidden(detail string) error {
+	return echo.NewHTTPError(http.StatusForbidden, "insufficient permissions: "+detail)
+}
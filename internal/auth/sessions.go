@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"agromart2/db"
+	"agromart2/internal/database"
+	"agromart2/internal/utils"
+	"github.com/google/uuid"
+)
+
+// RefreshTokenTTL is how long a refresh token can be used; every rotation issues a new one
+const RefreshTokenTTL = 7 * 24 * time.Hour
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token has already been used; session revoked")
+	ErrSessionRevoked      = errors.New("session has been revoked")
+)
+
+// ClientInfo identifies the device a session was started from
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// Session is an active login of a user on one device
+type Session struct {
+	ID              uuid.UUID `json:"id"`
+	UserAgent       string    `json:"user_agent"`
+	IPAddress       string    `json:"ip_address"`
+	LastRefreshedAt time.Time `json:"last_refreshed_at"`
+	ExpiresAt       time.Time `json:"expires_at"`
+	Current         bool      `json:"current"`
+	// TenantID is the tenant the session is logged into
+	TenantID uuid.UUID `json:"tenant_id"`
+}
+
+// issueTokens stores a new refresh token in the given session family and signs an access
+// token bound to that session and to the membership's tenant. The membership must already
+// have been checked with activeMembership.
+func (s *AuthService) issueTokens(ctx context.Context, q *db.Queries, user db.User, membership db.TenantMembership, familyID uuid.UUID, client ClientInfo) (*AuthResponse, error) {
+	refreshToken, refreshHash, err := newOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	_, err = q.CreateRefreshToken(ctx, db.CreateRefreshTokenParams{
+		TenantID:  membership.TenantID,
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: refreshHash,
+		UserAgent: utils.P.Text(client.UserAgent),
+		IpAddress: utils.P.Text(client.IPAddress),
+		ExpiresAt: time.Now().Add(RefreshTokenTTL),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	role, _ := membership.Role.(string)
+	token, err := s.jwt.GenerateToken(user.ID.String(), membership.TenantID.String(), user.Email, role, familyID.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	memberships, err := listMemberships(ctx, q, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthResponse{
+		User:         &user,
+		Token:        token,
+		RefreshToken: refreshToken,
+		TenantID:     &membership.TenantID,
+		Role:         role,
+		Memberships:  memberships,
+	}, nil
+}
+
+// Authenticate checks that the user, membership and session behind a valid access token
+// are still usable, so deactivation, role changes and logout take effect before the token
+// expires. It returns the user and their membership of the token's tenant.
+func (s *AuthService) Authenticate(ctx context.Context, claims *Claims) (*db.User, *db.TenantMembership, error) {
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid user ID in token: %w", err)
+	}
+	tenantID, err := uuid.Parse(claims.TenantID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid tenant ID in token: %w", err)
+	}
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return nil, nil, ErrSessionRevoked
+	}
+
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !isActive(*user) {
+		return nil, nil, ErrAccountDeactivated
+	}
+	membership, err := activeMembership(ctx, s.queries, userID, tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// An impersonation token is bound to its impersonation session rather than a refresh
+	// token family, and dies with it
+	if claims.ImpersonatorID != "" {
+		active, err := s.queries.IsImpersonationActive(database.WithSystem(ctx), sessionID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to check impersonation session: %w", err)
+		}
+		if !active {
+			return nil, nil, ErrSessionRevoked
+		}
+		return user, membership, nil
+	}
+
+	revoked, err := s.queries.IsSessionRevoked(ctx, sessionID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to check session: %w", err)
+	}
+	if revoked {
+		return nil, nil, ErrSessionRevoked
+	}
+
+	return user, membership, nil
+}
+
+// checkTenantActive refuses users and API keys of a suspended tenant
+func checkTenantActive(ctx context.Context, q *db.Queries, tenantID uuid.UUID) error {
+	active, err := q.IsTenantActive(ctx, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to check tenant: %w", err)
+	}
+	if !active {
+		return ErrTenantSuspended
+	}
+	return nil
+}
+
+// Logout ends the session the refresh token belongs to. Unknown tokens are ignored so
+// logging out twice is harmless.
+func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
+	ctx = database.WithSystem(ctx)
+
+	if err := s.queries.RevokeRefreshTokenFamilyByHash(ctx, hashToken(refreshToken)); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+// LogoutSession ends one of the user's sessions, whichever tenant it is logged into
+func (s *AuthService) LogoutSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	// A user's sessions span their tenants; userID keeps this to their own
+	ctx = database.WithSystem(ctx)
+
+	n, err := s.queries.RevokeUserSession(ctx, db.RevokeUserSessionParams{
+		FamilyID: sessionID,
+		UserID:   userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if n == 0 {
+		return database.ErrNotFound
+	}
+	return nil
+}
+
+// LogoutAll ends every session of the user, on every device and in every tenant
+func (s *AuthService) LogoutAll(ctx context.Context, userID uuid.UUID) error {
+	ctx = database.WithSystem(ctx)
+
+	if err := s.queries.RevokeUserRefreshTokens(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return nil
+}
+
+// ListSessions lists the user's active sessions in every tenant, marking the one
+// currentSessionID belongs to
+func (s *AuthService) ListSessions(ctx context.Context, userID uuid.UUID, currentSessionID string) ([]Session, error) {
+	ctx = database.WithSystem(ctx)
+
+	tokens, err := s.queries.ListUserSessions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	sessions := make([]Session, 0, len(tokens))
+	for _, t := range tokens {
+		sessions = append(sessions, Session{
+			ID:              t.FamilyID,
+			UserAgent:       t.UserAgent.String,
+			IPAddress:       t.IpAddress.String,
+			LastRefreshedAt: t.CreatedAt,
+			ExpiresAt:       t.ExpiresAt,
+			Current:         t.FamilyID.String() == currentSessionID,
+			TenantID:        t.TenantID,
+		})
+	}
+	return sessions, nil
+}
@@ -0,0 +1,501 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"agromart2/db"
+	"agromart2/internal/database"
+	"github.com/google/uuid"
+)
+
+// TwoFactorChallengeTTL is how long a user has to enter their code after the password
+const TwoFactorChallengeTTL = 5 * time.Minute
+
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorNotStarted     = errors.New("two-factor enrolment has not been started")
+	ErrTwoFactorRequired       = errors.New("two-factor authentication is mandatory for your role")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+)
+
+// TwoFactorRequiredForRole reports whether a tenant's two-factor policy applies to role:
+// managers, who approve purchase orders and adjust stock, and every role above them
+func TwoFactorRequiredForRole(role string) bool {
+	return RoleRank(role) >= RoleRank(RoleManager)
+}
+
+// TwoFactorStatus describes a user's two-factor setup
+type TwoFactorStatus struct {
+	Enabled           bool  `json:"enabled"`
+	Pending           bool  `json:"pending"`
+	Required          bool  `json:"required"`
+	RecoveryCodesLeft int64 `json:"recovery_codes_left"`
+}
+
+// TwoFactorEnrolment is what an authenticator app needs; ProvisioningURI is meant to be
+// shown as a QR code
+type TwoFactorEnrolment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// TwoFactorLoginRequest completes a login that returned a challenge. Code is either the
+// current authenticator code or one of the user's recovery codes.
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+	// TenantID picks the tenant to log into, as in LoginRequest
+	TenantID *uuid.UUID `json:"tenant_id,omitempty"`
+}
+
+// getUserTOTP returns the user's TOTP enrolment, or nil if they never started one
+func getUserTOTP(ctx context.Context, q *db.Queries, userID uuid.UUID) (*db.UserTotp, error) {
+	t, err := q.GetUserTOTP(ctx, userID)
+	if err != nil {
+		if database.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get two-factor settings: %w", err)
+	}
+	return &t, nil
+}
+
+func totpEnabled(t *db.UserTotp) bool {
+	return t != nil && t.EnabledAt.Valid
+}
+
+// twoFactorRequired reports whether any of the user's tenants makes two-factor mandatory for
+// the role they hold there. The second factor protects the account, so one such tenant is
+// enough to require it for every login.
+func twoFactorRequired(ctx context.Context, q *db.Queries, user db.User) (bool, error) {
+	rows, err := q.ListUserMemberships(ctx, user.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to list memberships: %w", err)
+	}
+	for _, row := range rows {
+		role, _ := row.TenantMembership.Role.(string)
+		if row.TenantMembership.IsActive && row.Tenant.RequireTwoFactor && TwoFactorRequiredForRole(role) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// twoFactorChallenge is called once the password checked out. It returns nil when the user
+// can be logged in straight away, otherwise a challenge the second step must redeem.
+func (s *AuthService) twoFactorChallenge(ctx context.Context, user db.User) (*AuthResponse, error) {
+	totp, err := getUserTOTP(ctx, s.queries, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	setup := false
+	if !totpEnabled(totp) {
+		required, err := twoFactorRequired(ctx, s.queries, user)
+		if err != nil {
+			return nil, err
+		}
+		if !required {
+			return nil, nil
+		}
+		setup = true
+	}
+
+	token, _, err := createUserToken(ctx, s.queries, user, TokenPurposeTwoFactor, TwoFactorChallengeTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthResponse{
+		TwoFactorRequired:      true,
+		TwoFactorSetupRequired: setup,
+		ChallengeToken:         token,
+	}, nil
+}
+
+// VerifyTwoFactor is the second step of a login: it checks the code against the challenge
+// and starts the session. A user whose tenant forced enrolment at login confirms their new
+// authenticator here and receives their recovery codes with the tokens. Wrong codes count
+// as failed logins, so guessing runs into the same lockout as guessing passwords.
+func (s *AuthService) VerifyTwoFactor(ctx context.Context, req TwoFactorLoginRequest, client ClientInfo) (*AuthResponse, error) {
+	ctx = database.WithSystem(ctx)
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	challenge, user, err := lookupUserToken(ctx, qtx, req.ChallengeToken, TokenPurposeTwoFactor)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkLoginThrottle(ctx, throttleKeys(user.Email, client)); err != nil {
+		return nil, err
+	}
+
+	totp, err := getUserTOTP(ctx, qtx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if totp == nil {
+		return nil, ErrTwoFactorNotStarted
+	}
+
+	var recoveryCodes []string
+	usedRecoveryCode := false
+	if totpEnabled(totp) {
+		usedRecoveryCode, err = checkSecondFactor(ctx, qtx, *totp, req.Code)
+	} else {
+		// Enrolment forced at login: the first code confirms the new authenticator
+		err = useTOTPCode(ctx, qtx, *totp, req.Code)
+		if err == nil {
+			recoveryCodes, err = enableTOTP(ctx, qtx, user.ID)
+		}
+	}
+	if err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			s.recordLoginFailure(ctx, user.Email, &user, client)
+		}
+		return nil, err
+	}
+
+	if err := qtx.MarkUserTokenUsed(ctx, challenge.ID); err != nil {
+		return nil, fmt.Errorf("failed to mark challenge used: %w", err)
+	}
+
+	if err := clearAccountThrottle(ctx, qtx, user.Email); err != nil {
+		return nil, err
+	}
+
+	membership, err := chooseMembership(ctx, qtx, user, req.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.issueTokens(ctx, qtx, user, *membership, uuid.New(), client)
+	if err != nil {
+		return nil, err
+	}
+	response.RecoveryCodes = recoveryCodes
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if recoveryCodes != nil {
+		recordSecurityEvent(ctx, s.queries, EventTwoFactorEnabled, &user, user.Email, client, nil)
+	}
+	if usedRecoveryCode {
+		recordSecurityEvent(ctx, s.queries, EventRecoveryCodeUsed, &user, user.Email, client, nil)
+	}
+
+	return response, nil
+}
+
+// BeginTwoFactorSetup starts the enrolment a login challenge asked for
+func (s *AuthService) BeginTwoFactorSetup(ctx context.Context, challengeToken string) (*TwoFactorEnrolment, error) {
+	ctx = database.WithSystem(ctx)
+
+	_, user, err := lookupUserToken(ctx, s.queries, challengeToken, TokenPurposeTwoFactor)
+	if err != nil {
+		return nil, err
+	}
+	return beginEnrolment(ctx, s.queries, user)
+}
+
+// BeginTwoFactorEnrolment generates a new secret for a logged-in user. Two-factor is not
+// on until EnableTwoFactor confirms a code from it.
+func (s *AuthService) BeginTwoFactorEnrolment(ctx context.Context, userID uuid.UUID) (*TwoFactorEnrolment, error) {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return beginEnrolment(ctx, s.queries, *user)
+}
+
+// beginEnrolment stores a fresh pending secret, replacing an unconfirmed one
+func beginEnrolment(ctx context.Context, q *db.Queries, user db.User) (*TwoFactorEnrolment, error) {
+	secret, err := newTOTPSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	totp, err := q.UpsertPendingUserTOTP(ctx, db.UpsertPendingUserTOTPParams{
+		UserID: user.ID,
+		Secret: secret,
+	})
+	if err != nil {
+		// The upsert leaves an enabled secret alone and returns no row
+		if database.IsNotFound(err) {
+			return nil, ErrTwoFactorAlreadyEnabled
+		}
+		return nil, fmt.Errorf("failed to store secret: %w", err)
+	}
+
+	return &TwoFactorEnrolment{
+		Secret:          totp.Secret,
+		ProvisioningURI: totpProvisioningURI(user.Email, totp.Secret),
+	}, nil
+}
+
+// EnableTwoFactor confirms a pending enrolment with a code from the authenticator and
+// returns the user's recovery codes, which are only ever shown this once
+func (s *AuthService) EnableTwoFactor(ctx context.Context, userID uuid.UUID, code string, client ClientInfo) ([]string, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	totp, err := getUserTOTP(ctx, qtx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if totp == nil {
+		return nil, ErrTwoFactorNotStarted
+	}
+	if totpEnabled(totp) {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	if err := useTOTPCode(ctx, qtx, *totp, code); err != nil {
+		return nil, err
+	}
+
+	codes, err := enableTOTP(ctx, qtx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if user, err := s.queries.GetUserByID(ctx, userID); err == nil {
+		recordSecurityEvent(ctx, s.queries, EventTwoFactorEnabled, &user, user.Email, client, nil)
+	}
+	return codes, nil
+}
+
+// DisableTwoFactor turns two-factor off after checking both the password and a code. Users
+// who hold a role in any tenant that requires two-factor for it cannot turn it off.
+func (s *AuthService) DisableTwoFactor(ctx context.Context, userID uuid.UUID, password, code string, client ClientInfo) error {
+	if err := s.CheckPassword(ctx, userID, password); err != nil {
+		return err
+	}
+
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	required, err := twoFactorRequired(database.WithSystem(ctx), s.queries, *user)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrTwoFactorRequired
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	totp, err := getUserTOTP(ctx, qtx, userID)
+	if err != nil {
+		return err
+	}
+	if !totpEnabled(totp) {
+		return ErrTwoFactorNotEnabled
+	}
+
+	if _, err := checkSecondFactor(ctx, qtx, *totp, code); err != nil {
+		return err
+	}
+
+	if err := removeTwoFactor(ctx, qtx, userID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	recordSecurityEvent(ctx, s.queries, EventTwoFactorDisabled, user, user.Email, client, nil)
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces all of the user's recovery codes after checking a code
+func (s *AuthService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	totp, err := getUserTOTP(ctx, qtx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !totpEnabled(totp) {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	if _, err := checkSecondFactor(ctx, qtx, *totp, code); err != nil {
+		return nil, err
+	}
+
+	codes, err := replaceRecoveryCodes(ctx, qtx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return codes, nil
+}
+
+// TwoFactorStatus reports whether the user has two-factor on and whether they must
+func (s *AuthService) TwoFactorStatus(ctx context.Context, userID uuid.UUID) (*TwoFactorStatus, error) {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	totp, err := getUserTOTP(ctx, s.queries, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The policy of every tenant the user belongs to applies
+	required, err := twoFactorRequired(database.WithSystem(ctx), s.queries, *user)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &TwoFactorStatus{
+		Enabled:  totpEnabled(totp),
+		Pending:  totp != nil && !totpEnabled(totp),
+		Required: required,
+	}
+	if status.Enabled {
+		status.RecoveryCodesLeft, err = s.queries.CountUnusedRecoveryCodes(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count recovery codes: %w", err)
+		}
+	}
+	return status, nil
+}
+
+// useTOTPCode accepts a code from the authenticator at most once: its time step must be
+// later than the last one accepted for the user
+func useTOTPCode(ctx context.Context, q *db.Queries, totp db.UserTotp, code string) error {
+	step, ok := matchTOTP(totp.Secret, code, time.Now())
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+
+	n, err := q.AdvanceUserTOTPStep(ctx, db.AdvanceUserTOTPStepParams{
+		UserID:       totp.UserID,
+		LastUsedStep: step,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record two-factor code: %w", err)
+	}
+	if n == 0 {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// checkSecondFactor accepts an authenticator code or spends a recovery code, and reports
+// which it was
+func checkSecondFactor(ctx context.Context, q *db.Queries, totp db.UserTotp, code string) (bool, error) {
+	if isTOTPCode(code) {
+		return false, useTOTPCode(ctx, q, totp, code)
+	}
+
+	n, err := q.UseUserRecoveryCode(ctx, db.UseUserRecoveryCodeParams{
+		UserID:   totp.UserID,
+		CodeHash: hashRecoveryCode(code),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	if n == 0 {
+		return false, ErrInvalidTwoFactorCode
+	}
+	return true, nil
+}
+
+// isTOTPCode tells authenticator codes, which are all digits, from recovery codes
+func isTOTPCode(code string) bool {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) != totpDigits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// enableTOTP switches a confirmed enrolment on and issues its recovery codes
+func enableTOTP(ctx context.Context, q *db.Queries, userID uuid.UUID) ([]string, error) {
+	if err := q.EnableUserTOTP(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed to enable two-factor: %w", err)
+	}
+	return replaceRecoveryCodes(ctx, q, userID)
+}
+
+// replaceRecoveryCodes invalidates the user's recovery codes and returns a new set
+func replaceRecoveryCodes(ctx context.Context, q *db.Queries, userID uuid.UUID) ([]string, error) {
+	codes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+	}
+
+	if err := q.DeleteUserRecoveryCodes(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed to replace recovery codes: %w", err)
+	}
+	for _, code := range codes {
+		if err := q.CreateUserRecoveryCode(ctx, db.CreateUserRecoveryCodeParams{
+			UserID:   userID,
+			CodeHash: hashRecoveryCode(code),
+		}); err != nil {
+			return nil, fmt.Errorf("failed to store recovery code: %w", err)
+		}
+	}
+	return codes, nil
+}
+
+// removeTwoFactor deletes the user's secret and recovery codes
+func removeTwoFactor(ctx context.Context, q *db.Queries, userID uuid.UUID) error {
+	if err := q.DeleteUserTOTP(ctx, userID); err != nil {
+		return fmt.Errorf("failed to remove two-factor: %w", err)
+	}
+	if err := q.DeleteUserRecoveryCodes(ctx, userID); err != nil {
+		return fmt.Errorf("failed to remove recovery codes: %w", err)
+	}
+	return nil
+}
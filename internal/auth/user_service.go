@@ -0,0 +1,619 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"agromart2/db"
+	"agromart2/internal/database"
+	"agromart2/internal/mailer"
+	"agromart2/internal/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// InviteTTL is how long an emailed invite link stays valid
+const InviteTTL = 7 * 24 * time.Hour
+
+// MinPasswordLength matches the validation on LoginRequest and RegisterRequest
+const MinPasswordLength = 6
+
+var (
+	ErrInvalidEmail     = errors.New("a valid email address is required")
+	ErrInvalidRole      = errors.New("invalid role")
+	ErrRoleNotAllowed   = errors.New("cannot assign a role above your own")
+	ErrUserOutranks     = errors.New("cannot manage a user with a higher role than your own")
+	ErrCannotModifySelf = errors.New("cannot change your own role, status or sessions here")
+	ErrEmailTaken       = errors.New("a user with this email already exists")
+	ErrInvalidInvite    = errors.New("invite is invalid, already used or expired")
+	ErrPasswordTooShort = fmt.Errorf("password must be at least %d characters", MinPasswordLength)
+	ErrNameRequired     = errors.New("name is required")
+	ErrForeignAccount   = errors.New("this user's account belongs to another organization")
+)
+
+// UserService manages the users of a tenant: invites, roles and deactivation
+type UserService struct {
+	db      *pgxpool.Pool
+	queries *db.Queries
+	mailer  mailer.Mailer
+	appURL  string
+}
+
+func NewUserService(dbPool *pgxpool.Pool, queries *db.Queries, m mailer.Mailer, appURL string) *UserService {
+	return &UserService{
+		db:      dbPool,
+		queries: queries,
+		mailer:  m,
+		appURL:  strings.TrimRight(appURL, "/"),
+	}
+}
+
+// Actor is the authenticated user performing a user-management action
+type Actor struct {
+	UserID   uuid.UUID
+	TenantID uuid.UUID
+	Role     string
+}
+
+// UserView is a user as exposed by the API, without the password hash. TenantID, Role and
+// IsActive describe their membership of the tenant they are viewed from.
+type UserView struct {
+	ID            uuid.UUID `json:"id"`
+	Name          string    `json:"name"`
+	Email         string    `json:"email"`
+	Phone         string    `json:"phone"`
+	TenantID      uuid.UUID `json:"tenant_id"`
+	Role          string    `json:"role"`
+	EmailVerified bool      `json:"email_verified"`
+	IsActive      bool      `json:"is_active"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func NewUserView(u db.User, m db.TenantMembership) UserView {
+	role, _ := m.Role.(string)
+	return UserView{
+		ID:            u.ID,
+		Name:          u.Name,
+		Email:         u.Email,
+		Phone:         u.Phone,
+		TenantID:      m.TenantID,
+		Role:          role,
+		EmailVerified: u.EmailVerified.Valid && u.EmailVerified.Bool,
+		IsActive:      isActive(u) && m.IsActive,
+		CreatedAt:     u.CreatedAt,
+	}
+}
+
+// Invite is a pending invite as exposed by the API, without the token hash
+type Invite struct {
+	ID        uuid.UUID  `json:"id"`
+	Email     string     `json:"email"`
+	Role      string     `json:"role"`
+	InvitedBy *uuid.UUID `json:"invited_by"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func newInvite(inv db.UserInvite) Invite {
+	role, _ := inv.Role.(string)
+	invite := Invite{
+		ID:        inv.ID,
+		Email:     inv.Email,
+		Role:      role,
+		ExpiresAt: inv.ExpiresAt,
+		CreatedAt: inv.CreatedAt,
+	}
+	if inv.InvitedBy.Valid {
+		id := uuid.UUID(inv.InvitedBy.Bytes)
+		invite.InvitedBy = &id
+	}
+	return invite
+}
+
+// AcceptInviteParams holds what an invited user provides to create their account. Someone
+// who already has an account only gives their current password, which proves it is theirs.
+type AcceptInviteParams struct {
+	Token    string
+	Name     string
+	Phone    string
+	Password string
+}
+
+// ListUsers lists a tenant's members, optionally only those with the given role
+func (s *UserService) ListUsers(ctx context.Context, tenantID uuid.UUID, role string, limit, offset int32) ([]UserView, error) {
+	if role != "" && !ValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+
+	users, err := s.queries.ListUsers(ctx, db.ListUsersParams{
+		TenantID: tenantID,
+		Role:     utils.P.Text(role),
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	views := make([]UserView, 0, len(users))
+	for _, u := range users {
+		views = append(views, NewUserView(u.User, u.TenantMembership))
+	}
+	return views, nil
+}
+
+// InviteUser emails a one-time link that lets email join the actor's tenant with role. The
+// address may already have an account in another tenant; accepting then adds a membership.
+// Inviting an address that already has a pending invite replaces the old invite.
+func (s *UserService) InviteUser(ctx context.Context, actor Actor, email, role string) (*Invite, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if !strings.Contains(email, "@") {
+		return nil, ErrInvalidEmail
+	}
+	if role == "" {
+		role = RoleUser
+	}
+	if !ValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+	if !CanAssignRole(actor.Role, role) {
+		return nil, ErrRoleNotAllowed
+	}
+
+	// Accounts are shared across tenants, so the lookup has to look past row-level security
+	existing, err := s.queries.FindUserByEmail(database.WithSystem(ctx), email)
+	if err != nil && !database.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to check email: %w", err)
+	}
+	hasAccount := err == nil
+	if hasAccount {
+		_, err := s.queries.GetTenantMembership(database.WithSystem(ctx), db.GetTenantMembershipParams{
+			TenantID: actor.TenantID,
+			UserID:   existing.ID,
+		})
+		if err == nil {
+			return nil, ErrAlreadyMember
+		}
+		if !database.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to check membership: %w", err)
+		}
+	}
+
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invite token: %w", err)
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	if err := qtx.DeletePendingUserInvites(ctx, db.DeletePendingUserInvitesParams{
+		TenantID: actor.TenantID,
+		Lower:    email,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to replace pending invite: %w", err)
+	}
+
+	inv, err := qtx.CreateUserInvite(ctx, db.CreateUserInviteParams{
+		TenantID:  actor.TenantID,
+		Email:     email,
+		Role:      role,
+		TokenHash: tokenHash,
+		InvitedBy: utils.P.UUID(actor.UserID),
+		ExpiresAt: time.Now().Add(InviteTTL),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invite: %w", err)
+	}
+
+	tenant, err := qtx.GetTenantByID(ctx, actor.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("tenant not found: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	link := s.appURL + "/accept-invite?token=" + url.QueryEscape(token)
+	action := "Set your password and activate your account here"
+	if hasAccount {
+		action = "Confirm with your existing AgroMart password here"
+	}
+	err = s.mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: fmt.Sprintf("You have been invited to %s on AgroMart", tenant.Name),
+		Body: fmt.Sprintf("You have been invited to join %s on AgroMart as %s.\n\n"+
+			"%s:\n%s\n\n"+
+			"This link expires on %s.\n",
+			tenant.Name, role, action, link, inv.ExpiresAt.UTC().Format(time.RFC1123)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invite created but email could not be sent: %w", err)
+	}
+
+	invite := newInvite(inv)
+	return &invite, nil
+}
+
+// ListInvites lists a tenant's invites that have not been accepted yet
+func (s *UserService) ListInvites(ctx context.Context, tenantID uuid.UUID) ([]Invite, error) {
+	invites, err := s.queries.ListPendingUserInvites(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invites: %w", err)
+	}
+
+	views := make([]Invite, 0, len(invites))
+	for _, inv := range invites {
+		views = append(views, newInvite(inv))
+	}
+	return views, nil
+}
+
+// RevokeInvite deletes a pending invite so its link can no longer be used
+func (s *UserService) RevokeInvite(ctx context.Context, tenantID, inviteID uuid.UUID) error {
+	n, err := s.queries.DeleteUserInvite(ctx, db.DeleteUserInviteParams{
+		ID:       inviteID,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke invite: %w", err)
+	}
+	if n == 0 {
+		return database.ErrNotFound
+	}
+	return nil
+}
+
+// AcceptInvite redeems an invite token. An invited address without an account gets one
+// with the chosen password; an existing account joins the tenant once its password checks out.
+func (s *UserService) AcceptInvite(ctx context.Context, params AcceptInviteParams) (*UserView, error) {
+	ctx = database.WithSystem(ctx)
+
+	params.Name = strings.TrimSpace(params.Name)
+	if params.Token == "" {
+		return nil, ErrInvalidInvite
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	// Locking the invite makes concurrent redemptions of the same token wait, then fail
+	inv, err := qtx.GetUserInviteByTokenHashForUpdate(ctx, hashToken(params.Token))
+	if err != nil {
+		if database.IsNotFound(err) {
+			return nil, ErrInvalidInvite
+		}
+		return nil, fmt.Errorf("failed to get invite: %w", err)
+	}
+	if inv.AcceptedAt.Valid || time.Now().After(inv.ExpiresAt) {
+		return nil, ErrInvalidInvite
+	}
+
+	user, err := qtx.FindUserByEmail(ctx, inv.Email)
+	switch {
+	case err == nil:
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(params.Password)); err != nil {
+			return nil, ErrInvalidCredentials
+		}
+		if !isActive(user) {
+			return nil, ErrAccountDeactivated
+		}
+	case database.IsNotFound(err):
+		user, err = createInvitedUser(ctx, qtx, inv, params)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	membership, err := qtx.CreateTenantMembership(ctx, db.CreateTenantMembershipParams{
+		TenantID:  inv.TenantID,
+		UserID:    user.ID,
+		Role:      inv.Role,
+		InvitedBy: inv.InvitedBy,
+	})
+	if err != nil {
+		if database.IsDuplicateKey(err) {
+			return nil, ErrAlreadyMember
+		}
+		return nil, fmt.Errorf("failed to create membership: %w", err)
+	}
+
+	if err := qtx.MarkUserInviteAccepted(ctx, inv.ID); err != nil {
+		return nil, fmt.Errorf("failed to mark invite accepted: %w", err)
+	}
+
+	// The invite link was emailed, so redeeming it proves the address
+	if err := qtx.SetUserEmailVerified(ctx, user.ID); err != nil {
+		return nil, fmt.Errorf("failed to verify email: %w", err)
+	}
+	user.EmailVerified = utils.P.Bool(true)
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	view := NewUserView(user, membership)
+	return &view, nil
+}
+
+// createInvitedUser creates the account of an invited address that has none yet; the
+// invite's tenant becomes its home tenant
+func createInvitedUser(ctx context.Context, q *db.Queries, inv db.UserInvite, params AcceptInviteParams) (db.User, error) {
+	if params.Name == "" {
+		return db.User{}, ErrNameRequired
+	}
+	if len(params.Password) < MinPasswordLength {
+		return db.User{}, ErrPasswordTooShort
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(params.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return db.User{}, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user, err := q.CreateUser(ctx, db.CreateUserParams{
+		Name:     params.Name,
+		Email:    inv.Email,
+		Password: string(hashedPassword),
+		Phone:    params.Phone,
+		TenantID: inv.TenantID,
+	})
+	if err != nil {
+		if database.IsDuplicateKey(err) {
+			return db.User{}, ErrEmailTaken
+		}
+		return db.User{}, fmt.Errorf("failed to create user: %w", err)
+	}
+	return user, nil
+}
+
+// ChangeRole gives another member of the actor's tenant a new role there
+func (s *UserService) ChangeRole(ctx context.Context, actor Actor, userID uuid.UUID, role string) (*UserView, error) {
+	if !ValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+	if !CanAssignRole(actor.Role, role) {
+		return nil, ErrRoleNotAllowed
+	}
+	member, err := s.manageableUser(ctx, actor, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	membership, err := s.queries.UpdateTenantMembershipRole(ctx, db.UpdateTenantMembershipRoleParams{
+		TenantID: actor.TenantID,
+		UserID:   userID,
+		Role:     role,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update role: %w", err)
+	}
+
+	view := NewUserView(member.User, membership)
+	return &view, nil
+}
+
+// SetActive activates or deactivates another member of the actor's tenant. Only their
+// membership changes, so they keep access to their other tenants. A deactivated member is
+// rejected on their next request, not only at their next login.
+func (s *UserService) SetActive(ctx context.Context, actor Actor, userID uuid.UUID, active bool) (*UserView, error) {
+	member, err := s.manageableUser(ctx, actor, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	membership, err := qtx.SetTenantMembershipActive(ctx, db.SetTenantMembershipActiveParams{
+		TenantID: actor.TenantID,
+		UserID:   userID,
+		IsActive: active,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update user status: %w", err)
+	}
+
+	// Ending their sessions in this tenant also rejects the access tokens they still hold
+	if !active {
+		if err := qtx.RevokeUserTenantRefreshTokens(ctx, db.RevokeUserTenantRefreshTokensParams{
+			UserID:   userID,
+			TenantID: actor.TenantID,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	view := NewUserView(member.User, membership)
+	return &view, nil
+}
+
+// RevokeSessions logs another member of the actor's tenant out of it on every device
+func (s *UserService) RevokeSessions(ctx context.Context, actor Actor, userID uuid.UUID) error {
+	if _, err := s.manageableUser(ctx, actor, userID); err != nil {
+		return err
+	}
+
+	if err := s.queries.RevokeUserTenantRefreshTokens(ctx, db.RevokeUserTenantRefreshTokensParams{
+		UserID:   userID,
+		TenantID: actor.TenantID,
+	}); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return nil
+}
+
+// UnlockUser lifts a login lockout on another user of the actor's tenant
+func (s *UserService) UnlockUser(ctx context.Context, actor Actor, userID uuid.UUID, client ClientInfo) error {
+	member, err := s.manageableUser(ctx, actor, userID)
+	if err != nil {
+		return err
+	}
+	user := &member.User
+
+	if err := clearAccountThrottle(ctx, s.queries, user.Email); err != nil {
+		return err
+	}
+
+	recordSecurityEvent(ctx, s.queries, EventAccountUnlocked, user, user.Email, client, map[string]interface{}{
+		"unlocked_by": actor.UserID,
+	})
+	return nil
+}
+
+// ResetTwoFactor removes another user's authenticator, for when they lost it along with
+// their recovery codes. If their tenant requires two-factor they enrol again at next login.
+// The authenticator protects the whole account, so only the tenant the account was created
+// in may reset it.
+func (s *UserService) ResetTwoFactor(ctx context.Context, actor Actor, userID uuid.UUID, client ClientInfo) error {
+	member, err := s.manageableUser(ctx, actor, userID)
+	if err != nil {
+		return err
+	}
+	user := &member.User
+	if user.TenantID != actor.TenantID {
+		return ErrForeignAccount
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := removeTwoFactor(ctx, s.queries.WithTx(tx), userID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	recordSecurityEvent(ctx, s.queries, EventTwoFactorReset, user, user.Email, client, map[string]interface{}{
+		"reset_by": actor.UserID,
+	})
+	return nil
+}
+
+// TwoFactorPolicy is a tenant's two-factor requirement
+type TwoFactorPolicy struct {
+	Required bool `json:"required"`
+	// Roles lists the roles the requirement applies to
+	Roles []string `json:"roles"`
+}
+
+func newTwoFactorPolicy(t db.Tenant) TwoFactorPolicy {
+	roles := []string{}
+	for _, role := range Roles {
+		if TwoFactorRequiredForRole(role) {
+			roles = append(roles, role)
+		}
+	}
+	return TwoFactorPolicy{Required: t.RequireTwoFactor, Roles: roles}
+}
+
+// GetTwoFactorPolicy returns whether the tenant requires two-factor for privileged roles
+func (s *UserService) GetTwoFactorPolicy(ctx context.Context, tenantID uuid.UUID) (*TwoFactorPolicy, error) {
+	tenant, err := s.queries.GetTenantByID(ctx, tenantID)
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get tenant")
+	}
+	policy := newTwoFactorPolicy(tenant)
+	return &policy, nil
+}
+
+// SetTwoFactorPolicy makes two-factor mandatory, or optional again, for the tenant's
+// managers and above. Users it newly applies to enrol the next time they log in.
+func (s *UserService) SetTwoFactorPolicy(ctx context.Context, actor Actor, required bool, client ClientInfo) (*TwoFactorPolicy, error) {
+	tenant, err := s.queries.SetTenantRequireTwoFactor(ctx, db.SetTenantRequireTwoFactorParams{
+		ID:               actor.TenantID,
+		RequireTwoFactor: required,
+	})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to update tenant")
+	}
+
+	if user, err := s.queries.GetUserByID(ctx, actor.UserID); err == nil {
+		recordSecurityEvent(ctx, s.queries, EventTwoFactorPolicyChanged, &user, user.Email, client, map[string]interface{}{
+			"required": required,
+		})
+	}
+
+	policy := newTwoFactorPolicy(tenant)
+	return &policy, nil
+}
+
+// ListSecurityEvents lists the tenant's security events, newest first
+func (s *UserService) ListSecurityEvents(ctx context.Context, tenantID uuid.UUID, filter SecurityEventFilter, limit, offset int32) ([]SecurityEvent, error) {
+	events, err := s.queries.ListSecurityEvents(ctx, db.ListSecurityEventsParams{
+		TenantID:  tenantID,
+		EventType: utils.P.Text(filter.EventType),
+		UserID:    utils.P.UUIDPtr(filter.UserID),
+		IpAddress: utils.P.Text(filter.IPAddress),
+		Since:     utils.P.TimestamptzPtr(filter.Since),
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list security events: %w", err)
+	}
+
+	views := make([]SecurityEvent, 0, len(events))
+	for _, e := range events {
+		views = append(views, newSecurityEvent(e))
+	}
+	return views, nil
+}
+
+// manageableUser loads a member of the actor's tenant that the actor is allowed to change
+func (s *UserService) manageableUser(ctx context.Context, actor Actor, userID uuid.UUID) (*db.GetTenantMemberRow, error) {
+	if userID == actor.UserID {
+		return nil, ErrCannotModifySelf
+	}
+
+	member, err := s.queries.GetTenantMember(ctx, db.GetTenantMemberParams{
+		ID:       userID,
+		TenantID: actor.TenantID,
+	})
+	if err != nil {
+		return nil, database.WrapError(err, "failed to get user")
+	}
+
+	role, _ := member.TenantMembership.Role.(string)
+	if RoleRank(role) > RoleRank(actor.Role) {
+		return nil, ErrUserOutranks
+	}
+	return &member, nil
+}
+
+// isActive treats a NULL is_active as active, as login does
+func isActive(u db.User) bool {
+	return !u.IsActive.Valid || u.IsActive.Bool
+}
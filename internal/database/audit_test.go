@@ -0,0 +1,92 @@
+package database_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"agromart2/db"
+	"agromart2/internal/database"
+	"agromart2/internal/utils"
+	"github.com/google/uuid"
+)
+
+func TestAuditTrailRecordsChanges(t *testing.T) {
+	f := newRLSFixture(t)
+	ctx := database.WithTenant(context.Background(), f.tenantA.tenant.ID)
+	ctx = database.WithRequestInfo(ctx, database.RequestInfo{
+		UserID:    f.tenantA.user.ID,
+		RequestID: "req-" + uuid.NewString(),
+		IPAddress: "192.0.2.1",
+	})
+
+	if _, err := f.queries.UpdateLocation(ctx, db.UpdateLocationParams{
+		ID:           f.tenantA.location.ID,
+		TenantID:     f.tenantA.tenant.ID,
+		Name:         "renamed",
+		LocationType: f.tenantA.location.LocationType,
+		IsActive:     f.tenantA.location.IsActive,
+	}); err != nil {
+		t.Fatalf("failed to update location: %v", err)
+	}
+
+	info := database.RequestInfoFromContext(ctx)
+	entries, err := f.queries.ListAuditLog(ctx, db.ListAuditLogParams{
+		TenantID:  f.tenantA.tenant.ID,
+		EntityID:  utils.P.UUID(f.tenantA.location.ID),
+		Action:    utils.P.Text("update"),
+		RequestID: utils.P.Text(info.RequestID),
+		Limit:     10,
+	})
+	if err != nil {
+		t.Fatalf("failed to list audit log: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d audit entries for the update, want 1", len(entries))
+	}
+
+	entry := entries[0]
+	if entry.EntityType != "location" || uuid.UUID(entry.ActorUserID.Bytes) != f.tenantA.user.ID || entry.IpAddress.String != info.IPAddress {
+		t.Errorf("entry not attributed to the request: %+v", entry)
+	}
+
+	var before, after map[string]interface{}
+	if err := json.Unmarshal(entry.Before, &before); err != nil {
+		t.Fatalf("failed to decode before: %v", err)
+	}
+	if err := json.Unmarshal(entry.After, &after); err != nil {
+		t.Fatalf("failed to decode after: %v", err)
+	}
+	if before["name"] != f.tenantA.location.Name || after["name"] != "renamed" {
+		t.Errorf("diff = %v -> %v, want the name change", before, after)
+	}
+	if _, ok := after["updated_at"]; ok {
+		t.Error("diff includes updated_at")
+	}
+
+	// The trail is append-only, even for the tenant it belongs to
+	if _, err := f.pool.Exec(ctx, "UPDATE audit_log SET action = 'create' WHERE id = $1", entry.ID); err == nil {
+		t.Error("updating an audit entry succeeded")
+	}
+	if _, err := f.pool.Exec(ctx, "DELETE FROM audit_log WHERE id = $1", entry.ID); err == nil {
+		t.Error("deleting an audit entry succeeded")
+	}
+}
+
+func TestAuditTrailIsTenantScoped(t *testing.T) {
+	f := newRLSFixture(t)
+	ctx := database.WithTenant(context.Background(), f.tenantA.tenant.ID)
+
+	// Creating tenant B's user and location was audited, but under tenant B
+	entries, err := f.queries.ListAuditLog(ctx, db.ListAuditLogParams{
+		TenantID: f.tenantB.tenant.ID,
+		EntityID: utils.P.UUID(f.tenantB.user.ID),
+		Limit:    10,
+	})
+	if err != nil {
+		t.Fatalf("failed to list audit log: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("tenant A saw %d of tenant B's audit entries", len(entries))
+	}
+}
@@ -22,6 +22,9 @@ type Config struct {
 	MaxConnLifetime time.Duration
 	MaxConnIdleTime time.Duration
 	HealthCheckPeriod time.Duration
+	// AppRole is the role connections switch to so row-level security applies; empty keeps
+	// the login role
+	AppRole         string
 }
 
 // DefaultConfig returns a default database configuration
@@ -38,6 +41,7 @@ func DefaultConfig() *Config {
 		MaxConnLifetime:   time.Hour,
 		MaxConnIdleTime:   time.Minute * 30,
 		HealthCheckPeriod: time.Minute * 5,
+		AppRole:           DefaultAppRole,
 	}
 }
 
@@ -67,6 +71,9 @@ func (c *Config) NewPool(ctx context.Context) (*pgxpool.Pool, error) {
 	config.ConnConfig.ConnectTimeout = 10 * time.Second
 	config.ConnConfig.RuntimeParams["application_name"] = "agromart"
 
+	// Scope every connection to the tenant of the context it is acquired with
+	ConfigureTenantIsolation(config, c.AppRole)
+
 	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create connection pool: %w", err)
@@ -84,6 +91,7 @@ func (c *Config) NewPool(ctx context.Context) (*pgxpool.Pool, error) {
 		Str("database", c.Database).
 		Int32("max_conns", c.MaxConns).
 		Int32("min_conns", c.MinConns).
+		Str("app_role", c.AppRole).
 		Msg("database connection pool created")
 
 	return pool, nil
@@ -0,0 +1,31 @@
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type requestInfoKey struct{}
+
+// RequestInfo identifies the request a query runs for. It is written to every connection
+// checked out with the context so the audit triggers can attribute changes.
+type RequestInfo struct {
+	UserID   uuid.UUID
+	APIKeyID uuid.UUID
+	// ImpersonatorID is the platform super admin acting as UserID, if any
+	ImpersonatorID uuid.UUID
+	RequestID      string
+	IPAddress      string
+}
+
+// WithRequestInfo attaches info to ctx, replacing any info already attached
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// RequestInfoFromContext returns the info attached to ctx, or the zero value
+func RequestInfoFromContext(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info
+}
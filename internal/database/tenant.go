@@ -0,0 +1,145 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultAppRole is the role created by the row-level security migration. Connections switch
+// to it so that tenant policies apply even when the pool logs in as a superuser.
+const DefaultAppRole = "agromart_app"
+
+const setSessionSettings = `SELECT
+	set_config('app.tenant_id', $1, false),
+	set_config('app.rls_bypass', $2, false),
+	set_config('app.user_id', $3, false),
+	set_config('app.api_key_id', $4, false),
+	set_config('app.impersonator_id', $5, false),
+	set_config('app.request_id', $6, false),
+	set_config('app.ip_address', $7, false)`
+
+type tenantScopeKey struct{}
+
+// tenantScope is what a connection checked out for a context is allowed to see
+type tenantScope struct {
+	tenantID uuid.UUID
+	system   bool
+}
+
+// WithTenant scopes every query run with the returned context to one tenant's rows
+func WithTenant(ctx context.Context, tenantID uuid.UUID) context.Context {
+	return context.WithValue(ctx, tenantScopeKey{}, tenantScope{tenantID: tenantID})
+}
+
+// WithSystem lets queries run with the returned context see every tenant's rows. It is meant
+// for flows that run before the tenant is known, such as login, token redemption and
+// signup, and must never wrap a request that acts on behalf of a user.
+func WithSystem(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tenantScopeKey{}, tenantScope{system: true})
+}
+
+// TenantFromContext returns the tenant a context is scoped to, if any
+func TenantFromContext(ctx context.Context) (uuid.UUID, bool) {
+	scope, ok := ctx.Value(tenantScopeKey{}).(tenantScope)
+	if !ok || scope.system {
+		return uuid.Nil, false
+	}
+	return scope.tenantID, true
+}
+
+// sessionSettings are the app.* settings a connection carries while it is checked out
+type sessionSettings struct {
+	tenantID       string
+	rlsBypass      string
+	userID         string
+	apiKeyID       string
+	impersonatorID string
+	requestID      string
+	ipAddress      string
+}
+
+// settingsFor returns the settings for a connection acquired with ctx. A context with no
+// tenant scope gets neither app.tenant_id nor app.rls_bypass, so row-level security hides
+// every tenant-owned row from it.
+func settingsFor(ctx context.Context) sessionSettings {
+	var settings sessionSettings
+
+	scope, _ := ctx.Value(tenantScopeKey{}).(tenantScope)
+	switch {
+	case scope.system:
+		settings.rlsBypass = "on"
+	case scope.tenantID != uuid.Nil:
+		settings.tenantID = scope.tenantID.String()
+	}
+
+	info := RequestInfoFromContext(ctx)
+	if info.UserID != uuid.Nil {
+		settings.userID = info.UserID.String()
+	}
+	if info.APIKeyID != uuid.Nil {
+		settings.apiKeyID = info.APIKeyID.String()
+	}
+	if info.ImpersonatorID != uuid.Nil {
+		settings.impersonatorID = info.ImpersonatorID.String()
+	}
+	settings.requestID = info.RequestID
+	settings.ipAddress = info.IPAddress
+	return settings
+}
+
+// ConfigureTenantIsolation installs the pool hooks row-level security and the audit trail
+// rely on: every connection switches to role (unless it is empty) when it is opened, and the
+// tenant scope and request info of the context a connection is acquired with are written to
+// its app.* settings before it is handed out. Transactions inherit the settings of the
+// context passed to Begin.
+func ConfigureTenantIsolation(config *pgxpool.Config, role string) {
+	// The settings last written to each connection, so a checkout for the same scope skips
+	// the round trip
+	var applied sync.Map
+
+	if role != "" {
+		afterConnect := config.AfterConnect
+		config.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+			if afterConnect != nil {
+				if err := afterConnect(ctx, conn); err != nil {
+					return err
+				}
+			}
+			if _, err := conn.Exec(ctx, "SET ROLE "+pgx.Identifier{role}.Sanitize()); err != nil {
+				return fmt.Errorf("failed to set role %s: %w", role, err)
+			}
+			return nil
+		}
+	}
+
+	config.BeforeAcquire = func(ctx context.Context, conn *pgx.Conn) bool {
+		want := settingsFor(ctx)
+
+		if current, ok := applied.Load(conn); ok && current.(sessionSettings) == want {
+			return true
+		}
+
+		if _, err := conn.Exec(ctx, setSessionSettings,
+			want.tenantID, want.rlsBypass, want.userID, want.apiKeyID, want.impersonatorID,
+			want.requestID, want.ipAddress,
+		); err != nil {
+			// Handing out a connection still scoped to another tenant is never acceptable;
+			// returning false makes the pool close it and try another one
+			log.Error().Err(err).Msg("failed to set session settings on connection")
+			applied.Delete(conn)
+			return false
+		}
+		applied.Store(conn, want)
+		return true
+	}
+
+	config.BeforeClose = func(conn *pgx.Conn) {
+		applied.Delete(conn)
+	}
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"math/big"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -23,18 +25,13 @@ func PgUUIDToUUID(pgUUID pgtype.UUID) uuid.UUID {
 
 // IntToPgNumeric converts an int to pgx/v5/pgtype.Numeric
 func IntToPgNumeric(num int) pgtype.Numeric {
-	var n pgtype.Numeric
-	err := n.Scan(num)
-	if err != nil {
-		log.Error().Err(err).Msg("failed to convert int to pgtype.Numeric")
-	}
-	return n
+	return pgtype.Numeric{Int: big.NewInt(int64(num)), Valid: true}
 }
 
 // Float64ToPgNumeric converts a float64 to pgx/v5/pgtype.Numeric
 func Float64ToPgNumeric(num float64) pgtype.Numeric {
 	var n pgtype.Numeric
-	err := n.Scan(num)
+	err := n.ScanScientific(strconv.FormatFloat(num, 'f', -1, 64))
 	if err != nil {
 		log.Error().Err(err).Msg("failed to convert float64 to pgtype.Numeric")
 	}
@@ -54,6 +51,33 @@ func PgNumericToFloat64(n pgtype.Numeric) float64 {
 	return result.Float64
 }
 
+// PgNumericEqual reports whether two pgx/v5/pgtype.Numeric values are exactly equal,
+// regardless of scale, so 1.50 equals 1.5. NULL equals only NULL; NaN and infinities
+// equal only themselves.
+func PgNumericEqual(a, b pgtype.Numeric) bool {
+	if !a.Valid || !b.Valid {
+		return a.Valid == b.Valid
+	}
+	if a.NaN || b.NaN || a.InfinityModifier != pgtype.Finite || b.InfinityModifier != pgtype.Finite {
+		return a.NaN == b.NaN && a.InfinityModifier == b.InfinityModifier
+	}
+	return pgNumericRat(a).Cmp(pgNumericRat(b)) == 0
+}
+
+// pgNumericRat converts a finite pgx/v5/pgtype.Numeric to an exact big.Rat
+func pgNumericRat(n pgtype.Numeric) *big.Rat {
+	r := new(big.Rat)
+	if n.Int == nil {
+		return r
+	}
+	if n.Exp >= 0 {
+		scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n.Exp)), nil)
+		return r.SetInt(new(big.Int).Mul(n.Int, scale))
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(-int64(n.Exp)), nil)
+	return r.SetFrac(n.Int, scale)
+}
+
 // TimeToPgDate converts a time.Time to pgx/v5/pgtype.Date
 func TimeToPgDate(t time.Time) pgtype.Date {
 	return pgtype.Date{
@@ -134,13 +158,7 @@ func NullableIntToPgNumeric(i *int) pgtype.Numeric {
 	if i == nil {
 		return pgtype.Numeric{Valid: false}
 	}
-	var n pgtype.Numeric
-	err := n.Scan(*i)
-	if err != nil {
-		log.Error().Err(err).Msg("failed to convert nullable int to pgtype.Numeric")
-		return pgtype.Numeric{Valid: false}
-	}
-	return n
+	return pgtype.Numeric{Int: big.NewInt(int64(*i)), Valid: true}
 }
 
 // NullableUUIDToPgUUID converts a nullable UUID to pgx/v5/pgtype.UUID
@@ -1,6 +1,9 @@
 package utils
 
 import (
+	"math/big"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
@@ -12,9 +15,7 @@ var P PGX
 
 // Numeric converts int to pgtype.Numeric (for nullable numeric fields)
 func (PGX) Numeric(i int) pgtype.Numeric {
-	var n pgtype.Numeric
-	n.Scan(i)
-	return n
+	return pgtype.Numeric{Int: big.NewInt(int64(i)), Valid: true}
 }
 
 // NumericPtr converts *int to pgtype.Numeric
@@ -22,9 +23,7 @@ func (PGX) NumericPtr(i *int) pgtype.Numeric {
 	if i == nil {
 		return pgtype.Numeric{Valid: false}
 	}
-	var n pgtype.Numeric
-	n.Scan(*i)
-	return n
+	return pgtype.Numeric{Int: big.NewInt(int64(*i)), Valid: true}
 }
 
 // Text converts string to pgtype.Text (for nullable text fields)
@@ -65,3 +64,24 @@ func (PGX) BoolPtr(b *bool) pgtype.Bool {
 	}
 	return pgtype.Bool{Bool: *b, Valid: true}
 }
+
+// DatePtr converts *time.Time to pgtype.Date
+func (PGX) DatePtr(t *time.Time) pgtype.Date {
+	if t == nil {
+		return pgtype.Date{Valid: false}
+	}
+	return pgtype.Date{Time: *t, Valid: true}
+}
+
+// Timestamptz converts time.Time to pgtype.Timestamptz
+func (PGX) Timestamptz(t time.Time) pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: t, Valid: true}
+}
+
+// TimestamptzPtr converts *time.Time to pgtype.Timestamptz
+func (PGX) TimestamptzPtr(t *time.Time) pgtype.Timestamptz {
+	if t == nil {
+		return pgtype.Timestamptz{Valid: false}
+	}
+	return pgtype.Timestamptz{Time: *t, Valid: true}
+}